@@ -1,18 +1,38 @@
 package main
 
 import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
+	"text/tabwriter"
+	"text/template"
+	"time"
 
 	"github.com/brandonhon/hosts-manager/internal/audit"
 	"github.com/brandonhon/hosts-manager/internal/backup"
+	"github.com/brandonhon/hosts-manager/internal/blocklist"
 	"github.com/brandonhon/hosts-manager/internal/config"
+	"github.com/brandonhon/hosts-manager/internal/errors"
 	"github.com/brandonhon/hosts-manager/internal/hosts"
 	"github.com/brandonhon/hosts-manager/internal/tui"
+	"github.com/brandonhon/hosts-manager/internal/watch"
 	"github.com/brandonhon/hosts-manager/pkg/platform"
 
 	"github.com/spf13/cobra"
@@ -20,12 +40,14 @@ import (
 )
 
 func backupCmd() *cobra.Command {
+	var note string
+
 	cmd := &cobra.Command{
 		Use:   "backup",
 		Short: "Create a backup of the hosts file",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			backupMgr := backup.NewManager(cfg)
-			backupPath, err := backupMgr.CreateBackup()
+			backupPath, err := backupMgr.CreateBackup(note)
 			if err != nil {
 				return err
 			}
@@ -35,49 +57,147 @@ func backupCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().StringVar(&note, "note", "", "Label the backup (e.g. pre-firewall-migration); shown by restore --list")
+
+	cmd.AddCommand(backupPruneCmd())
+	cmd.AddCommand(backupVerifyCmd())
+
 	return cmd
 }
 
+func backupPruneCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prune",
+		Short: "Delete backups outside the retention policy",
+		Long: `Delete backups beyond backup.max_backups, and backups older than
+backup.retention_days. This is the same cleanup that runs after every backup.
+Use --dry-run to list what would be deleted and why.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backupMgr := backup.NewManager(cfg)
+			candidates, err := backupMgr.PruneBackups(dryRun)
+			if err != nil {
+				return fmt.Errorf("failed to prune backups: %w", err)
+			}
+
+			if len(candidates) == 0 {
+				fmt.Println("No backups to prune")
+				return nil
+			}
+
+			action := "Deleted"
+			if dryRun {
+				action = "Would delete"
+			}
+			for _, candidate := range candidates {
+				fmt.Printf("%s %s (age %s, %s)\n", action, filepath.Base(candidate.FilePath),
+					formatAge(time.Since(candidate.Timestamp)), pruneReasonDetail(candidate.Reason))
+			}
+			return nil
+		},
+	}
+}
+
+func backupVerifyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify",
+		Short: "Check every backup against the hash recorded when it was created",
+		Long: `Check every backup against the hash recorded when it was created and
+report OK or CORRUPT for each. Backups taken before hashes were recorded are
+reported as UNVERIFIED. Exits with an error if any backup is corrupt.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backupMgr := backup.NewManager(cfg)
+			backups, err := backupMgr.ListBackups()
+			if err != nil {
+				return err
+			}
+
+			if len(backups) == 0 {
+				fmt.Println("No backups found")
+				return nil
+			}
+
+			corrupt := 0
+			for _, info := range backups {
+				name := filepath.Base(info.FilePath)
+				err := backupMgr.VerifyBackupIntegrity(info.FilePath)
+				switch {
+				case err == nil:
+					fmt.Printf("OK          %s\n", name)
+				case stderrors.Is(err, backup.ErrNoStoredHash):
+					fmt.Printf("UNVERIFIED  %s (no recorded hash)\n", name)
+				default:
+					corrupt++
+					fmt.Printf("CORRUPT     %s: %v\n", name, err)
+				}
+			}
+
+			if corrupt > 0 {
+				return fmt.Errorf("%d of %d backups failed verification", corrupt, len(backups))
+			}
+			return nil
+		},
+	}
+}
+
+// pruneReasonDetail names the configured limit a prune candidate exceeds
+func pruneReasonDetail(reason backup.PruneReason) string {
+	switch reason {
+	case backup.PruneExceedsMax:
+		return fmt.Sprintf("%s of %d", reason, cfg.Backup.MaxBackups)
+	case backup.PruneExceedsRetention:
+		return fmt.Sprintf("%s of %d days", reason, cfg.Backup.RetentionDays)
+	default:
+		return string(reason)
+	}
+}
+
+// formatAge renders a duration in the largest whole unit of days, hours or minutes
+func formatAge(age time.Duration) string {
+	switch {
+	case age >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	case age >= time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	}
+}
+
 func restoreCmd() *cobra.Command {
 	var listBackups bool
+	var to string
+	var window backupWindow
 
 	cmd := &cobra.Command{
 		Use:   "restore [backup-file]",
 		Short: "Restore hosts file from backup",
+		Long: `Restore the hosts file from a backup, first backing up the current file.
+
+With --to, the backup is decompressed and decrypted into that file instead,
+leaving the live hosts file untouched, so its contents can be inspected.
+
+--since and --before narrow --list to a time window. Each takes a date
+(2026-09-01), an RFC 3339 time, or an age such as 48h or 7d.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			backupMgr := backup.NewManager(cfg)
 
+			filter, err := window.filter(time.Now())
+			if err != nil {
+				return err
+			}
 			if listBackups {
-				backups, err := backupMgr.ListBackups()
-				if err != nil {
-					return err
-				}
-
-				if len(backups) == 0 {
-					fmt.Println("No backups found")
-					return nil
-				}
-
-				fmt.Println("Available backups:")
-				for i, backup := range backups {
-					fmt.Printf("%d. %s (%s, %s)\n",
-						i+1,
-						filepath.Base(backup.FilePath),
-						backup.Timestamp.Format("2006-01-02 15:04:05"),
-						formatSize(backup.Size))
-				}
-				return nil
+				return printBackupList(backupMgr, filter)
+			}
+			if window.since != "" || window.before != "" {
+				return fmt.Errorf("--since and --before require --list")
 			}
 
 			if len(args) == 0 {
 				return fmt.Errorf("backup file path required. Use --list to see available backups")
 			}
 
-			p := platform.New()
-			if err := p.ElevateIfNeeded(); err != nil {
-				return err
-			}
-
 			userPath := args[0]
 
 			// Validate and secure the backup path
@@ -86,236 +206,2314 @@ func restoreCmd() *cobra.Command {
 				return fmt.Errorf("invalid backup path: %w", err)
 			}
 
+			// The system file is not written, so no elevation is needed
+			if to != "" {
+				if err := ensureSecureDirectories(); err != nil {
+					return fmt.Errorf("failed to initialize secure directories: %w", err)
+				}
+
+				outputPath, err := validateFilePathStrict(to, getAllowedDirectories(), "restore")
+				if err != nil {
+					return fmt.Errorf("restore path validation failed: %w", err)
+				}
+
+				if err := backupMgr.RestoreBackupTo(backupPath, outputPath); err != nil {
+					return err
+				}
+				fmt.Printf("Backup restored to: %s\n", outputPath)
+				return nil
+			}
+
+			p := platform.New()
+			if err := p.ElevateIfNeeded(); err != nil {
+				return err
+			}
+
 			return backupMgr.RestoreBackup(backupPath)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&listBackups, "list", "l", false, "List available backups")
+	cmd.Flags().StringVar(&to, "to", "", "Write the backup's contents to this file instead of the hosts file")
+	window.addFlags(cmd)
 
 	return cmd
 }
 
-func tuiCmd() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "tui",
-		Short: "Start interactive TUI mode",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			p := platform.New()
-			parser := hosts.NewParser(p.GetHostsFilePath())
-			hostsFile, err := parser.Parse()
-			if err != nil {
-				return fmt.Errorf("failed to parse hosts file: %w", err)
-			}
+// backupWindow holds the --since and --before flags that narrow which
+// backups a command lists or picks from
+type backupWindow struct {
+	since  string
+	before string
+}
 
-			return tui.Run(hostsFile, cfg)
-		},
+func (w *backupWindow) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&w.since, "since", "", "Only consider backups taken at or after this date, time, or age (e.g. 48h)")
+	cmd.Flags().StringVar(&w.before, "before", "", "Only consider backups taken before this date, time, or age")
+}
+
+// filter parses the flags with parseTimeBound into a backup filter
+func (w backupWindow) filter(now time.Time) (backup.Filter, error) {
+	var filter backup.Filter
+	var err error
+	if filter.Since, err = parseTimeBound(w.since, now); err != nil {
+		return backup.Filter{}, fmt.Errorf("invalid --since: %w", err)
 	}
+	if filter.Before, err = parseTimeBound(w.before, now); err != nil {
+		return backup.Filter{}, fmt.Errorf("invalid --before: %w", err)
+	}
+	return filter, nil
+}
 
-	return cmd
+// printBackupList prints the backups matching filter numbered from 1,
+// newest first
+func printBackupList(backupMgr *backup.Manager, filter backup.Filter) error {
+	backups, err := backupMgr.ListBackupsFiltered(filter)
+	if err != nil {
+		return err
+	}
+
+	if len(backups) == 0 {
+		fmt.Println("No backups found")
+		return nil
+	}
+
+	fmt.Println("Available backups:")
+	for i, backup := range backups {
+		fmt.Printf("%d. %s (%s, %s)",
+			i+1,
+			filepath.Base(backup.FilePath),
+			backup.Timestamp.Format("2006-01-02 15:04:05"),
+			formatSize(backup.Size))
+		if backup.Note != "" {
+			fmt.Printf(" - %s", backup.Note)
+		}
+		fmt.Println()
+	}
+	return nil
 }
 
-func configCmd() *cobra.Command {
-	var show bool
-	var edit bool
+func diffCmd() *cobra.Command {
+	var listBackups bool
+	var window backupWindow
 
 	cmd := &cobra.Command{
-		Use:   "config",
-		Short: "Manage configuration",
+		Use:   "diff [backup-file|index]",
+		Short: "Show what restoring a backup would change",
+		Long: `Compare the live hosts file with a backup entry by entry and show what
+restoring the backup would change, grouped by category.
+
+The backup can be a path inside the backup directory or an index from
+--list. Without an argument the most recent backup is used. --since and
+--before narrow both the list and the backups an index or the default
+picks from.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if show {
-				data, err := yaml.Marshal(cfg)
-				if err != nil {
-					return err
-				}
-				fmt.Print(string(data))
-				return nil
+			backupMgr := backup.NewManager(cfg)
+
+			filter, err := window.filter(time.Now())
+			if err != nil {
+				return err
+			}
+			if listBackups {
+				return printBackupList(backupMgr, filter)
 			}
 
-			if edit {
-				p := platform.New()
-				configPath := filepath.Join(p.GetConfigDir(), "config.yaml")
-				editor := cfg.General.Editor
+			arg := ""
+			if len(args) > 0 {
+				arg = args[0]
+			}
+			backupPath, err := resolveBackup(backupMgr, arg, filter)
+			if err != nil {
+				return err
+			}
 
-				if editor == "" {
-					editor = "nano"
-				}
+			p := platform.New()
+			current, err := parseHostsFile(p.GetHostsFilePath())
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
 
-				// Validate editor command for security
-				if !isValidEditor(editor) {
-					return fmt.Errorf("editor '%s' is not allowed for security reasons. Allowed editors: nano, vim, vi, emacs, code, notepad", editor)
-				}
+			data, err := backupMgr.ReadBackup(backupPath)
+			if err != nil {
+				return fmt.Errorf("failed to read backup: %w", err)
+			}
+			previous, err := hosts.NewParser(backupPath).ParseReader(bytes.NewReader(data))
+			if err != nil {
+				return fmt.Errorf("failed to parse backup: %w", err)
+			}
 
-				return runCommand(editor, configPath)
+			changes := hosts.DiffHostsFiles(current, previous)
+			if len(changes) == 0 {
+				fmt.Printf("No differences between the hosts file and %s\n", filepath.Base(backupPath))
+				return nil
 			}
 
-			return cmd.Help()
+			fmt.Printf("Restoring %s would make these changes:\n", filepath.Base(backupPath))
+			category := ""
+			for _, change := range changes {
+				if change.Category != category {
+					category = change.Category
+					fmt.Printf("\n=== %s ===\n", category)
+				}
+				fmt.Println(formatEntryChange(change))
+			}
+			return nil
 		},
 	}
 
-	cmd.Flags().BoolVar(&show, "show", false, "Show current configuration")
-	cmd.Flags().BoolVar(&edit, "edit", false, "Edit configuration file")
+	cmd.Flags().BoolVarP(&listBackups, "list", "l", false, "List available backups")
+	window.addFlags(cmd)
 
 	return cmd
 }
 
-func exportCmd() *cobra.Command {
-	var format string
-	var output string
-	var categoryFilter string
+// resolveBackup returns the path of the backup selected by arg: a 1-based
+// index into the backups matching filter as printed by --list, or a path
+// inside the backup directory. An empty arg selects the most recent backup
+// matching filter.
+func resolveBackup(backupMgr *backup.Manager, arg string, filter backup.Filter) (string, error) {
+	index, err := strconv.Atoi(arg)
+	if arg != "" && err != nil {
+		backupPath, err := validateFilePath(arg, cfg.Backup.Directory)
+		if err != nil {
+			return "", fmt.Errorf("invalid backup path: %w", err)
+		}
+		return backupPath, nil
+	}
 
-	cmd := &cobra.Command{
-		Use:   "export",
-		Short: "Export hosts entries",
-		Long: `Export hosts file to different format (json, yaml, hosts).
+	backups, err := backupMgr.ListBackupsFiltered(filter)
+	if err != nil {
+		return "", err
+	}
+	if len(backups) == 0 {
+		return "", fmt.Errorf("no backups found")
+	}
 
-For security, export operations are restricted to these directories:
-• ~/.local/share/hosts-manager/ (data directory)
-• ~/.config/hosts-manager/ (config directory)
-• /tmp/hosts-manager/ (temporary directory)
+	if arg == "" {
+		index = 1
+	}
+	if index < 1 || index > len(backups) {
+		return "", fmt.Errorf("backup index %d out of range (1-%d)", index, len(backups))
+	}
+	return backups[index-1].FilePath, nil
+}
 
-Use relative paths (e.g., 'my-export.json') or paths within these directories.`,
+// formatEntryChange renders a single diff line: + added, - removed, ~ changed
+func formatEntryChange(change hosts.EntryChange) string {
+	switch change.Kind {
+	case hosts.ChangeAdded:
+		return fmt.Sprintf("+ %s %s", change.New.IP, change.Hostname)
+	case hosts.ChangeRemoved:
+		return fmt.Sprintf("- %s %s", change.Old.IP, change.Hostname)
+	case hosts.ChangeIP:
+		return fmt.Sprintf("~ %s: %s -> %s", change.Hostname, change.Old.IP, change.New.IP)
+	case hosts.ChangeMoved:
+		return fmt.Sprintf("~ %s: moved from %s", change.Hostname, change.Old.Category)
+	default:
+		return fmt.Sprintf("~ %s: %s", change.Hostname, change.Kind)
+	}
+}
+
+func rollbackCmd() *cobra.Command {
+	var yes bool
+	var window backupWindow
+
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Undo the last managed change",
+		Long: `Restore the hosts file from the most recent automatic backup, undoing the
+last change made by hosts-manager. The changes are shown before asking for
+confirmation. Each rollback consumes its backup, so running rollback again
+steps further back. --since and --before limit the automatic backups
+considered, e.g. --before 2026-10-01 rolls back to the last change before
+that date.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			p := platform.New()
-			parser := hosts.NewParser(p.GetHostsFilePath())
-			hostsFile, err := parser.Parse()
+			backupMgr := backup.NewManager(cfg)
+
+			filter, err := window.filter(time.Now())
 			if err != nil {
-				return fmt.Errorf("failed to parse hosts file: %w", err)
+				return err
 			}
-
-			if categoryFilter != "" {
-				filteredCategories := []hosts.Category{}
-				for _, category := range hostsFile.Categories {
-					if category.Name == categoryFilter {
-						filteredCategories = append(filteredCategories, category)
-						break
-					}
-				}
-				hostsFile.Categories = filteredCategories
+			latest, err := backupMgr.LatestAutoBackupFiltered(filter)
+			if err != nil {
+				return err
 			}
 
-			var data []byte
-			switch format {
-			case "json":
-				data, err = json.MarshalIndent(hostsFile, "", "  ")
-			case "yaml":
-				data, err = yaml.Marshal(hostsFile)
-			case "hosts":
-				data, err = exportToHosts(hostsFile)
-			default:
-				return fmt.Errorf("unsupported format: %s", format)
+			ok, err := previewRestore(cmd, p, backupMgr, latest, "Rolling back to", "Apply rollback?", yes)
+			if err != nil || !ok {
+				return err
 			}
 
-			if err != nil {
+			if err := p.ElevateIfNeeded(); err != nil {
 				return err
 			}
 
-			if output == "" {
-				fmt.Print(string(data))
-			} else {
-				// Ensure secure directories exist
-				if err := ensureSecureDirectories(); err != nil {
-					return fmt.Errorf("failed to initialize secure directories: %w", err)
-				}
-
-				// Validate output path using secure directory restrictions
-				allowedDirs := getAllowedDirectories()
-				outputPath, err := validateFilePathStrict(output, allowedDirs, "export")
-				if err != nil {
-					return fmt.Errorf("export path validation failed: %w", err)
+			if err := backupMgr.Rollback(latest); err != nil {
+				if logger, logErr := audit.NewLogger(); logErr == nil {
+					logger.LogBackupOperation("rollback", latest.FilePath, false, err.Error())
 				}
+				return fmt.Errorf("failed to roll back: %w", err)
+			}
 
-				if err := os.WriteFile(outputPath, data, 0600); err != nil {
-					return err
-				}
-				fmt.Printf("Exported to: %s\n", outputPath)
+			if logger, err := audit.NewLogger(); err == nil {
+				logger.LogBackupOperation("rollback", latest.FilePath, true, "")
 			}
 
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&format, "format", "f", cfg.Export.DefaultFormat, "Export format (json, yaml, hosts)")
-	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file path")
-	cmd.Flags().StringVarP(&categoryFilter, "category", "c", "", "Export only specific category")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+	window.addFlags(cmd)
 
 	return cmd
 }
 
-func importCmd() *cobra.Command {
-	var format string
-	var merge bool
+func undoCmd() *cobra.Command {
+	var yes bool
+	var window backupWindow
 
 	cmd := &cobra.Command{
-		Use:   "import <file>",
-		Short: "Import hosts entries from file",
-		Long: `Import hosts entries from a file (json or yaml format).
-
-For security, import operations are restricted to these directories:
-• ~/.local/share/hosts-manager/ (data directory)
-• ~/.config/hosts-manager/ (config directory)
-• /tmp/hosts-manager/ (temporary directory)
-
-Use relative paths (e.g., 'my-import.json') or paths within these directories.`,
-		Args: cobra.ExactArgs(1),
+		Use:   "undo",
+		Short: "Restore the most recent backup",
+		Long: `Restore the hosts file from the most recent backup, automatic or manual.
+The changes are shown before asking for confirmation. The current hosts file
+is backed up first, so running undo again reverts the undo. --since and
+--before restore the most recent backup inside that time window instead.`,
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			p := platform.New()
-			if err := p.ElevateIfNeeded(); err != nil {
+			backupMgr := backup.NewManager(cfg)
+
+			filter, err := window.filter(time.Now())
+			if err != nil {
 				return err
 			}
+			backups, err := backupMgr.ListBackupsFiltered(filter)
+			if err != nil {
+				return err
+			}
+			if len(backups) == 0 {
+				fmt.Println("No backups found, nothing to undo")
+				return nil
+			}
+			latest := backups[0]
 
-			userPath := args[0]
-
-			// Ensure secure directories exist
-			if err := ensureSecureDirectories(); err != nil {
-				return fmt.Errorf("failed to initialize secure directories: %w", err)
+			ok, err := previewRestore(cmd, p, backupMgr, latest, "Restoring", "Apply undo?", yes)
+			if err != nil || !ok {
+				return err
+			}
+
+			if err := p.ElevateIfNeeded(); err != nil {
+				return err
+			}
+
+			if err := backupMgr.RestoreBackup(latest.FilePath); err != nil {
+				if logger, logErr := audit.NewLogger(); logErr == nil {
+					logger.LogBackupOperation("undo", latest.FilePath, false, err.Error())
+				}
+				return fmt.Errorf("failed to undo: %w", err)
+			}
+
+			if logger, err := audit.NewLogger(); err == nil {
+				logger.LogBackupOperation("undo", latest.FilePath, true, "")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+	window.addFlags(cmd)
+
+	return cmd
+}
+
+// previewRestore prints the line changes restoring info would make and asks
+// for confirmation unless yes is set. It returns false when the restore
+// should not go ahead: in dry-run mode or when the user declines.
+func previewRestore(cmd *cobra.Command, p *platform.Platform, backupMgr *backup.Manager, info backup.BackupInfo, action, prompt string, yes bool) (bool, error) {
+	current, err := os.ReadFile(p.GetHostsFilePath())
+	if err != nil {
+		return false, fmt.Errorf("failed to read hosts file: %w", err)
+	}
+	previous, err := backupMgr.ReadBackup(info.FilePath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	fmt.Printf("%s %s (%s)\n",
+		action,
+		filepath.Base(info.FilePath),
+		info.Timestamp.Format("2006-01-02 15:04:05"))
+
+	removed, added := lineChanges(current, previous)
+	if len(removed) == 0 && len(added) == 0 {
+		fmt.Println("Hosts file already matches the backup")
+	}
+	for _, line := range removed {
+		fmt.Printf("- %s\n", line)
+	}
+	for _, line := range added {
+		fmt.Printf("+ %s\n", line)
+	}
+
+	if dryRun {
+		return false, nil
+	}
+
+	if !yes {
+		ok, err := confirm(cmd.InOrStdin(), prompt)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			fmt.Println("Cancelled")
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func historyCmd() *cobra.Command {
+	var limit int
+	var eventType string
+	var since time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show recent changes from the audit log",
+		Long: `Show the most recent audit log events, oldest first, as a table of
+timestamp, event type, operation, resource, and outcome.
+
+Use --type to show one event type (e.g. hosts_add, backup_restore) and
+--since to show only events within a duration (e.g. 24h, 30m).`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if limit < 1 {
+				return fmt.Errorf("limit must be at least 1")
+			}
+
+			logger, err := audit.NewLogger()
+			if err != nil {
+				return fmt.Errorf("failed to open audit log: %w", err)
+			}
+
+			// GetRecentEvents reads from the start of the log, so filter then keep the tail
+			all, err := logger.GetRecentEvents(math.MaxInt)
+			if err != nil {
+				return err
+			}
+
+			var cutoff time.Time
+			if since > 0 {
+				cutoff = time.Now().Add(-since)
+			}
+			events := filterEvents(all, audit.EventType(eventType), cutoff)
+			events = events[max(len(events)-limit, 0):]
+
+			if len(events) == 0 {
+				fmt.Println("No matching audit events")
+				return nil
+			}
+			return writeHistory(os.Stdout, events)
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Maximum number of events to show")
+	cmd.Flags().StringVarP(&eventType, "type", "t", "", "Only show events of this type (e.g. hosts_add)")
+	cmd.Flags().DurationVar(&since, "since", 0, "Only show events within this duration (e.g. 24h)")
+
+	return cmd
+}
+
+// filterEvents keeps events of eventType (any type if empty) logged at or
+// after cutoff (any time if zero)
+func filterEvents(events []audit.AuditEvent, eventType audit.EventType, cutoff time.Time) []audit.AuditEvent {
+	var filtered []audit.AuditEvent
+	for _, event := range events {
+		if eventType != "" && event.EventType != eventType {
+			continue
+		}
+		if !cutoff.IsZero() && event.Timestamp.Before(cutoff) {
+			continue
+		}
+		filtered = append(filtered, event)
+	}
+	return filtered
+}
+
+func auditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Query the audit log",
+	}
+
+	cmd.AddCommand(auditExportCmd())
+
+	return cmd
+}
+
+func auditExportCmd() *cobra.Command {
+	var severity, eventType, since, until, result, format string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export audit events, including rotated logs, as JSON or CSV",
+		Long: `Export audit events from the current and rotated audit logs, oldest first.
+
+--severity keeps events at or above a level (info, warning, error, critical).
+--since and --until take a date (2026-09-01), an RFC 3339 time, or an age
+such as 30d or 12h. --result keeps only ok or failed events.`,
+		Example: `  hosts-manager audit export --severity critical --since 30d --format csv
+  hosts-manager audit export --type security_violation --since 2026-09-01 --until 2026-10-01`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter := audit.EventFilter{EventType: audit.EventType(eventType)}
+			if severity != "" {
+				minSeverity, err := audit.ParseSeverity(severity)
+				if err != nil {
+					return err
+				}
+				filter.MinSeverity = minSeverity
+			}
+
+			now := time.Now()
+			var err error
+			if filter.Since, err = parseTimeBound(since, now); err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+			if filter.Until, err = parseTimeBound(until, now); err != nil {
+				return fmt.Errorf("invalid --until: %w", err)
+			}
+
+			switch result {
+			case "":
+			case "ok", "failed":
+				success := result == "ok"
+				filter.Success = &success
+			default:
+				return fmt.Errorf("invalid --result %q (must be ok or failed)", result)
+			}
+
+			logger, err := audit.NewLogger()
+			if err != nil {
+				return fmt.Errorf("failed to open audit log: %w", err)
+			}
+
+			events, err := logger.QueryEvents(filter)
+			if err != nil {
+				return err
+			}
+			return writeAuditEvents(os.Stdout, events, format)
+		},
+	}
+
+	cmd.Flags().StringVar(&severity, "severity", "", "Only export events at or above this severity")
+	cmd.Flags().StringVarP(&eventType, "type", "t", "", "Only export events of this type (e.g. security_violation)")
+	cmd.Flags().StringVar(&since, "since", "", "Only export events at or after this date, time, or age (e.g. 30d)")
+	cmd.Flags().StringVar(&until, "until", "", "Only export events at or before this date, time, or age")
+	cmd.Flags().StringVar(&result, "result", "", "Only export ok or failed events")
+	cmd.Flags().StringVarP(&format, "format", "f", "json", "Output format (json, csv)")
+
+	return cmd
+}
+
+// parseTimeBound parses an RFC 3339 time, a YYYY-MM-DD date in local time, or
+// an age before now written as a Go duration or a number of days (30d).
+// An empty value is the zero time.
+func parseTimeBound(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if age, err := time.ParseDuration(value); err == nil && age >= 0 {
+		return now.Add(-age), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date, RFC 3339 time, or age such as 30d", value)
+}
+
+// writeAuditEvents writes events as an indented JSON array or as CSV with a
+// header row; CSV details are JSON-encoded in a single column
+func writeAuditEvents(w io.Writer, events []audit.AuditEvent, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(events)
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"timestamp", "event_type", "severity", "username", "user_id", "operation", "resource", "success", "error_message", "details"}); err != nil {
+			return err
+		}
+		for _, event := range events {
+			details := ""
+			if len(event.Details) > 0 {
+				data, err := json.Marshal(event.Details)
+				if err != nil {
+					return err
+				}
+				details = string(data)
+			}
+			if err := cw.Write([]string{
+				event.Timestamp.Format(time.RFC3339),
+				string(event.EventType),
+				string(event.Severity),
+				event.Username,
+				strconv.Itoa(event.UserID),
+				event.Operation,
+				event.Resource,
+				strconv.FormatBool(event.Success),
+				event.ErrorMsg,
+				details,
+			}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unsupported audit export format: %s", format)
+	}
+}
+
+// writeHistory renders audit events as an aligned table
+func writeHistory(w io.Writer, events []audit.AuditEvent) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIMESTAMP\tTYPE\tOPERATION\tRESOURCE\tRESULT")
+	for _, event := range events {
+		result := "ok"
+		if !event.Success {
+			result = "FAILED"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			event.Timestamp.Local().Format("2006-01-02 15:04:05"),
+			event.EventType,
+			event.Operation,
+			event.Resource,
+			result)
+	}
+	return tw.Flush()
+}
+
+func statsCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Summarize the hosts file",
+		Long: `Print total, enabled, and disabled entry counts, IPv4 and IPv6 counts,
+the number of duplicated hostnames, the number of hostnames shadowed by an
+earlier entry, and entries per category.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p := platform.New()
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+
+			return writeStats(os.Stdout, hostsFile.Stats(), format)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "table", "Output format (table, json)")
+
+	return cmd
+}
+
+// writeStats renders hosts file statistics as a table or JSON
+func writeStats(w io.Writer, stats hosts.Stats, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	case "table":
+	default:
+		return fmt.Errorf("unsupported stats format: %s", format)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Total entries:\t%d\n", stats.Total)
+	fmt.Fprintf(tw, "Enabled:\t%d\n", stats.Enabled)
+	fmt.Fprintf(tw, "Disabled:\t%d\n", stats.Disabled)
+	fmt.Fprintf(tw, "IPv4 entries:\t%d\n", stats.IPv4)
+	fmt.Fprintf(tw, "IPv6 entries:\t%d\n", stats.IPv6)
+	fmt.Fprintf(tw, "Duplicate hostnames:\t%d\n", stats.DuplicateHostnames)
+	fmt.Fprintf(tw, "Shadowed hostnames:\t%d\n", stats.ShadowedHostnames)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(stats.Categories) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w, "\nEntries per category:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, category := range stats.Categories {
+		fmt.Fprintf(tw, "  %s\t%d\t(%d enabled, %d disabled)\n", category.Name, category.Total, category.Enabled, category.Disabled)
+	}
+	return tw.Flush()
+}
+
+// lineChanges compares two versions of a file line by line, ignoring blank
+// lines and ordering, and returns the lines only in from and only in to
+func lineChanges(from, to []byte) (removed, added []string) {
+	counts := make(map[string]int)
+	for _, line := range strings.Split(string(to), "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			counts[line]++
+		}
+	}
+
+	for _, line := range strings.Split(string(from), "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) == "" {
+			continue
+		}
+		if counts[line] > 0 {
+			counts[line]--
+			continue
+		}
+		removed = append(removed, line)
+	}
+
+	for _, line := range strings.Split(string(to), "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) == "" {
+			continue
+		}
+		if counts[line] > 0 {
+			counts[line]--
+			added = append(added, line)
+		}
+	}
+
+	return removed, added
+}
+
+// confirm asks a yes/no question and reads the answer from in, defaulting to no
+func confirm(in io.Reader, question string) (bool, error) {
+	fmt.Printf("%s [y/N]: ", question)
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+func tuiCmd() *cobra.Command {
+	var watch bool
+	var autoSave bool
+
+	cmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Start interactive TUI mode",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p := platform.New()
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+
+			opts := tui.Options{
+				Force:    force,
+				Watch:    watch,
+				AutoSave: autoSave || cfg.UI.AutoSave,
+				Reload:   parseHostsFile,
+				Copy:     p.CopyToClipboard,
+				NoColor:  !colorEnabled(os.Stdout),
+			}
+			if cfg.General.FlushDNSAfterWrite {
+				opts.AfterSave = flushDNSAfterWrite
+			}
+			opts.FlushDNS = func() error {
+				// Same elevation as flush-dns; a failure is shown in the TUI
+				if p.DNSFlushNeedsElevation() {
+					if err := p.ElevateIfNeeded(); err != nil {
+						return err
+					}
+				}
+				return flushDNSAfterWrite()
+			}
+			if autoBackupEnabled("tui") {
+				backupMgr := backup.NewManager(cfg)
+				opts.Backup = func() error {
+					_, err := backupMgr.CreateAutoBackup()
+					return err
+				}
+			}
+
+			return tui.Run(hostsFile, cfg, opts)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Watch the hosts file and prompt to reload on external changes")
+	cmd.Flags().BoolVar(&autoSave, "autosave", false, "Save the hosts file after every change (also ui.auto_save)")
+
+	return cmd
+}
+
+func configCmd() *cobra.Command {
+	var show bool
+	var edit bool
+
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage configuration",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if show {
+				data, err := yaml.Marshal(cfg)
+				if err != nil {
+					return err
+				}
+				fmt.Print(string(data))
+				return nil
+			}
+
+			if edit {
+				p := platform.New()
+				configPath := filepath.Join(p.GetConfigDir(), "config.yaml")
+				editor := cfg.General.Editor
+
+				if editor == "" {
+					editor = "nano"
+				}
+
+				// Validate editor command for security
+				if !isValidEditor(editor) {
+					return fmt.Errorf("editor '%s' is not allowed for security reasons. Allowed editors: nano, vim, vi, emacs, code, notepad", editor)
+				}
+
+				return runCommand(editor, configPath)
+			}
+
+			return cmd.Help()
+		},
+	}
+
+	cmd.Flags().BoolVar(&show, "show", false, "Show current configuration")
+	cmd.Flags().BoolVar(&edit, "edit", false, "Edit configuration file")
+
+	cmd.AddCommand(configGetCmd())
+	cmd.AddCommand(configSetCmd())
+
+	return cmd
+}
+
+func configGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print a configuration value",
+		Long: `Print the configuration value at a dotted key, using the names from the
+config file, e.g. general.default_category or backup.max_backups.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			value, err := cfg.Get(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(value)
+			return nil
+		},
+	}
+}
+
+func configSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Change a configuration value",
+		Long: `Set the configuration value at a dotted key and save the config file.
+
+The value must match the key's type; lists take comma-separated values. The
+whole configuration is validated before it is saved.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, value := args[0], args[1]
+			if err := cfg.Set(key, value); err != nil {
+				return err
+			}
+			if err := config.NewValidator().Validate(cfg); err != nil {
+				return fmt.Errorf("invalid value for %s: %w", key, err)
+			}
+
+			if dryRun {
+				fmt.Printf("Would set %s = %s\n", key, value)
+				return nil
+			}
+
+			if err := config.Save(cfg); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+			if logger, err := audit.NewLogger(); err == nil {
+				_ = logger.Log(audit.AuditEvent{
+					EventType: audit.EventConfigEdit,
+					Severity:  audit.SeverityInfo,
+					Operation: "config set",
+					Resource:  key,
+					Success:   true,
+				})
+			}
+
+			fmt.Printf("Set %s = %s\n", key, value)
+			return nil
+		},
+	}
+}
+
+func exportCmd() *cobra.Command {
+	var format string
+	var output string
+	var categoryFilter string
+	var enabledOnly bool
+	var profileName string
+	var bundle string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export hosts entries",
+		Long: `Export hosts file to different format (json, yaml, hosts, dnsmasq).
+
+The dnsmasq format writes an address=/hostname/ip line for every hostname of
+the enabled entries, ready to be included in a dnsmasq configuration.
+
+Any other --format names a format defined under export.formats in the
+config, whose Go text/template is rendered with the hosts file's
+categories and entries, e.g. to write dnsmasq configs or Ansible inventories.
+
+For security, export operations are restricted to these directories:
+• ~/.local/share/hosts-manager/ (data directory)
+• ~/.config/hosts-manager/ (config directory)
+• /tmp/hosts-manager/ (temporary directory)
+
+Use relative paths (e.g., 'my-export.json') or paths within these directories.
+
+With --bundle, the configuration and a YAML export of the hosts file are
+packed into one .tar.gz, which "import --bundle" restores on another machine.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p := platform.New()
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+
+			if profileName != "" {
+				profile, exists := cfg.Profiles[profileName]
+				if !exists {
+					return fmt.Errorf("profile not found: %s", profileName)
+				}
+				// Only the in-memory copy changes; the live file is untouched
+				applyProfile(hostsFile, profile)
+				enabledOnly = true
+			}
+
+			if categoryFilter != "" {
+				filteredCategories := []hosts.Category{}
+				for _, category := range hostsFile.Categories {
+					if category.Name == categoryFilter {
+						filteredCategories = append(filteredCategories, category)
+						break
+					}
+				}
+				hostsFile.Categories = filteredCategories
+			}
+
+			if enabledOnly {
+				filterEnabled(hostsFile)
+			}
+
+			if bundle != "" {
+				return exportBundle(p, hostsFile, bundle)
+			}
+
+			var data []byte
+			switch format {
+			case "json":
+				data, err = json.MarshalIndent(hostsFile, "", "  ")
+			case "yaml":
+				data, err = yaml.Marshal(hostsFile)
+			case "hosts":
+				var style hosts.BannerStyle
+				if style, err = hosts.ParseBannerStyle(cfg.General.BannerStyle); err == nil {
+					data, err = exportToHosts(hostsFile, style)
+				}
+			case "dnsmasq":
+				data = exportToDnsmasq(hostsFile)
+			default:
+				custom, ok := cfg.Export.Formats[format]
+				if !ok {
+					return fmt.Errorf("unsupported format: %s", format)
+				}
+				data, err = exportToTemplate(hostsFile, format, custom)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				fmt.Print(string(data))
+			} else {
+				// Ensure secure directories exist
+				if err := ensureSecureDirectories(); err != nil {
+					return fmt.Errorf("failed to initialize secure directories: %w", err)
+				}
+
+				// Validate output path using secure directory restrictions
+				allowedDirs := getAllowedDirectories()
+				outputPath, err := validateFilePathStrict(output, allowedDirs, "export")
+				if err != nil {
+					return fmt.Errorf("export path validation failed: %w", err)
+				}
+
+				if err := os.WriteFile(outputPath, data, 0600); err != nil {
+					return err
+				}
+				fmt.Printf("Exported to: %s\n", outputPath)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", cfg.Export.DefaultFormat, "Export format (json, yaml, hosts, dnsmasq, or a name from export.formats)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file path")
+	cmd.Flags().StringVarP(&categoryFilter, "category", "c", "", "Export only specific category")
+	cmd.Flags().BoolVar(&enabledOnly, "enabled-only", false, "Export only enabled categories and entries")
+	cmd.Flags().StringVar(&profileName, "profile", "", "Export what the named profile would enable (implies --enabled-only)")
+	cmd.Flags().StringVar(&bundle, "bundle", "", "Write the config and hosts entries to this .tar.gz bundle")
+	cmd.MarkFlagsMutuallyExclusive("bundle", "output")
+	cmd.MarkFlagsMutuallyExclusive("bundle", "format")
+
+	return cmd
+}
+
+const (
+	bundleConfigName = "config.yaml"
+	bundleHostsName  = "hosts.yaml"
+	// maxBundleMemberSize bounds each file read from a bundle
+	maxBundleMemberSize = 64 * 1024 * 1024
+)
+
+// exportBundle writes the config file and hostsFile as YAML to a bundle at
+// path, which must be inside the allowed directories
+func exportBundle(p *platform.Platform, hostsFile *hosts.HostsFile, path string) error {
+	if err := ensureSecureDirectories(); err != nil {
+		return fmt.Errorf("failed to initialize secure directories: %w", err)
+	}
+	bundlePath, err := validateFilePathStrict(path, getAllowedDirectories(), "export")
+	if err != nil {
+		return fmt.Errorf("export path validation failed: %w", err)
+	}
+
+	// Bundle the file as written rather than cfg, which includes
+	// environment overrides and the auto-detected backup directory
+	configData, err := os.ReadFile(filepath.Join(p.GetConfigDir(), "config.yaml"))
+	if os.IsNotExist(err) {
+		configData, err = yaml.Marshal(cfg)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	hostsData, err := yaml.Marshal(hostsFile)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := writeBundle(&buf, configData, hostsData); err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	if err := os.WriteFile(bundlePath, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	fmt.Printf("Exported config and %d categories to: %s\n", len(hostsFile.Categories), bundlePath)
+	return nil
+}
+
+// writeBundle writes a gzip-compressed tar holding the config and hosts YAML
+func writeBundle(w io.Writer, configData, hostsData []byte) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	now := time.Now()
+	for _, member := range []struct {
+		name string
+		data []byte
+	}{
+		{bundleConfigName, configData},
+		{bundleHostsName, hostsData},
+	} {
+		header := &tar.Header{
+			Name:    member.name,
+			Mode:    0600,
+			Size:    int64(len(member.data)),
+			ModTime: now,
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tarWriter.Write(member.data); err != nil {
+			return err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// readBundle returns the config and hosts YAML from a bundle written by
+// writeBundle. Other members are ignored; both files must be present.
+func readBundle(r io.Reader) (configData, hostsData []byte, err error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("not a bundle: %w", err)
+	}
+	defer func() { _ = gzipReader.Close() }()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || (header.Name != bundleConfigName && header.Name != bundleHostsName) {
+			continue
+		}
+		if header.Size > maxBundleMemberSize {
+			return nil, nil, fmt.Errorf("bundle member %s is too large: %d bytes", header.Name, header.Size)
+		}
+
+		data, err := io.ReadAll(io.LimitReader(tarReader, maxBundleMemberSize))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s from bundle: %w", header.Name, err)
+		}
+		if header.Name == bundleConfigName {
+			configData = data
+		} else {
+			hostsData = data
+		}
+	}
+
+	if configData == nil || hostsData == nil {
+		return nil, nil, fmt.Errorf("bundle must contain %s and %s", bundleConfigName, bundleHostsName)
+	}
+	return configData, hostsData, nil
+}
+
+// parseBundledConfig decodes a bundled config over the defaults, as Load
+// does, and validates it
+func parseBundledConfig(data []byte) (*config.Config, error) {
+	bundled := config.DefaultConfig()
+	if err := yaml.Unmarshal(data, bundled); err != nil {
+		return nil, fmt.Errorf("failed to parse bundled config: %w", err)
+	}
+	if err := config.NewValidator().Validate(bundled); err != nil {
+		return nil, fmt.Errorf("bundled config is invalid: %w", err)
+	}
+	return bundled, nil
+}
+
+// backupConfigFile copies the current config file next to itself with a
+// timestamped .bak suffix and returns the copy's path, or "" if there is no
+// config file yet
+func backupConfigFile(p *platform.Platform) (string, error) {
+	configPath := filepath.Join(p.GetConfigDir(), "config.yaml")
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	backupPath := fmt.Sprintf("%s.%s.bak", configPath, time.Now().Format("2006-01-02T15-04-05"))
+	if err := os.WriteFile(backupPath, data, 0600); err != nil {
+		return "", err
+	}
+	return backupPath, nil
+}
+
+func importCmd() *cobra.Command {
+	var format string
+	var merge bool
+	var strategyName string
+	var lenientHostnames, strictHostnames bool
+	var bundle string
+	var skipInvalid bool
+
+	cmd := &cobra.Command{
+		Use:   "import [file]",
+		Short: "Import hosts entries from file",
+		Long: `Import hosts entries from a file (json or yaml format).
+
+For security, import operations are restricted to these directories:
+• ~/.local/share/hosts-manager/ (data directory)
+• ~/.config/hosts-manager/ (config directory)
+• /tmp/hosts-manager/ (temporary directory)
+
+Use relative paths (e.g., 'my-import.json') or paths within these directories.
+
+With --merge, --strategy decides what happens when an imported entry maps a
+hostname to a different IP than the current file:
+• keep-both: add the entry anyway, leaving both mappings (default)
+• prefer-base: keep the current mapping and skip the imported hostname
+• prefer-incoming: remove the hostname from the current entries
+• fail-on-conflict: import nothing and list the conflicts
+
+With --bundle, a bundle written by "export --bundle" restores both the hosts
+entries and the configuration. The bundled config is validated first, and
+the current config file is kept as config.yaml.<timestamp>.bak.
+
+Every imported entry is validated before anything is written, and import
+ends with a summary of entries added, skipped as duplicates and rejected as
+invalid. Any invalid entry fails the import unless --skip-invalid is given.
+Large imports show their progress when stderr is a terminal.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if bundle != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			strategy, err := hosts.ParseMergeStrategy(strategyName)
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("strategy") && !merge {
+				return fmt.Errorf("--strategy requires --merge")
+			}
+
+			p := platform.New()
+			if err := p.ElevateIfNeeded(); err != nil {
+				return err
+			}
+
+			userPath := bundle
+			if userPath == "" {
+				userPath = args[0]
+			}
+
+			// Ensure secure directories exist
+			if err := ensureSecureDirectories(); err != nil {
+				return fmt.Errorf("failed to initialize secure directories: %w", err)
 			}
 
 			// Validate import file path using secure directory restrictions
 			allowedDirs := getAllowedDirectories()
 			filePath, err := validateFilePathStrict(userPath, allowedDirs, "import")
 			if err != nil {
-				return fmt.Errorf("import path validation failed: %w", err)
+				return fmt.Errorf("import path validation failed: %w", err)
+			}
+
+			data, err := os.ReadFile(filePath)
+			if err != nil {
+				return fmt.Errorf("failed to read import file: %w", err)
+			}
+
+			var bundledConfig *config.Config
+			if bundle != "" {
+				var configData []byte
+				if configData, data, err = readBundle(bytes.NewReader(data)); err != nil {
+					return err
+				}
+				if bundledConfig, err = parseBundledConfig(configData); err != nil {
+					return err
+				}
+				format = "yaml"
+			}
+
+			var importedHosts *hosts.HostsFile
+			switch format {
+			case "json":
+				err = json.Unmarshal(data, &importedHosts)
+			case "yaml":
+				err = yaml.Unmarshal(data, &importedHosts)
+			default:
+				return fmt.Errorf("unsupported import format: %s", format)
+			}
+
+			if err != nil {
+				return fmt.Errorf("failed to parse import file: %w", err)
+			}
+
+			mode := resolveHostnameMode(lenientHostnames, hosts.HostnameLenient, strictHostnames)
+
+			var currentHosts *hosts.HostsFile
+			var preview []hosts.MergeResult
+			if merge {
+				currentHosts, err = parseHostsFile(p.GetHostsFilePath())
+				if err != nil {
+					return fmt.Errorf("failed to parse current hosts file: %w", err)
+				}
+				if dryRun && importedHosts != nil {
+					// Classify against the file as it is before the merge
+					preview = currentHosts.CompareForMerge(importedEntries(importedHosts))
+				}
+			}
+
+			var progress func(done, total int)
+			if importedHosts != nil {
+				progress = importProgress(os.Stderr, len(importedEntries(importedHosts)))
+			}
+			importedHosts, conflicts, summary, err := prepareImport(importedHosts, currentHosts, importOptions{
+				mode:        mode,
+				strategy:    strategy,
+				skipInvalid: skipInvalid,
+				progress:    progress,
+			})
+			if len(summary.Rejected) > 0 {
+				writeRejectedEntries(os.Stderr, summary.Rejected)
+			}
+			if stderrors.Is(err, hosts.ErrMergeConflict) {
+				writeMergeConflicts(os.Stderr, conflicts)
+			}
+			if err != nil {
+				return err
+			}
+
+			if dryRun && bundledConfig != nil {
+				fmt.Println("Would restore the bundled config")
+			}
+			if dryRun && merge {
+				writeMergePreview(os.Stdout, preview)
+				return nil
+			}
+			if dryRun {
+				fmt.Printf("Would import %d categories with entries\n", len(importedHosts.Categories))
+				for _, category := range importedHosts.Categories {
+					fmt.Printf("  %s: %d entries\n", category.Name, len(category.Entries))
+				}
+				return nil
+			}
+
+			backupMgr := backup.NewManager(cfg)
+			if autoBackupEnabled("import") {
+				if _, err := backupMgr.CreateAutoBackup(); err != nil {
+					return fmt.Errorf("failed to create backup: %w", err)
+				}
+				if verbose {
+					fmt.Println("Backup created successfully")
+				}
+			}
+
+			if err := writeHostsFile(importedHosts, p.GetHostsFilePath()); err != nil {
+				return fmt.Errorf("failed to write hosts file: %w", err)
+			}
+
+			if len(conflicts) > 0 && strategy != hosts.MergeKeepBoth {
+				fmt.Printf("Resolved %d conflicts with %s\n", len(conflicts), strategy)
+			}
+			fmt.Printf("Successfully imported %d categories\n", len(importedHosts.Categories))
+			writeImportSummary(os.Stdout, summary)
+
+			if bundledConfig != nil {
+				configBackup, err := backupConfigFile(p)
+				if err != nil {
+					return fmt.Errorf("failed to back up config: %w", err)
+				}
+				if err := config.Save(bundledConfig); err != nil {
+					return fmt.Errorf("failed to restore config: %w", err)
+				}
+				if configBackup != "" {
+					fmt.Printf("Previous config saved to: %s\n", configBackup)
+				}
+				fmt.Println("Restored config from bundle")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "yaml", "Import format (json, yaml)")
+	cmd.Flags().BoolVarP(&merge, "merge", "m", false, "Merge with existing entries")
+	cmd.Flags().StringVar(&bundle, "bundle", "", "Restore the config and hosts entries from a bundle written by export --bundle")
+	cmd.MarkFlagsMutuallyExclusive("bundle", "format")
+	cmd.Flags().StringVar(&strategyName, "strategy", string(hosts.MergeKeepBoth), "Conflict strategy for --merge (keep-both, prefer-base, prefer-incoming, fail-on-conflict)")
+	cmd.Flags().BoolVar(&lenientHostnames, "lenient-hostnames", false, "Accept underscores and skip homograph checks in hostnames (logged as warnings)")
+	cmd.Flags().BoolVar(&strictHostnames, "strict-hostnames", false, "Reject underscores in hostnames even if allow_underscore_hostnames is set")
+	cmd.MarkFlagsMutuallyExclusive("lenient-hostnames", "strict-hostnames")
+	cmd.Flags().BoolVar(&skipInvalid, "skip-invalid", false, "Import the valid entries and skip invalid ones instead of failing")
+
+	return cmd
+}
+
+// importedEntries flattens an imported file, filling in each entry's category
+func importedEntries(imported *hosts.HostsFile) []hosts.Entry {
+	var entries []hosts.Entry
+	for _, category := range imported.Categories {
+		for _, entry := range category.Entries {
+			if entry.Category == "" {
+				entry.Category = category.Name
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// writeMergePreview prints which imported entries are new, already present,
+// or conflict with a hostname mapped to a different IP
+func writeMergePreview(w io.Writer, results []hosts.MergeResult) {
+	buckets := []struct {
+		status hosts.MergeStatus
+		title  string
+		marker string
+	}{
+		{hosts.MergeNew, "New", "+"},
+		{hosts.MergeExisting, "Already present", "="},
+		{hosts.MergeConflict, "Conflicts (hostname mapped to a different IP)", "!"},
+	}
+
+	_, _ = fmt.Fprintf(w, "Would merge %d entries\n", len(results))
+	for _, bucket := range buckets {
+		var lines []string
+		for _, result := range results {
+			if result.Status != bucket.status {
+				continue
+			}
+			line := fmt.Sprintf("  %s %s", bucket.marker, describeEntry(result.Entry))
+			if len(result.ConflictIPs) > 0 {
+				line += fmt.Sprintf(" (current: %s)", strings.Join(result.ConflictIPs, ", "))
+			}
+			lines = append(lines, line)
+		}
+
+		_, _ = fmt.Fprintf(w, "\n%s (%d):\n", bucket.title, len(lines))
+		for _, line := range lines {
+			_, _ = fmt.Fprintln(w, line)
+		}
+	}
+}
+
+// writeMergeConflicts lists hostnames that an import maps to a different IP
+// than the current file
+func writeMergeConflicts(w io.Writer, conflicts []hosts.Conflict) {
+	_, _ = fmt.Fprintf(w, "Conflicts (%d):\n", len(conflicts))
+	for _, conflict := range conflicts {
+		_, _ = fmt.Fprintf(w, "  ! %s: %s (current: %s)\n", conflict.Hostname, conflict.Incoming.IP, conflict.Base.IP)
+	}
+}
+
+// importBatchSize is how many imported entries are validated between
+// progress updates
+const importBatchSize = 1000
+
+// maxListedRejections caps how many rejected entries import prints
+const maxListedRejections = 10
+
+// importOptions controls how prepareImport validates and merges entries
+type importOptions struct {
+	mode        hosts.HostnameValidationMode
+	strategy    hosts.MergeStrategy
+	skipInvalid bool
+	// progress, when set, is called after each batch of entries
+	progress func(done, total int)
+}
+
+// prepareImport validates every imported entry under opts.mode, skipping
+// entries repeated within the import. Invalid entries fail the import unless
+// opts.skipInvalid is set. When current is non-nil the imported entries are
+// merged into it under opts.strategy, entries it already declares are
+// skipped as duplicates, and the merged file and any conflicts are returned.
+func prepareImport(imported, current *hosts.HostsFile, opts importOptions) (*hosts.HostsFile, []hosts.Conflict, hosts.AddSummary, error) {
+	if imported == nil {
+		return nil, nil, hosts.AddSummary{}, fmt.Errorf("import file contains no hosts data")
+	}
+
+	staged, summary := stageImport(imported, opts.mode, opts.progress)
+	if len(summary.Rejected) > 0 && !opts.skipInvalid {
+		return nil, nil, summary, fmt.Errorf("import has %d invalid entries (use --skip-invalid to import the rest)", len(summary.Rejected))
+	}
+	if current == nil {
+		return staged, nil, summary, nil
+	}
+
+	results := current.CompareForMerge(importedEntries(staged))
+	i := 0
+	for c := range staged.Categories {
+		category := &staged.Categories[c]
+		entries := category.Entries[:0]
+		for _, entry := range category.Entries {
+			if results[i].Status == hosts.MergeExisting {
+				summary.Added--
+				summary.Duplicates++
+			} else {
+				entries = append(entries, entry)
+			}
+			i++
+		}
+		category.Entries = entries
+	}
+
+	merged, conflicts, err := hosts.MergeFilesWithMode(current, staged, opts.strategy, opts.mode)
+	return merged, conflicts, summary, err
+}
+
+// stageImport validates the imported entries and collects them into a new
+// file with the same categories, in batches of importBatchSize so progress
+// can be reported while large files are checked
+func stageImport(imported *hosts.HostsFile, mode hosts.HostnameValidationMode, progress func(done, total int)) (*hosts.HostsFile, hosts.AddSummary) {
+	staged := &hosts.HostsFile{
+		Header:   imported.Header,
+		Footer:   imported.Footer,
+		Modified: imported.Modified,
+		FilePath: imported.FilePath,
+	}
+	for _, category := range imported.Categories {
+		staged.Categories = append(staged.Categories, hosts.Category{
+			Name:        category.Name,
+			Description: category.Description,
+			Enabled:     category.Enabled,
+		})
+	}
+
+	entries := importedEntries(imported)
+	var summary hosts.AddSummary
+	for start := 0; start < len(entries); start += importBatchSize {
+		end := min(start+importBatchSize, len(entries))
+		batch := staged.AddEntriesWithMode(entries[start:end], mode)
+		summary.Added += batch.Added
+		summary.Duplicates += batch.Duplicates
+		summary.Rejected = append(summary.Rejected, batch.Rejected...)
+		if progress != nil {
+			progress(end, len(entries))
+		}
+	}
+	return staged, summary
+}
+
+// importProgress returns a progress callback that redraws an entry counter
+// on w, or nil when w is not a terminal or the import fits in one batch
+func importProgress(w *os.File, total int) func(done, total int) {
+	if total <= importBatchSize || !isTerminal(w) {
+		return nil
+	}
+	return func(done, total int) {
+		_, _ = fmt.Fprintf(w, "\rValidating entries: %d/%d", done, total)
+		if done == total {
+			_, _ = fmt.Fprintln(w)
+		}
+	}
+}
+
+// writeRejectedEntries lists the first maxListedRejections rejected entries
+// and why they were rejected
+func writeRejectedEntries(w io.Writer, rejected []hosts.RejectedEntry) {
+	_, _ = fmt.Fprintf(w, "Rejected entries (%d):\n", len(rejected))
+	for i, rejection := range rejected {
+		if i == maxListedRejections {
+			_, _ = fmt.Fprintf(w, "  ... and %d more\n", len(rejected)-i)
+			break
+		}
+		_, _ = fmt.Fprintf(w, "  ! %s %s: %v\n", rejection.Entry.IP, strings.Join(rejection.Entry.Hostnames, " "), rejection.Err)
+	}
+}
+
+// writeImportSummary prints what happened to each imported entry
+func writeImportSummary(w io.Writer, summary hosts.AddSummary) {
+	_, _ = fmt.Fprintf(w, "%d added, %d skipped as duplicates, %d rejected as invalid\n",
+		summary.Added, summary.Duplicates, len(summary.Rejected))
+}
+
+func commentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "comment <line>",
+		Short: "Disable the entry on a specific line",
+		Long: `Comment out (disable) the entry on the given line of the hosts file.
+Unlike disable, this targets one exact line even when its hostname is
+declared elsewhere too. Line numbers are shown by "list --line-numbers".`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return toggleLine(args[0], false)
+		},
+	}
+
+	return cmd
+}
+
+func uncommentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "uncomment <line>",
+		Short: "Enable the entry on a specific line",
+		Long: `Uncomment (enable) the entry on the given line of the hosts file.
+Unlike enable, this targets one exact line even when its hostname is
+declared elsewhere too.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return toggleLine(args[0], true)
+		},
+	}
+
+	return cmd
+}
+
+func toggleLine(lineArg string, enable bool) error {
+	lineNum, err := strconv.Atoi(lineArg)
+	if err != nil || lineNum <= 0 {
+		return fmt.Errorf("invalid line number: %s", lineArg)
+	}
+
+	p := platform.New()
+	if err := p.ElevateIfNeeded(); err != nil {
+		return err
+	}
+
+	hostsFile, err := parseHostsFile(p.GetHostsFilePath())
+	if err != nil {
+		return fmt.Errorf("failed to parse hosts file: %w", err)
+	}
+
+	entry, err := setLineEnabled(hostsFile, lineNum, enable)
+	if err != nil {
+		return err
+	}
+
+	action := "comment"
+	if enable {
+		action = "uncomment"
+	}
+
+	if dryRun {
+		fmt.Printf("Would %s line %d: %s\n", action, lineNum, describeEntry(entry))
+		return nil
+	}
+
+	backupMgr := backup.NewManager(cfg)
+	if autoBackupEnabled(toggleOperation(enable)) {
+		if _, err := backupMgr.CreateAutoBackup(); err != nil {
+			return fmt.Errorf("failed to create backup: %w", err)
+		}
+		if verbose {
+			fmt.Println("Backup created successfully")
+		}
+	}
+
+	operation := toggleOperation(enable)
+
+	if err := writeHostsFile(hostsFile, p.GetHostsFilePath()); err != nil {
+		if logger, logErr := audit.NewLogger(); logErr == nil {
+			logger.LogHostsOperation(operation, entry.IP, entry.Hostnames, false, err.Error())
+		}
+		return fmt.Errorf("failed to write hosts file: %w", err)
+	}
+
+	if logger, err := audit.NewLogger(); err == nil {
+		logger.LogHostsOperation(operation, entry.IP, entry.Hostnames, true, "")
+	}
+
+	fmt.Printf("%sed line %d: %s\n", strings.ToUpper(action[:1])+action[1:], lineNum, describeEntry(entry))
+	return nil
+}
+
+// setLineEnabled sets the enabled state of the entry parsed from lineNum and
+// returns the updated entry. Read-only entries are refused unless --force is set.
+func setLineEnabled(hostsFile *hosts.HostsFile, lineNum int, enable bool) (hosts.Entry, error) {
+	entry := hostsFile.FindEntryByLine(lineNum)
+	if entry == nil {
+		return hosts.Entry{}, fmt.Errorf("no entry on line %d", lineNum)
+	}
+	if entry.ReadOnly && !force {
+		return hosts.Entry{}, fmt.Errorf("line %d is protected by %s; use --force to modify it", lineNum, hosts.IgnoreFileName)
+	}
+
+	entry.Enabled = enable
+	return *entry, nil
+}
+
+func moveCmd() *cobra.Command {
+	var create bool
+
+	cmd := &cobra.Command{
+		Use:   "move <hostname> <target-category>",
+		Short: "Move an entry to another category",
+		Long: `Move the entry declaring hostname to another category, as the TUI's
+move action does. The target category must exist unless --create is set.
+If the hostname is declared by more than one entry, the candidates are listed
+and nothing is changed.`,
+		Args: cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 1 {
+				return completeCategories(cmd, args, toComplete)
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hostname, target := args[0], args[1]
+
+			p := platform.New()
+			if err := p.ElevateIfNeeded(); err != nil {
+				return err
+			}
+
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+
+			if err := checkReadOnly(hostsFile, hostname); err != nil {
+				return err
+			}
+
+			before, err := moveHostname(hostsFile, hostname, target, create)
+			if err != nil {
+				return err
+			}
+			if before.Category == target {
+				fmt.Printf("%s is already in category %s\n", hostname, target)
+				return nil
+			}
+
+			if dryRun {
+				fmt.Printf("Would move %s from %s to %s\n", hostname, before.Category, target)
+				return nil
+			}
+
+			backupMgr := backup.NewManager(cfg)
+			if autoBackupEnabled("move") {
+				if _, err := backupMgr.CreateAutoBackup(); err != nil {
+					return fmt.Errorf("failed to create backup: %w", err)
+				}
+				if verbose {
+					fmt.Println("Backup created successfully")
+				}
+			}
+
+			if err := writeHostsFile(hostsFile, p.GetHostsFilePath()); err != nil {
+				if logger, logErr := audit.NewLogger(); logErr == nil {
+					logger.LogHostsOperation("move", before.IP, before.Hostnames, false, err.Error())
+				}
+				return fmt.Errorf("failed to write hosts file: %w", err)
+			}
+
+			if logger, err := audit.NewLogger(); err == nil {
+				logger.LogHostsOperation("move", before.IP, before.Hostnames, true, "")
+			}
+
+			fmt.Printf("Moved %s from %s to %s\n", hostname, before.Category, target)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&create, "create", false, "Create the target category if it does not exist")
+
+	return cmd
+}
+
+// moveHostname moves the single entry declaring hostname into target and
+// returns the entry as it was before the move
+func moveHostname(hostsFile *hosts.HostsFile, hostname, target string, create bool) (hosts.Entry, error) {
+	matches := hostsFile.FindEntryByHostname(hostname)
+	if len(matches) == 0 {
+		return hosts.Entry{}, fmt.Errorf("hostname not found: %s", hostname)
+	}
+	if len(matches) > 1 {
+		return hosts.Entry{}, ambiguousHostnameError(hostname, matches)
+	}
+
+	if hostsFile.GetCategory(target) == nil {
+		if !create {
+			return hosts.Entry{}, fmt.Errorf("category not found: %s (use --create to create it)", target)
+		}
+		if err := hostsFile.AddCategory(target, ""); err != nil {
+			return hosts.Entry{}, fmt.Errorf("failed to create category: %w", err)
+		}
+	}
+
+	before := *matches[0]
+	if err := hostsFile.MoveEntry(matches[0], target); err != nil {
+		return before, fmt.Errorf("failed to move entry: %w", err)
+	}
+	return before, nil
+}
+
+func syncCmd() *cobra.Command {
+	var urls []string
+	var category string
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Import hostnames from remote blocklists",
+		Long: `Download hosts-format blocklists (such as StevenBlack/hosts) and import
+every hostname mapped to 0.0.0.0 or 127.0.0.1 into a dedicated category.
+
+URLs come from --url or the blocklists.urls config setting. Hostnames already
+declared in other categories are skipped as duplicates, and hostnames that fail
+validation are rejected and listed. Each list's ETag and Last-Modified
+headers are remembered so unchanged lists are not re-applied. If any download
+fails, the hosts file is left untouched.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(urls) == 0 {
+				urls = cfg.Blocklists.URLs
+			}
+			if len(urls) == 0 {
+				return fmt.Errorf("no blocklist URLs configured; pass --url or set blocklists.urls in the config")
+			}
+			if category == "" {
+				category = cfg.Blocklists.Category
+			}
+			if category == "" {
+				category = "blocklist"
+			}
+
+			p := platform.New()
+			if err := p.ElevateIfNeeded(); err != nil {
+				return err
+			}
+
+			statePath := filepath.Join(p.GetConfigDir(), blocklist.StateFileName)
+			state, err := blocklist.LoadState(statePath)
+			if err != nil {
+				return err
+			}
+
+			fetcher := blocklist.NewFetcher(timeout)
+			next, hostnames, changed, err := fetcher.Sync(urls, state)
+			if err != nil {
+				return fmt.Errorf("sync aborted, hosts file unchanged: %w", err)
+			}
+			if !changed {
+				fmt.Println("Blocklists are up to date")
+				return nil
+			}
+
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+
+			if hostsFile.GetCategory(category) == nil {
+				if err := hostsFile.AddCategory(category, "Hostnames imported from remote blocklists"); err != nil {
+					return err
+				}
+			}
+
+			result := blocklist.Apply(hostsFile, category, hostnames, importProgress(os.Stderr, len(hostnames)))
+			if len(result.Rejected) > 0 {
+				writeRejectedEntries(os.Stderr, result.Rejected)
+			}
+
+			if dryRun {
+				fmt.Printf("Would sync category %s: %s\n", category, syncSummary(result))
+				return nil
+			}
+
+			if result.Added > 0 || result.Removed > 0 {
+				backupMgr := backup.NewManager(cfg)
+				if autoBackupEnabled("sync") {
+					if _, err := backupMgr.CreateAutoBackup(); err != nil {
+						return fmt.Errorf("failed to create backup: %w", err)
+					}
+					if verbose {
+						fmt.Println("Backup created successfully")
+					}
+				}
+
+				if err := writeHostsFile(hostsFile, p.GetHostsFilePath()); err != nil {
+					if logger, logErr := audit.NewLogger(); logErr == nil {
+						logger.LogHostsOperation("sync", blocklist.SinkIP, urls, false, err.Error())
+					}
+					return fmt.Errorf("failed to write hosts file: %w", err)
+				}
+
+				if logger, err := audit.NewLogger(); err == nil {
+					logger.LogHostsOperation("sync", blocklist.SinkIP, urls, true, "")
+				}
+			}
+
+			// Only remember the fetched versions once they are applied
+			if err := next.Save(statePath); err != nil {
+				return err
+			}
+
+			fmt.Printf("Synced category %s: %s\n", category, syncSummary(result))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&urls, "url", nil, "Blocklist URL to import (repeatable; defaults to blocklists.urls)")
+	cmd.Flags().StringVarP(&category, "category", "c", "", "Category to import into (defaults to blocklists.category)")
+	cmd.Flags().DurationVar(&timeout, "timeout", time.Duration(cfg.Blocklists.TimeoutSeconds)*time.Second, "HTTP timeout per download")
+
+	return cmd
+}
+
+// syncSummary describes a blocklist sync with the same counts as an import
+// summary, plus the hostnames dropped from the lists since the last sync
+func syncSummary(result blocklist.Result) string {
+	return fmt.Sprintf("%d added, %d skipped as duplicates, %d rejected as invalid, %d removed",
+		result.Added, result.Skipped, len(result.Rejected), result.Removed)
+}
+
+func validateCmd() *cobra.Command {
+	var strict bool
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check the hosts file for invalid or duplicate entries",
+		Long: `Parse the hosts file and validate every entry without modifying it.
+Each problem is reported with its line number, field, and reason. Lines
+that could not be parsed at all, and are therefore ignored by every other
+command, are reported as well. The command exits non-zero if any problems
+are found, so it can be used in pre-commit hooks; with the global --quiet
+flag only the problem count is printed, on standard error.
+
+A missing, misdirected, or duplicated localhost mapping is reported as a
+warning, which only fails validation with --strict.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p := platform.New()
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+
+			problems, warnings := validationFindings(hostsFile, strict)
+			for _, warning := range warnings {
+				fmt.Println("warning: " + formatProblem(warning))
+			}
+			if len(problems) == 0 {
+				fmt.Println("No problems found")
+				return nil
+			}
+
+			for _, problem := range problems {
+				fmt.Println(formatProblem(problem))
+			}
+			return fmt.Errorf("found %d problems in %s", len(problems), p.GetHostsFilePath())
+		},
+	}
+
+	cmd.Flags().BoolVar(&strict, "strict", false, "Treat warnings, such as a missing localhost mapping, as problems")
+
+	return cmd
+}
+
+func flushDNSCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "flush-dns",
+		Short: "Flush the operating system's DNS cache",
+		Long: `Flush the resolver cache so hosts file changes take effect immediately.
+
+Runs dscacheutil and killall -HUP mDNSResponder on macOS (requires root),
+ipconfig /flushdns on Windows, and resolvectl flush-caches (or the older
+systemd-resolve --flush-caches) on Linux. Other setups have no cache to flush
+or are not supported, and nothing is run.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p := platform.New()
+			commands := p.DNSFlushCommands()
+			if len(commands) == 0 {
+				fmt.Println("No supported DNS cache flush command found; nothing to do")
+				return nil
+			}
+
+			if dryRun {
+				for _, command := range commands {
+					fmt.Printf("Would run: %s\n", command)
+				}
+				return nil
+			}
+
+			if p.DNSFlushNeedsElevation() {
+				if err := p.ElevateIfNeeded(); err != nil {
+					return err
+				}
+			}
+
+			ran, err := p.FlushDNS()
+			for _, command := range ran {
+				fmt.Printf("Ran: %s\n", command)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to flush DNS cache: %w", err)
+			}
+
+			fmt.Println("DNS cache flushed")
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func watchCmd() *cobra.Command {
+	var interval, debounce time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Restore managed categories when the hosts file is changed externally",
+		Long: `Watch the hosts file and restore managed categories that another program
+removes.
+
+The categories present when the command starts, other than the default
+section, are remembered. Whenever the file changes and then stays unchanged
+for the debounce period, any of those categories that went missing or were
+emptied are written back with their entries. Each reconciliation is
+recorded in the audit log.
+
+The file is polled at --interval. With --dry-run, removed categories are
+reported but not restored. Stop watching with Ctrl+C.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval <= 0 || debounce < 0 {
+				return fmt.Errorf("--interval must be positive and --debounce must not be negative")
+			}
+
+			p := platform.New()
+			if !dryRun {
+				if err := p.ElevateIfNeeded(); err != nil {
+					return err
+				}
+			}
+
+			path := p.GetHostsFilePath()
+			snapshot, err := parseHostsFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+
+			watcher := watch.New(path, snapshot, watch.Options{
+				Interval: interval,
+				Debounce: debounce,
+				DryRun:   dryRun,
+				Parse:    parseHostsFile,
+				Write: func(hostsFile *hosts.HostsFile, path string) error {
+					if autoBackupEnabled("watch") {
+						if _, err := backup.NewManager(cfg).CreateAutoBackup(); err != nil {
+							return fmt.Errorf("failed to create backup: %w", err)
+						}
+					}
+					return writeHostsFile(hostsFile, path)
+				},
+				OnChange: func(change watch.Change) {
+					fmt.Println(describeWatchChange(change, dryRun))
+					if logger, err := audit.NewLogger(); err == nil {
+						errMsg := ""
+						if change.Err != nil {
+							errMsg = change.Err.Error()
+						}
+						logger.LogWatchReconcile(change.Missing, change.Restored, errMsg)
+					}
+				},
+			})
+
+			managed := watcher.Managed()
+			if len(managed) == 0 {
+				return fmt.Errorf("no managed categories to watch in %s", path)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			fmt.Printf("Watching %s for changes to: %s (Ctrl+C to stop)\n", path, strings.Join(managed, ", "))
+			if err := watcher.Run(ctx); err != nil {
+				return err
+			}
+			fmt.Println("Stopped watching")
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&interval, "interval", time.Second, "How often to check the hosts file")
+	cmd.Flags().DurationVar(&debounce, "debounce", 2*time.Second, "How long the file must stay unchanged before reconciling")
+
+	return cmd
+}
+
+// describeWatchChange summarizes how the watch command handled one external
+// change to the hosts file
+func describeWatchChange(change watch.Change, dryRun bool) string {
+	timestamp := time.Now().Format("15:04:05")
+	missing := strings.Join(change.Missing, ", ")
+
+	switch {
+	case change.Err != nil:
+		return fmt.Sprintf("%s Failed to reconcile external change: %v", timestamp, change.Err)
+	case len(change.Missing) == 0:
+		return fmt.Sprintf("%s External change detected; all managed categories present", timestamp)
+	case dryRun:
+		return fmt.Sprintf("%s External change removed: %s (dry run, not restored)", timestamp, missing)
+	default:
+		return fmt.Sprintf("%s External change removed: %s; restored", timestamp, missing)
+	}
+}
+
+// validationFindings returns the problems that fail validate and the
+// warnings that are only reported. With strict, warnings are problems too.
+func validationFindings(hostsFile *hosts.HostsFile, strict bool) (problems, warnings []hosts.Problem) {
+	problems = hostsFile.Lint()
+	warnings = hostsFile.LocalhostWarnings()
+	if !strict {
+		return problems, warnings
+	}
+
+	problems = append(problems, warnings...)
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].LineNum < problems[j].LineNum
+	})
+	return problems, nil
+}
+
+// formatProblem renders a validation problem as "line N: field "value": reason",
+// leaving out the line for problems about the file as a whole
+func formatProblem(problem hosts.Problem) string {
+	if problem.LineNum == 0 {
+		return fmt.Sprintf("%s %q: %s", problem.Field, problem.Value, problem.Reason)
+	}
+	return fmt.Sprintf("line %d: %s %q: %s", problem.LineNum, problem.Field, problem.Value, problem.Reason)
+}
+
+func dedupeCmd() *cobra.Command {
+	var fix bool
+
+	cmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Report or remove duplicate hostnames",
+		Long: `Report hostnames declared by more than one entry.
+
+With --fix, the first enabled occurrence of each hostname is kept and the
+others are removed. Entries left without hostnames are deleted.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p := platform.New()
+			if fix {
+				if err := p.ElevateIfNeeded(); err != nil {
+					return err
+				}
+			}
+
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+
+			duplicates := hostsFile.FindDuplicateHostnames()
+			if len(duplicates) == 0 {
+				fmt.Println("No duplicate hostnames found")
+				return nil
+			}
+
+			hostnames := make([]string, 0, len(duplicates))
+			for hostname := range duplicates {
+				hostnames = append(hostnames, hostname)
+			}
+			sort.Strings(hostnames)
+
+			for _, hostname := range hostnames {
+				fmt.Printf("%s:\n", hostname)
+				for _, occurrence := range duplicates[hostname] {
+					fmt.Printf("  line %d: %s\n", occurrence.LineNum, describeEntry(*occurrence.Entry))
+				}
+			}
+
+			if !fix {
+				fmt.Printf("\nFound %d duplicated hostnames. Use --fix to remove duplicates.\n", len(hostnames))
+				return nil
+			}
+
+			removed := hostsFile.RemoveDuplicateHostnames(!force)
+
+			if dryRun {
+				fmt.Printf("\nWould remove %d duplicate hostname declarations\n", removed)
+				return nil
+			}
+
+			if removed == 0 {
+				fmt.Println("\nNo duplicates removed; remaining duplicates are protected by .hostsignore")
+				return nil
+			}
+
+			backupMgr := backup.NewManager(cfg)
+			if autoBackupEnabled("dedupe") {
+				if _, err := backupMgr.CreateAutoBackup(); err != nil {
+					return fmt.Errorf("failed to create backup: %w", err)
+				}
+				if verbose {
+					fmt.Println("Backup created successfully")
+				}
+			}
+
+			if err := writeHostsFile(hostsFile, p.GetHostsFilePath()); err != nil {
+				if logger, logErr := audit.NewLogger(); logErr == nil {
+					logger.LogHostsOperation("dedupe", "", hostnames, false, err.Error())
+				}
+				return fmt.Errorf("failed to write hosts file: %w", err)
+			}
+
+			if logger, err := audit.NewLogger(); err == nil {
+				logger.LogHostsOperation("dedupe", "", hostnames, true, "")
+			}
+
+			fmt.Printf("\nRemoved %d duplicate hostname declarations\n", removed)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&fix, "fix", false, "Remove duplicates, keeping the first enabled occurrence")
+
+	return cmd
+}
+
+func expireCmd() *cobra.Command {
+	var disable bool
+
+	cmd := &cobra.Command{
+		Use:   "expire",
+		Short: "Remove entries past their expiry",
+		Long: `Remove entries whose @expires tag (set by "add --ttl") has passed.
+With --disable they are commented out instead. Suitable for running from cron.
+Entries protected by .hostsignore are skipped unless --force is given.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p := platform.New()
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+
+			now := time.Now()
+			var expired []hosts.Entry
+			var hostnames []string
+			for _, entry := range hostsFile.ExpiredEntries(now, force) {
+				// Disabling leaves already disabled entries as they are
+				if disable && !entry.Enabled {
+					continue
+				}
+				expired = append(expired, entry)
+				hostnames = append(hostnames, entry.Hostnames...)
+			}
+
+			if len(expired) == 0 {
+				fmt.Println("No expired entries")
+				return nil
+			}
+
+			verb := "remove"
+			if disable {
+				verb = "disable"
+			}
+			if dryRun {
+				fmt.Printf("Would %s %d expired entries:\n", verb, len(expired))
+			} else {
+				fmt.Printf("Expired entries to %s (%d):\n", verb, len(expired))
+			}
+			for _, entry := range expired {
+				fmt.Printf("  %s\n", describeEntry(entry))
+			}
+
+			if dryRun {
+				return nil
+			}
+
+			if err := p.ElevateIfNeeded(); err != nil {
+				return err
+			}
+
+			backupMgr := backup.NewManager(cfg)
+			if autoBackupEnabled("expire") {
+				if _, err := backupMgr.CreateAutoBackup(); err != nil {
+					return fmt.Errorf("failed to create backup: %w", err)
+				}
+				if verbose {
+					fmt.Println("Backup created successfully")
+				}
+			}
+
+			hostsFile.RemoveExpired(now, disable, force)
+
+			if err := writeHostsFile(hostsFile, p.GetHostsFilePath()); err != nil {
+				if logger, logErr := audit.NewLogger(); logErr == nil {
+					logger.LogHostsOperation("expire", "", hostnames, false, err.Error())
+				}
+				return fmt.Errorf("failed to write hosts file: %w", err)
 			}
 
-			data, err := os.ReadFile(filePath)
+			if logger, err := audit.NewLogger(); err == nil {
+				logger.LogHostsOperation("expire", "", hostnames, true, "")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&disable, "disable", false, "Comment out expired entries instead of removing them")
+
+	return cmd
+}
+
+func sortCmd() *cobra.Command {
+	var by string
+	var sortCategories bool
+
+	cmd := &cobra.Command{
+		Use:   "sort",
+		Short: "Sort entries within each category",
+		Long: `Reorder entries inside each category by first hostname, IP address, comment,
+or status (enabled entries first).
+IP addresses are compared numerically. Category order is kept unless
+--categories is given. Entries protected by .hostsignore keep their position
+unless --force is given.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := hosts.ParseSortKey(by)
 			if err != nil {
-				return fmt.Errorf("failed to read import file: %w", err)
+				return err
 			}
 
-			var importedHosts *hosts.HostsFile
-			switch format {
-			case "json":
-				err = json.Unmarshal(data, &importedHosts)
-			case "yaml":
-				err = yaml.Unmarshal(data, &importedHosts)
-			default:
-				return fmt.Errorf("unsupported import format: %s", format)
+			p := platform.New()
+			if !dryRun {
+				if err := p.ElevateIfNeeded(); err != nil {
+					return err
+				}
 			}
 
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
 			if err != nil {
-				return fmt.Errorf("failed to parse import file: %w", err)
+				return fmt.Errorf("failed to parse hosts file: %w", err)
 			}
 
-			if merge {
-				parser := hosts.NewParser(p.GetHostsFilePath())
-				currentHosts, err := parser.Parse()
-				if err != nil {
-					return fmt.Errorf("failed to parse current hosts file: %w", err)
-				}
+			if err := hostsFile.SortEntries(key, !force); err != nil {
+				return err
+			}
+			if sortCategories {
+				hostsFile.SortCategories()
+			}
 
-				for _, category := range importedHosts.Categories {
+			if dryRun {
+				fmt.Printf("Would sort entries by %s:\n", key)
+				for _, category := range hostsFile.Categories {
+					if len(category.Entries) == 0 {
+						continue
+					}
+					fmt.Printf("\n=== %s ===\n", category.Name)
 					for _, entry := range category.Entries {
-						if err := currentHosts.AddEntry(entry); err != nil {
-							return fmt.Errorf("failed to add imported entry %s: %w", entry.IP, err)
-						}
+						fmt.Printf("  %s\n", describeEntry(entry))
 					}
 				}
-				importedHosts = currentHosts
+				return nil
 			}
 
 			backupMgr := backup.NewManager(cfg)
-			if cfg.General.AutoBackup {
-				if _, err := backupMgr.CreateBackup(); err != nil {
+			if autoBackupEnabled("sort") {
+				if _, err := backupMgr.CreateAutoBackup(); err != nil {
 					return fmt.Errorf("failed to create backup: %w", err)
 				}
 				if verbose {
@@ -323,25 +2521,20 @@ Use relative paths (e.g., 'my-import.json') or paths within these directories.`,
 				}
 			}
 
-			if dryRun {
-				fmt.Printf("Would import %d categories with entries\n", len(importedHosts.Categories))
-				for _, category := range importedHosts.Categories {
-					fmt.Printf("  %s: %d entries\n", category.Name, len(category.Entries))
-				}
-				return nil
-			}
-
-			if err := importedHosts.Write(p.GetHostsFilePath()); err != nil {
+			if err := writeHostsFile(hostsFile, p.GetHostsFilePath()); err != nil {
 				return fmt.Errorf("failed to write hosts file: %w", err)
 			}
 
-			fmt.Printf("Successfully imported %d categories\n", len(importedHosts.Categories))
+			fmt.Printf("Sorted entries by %s\n", key)
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&format, "format", "f", "yaml", "Import format (json, yaml)")
-	cmd.Flags().BoolVarP(&merge, "merge", "m", false, "Merge with existing entries")
+	cmd.Flags().StringVar(&by, "by", string(hosts.SortByHostname), "Sort key (hostname, ip, comment, status)")
+	cmd.Flags().BoolVar(&sortCategories, "categories", false, "Also sort categories by name")
+	_ = cmd.RegisterFlagCompletionFunc("by", cobra.FixedCompletions(
+		[]string{string(hosts.SortByHostname), string(hosts.SortByIP), string(hosts.SortByComment), string(hosts.SortByStatus)},
+		cobra.ShellCompDirectiveNoFileComp))
 
 	return cmd
 }
@@ -356,6 +2549,7 @@ func categoryCmd() *cobra.Command {
 	cmd.AddCommand(categoryAddCmd())
 	cmd.AddCommand(categoryEnableCmd())
 	cmd.AddCommand(categoryDisableCmd())
+	cmd.AddCommand(categoryDeleteCmd())
 
 	return cmd
 }
@@ -366,8 +2560,7 @@ func categoryListCmd() *cobra.Command {
 		Short: "List all categories",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			p := platform.New()
-			parser := hosts.NewParser(p.GetHostsFilePath())
-			hostsFile, err := parser.Parse()
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
 			if err != nil {
 				return fmt.Errorf("failed to parse hosts file: %w", err)
 			}
@@ -404,8 +2597,7 @@ func categoryAddCmd() *cobra.Command {
 				return err
 			}
 
-			parser := hosts.NewParser(p.GetHostsFilePath())
-			hostsFile, err := parser.Parse()
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
 			if err != nil {
 				return fmt.Errorf("failed to parse hosts file: %w", err)
 			}
@@ -417,8 +2609,8 @@ func categoryAddCmd() *cobra.Command {
 			}
 
 			backupMgr := backup.NewManager(cfg)
-			if cfg.General.AutoBackup {
-				if _, err := backupMgr.CreateBackup(); err != nil {
+			if autoBackupEnabled("category add") {
+				if _, err := backupMgr.CreateAutoBackup(); err != nil {
 					return fmt.Errorf("failed to create backup: %w", err)
 				}
 				if verbose {
@@ -439,7 +2631,7 @@ func categoryAddCmd() *cobra.Command {
 				return fmt.Errorf("failed to add category: %w", err)
 			}
 
-			if err := hostsFile.Write(p.GetHostsFilePath()); err != nil {
+			if err := writeHostsFile(hostsFile, p.GetHostsFilePath()); err != nil {
 				return fmt.Errorf("failed to write hosts file: %w", err)
 			}
 
@@ -481,6 +2673,86 @@ func categoryDisableCmd() *cobra.Command {
 	return cmd
 }
 
+func categoryDeleteCmd() *cobra.Command {
+	var moveTo string
+
+	cmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a category",
+		Long: `Delete a category from the hosts file.
+
+A category that still has entries is only deleted if its entries are
+reassigned with --move-to, or deleted along with it using --force.
+The default category cannot be deleted.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeCategories,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			p := platform.New()
+			if err := p.ElevateIfNeeded(); err != nil {
+				return err
+			}
+
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+
+			if category := hostsFile.GetCategory(name); category != nil && len(category.Entries) > 0 && moveTo == "" && !force {
+				return fmt.Errorf("category %s has %d entries; use --move-to <category> to reassign them or --force to delete them", name, len(category.Entries))
+			}
+
+			moved, deleted, err := hostsFile.RemoveCategory(name, moveTo, force)
+			if err != nil {
+				return fmt.Errorf("failed to delete category: %w", err)
+			}
+
+			if dryRun {
+				fmt.Printf("Would delete category: %s%s\n", name, categoryDeleteSummary(moved, deleted, moveTo))
+				return nil
+			}
+
+			backupMgr := backup.NewManager(cfg)
+			if autoBackupEnabled("category delete") {
+				if _, err := backupMgr.CreateAutoBackup(); err != nil {
+					return fmt.Errorf("failed to create backup: %w", err)
+				}
+				if verbose {
+					fmt.Println("Backup created successfully")
+				}
+			}
+
+			if err := writeHostsFile(hostsFile, p.GetHostsFilePath()); err != nil {
+				return fmt.Errorf("failed to write hosts file: %w", err)
+			}
+
+			if logger, err := audit.NewLogger(); err == nil {
+				logger.LogHostsOperation("category_delete", "", []string{name}, true, "")
+			}
+
+			fmt.Printf("Deleted category: %s%s\n", name, categoryDeleteSummary(moved, deleted, moveTo))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&moveTo, "move-to", "", "Reassign the category's entries to this category")
+	_ = cmd.RegisterFlagCompletionFunc("move-to", completeCategories)
+
+	return cmd
+}
+
+func categoryDeleteSummary(moved, deleted int, moveTo string) string {
+	switch {
+	case moved > 0:
+		return fmt.Sprintf(" (%d entries moved to %s)", moved, moveTo)
+	case deleted > 0:
+		return fmt.Sprintf(" (%d entries deleted)", deleted)
+	default:
+		return ""
+	}
+}
+
 func profileCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "profile",
@@ -488,7 +2760,11 @@ func profileCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(profileListCmd())
+	cmd.AddCommand(profileShowCmd())
 	cmd.AddCommand(profileActivateCmd())
+	cmd.AddCommand(profileCreateCmd())
+	cmd.AddCommand(profileEditCmd())
+	cmd.AddCommand(profileDeleteCmd())
 
 	return cmd
 }
@@ -509,87 +2785,382 @@ func profileListCmd() *cobra.Command {
 				fmt.Printf("    Categories: %v\n", profile.Categories)
 			}
 
-			return nil
-		},
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func profileShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show <profile>",
+		Short: "Preview what activating a profile would change",
+		Long: `Show each category as it would be after activating the profile, and how
+many entries would change state. Nothing is written.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName := args[0]
+			profile, exists := cfg.Profiles[profileName]
+			if !exists {
+				return fmt.Errorf("profile not found: %s", profileName)
+			}
+
+			p := platform.New()
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+
+			simulated, changed := previewProfile(hostsFile, profile)
+
+			fmt.Printf("Profile: %s - %s\n", profileName, profile.Description)
+			for i, category := range simulated.Categories {
+				state := "disabled"
+				if category.Enabled {
+					state = "enabled"
+				}
+				note := ""
+				if category.Enabled != hostsFile.Categories[i].Enabled {
+					note = " (changes)"
+				}
+				fmt.Printf("  %-8s %s (%d entries)%s\n", state, category.Name, len(category.Entries), note)
+			}
+			fmt.Printf("%d entries would change state\n", changed)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func profileActivateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "activate <profile>",
+		Short: "Activate a profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName := args[0]
+			profile, exists := cfg.Profiles[profileName]
+			if !exists {
+				return fmt.Errorf("profile not found: %s", profileName)
+			}
+
+			p := platform.New()
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+
+			if dryRun {
+				simulated, _ := previewProfile(hostsFile, profile)
+
+				fmt.Printf("Would activate profile: %s\n", profileName)
+				fmt.Printf("Enabled categories: %v\n", profile.Categories)
+				for i, category := range simulated.Categories {
+					if category.Enabled == hostsFile.Categories[i].Enabled {
+						continue
+					}
+					if category.Enabled {
+						fmt.Printf("Would enable category: %s\n", category.Name)
+					} else {
+						fmt.Printf("Would disable category: %s\n", category.Name)
+					}
+				}
+				return nil
+			}
+
+			if err := p.ElevateIfNeeded(); err != nil {
+				return err
+			}
+
+			backupMgr := backup.NewManager(cfg)
+			if autoBackupEnabled("profile activate") {
+				if _, err := backupMgr.CreateAutoBackup(); err != nil {
+					return fmt.Errorf("failed to create backup: %w", err)
+				}
+				if verbose {
+					fmt.Println("Backup created successfully")
+				}
+			}
+
+			applyProfile(hostsFile, profile)
+
+			if err := writeHostsFile(hostsFile, p.GetHostsFilePath()); err != nil {
+				return fmt.Errorf("failed to write hosts file: %w", err)
+			}
+
+			for name, prof := range cfg.Profiles {
+				prof.Default = (name == profileName)
+				cfg.Profiles[name] = prof
+			}
+
+			if err := config.Save(cfg); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+
+			fmt.Printf("Activated profile: %s\n", profileName)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func profileCreateCmd() *cobra.Command {
+	var categories []string
+	var description string
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a profile",
+		Long: `Create a profile that enables the given categories. Categories must be
+defined in the configuration or present in the hosts file.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if _, exists := cfg.Profiles[name]; exists {
+				return fmt.Errorf("profile already exists: %s", name)
+			}
+
+			p := platform.New()
+			if err := checkProfileCategories(categories, categoryCompletions(p.GetHostsFilePath(), "")); err != nil {
+				return err
+			}
+
+			if cfg.Profiles == nil {
+				cfg.Profiles = make(map[string]config.Profile)
+			}
+			cfg.Profiles[name] = config.Profile{
+				Description: description,
+				Categories:  categories,
+			}
+
+			return saveProfileConfig(fmt.Sprintf("Created profile: %s", name))
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&categories, "categories", nil, "Categories the profile enables (comma-separated)")
+	cmd.Flags().StringVar(&description, "description", "", "Profile description")
+	_ = cmd.MarkFlagRequired("categories")
+	_ = cmd.RegisterFlagCompletionFunc("categories", completeCategories)
+
+	return cmd
+}
+
+func profileEditCmd() *cobra.Command {
+	var addCategories []string
+	var removeCategories []string
+	var description string
+
+	cmd := &cobra.Command{
+		Use:   "edit <name>",
+		Short: "Change a profile's categories or description",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			profile, exists := cfg.Profiles[name]
+			if !exists {
+				return fmt.Errorf("profile not found: %s", name)
+			}
+			if len(addCategories) == 0 && len(removeCategories) == 0 && !cmd.Flags().Changed("description") {
+				return fmt.Errorf("nothing to change: use --add-category, --remove-category, or --description")
+			}
+
+			p := platform.New()
+			if err := checkProfileCategories(addCategories, categoryCompletions(p.GetHostsFilePath(), "")); err != nil {
+				return err
+			}
+
+			updated, err := editProfile(profile, addCategories, removeCategories)
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("description") {
+				updated.Description = description
+			}
+			cfg.Profiles[name] = updated
+
+			return saveProfileConfig(fmt.Sprintf("Updated profile: %s (categories: %s)", name, strings.Join(updated.Categories, ", ")))
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&addCategories, "add-category", nil, "Add categories to the profile")
+	cmd.Flags().StringSliceVar(&removeCategories, "remove-category", nil, "Remove categories from the profile")
+	cmd.Flags().StringVar(&description, "description", "", "Replace the profile description")
+	_ = cmd.RegisterFlagCompletionFunc("add-category", completeCategories)
+
+	return cmd
+}
+
+func profileDeleteCmd() *cobra.Command {
+	var newDefault string
+
+	cmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a profile",
+		Long: `Delete a profile. The last remaining profile cannot be deleted, and the
+default profile can only be deleted with --default naming its replacement.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if err := deleteProfile(cfg, name, newDefault); err != nil {
+				return err
+			}
+
+			return saveProfileConfig(fmt.Sprintf("Deleted profile: %s", name))
+		},
+	}
+
+	cmd.Flags().StringVar(&newDefault, "default", "", "Profile to make the default when deleting the current default")
+
+	return cmd
+}
+
+// checkProfileCategories returns an error naming any category that is not in
+// known
+func checkProfileCategories(categories, known []string) error {
+	var unknown []string
+	for _, category := range categories {
+		if !slices.Contains(known, category) {
+			unknown = append(unknown, category)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown categories: %s (known: %s)", strings.Join(unknown, ", "), strings.Join(known, ", "))
+	}
+	return nil
+}
+
+// editProfile returns a copy of profile with categories added and removed.
+// Removing a category the profile does not enable is an error.
+func editProfile(profile config.Profile, add, remove []string) (config.Profile, error) {
+	categories := slices.Clone(profile.Categories)
+	for _, category := range remove {
+		i := slices.Index(categories, category)
+		if i < 0 {
+			return profile, fmt.Errorf("profile does not include category: %s", category)
+		}
+		categories = slices.Delete(categories, i, i+1)
+	}
+	for _, category := range add {
+		if !slices.Contains(categories, category) {
+			categories = append(categories, category)
+		}
+	}
+	if len(categories) == 0 {
+		return profile, fmt.Errorf("a profile must keep at least one category")
 	}
 
-	return cmd
+	profile.Categories = categories
+	return profile, nil
 }
 
-func profileActivateCmd() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "activate <profile>",
-		Short: "Activate a profile",
-		Args:  cobra.ExactArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			profileName := args[0]
-			profile, exists := cfg.Profiles[profileName]
-			if !exists {
-				return fmt.Errorf("profile not found: %s", profileName)
-			}
+// deleteProfile removes the named profile from c. Deleting the default
+// profile requires newDefault, which becomes the default instead.
+func deleteProfile(c *config.Config, name, newDefault string) error {
+	profile, exists := c.Profiles[name]
+	if !exists {
+		return fmt.Errorf("profile not found: %s", name)
+	}
+	if len(c.Profiles) == 1 {
+		return fmt.Errorf("cannot delete %s: it is the only profile", name)
+	}
 
-			p := platform.New()
-			if err := p.ElevateIfNeeded(); err != nil {
-				return err
-			}
+	if profile.Default {
+		if newDefault == "" {
+			return fmt.Errorf("cannot delete %s: it is the default profile; choose a replacement with --default", name)
+		}
+		replacement, exists := c.Profiles[newDefault]
+		if !exists || newDefault == name {
+			return fmt.Errorf("invalid --default profile: %s", newDefault)
+		}
+		replacement.Default = true
+		c.Profiles[newDefault] = replacement
+	} else if newDefault != "" {
+		return fmt.Errorf("--default is only needed when deleting the default profile")
+	}
 
-			parser := hosts.NewParser(p.GetHostsFilePath())
-			hostsFile, err := parser.Parse()
-			if err != nil {
-				return fmt.Errorf("failed to parse hosts file: %w", err)
-			}
+	delete(c.Profiles, name)
+	return nil
+}
 
-			backupMgr := backup.NewManager(cfg)
-			if cfg.General.AutoBackup {
-				if _, err := backupMgr.CreateBackup(); err != nil {
-					return fmt.Errorf("failed to create backup: %w", err)
-				}
-				if verbose {
-					fmt.Println("Backup created successfully")
-				}
-			}
+// saveProfileConfig validates and saves the configuration after a profile
+// change, or only reports the change on a dry run
+func saveProfileConfig(message string) error {
+	if err := config.NewValidator().Validate(cfg); err != nil {
+		return fmt.Errorf("invalid profile: %w", err)
+	}
 
-			for i := range hostsFile.Categories {
-				category := &hostsFile.Categories[i]
-				enabled := false
-				for _, activeCat := range profile.Categories {
-					if category.Name == activeCat {
-						enabled = true
-						break
-					}
-				}
+	if dryRun {
+		fmt.Printf("Would save: %s\n", message)
+		return nil
+	}
 
-				category.Enabled = enabled
-				for j := range category.Entries {
-					category.Entries[j].Enabled = enabled
-				}
-			}
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
 
-			if dryRun {
-				fmt.Printf("Would activate profile: %s\n", profileName)
-				fmt.Printf("Enabled categories: %v\n", profile.Categories)
-				return nil
-			}
+	fmt.Println(message)
+	return nil
+}
 
-			if err := hostsFile.Write(p.GetHostsFilePath()); err != nil {
-				return fmt.Errorf("failed to write hosts file: %w", err)
+// previewProfile applies profile to a copy of hostsFile and returns the copy
+// with the number of entries whose enabled state would change
+func previewProfile(hostsFile *hosts.HostsFile, profile config.Profile) (*hosts.HostsFile, int) {
+	// Simulate on a copy so the preview never touches the parsed file
+	simulated := hostsFile.Clone()
+	applyProfile(simulated, profile)
+
+	changed := 0
+	for i, category := range simulated.Categories {
+		for j, entry := range category.Entries {
+			if entry.Enabled != hostsFile.Categories[i].Entries[j].Enabled {
+				changed++
 			}
+		}
+	}
+	return simulated, changed
+}
 
-			for name, prof := range cfg.Profiles {
-				prof.Default = (name == profileName)
-				cfg.Profiles[name] = prof
-			}
+// applyProfile enables the profile's categories and their entries and
+// disables everything else
+func applyProfile(hostsFile *hosts.HostsFile, profile config.Profile) {
+	for i := range hostsFile.Categories {
+		category := &hostsFile.Categories[i]
+		enabled := slices.Contains(profile.Categories, category.Name)
 
-			if err := config.Save(cfg); err != nil {
-				return fmt.Errorf("failed to save config: %w", err)
+		category.Enabled = enabled
+		for j := range category.Entries {
+			category.Entries[j].Enabled = enabled
+		}
+	}
+}
+
+// filterEnabled drops disabled categories and disabled entries, along with
+// categories left without entries
+func filterEnabled(hostsFile *hosts.HostsFile) {
+	var categories []hosts.Category
+	for _, category := range hostsFile.Categories {
+		if !category.Enabled {
+			continue
+		}
+
+		var entries []hosts.Entry
+		for _, entry := range category.Entries {
+			if entry.Enabled {
+				entries = append(entries, entry)
 			}
+		}
+		if len(entries) == 0 {
+			continue
+		}
 
-			fmt.Printf("Activated profile: %s\n", profileName)
-			return nil
-		},
+		category.Entries = entries
+		categories = append(categories, category)
 	}
-
-	return cmd
+	hostsFile.Categories = categories
 }
 
 func toggleCategory(categoryName string, enable bool) error {
@@ -599,8 +3170,8 @@ func toggleCategory(categoryName string, enable bool) error {
 	}
 
 	backupMgr := backup.NewManager(cfg)
-	if cfg.General.AutoBackup {
-		if _, err := backupMgr.CreateBackup(); err != nil {
+	if autoBackupEnabled(toggleOperation(enable)) {
+		if _, err := backupMgr.CreateAutoBackup(); err != nil {
 			return fmt.Errorf("failed to create backup: %w", err)
 		}
 		if verbose {
@@ -608,8 +3179,7 @@ func toggleCategory(categoryName string, enable bool) error {
 		}
 	}
 
-	parser := hosts.NewParser(p.GetHostsFilePath())
-	hostsFile, err := parser.Parse()
+	hostsFile, err := parseHostsFile(p.GetHostsFilePath())
 	if err != nil {
 		return fmt.Errorf("failed to parse hosts file: %w", err)
 	}
@@ -630,7 +3200,7 @@ func toggleCategory(categoryName string, enable bool) error {
 		hostsFile.DisableCategory(categoryName)
 	}
 
-	if err := hostsFile.Write(p.GetHostsFilePath()); err != nil {
+	if err := writeHostsFile(hostsFile, p.GetHostsFilePath()); err != nil {
 		return fmt.Errorf("failed to write hosts file: %w", err)
 	}
 
@@ -640,7 +3210,357 @@ func toggleCategory(categoryName string, enable bool) error {
 	return nil
 }
 
-func exportToHosts(hostsFile *hosts.HostsFile) ([]byte, error) {
+func bugreportCmd() *cobra.Command {
+	var eventLimit int
+
+	cmd := &cobra.Command{
+		Use:   "bugreport",
+		Short: "Write a redacted diagnostics bundle for bug reports",
+		Long: `Collect version information, the configuration, recent audit events, and a
+summary of the hosts file's structure into a single file in the data directory.
+
+Hostnames, IP addresses, usernames, and file paths are redacted; the hosts
+file is described by counts only.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p := platform.New()
+
+			hostsFile, hostsErr := parseHostsFile(p.GetHostsFilePath())
+
+			var events []audit.AuditEvent
+			if logger, err := audit.NewLogger(); err == nil {
+				// GetRecentEvents reads from the start of the log, so keep the tail
+				all, err := logger.GetRecentEvents(math.MaxInt)
+				if err == nil {
+					events = all[max(len(all)-eventLimit, 0):]
+				}
+			}
+
+			dir := filepath.Join(p.GetDataDir(), "bugreports")
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				return fmt.Errorf("failed to create bug report directory: %w", err)
+			}
+			path := filepath.Join(dir, fmt.Sprintf("bugreport-%s.txt", time.Now().Format("2006-01-02T15-04-05")))
+
+			file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+			if err != nil {
+				return fmt.Errorf("failed to create bug report: %w", err)
+			}
+			defer func() { _ = file.Close() }()
+
+			if err := writeBugReport(file, cfg, hostsFile, hostsErr, events); err != nil {
+				return fmt.Errorf("failed to write bug report: %w", err)
+			}
+
+			fmt.Printf("Bug report written to %s\n", path)
+			fmt.Println("Review it before attaching it to an issue.")
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&eventLimit, "events", 50, "Number of recent audit events to include")
+
+	return cmd
+}
+
+// bugReportSummary describes the structure of a hosts file without its contents
+type bugReportSummary struct {
+	Entries            int            `yaml:"entries"`
+	Enabled            int            `yaml:"enabled"`
+	Disabled           int            `yaml:"disabled"`
+	IPv4               int            `yaml:"ipv4"`
+	IPv6               int            `yaml:"ipv6"`
+	ReadOnly           int            `yaml:"read_only"`
+	DuplicateHostnames int            `yaml:"duplicate_hostnames"`
+	ParseIssues        int            `yaml:"parse_issues"`
+	HeaderLines        int            `yaml:"header_lines"`
+	Categories         map[string]int `yaml:"entries_per_category"`
+}
+
+func summarizeForBugReport(hostsFile *hosts.HostsFile) bugReportSummary {
+	summary := bugReportSummary{
+		Categories:         make(map[string]int),
+		DuplicateHostnames: len(hostsFile.FindDuplicateHostnames()),
+		ParseIssues:        len(hostsFile.ParseIssues),
+		HeaderLines:        len(hostsFile.Header),
+	}
+
+	for _, category := range hostsFile.Categories {
+		summary.Categories[category.Name] = len(category.Entries)
+		for _, entry := range category.Entries {
+			summary.Entries++
+			if entry.Enabled {
+				summary.Enabled++
+			} else {
+				summary.Disabled++
+			}
+			if strings.Contains(entry.IP, ":") {
+				summary.IPv6++
+			} else {
+				summary.IPv4++
+			}
+			if entry.ReadOnly {
+				summary.ReadOnly++
+			}
+		}
+	}
+
+	return summary
+}
+
+// writeBugReport renders the bug report sections. Every free-form string that
+// could carry hosts data is passed through the error sanitizer and redacted
+// against the hostnames and IPs found in hostsFile.
+func writeBugReport(w io.Writer, cfg *config.Config, hostsFile *hosts.HostsFile, hostsErr error, events []audit.AuditEvent) error {
+	var sensitive []string
+	if hostsFile != nil {
+		for _, category := range hostsFile.Categories {
+			for _, entry := range category.Entries {
+				sensitive = append(sensitive, entry.IP)
+				sensitive = append(sensitive, entry.Hostnames...)
+			}
+		}
+	}
+	redact := func(s string) string {
+		return errors.SanitizeErrorMessage(errors.RedactHostnames(s, sensitive))
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# hosts-manager bug report\nGenerated: %s\n\n", time.Now().UTC().Format(time.RFC3339))
+
+	b.WriteString("## Version\n")
+	fmt.Fprintf(&b, "version: %s\ngo: %s\nplatform: %s/%s\n\n", version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+
+	b.WriteString("## Configuration\n")
+	redacted := *cfg
+	redacted.Backup.Directory = redact(cfg.Backup.Directory)
+	redacted.General.Editor = filepath.Base(cfg.General.Editor)
+	redacted.Blocklists.URLs = make([]string, len(cfg.Blocklists.URLs))
+	for i := range cfg.Blocklists.URLs {
+		redacted.Blocklists.URLs[i] = "[redacted-url]"
+	}
+	configYAML, err := yaml.Marshal(&redacted)
+	if err != nil {
+		return err
+	}
+	b.Write(configYAML)
+	b.WriteString("\n")
+
+	b.WriteString("## Hosts File Summary\n")
+	if hostsErr != nil {
+		fmt.Fprintf(&b, "unavailable: %s\n", redact(hostsErr.Error()))
+	} else {
+		summaryYAML, err := yaml.Marshal(summarizeForBugReport(hostsFile))
+		if err != nil {
+			return err
+		}
+		b.Write(summaryYAML)
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "## Recent Audit Events (%d)\n", len(events))
+	for _, event := range events {
+		fmt.Fprintf(&b, "%s %s %s op=%s resource=%s success=%t",
+			event.Timestamp.UTC().Format(time.RFC3339), event.Severity, event.EventType,
+			redact(event.Operation), redact(event.Resource), event.Success)
+		if event.ErrorMsg != "" {
+			fmt.Fprintf(&b, " error=%q", redact(event.ErrorMsg))
+		}
+		b.WriteString("\n")
+	}
+
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+// autoBackupEnabled reports whether operation should take an automatic
+// backup first. --backup and --no-backup override general.auto_backup, and a
+// backup skipped with --no-backup is recorded in the audit log.
+func autoBackupEnabled(operation string) bool {
+	if backupNow {
+		return true
+	}
+	if noBackup {
+		if logger, err := audit.NewLogger(); err == nil {
+			logger.LogBackupSkipped(operation, "--no-backup")
+		}
+		return false
+	}
+	return cfg.General.AutoBackup
+}
+
+// toggleOperation names an enable or disable operation
+func toggleOperation(enable bool) string {
+	if enable {
+		return "enable"
+	}
+	return "disable"
+}
+
+// parseHostsFile parses the hosts file at path, marking entries listed in the
+// user's ignore file as read-only and applying the configured IP format,
+// normalization and IDN handling
+func parseHostsFile(path string) (*hosts.HostsFile, error) {
+	p := platform.New()
+	ignore, err := hosts.LoadIgnoreFile(filepath.Join(p.GetConfigDir(), hosts.IgnoreFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	ipMode, err := hosts.ParseIPMode(cfg.General.IPFormat)
+	if err != nil {
+		return nil, err
+	}
+	idnMode, err := hosts.ParseIDNMode(cfg.General.IDN)
+	if err != nil {
+		return nil, err
+	}
+	reversedMode, err := hosts.ParseReversedLineMode(cfg.General.ReversedLines)
+	if err != nil {
+		return nil, err
+	}
+	bannerStyle, err := hosts.ParseBannerStyle(cfg.General.BannerStyle)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := hosts.NewParser(path)
+	parser.SetIgnoreList(ignore)
+	parser.SetIPMode(ipMode)
+	parser.SetBannerStyle(bannerStyle)
+	parser.SetReversedLineMode(reversedMode)
+	parser.SetManagedBlockOnly(cfg.General.ManagedBlockOnly)
+	hostsFile, err := parser.Parse()
+	if err != nil {
+		return nil, err
+	}
+	hostsFile.SetNormalizeIPs(cfg.General.NormalizeIPs)
+	hostsFile.SetIDNMode(idnMode)
+	hostsFile.SetHostnameMode(configuredHostnameMode())
+	return hostsFile, nil
+}
+
+// configuredHostnameMode returns the hostname validation mode selected by
+// general.allow_underscore_hostnames
+func configuredHostnameMode() hosts.HostnameValidationMode {
+	if cfg.General.AllowUnderscoreHostnames {
+		return hosts.HostnameUnderscores
+	}
+	return hosts.HostnameStrict
+}
+
+// resolveHostnameMode returns the hostname validation mode for a command with
+// a lenient flag and --strict-hostnames: the lenient flag selects lenientMode,
+// --strict-hostnames forces HostnameStrict, and otherwise
+// general.allow_underscore_hostnames decides
+func resolveHostnameMode(lenient bool, lenientMode hosts.HostnameValidationMode, strict bool) hosts.HostnameValidationMode {
+	switch {
+	case lenient:
+		return lenientMode
+	case strict:
+		return hosts.HostnameStrict
+	default:
+		return configuredHostnameMode()
+	}
+}
+
+// checkReadOnly returns an error if hostname belongs to an entry protected by
+// the ignore file, unless --force is set
+func checkReadOnly(hostsFile *hosts.HostsFile, hostname string) error {
+	if !force && hostsFile.IsReadOnly(hostname) {
+		return fmt.Errorf("hostname %s is protected by %s; use --force to modify it", hostname, hosts.IgnoreFileName)
+	}
+	return nil
+}
+
+// writeHostsFile writes hostsFile to path, refusing writes that would empty the
+// hosts file or drop more entries than the configured threshold unless --force is set
+func writeHostsFile(hostsFile *hosts.HostsFile, path string) error {
+	if !force {
+		if err := hostsFile.CheckWriteSafety(path, cfg.General.WriteGuardThreshold); err != nil {
+			return fmt.Errorf("%w; use --force to write anyway", err)
+		}
+	}
+
+	bannerStyle, err := hosts.ParseBannerStyle(cfg.General.BannerStyle)
+	if err != nil {
+		return err
+	}
+	hostsFile.SetBannerStyle(bannerStyle)
+	hostsFile.SetNormalizeIPs(cfg.General.NormalizeIPs)
+
+	if err := hostsFile.Write(path); err != nil {
+		return err
+	}
+
+	if cfg.General.FlushDNSAfterWrite {
+		if err := flushDNSAfterWrite(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: hosts file written but DNS cache not flushed: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// flushDNSAfterWrite flushes the resolver cache once the hosts file has been
+// written and records the attempt in the audit log. The write has already
+// succeeded, so callers only report a failed or unsupported flush.
+func flushDNSAfterWrite() error {
+	ran, err := platform.New().FlushDNS()
+
+	if logger, logErr := audit.NewLogger(); logErr == nil {
+		commands := make([]string, len(ran))
+		for i, command := range ran {
+			commands[i] = command.String()
+		}
+		errMsg := ""
+		if err != nil {
+			errMsg = err.Error()
+		}
+		logger.LogDNSFlush(commands, err == nil, errMsg)
+	}
+
+	return err
+}
+
+// completeCategories provides shell completion for --category flags
+func completeCategories(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	p := platform.New()
+	return categoryCompletions(p.GetHostsFilePath(), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// categoryCompletions returns the sorted union of categories found in the hosts
+// file at hostsPath and those defined in the configuration, filtered by prefix.
+// It only reads the hosts file; an unreadable file falls back to config categories.
+func categoryCompletions(hostsPath, prefix string) []string {
+	seen := make(map[string]bool)
+
+	if hostsFile, err := hosts.NewParser(hostsPath).Parse(); err == nil {
+		for _, category := range hostsFile.Categories {
+			seen[category.Name] = true
+		}
+	}
+
+	if cfg != nil {
+		for name := range cfg.Categories {
+			seen[name] = true
+		}
+	}
+
+	var names []string
+	for name := range seen {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// exportToHosts renders the enabled entries in hosts format. Styles with a
+// banner write only the banner per category, as exports always have.
+func exportToHosts(hostsFile *hosts.HostsFile, style hosts.BannerStyle) ([]byte, error) {
+	hostsFile.SetBannerStyle(style)
+
 	var builder strings.Builder
 
 	for _, headerLine := range hostsFile.Header {
@@ -656,7 +3576,13 @@ func exportToHosts(hostsFile *hosts.HostsFile) ([]byte, error) {
 			continue
 		}
 
-		builder.WriteString(fmt.Sprintf("# =============== %s ===============\n", strings.ToUpper(category.Name)))
+		header := hostsFile.CategoryHeader(category)
+		if len(header) > 1 {
+			header = header[1:]
+		}
+		for _, line := range header {
+			builder.WriteString(line + "\n")
+		}
 
 		for _, entry := range category.Entries {
 			if !entry.Enabled {
@@ -664,8 +3590,8 @@ func exportToHosts(hostsFile *hosts.HostsFile) ([]byte, error) {
 			}
 
 			line := fmt.Sprintf("%s %s", entry.IP, strings.Join(entry.Hostnames, " "))
-			if entry.Comment != "" {
-				line += " # " + entry.Comment
+			if comment := entry.TaggedComment(); comment != "" {
+				line += " # " + comment
 			}
 			builder.WriteString(line + "\n")
 		}
@@ -680,6 +3606,62 @@ func exportToHosts(hostsFile *hosts.HostsFile) ([]byte, error) {
 	return []byte(builder.String()), nil
 }
 
+// exportToDnsmasq renders the enabled entries of enabled categories as
+// dnsmasq address lines, one per hostname
+func exportToDnsmasq(hostsFile *hosts.HostsFile) []byte {
+	var builder strings.Builder
+	for _, category := range hostsFile.Categories {
+		if !category.Enabled {
+			continue
+		}
+		for _, entry := range category.Entries {
+			if !entry.Enabled {
+				continue
+			}
+			for _, hostname := range entry.Hostnames {
+				fmt.Fprintf(&builder, "address=/%s/%s\n", hostname, entry.IP)
+			}
+		}
+	}
+	return []byte(builder.String())
+}
+
+// exportTemplateData is what custom export templates render. It holds the
+// parsed structure only, so templates cannot call HostsFile methods.
+type exportTemplateData struct {
+	Categories []hosts.Category
+	Header     []string
+	Footer     []string
+}
+
+// exportToTemplate renders hostsFile through the text/template of the
+// config-defined format name. Templates are checked for dangerous content
+// again here, as the config file may have changed since it was validated.
+func exportToTemplate(hostsFile *hosts.HostsFile, name string, format config.Format) ([]byte, error) {
+	if config.IsSuspiciousTemplate(format.Template) {
+		return nil, fmt.Errorf("template for export format %s contains potentially dangerous content", name)
+	}
+
+	tmpl, err := template.New(name).
+		Funcs(template.FuncMap{"join": strings.Join}).
+		Option("missingkey=error").
+		Parse(format.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template for export format %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	data := exportTemplateData{
+		Categories: hostsFile.Categories,
+		Header:     hostsFile.Header,
+		Footer:     hostsFile.Footer,
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render export format %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
 func formatSize(size int64) string {
 	const unit = 1024
 	if size < unit {
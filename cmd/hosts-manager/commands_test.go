@@ -2,8 +2,28 @@ package main
 
 import (
 	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/brandonhon/hosts-manager/internal/audit"
+	"github.com/brandonhon/hosts-manager/internal/backup"
+	"github.com/brandonhon/hosts-manager/internal/config"
+	"github.com/brandonhon/hosts-manager/internal/hosts"
+	"github.com/brandonhon/hosts-manager/internal/watch"
+	"github.com/brandonhon/hosts-manager/pkg/platform"
+	"github.com/brandonhon/hosts-manager/pkg/search"
+
+	"github.com/spf13/cobra"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestCategoryAddCmd(t *testing.T) {
@@ -92,3 +112,1617 @@ func TestCategoryAddCmdStructure(t *testing.T) {
 		t.Errorf("Expected specific argument error, got: %v", err)
 	}
 }
+
+func TestWriteHostsFileGuard(t *testing.T) {
+	origCfg, origForce := cfg, force
+	defer func() { cfg, force = origCfg, origForce }()
+	cfg = config.DefaultConfig()
+
+	hostsPath := filepath.Join(t.TempDir(), "hosts")
+	content := "127.0.0.1 localhost\n192.168.1.10 api.dev\n"
+	if err := os.WriteFile(hostsPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test hosts file: %v", err)
+	}
+
+	hostsFile, err := hosts.NewParser(hostsPath).Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse test hosts file: %v", err)
+	}
+	for i := range hostsFile.Categories {
+		hostsFile.DisableCategory(hostsFile.Categories[i].Name)
+	}
+
+	// Near-empty write is refused without --force
+	force = false
+	err = writeHostsFile(hostsFile, hostsPath)
+	if err == nil {
+		t.Fatal("Expected write with zero enabled entries to be refused")
+	}
+	if !strings.Contains(err.Error(), "--force") {
+		t.Errorf("Expected error to mention --force, got: %v", err)
+	}
+
+	data, _ := os.ReadFile(hostsPath)
+	if string(data) != content {
+		t.Error("Hosts file should be unchanged after a refused write")
+	}
+
+	// The same write succeeds with --force
+	force = true
+	if err := writeHostsFile(hostsFile, hostsPath); err != nil {
+		t.Fatalf("Expected forced write to succeed, got: %v", err)
+	}
+
+	written, err := hosts.NewParser(hostsPath).Parse()
+	if err != nil {
+		t.Fatalf("Failed to re-parse written file: %v", err)
+	}
+	if written.EnabledEntryCount() != 0 {
+		t.Errorf("Expected 0 enabled entries after forced write, got %d", written.EnabledEntryCount())
+	}
+}
+
+func TestCategoryCompletions(t *testing.T) {
+	origCfg := cfg
+	defer func() { cfg = origCfg }()
+	cfg = config.DefaultConfig()
+	cfg.Categories = map[string]string{
+		"development": "Development",
+		"custom":      "Custom",
+	}
+
+	hostsPath := filepath.Join(t.TempDir(), "hosts")
+	content := `127.0.0.1 localhost
+
+# @category development
+192.168.1.10 api.dev
+
+# @category lab
+10.0.0.5 printer.lab
+`
+	if err := os.WriteFile(hostsPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test hosts file: %v", err)
+	}
+	before, _ := os.ReadFile(hostsPath)
+
+	got := categoryCompletions(hostsPath, "")
+	want := []string{"custom", "default", "development", "lab"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected completions %v, got %v", want, got)
+	}
+
+	got = categoryCompletions(hostsPath, "de")
+	want = []string{"default", "development"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected prefix completions %v, got %v", want, got)
+	}
+
+	// Completion must not modify the hosts file
+	after, _ := os.ReadFile(hostsPath)
+	if string(before) != string(after) {
+		t.Error("Completion should not modify the hosts file")
+	}
+
+	// A missing hosts file still yields the configured categories
+	got = categoryCompletions(filepath.Join(t.TempDir(), "missing"), "")
+	want = []string{"custom", "development"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected config-only completions %v, got %v", want, got)
+	}
+}
+
+func TestAddCmdCategoryCompletionRegistered(t *testing.T) {
+	cmd := addCmd()
+	if _, ok := cmd.GetFlagCompletionFunc("category"); !ok {
+		t.Error("Expected completion function registered for --category")
+	}
+}
+
+func TestCheckReadOnly(t *testing.T) {
+	origForce := force
+	defer func() { force = origForce }()
+
+	hostsPath := filepath.Join(t.TempDir(), "hosts")
+	content := "172.17.0.1 host.docker.internal\n192.168.1.10 api.dev\n"
+	if err := os.WriteFile(hostsPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test hosts file: %v", err)
+	}
+
+	ignore, _ := hosts.NewIgnoreList([]string{"host.docker.internal"})
+	parser := hosts.NewParser(hostsPath)
+	parser.SetIgnoreList(ignore)
+	hostsFile, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse test hosts file: %v", err)
+	}
+
+	force = false
+	if err := checkReadOnly(hostsFile, "host.docker.internal"); err == nil {
+		t.Error("Expected ignored hostname to be refused without --force")
+	}
+	if err := checkReadOnly(hostsFile, "api.dev"); err != nil {
+		t.Errorf("Expected managed hostname to be allowed, got: %v", err)
+	}
+
+	force = true
+	if err := checkReadOnly(hostsFile, "host.docker.internal"); err != nil {
+		t.Errorf("Expected ignored hostname to be allowed with --force, got: %v", err)
+	}
+}
+
+func TestApplyEntryUpdate(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	boolPtr := func(b bool) *bool { return &b }
+
+	content := `127.0.0.1 localhost
+::1 localhost
+
+# @category development
+192.168.1.100 api.dev web.dev # API
+`
+
+	tests := []struct {
+		name     string
+		hostname string
+		changes  entryUpdate
+		wantErr  string
+		validate func(*testing.T, *hosts.HostsFile)
+	}{
+		{
+			name:     "change IP and comment",
+			hostname: "web.dev",
+			changes:  entryUpdate{ip: strPtr("192.168.1.200"), comment: strPtr("moved")},
+			validate: func(t *testing.T, hf *hosts.HostsFile) {
+				entry := hf.FindEntryByHostname("web.dev")[0]
+				if entry.IP != "192.168.1.200" || entry.Comment != "moved" {
+					t.Errorf("Expected updated IP and comment, got %+v", entry)
+				}
+			},
+		},
+		{
+			name:     "move to new category",
+			hostname: "api.dev",
+			changes:  entryUpdate{category: strPtr("staging")},
+			validate: func(t *testing.T, hf *hosts.HostsFile) {
+				category := hf.GetCategory("staging")
+				if category == nil || len(category.Entries) != 1 {
+					t.Fatalf("Expected entry moved into new staging category")
+				}
+				if len(hf.GetCategory("development").Entries) != 0 {
+					t.Error("Expected entry removed from development")
+				}
+			},
+		},
+		{
+			name:     "disable and enable",
+			hostname: "api.dev",
+			changes:  entryUpdate{enabled: boolPtr(false)},
+			validate: func(t *testing.T, hf *hosts.HostsFile) {
+				entry := hf.FindEntryByHostname("api.dev")[0]
+				if entry.Enabled || entry.IP != "192.168.1.100" {
+					t.Fatalf("Expected entry disabled and otherwise unchanged, got %+v", entry)
+				}
+				if _, _, err := applyEntryUpdate(hf, "api.dev", entryUpdate{enabled: boolPtr(true)}); err != nil || !entry.Enabled {
+					t.Errorf("Expected entry enabled again, got %+v (err %v)", entry, err)
+				}
+			},
+		},
+		{
+			name:     "disable with invalid IP",
+			hostname: "api.dev",
+			changes:  entryUpdate{ip: strPtr("not-an-ip"), enabled: boolPtr(false)},
+			wantErr:  "invalid update",
+		},
+		{
+			name:     "invalid IP",
+			hostname: "api.dev",
+			changes:  entryUpdate{ip: strPtr("not-an-ip")},
+			wantErr:  "invalid update",
+		},
+		{
+			name:     "unknown hostname",
+			hostname: "missing.dev",
+			changes:  entryUpdate{comment: strPtr("x")},
+			wantErr:  "hostname not found",
+		},
+		{
+			name:     "ambiguous hostname",
+			hostname: "localhost",
+			changes:  entryUpdate{comment: strPtr("x")},
+			wantErr:  "matches 2 entries",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hostsPath := filepath.Join(t.TempDir(), "hosts")
+			if err := os.WriteFile(hostsPath, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test hosts file: %v", err)
+			}
+			hostsFile, err := hosts.NewParser(hostsPath).Parse()
+			if err != nil {
+				t.Fatalf("Failed to parse test hosts file: %v", err)
+			}
+
+			_, _, err = applyEntryUpdate(hostsFile, tt.hostname, tt.changes)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyEntryUpdate() error = %v", err)
+			}
+			tt.validate(t, hostsFile)
+		})
+	}
+}
+
+func TestPrepareImportHostnameModes(t *testing.T) {
+	data := []byte(`{"categories":[{"name":"blocklist","enabled":true,"entries":[` +
+		`{"ip":"127.0.0.1","hostnames":["ads_tracker.example.com"],"enabled":true,"category":"blocklist"},` +
+		`{"ip":"127.0.0.1","hostnames":["clean.example.com"],"enabled":true,"category":"blocklist"}]}]}`)
+
+	decode := func(t *testing.T) *hosts.HostsFile {
+		t.Helper()
+		var imported *hosts.HostsFile
+		if err := json.Unmarshal(data, &imported); err != nil {
+			t.Fatalf("Failed to decode import data: %v", err)
+		}
+		return imported
+	}
+
+	t.Run("strict rejects underscores", func(t *testing.T) {
+		if _, _, _, err := prepareImport(decode(t), nil, importOptions{mode: hosts.HostnameStrict}); err == nil {
+			t.Error("Expected strict import of underscore hostname to fail")
+		}
+	})
+
+	t.Run("lenient accepts underscores", func(t *testing.T) {
+		result, _, _, err := prepareImport(decode(t), nil, importOptions{mode: hosts.HostnameLenient})
+		if err != nil {
+			t.Fatalf("Expected lenient import to succeed, got: %v", err)
+		}
+		if len(result.Categories) != 1 || len(result.Categories[0].Entries) != 2 {
+			t.Errorf("Expected 2 imported entries, got %+v", result.Categories)
+		}
+	})
+
+	t.Run("lenient merge", func(t *testing.T) {
+		current := &hosts.HostsFile{}
+		result, _, _, err := prepareImport(decode(t), current, importOptions{mode: hosts.HostnameLenient, strategy: hosts.MergeKeepBoth})
+		if err != nil {
+			t.Fatalf("Expected lenient merge to succeed, got: %v", err)
+		}
+		if len(result.FindEntryByHostname("ads_tracker.example.com")) != 1 {
+			t.Error("Expected underscore hostname merged into current hosts file")
+		}
+	})
+
+	t.Run("strict merge", func(t *testing.T) {
+		if _, _, _, err := prepareImport(decode(t), &hosts.HostsFile{}, importOptions{mode: hosts.HostnameStrict, strategy: hosts.MergeKeepBoth}); err == nil {
+			t.Error("Expected strict merge of underscore hostname to fail")
+		}
+	})
+}
+
+// runImportDryRun runs import --dry-run on an import file holding an
+// underscore hostname, in a scratch home directory and hosts file
+func runImportDryRun(t *testing.T, allowUnderscores bool, flags ...string) error {
+	t.Helper()
+	origCfg, origDryRun := cfg, dryRun
+	t.Cleanup(func() {
+		cfg, dryRun = origCfg, origDryRun
+		audit.SetDefaultConfig(origCfg)
+		_ = platform.SetHostsFileOverride("")
+	})
+
+	tempDir := t.TempDir()
+	for _, env := range []string{"HOME", "XDG_CONFIG_HOME", "XDG_DATA_HOME", "APPDATA", "LOCALAPPDATA", "TMPDIR"} {
+		t.Setenv(env, filepath.Join(tempDir, strings.ToLower(env)))
+	}
+	hostsPath := filepath.Join(tempDir, "hosts")
+	if err := os.WriteFile(hostsPath, []byte("127.0.0.1 localhost\n"), 0644); err != nil {
+		t.Fatalf("Failed to write hosts file: %v", err)
+	}
+	if err := platform.SetHostsFileOverride(hostsPath); err != nil {
+		t.Fatalf("SetHostsFileOverride() error = %v", err)
+	}
+
+	cfg = config.DefaultConfig()
+	cfg.Audit.Enabled = false
+	cfg.General.AllowUnderscoreHostnames = allowUnderscores
+	audit.SetDefaultConfig(cfg)
+	dryRun = true
+
+	dataDir := platform.New().GetDataDir()
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	importPath := filepath.Join(dataDir, "import.json")
+	data := `{"categories":[{"name":"corp","enabled":true,"entries":[` +
+		`{"ip":"10.0.0.20","hostnames":["build_01.corp.internal"],"enabled":true,"category":"corp"}]}]}`
+	if err := os.WriteFile(importPath, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := importCmd()
+	cmd.SetArgs(append([]string{importPath, "--format", "json"}, flags...))
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	return cmd.Execute()
+}
+
+func TestImportStrictHostnamesFlag(t *testing.T) {
+	if flag := importCmd().Flags().Lookup("strict-hostnames"); flag == nil || flag.DefValue != "false" {
+		t.Fatalf("Expected --strict-hostnames to default to false, got %+v", flag)
+	}
+
+	if err := runImportDryRun(t, false); err == nil {
+		t.Error("Expected underscore hostname to be rejected by default")
+	}
+	if err := runImportDryRun(t, true, "--strict-hostnames=false"); err != nil {
+		t.Errorf("Expected --strict-hostnames=false to keep the configured mode, got %v", err)
+	}
+	if err := runImportDryRun(t, false, "--strict-hostnames"); err == nil {
+		t.Error("Expected --strict-hostnames to reject underscore hostnames")
+	}
+}
+
+func TestImportStrictHostnamesOverridesConfig(t *testing.T) {
+	if err := runImportDryRun(t, true); err != nil {
+		t.Fatalf("Expected allow_underscore_hostnames to accept underscores, got %v", err)
+	}
+	if err := runImportDryRun(t, true, "--strict-hostnames"); err == nil {
+		t.Error("Expected --strict-hostnames to reject underscores with allow_underscore_hostnames set")
+	}
+}
+
+func TestResolveHostnameMode(t *testing.T) {
+	origCfg := cfg
+	defer func() { cfg = origCfg }()
+	cfg = config.DefaultConfig()
+
+	tests := []struct {
+		allowUnderscores bool
+		lenient, strict  bool
+		want             hosts.HostnameValidationMode
+	}{
+		{allowUnderscores: false, want: hosts.HostnameStrict},
+		{allowUnderscores: true, want: hosts.HostnameUnderscores},
+		{allowUnderscores: true, strict: true, want: hosts.HostnameStrict},
+		{allowUnderscores: false, lenient: true, want: hosts.HostnameLenient},
+	}
+	for _, tt := range tests {
+		cfg.General.AllowUnderscoreHostnames = tt.allowUnderscores
+		if got := resolveHostnameMode(tt.lenient, hosts.HostnameLenient, tt.strict); got != tt.want {
+			t.Errorf("resolveHostnameMode(lenient=%v, strict=%v) with allow_underscore_hostnames=%v = %v, want %v",
+				tt.lenient, tt.strict, tt.allowUnderscores, got, tt.want)
+		}
+	}
+}
+
+func TestPrepareImportSummary(t *testing.T) {
+	imported := &hosts.HostsFile{Categories: []hosts.Category{{Name: "development", Enabled: true, Entries: []hosts.Entry{
+		{IP: "192.168.1.10", Hostnames: []string{"api.dev"}, Enabled: true},
+		{IP: "192.168.1.11", Hostnames: []string{"web.dev"}, Enabled: true},
+		{IP: "192.168.1.11", Hostnames: []string{"web.dev"}, Enabled: true},
+		{IP: "192.168.1.12", Hostnames: []string{"bad host"}, Enabled: true},
+	}}}}
+	current, err := hosts.NewParser("").ParseReader(strings.NewReader("# @category development\n192.168.1.10 api.dev\n"))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	if _, _, summary, err := prepareImport(imported, current, importOptions{strategy: hosts.MergeKeepBoth}); err == nil || len(summary.Rejected) != 1 {
+		t.Fatalf("Expected the invalid entry to fail the import, got %v, %+v", err, summary)
+	}
+
+	var batches []int
+	merged, _, summary, err := prepareImport(imported, current, importOptions{
+		strategy:    hosts.MergeKeepBoth,
+		skipInvalid: true,
+		progress:    func(done, total int) { batches = append(batches, done) },
+	})
+	if err != nil {
+		t.Fatalf("prepareImport() error = %v", err)
+	}
+	if summary.Added != 1 || summary.Duplicates != 2 || len(summary.Rejected) != 1 {
+		t.Errorf("prepareImport() summary = %+v, want 1 added, 2 duplicates, 1 rejected", summary)
+	}
+	if len(merged.FindEntryByHostname("api.dev")) != 1 || len(merged.FindEntryByHostname("web.dev")) != 1 {
+		t.Errorf("Duplicates should not be merged, got %+v", merged.Categories)
+	}
+	if !slices.Equal(batches, []int{4}) {
+		t.Errorf("progress called with %v, want [4]", batches)
+	}
+
+	var buf bytes.Buffer
+	writeImportSummary(&buf, summary)
+	if got := buf.String(); got != "1 added, 2 skipped as duplicates, 1 rejected as invalid\n" {
+		t.Errorf("writeImportSummary() = %q", got)
+	}
+}
+
+func TestWriteMergePreview(t *testing.T) {
+	current, err := hosts.NewParser("").ParseReader(strings.NewReader("# @category development\n192.168.1.10 api.dev\n"))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	imported := &hosts.HostsFile{Categories: []hosts.Category{{
+		Name: "development",
+		Entries: []hosts.Entry{
+			{IP: "192.168.1.10", Hostnames: []string{"api.dev"}, Enabled: true},
+			{IP: "192.168.1.20", Hostnames: []string{"web.dev"}, Enabled: true},
+			{IP: "10.0.0.1", Hostnames: []string{"api.dev"}, Enabled: true},
+		},
+	}}}
+
+	var buf bytes.Buffer
+	writeMergePreview(&buf, current.CompareForMerge(importedEntries(imported)))
+	out := buf.String()
+
+	for _, want := range []string{
+		"Would merge 3 entries",
+		"New (1):\n  + ✓ [development] 192.168.1.20 -> [web.dev]",
+		"Already present (1):\n  = ✓ [development] 192.168.1.10 -> [api.dev]",
+		"  ! ✓ [development] 10.0.0.1 -> [api.dev] (current: 192.168.1.10)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected preview to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestListEntriesStructuredOutput(t *testing.T) {
+	hostsPath := filepath.Join(t.TempDir(), "hosts")
+	content := `127.0.0.1 localhost
+
+# @category development
+192.168.1.100 api.dev web.dev # API @tags web,backend
+# 192.168.1.101 old.dev
+`
+	if err := os.WriteFile(hostsPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test hosts file: %v", err)
+	}
+	hostsFile, err := hosts.NewParser(hostsPath).Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse test hosts file: %v", err)
+	}
+
+	entries := listEntries(hostsFile, "development", "", false)
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 enabled development entry, got %d", len(entries))
+	}
+	if entries[0].LineNum == 0 || entries[0].Category != "development" {
+		t.Errorf("Expected line number and category to be set, got %+v", entries[0])
+	}
+	if got := len(listEntries(hostsFile, "development", "", true)); got != 2 {
+		t.Errorf("Expected 2 entries with --show-disabled, got %d", got)
+	}
+	if got := len(listEntries(hostsFile, "", "WEB", true)); got != 1 {
+		t.Errorf("Expected 1 entry tagged web, got %d", got)
+	}
+	if got := len(listEntries(hostsFile, "", "frontend", true)); got != 0 {
+		t.Errorf("Expected no entries tagged frontend, got %d", got)
+	}
+
+	var buf bytes.Buffer
+	if err := writeEntryList(&buf, entries, "json"); err != nil {
+		t.Fatalf("writeEntryList(json) error = %v", err)
+	}
+	var decoded []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Failed to decode JSON output: %v", err)
+	}
+	for _, key := range []string{"ip", "hostnames", "comment", "category", "enabled", "line_num", "tags"} {
+		if _, ok := decoded[0][key]; !ok {
+			t.Errorf("JSON output missing %q field", key)
+		}
+	}
+
+	buf.Reset()
+	if err := writeEntryList(&buf, entries, "yaml"); err != nil {
+		t.Fatalf("writeEntryList(yaml) error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "line_num:") {
+		t.Errorf("YAML output missing line_num: %s", buf.String())
+	}
+
+	empty := listEntries(&hosts.HostsFile{}, "", "", false)
+	buf.Reset()
+	if err := writeEntryList(&buf, empty, "json"); err != nil {
+		t.Fatalf("writeEntryList(json) on empty list error = %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("Expected [] for empty list, got %q", buf.String())
+	}
+
+	if err := writeEntryList(&buf, entries, "xml"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestWriteEntryTable(t *testing.T) {
+	content := `127.0.0.1 localhost
+
+# @category development
+192.168.1.100 api.dev web.dev # API @tags web,backend
+# 192.168.1.101 old.dev
+`
+	hostsFile, err := hosts.NewParser("").ParseReader(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to parse test hosts file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	writeEntryTable(&buf, hostsFile, "development", "", false, true)
+	expected := "\n=== development ===\nStatus: Enabled\n   4  ✓ 192.168.1.100 -> [api.dev web.dev] # API [tags: web,backend]\n"
+	if buf.String() != expected {
+		t.Errorf("writeEntryTable() = %q, want %q", buf.String(), expected)
+	}
+
+	buf.Reset()
+	writeEntryTable(&buf, hostsFile, "", "", true, false)
+	for _, want := range []string{"=== default ===", "✓ 127.0.0.1 -> [localhost]", "✗ 192.168.1.101 -> [old.dev]"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeEntryTable() missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestLineChanges(t *testing.T) {
+	current := []byte("127.0.0.1 localhost\n192.168.1.10 api.dev\n\n192.168.1.20 new.dev\n")
+	previous := []byte("127.0.0.1 localhost\n192.168.1.10 api.dev\n192.168.1.30 old.dev\n")
+
+	removed, added := lineChanges(current, previous)
+	if len(removed) != 1 || removed[0] != "192.168.1.20 new.dev" {
+		t.Errorf("Expected new.dev removed, got %v", removed)
+	}
+	if len(added) != 1 || added[0] != "192.168.1.30 old.dev" {
+		t.Errorf("Expected old.dev added, got %v", added)
+	}
+
+	removed, added = lineChanges(current, current)
+	if len(removed) != 0 || len(added) != 0 {
+		t.Errorf("Expected no changes for identical input, got -%v +%v", removed, added)
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		got, err := confirm(strings.NewReader(tt.input), "Proceed?")
+		if err != nil {
+			t.Fatalf("confirm(%q) error = %v", tt.input, err)
+		}
+		if got != tt.expected {
+			t.Errorf("confirm(%q) = %v, want %v", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestResolveBackup(t *testing.T) {
+	origCfg := cfg
+	defer func() { cfg = origCfg }()
+	cfg = config.DefaultConfig()
+	cfg.Backup.Directory = t.TempDir()
+	backupMgr := backup.NewManager(cfg)
+
+	if _, err := resolveBackup(backupMgr, "", backup.Filter{}); err == nil {
+		t.Error("Expected error when no backups exist")
+	}
+
+	older := filepath.Join(cfg.Backup.Directory, "hosts.backup.2023-12-01T10-30-00")
+	newer := filepath.Join(cfg.Backup.Directory, "hosts.backup.auto.2023-12-02T10-30-00")
+	for _, path := range []string{older, newer} {
+		if err := os.WriteFile(path, []byte("127.0.0.1 localhost\n"), 0600); err != nil {
+			t.Fatalf("Failed to write backup: %v", err)
+		}
+	}
+
+	tests := []struct {
+		arg      string
+		expected string
+		wantErr  bool
+	}{
+		{arg: "", expected: newer},
+		{arg: "1", expected: newer},
+		{arg: "2", expected: older},
+		{arg: "3", wantErr: true},
+		{arg: "0", wantErr: true},
+		{arg: "hosts.backup.2023-12-01T10-30-00", expected: older},
+		{arg: "../outside", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveBackup(backupMgr, tt.arg, backup.Filter{})
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("resolveBackup(%q) expected error, got %s", tt.arg, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("resolveBackup(%q) error = %v", tt.arg, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("resolveBackup(%q) = %s, want %s", tt.arg, got, tt.expected)
+		}
+	}
+
+	// Indexes and the default count only backups inside the window
+	window := backup.Filter{Before: time.Date(2023, 12, 2, 0, 0, 0, 0, time.UTC)}
+	if got, err := resolveBackup(backupMgr, "", window); err != nil || got != older {
+		t.Errorf("resolveBackup(\"\") with --before = %s, %v, want %s", got, err, older)
+	}
+	if _, err := resolveBackup(backupMgr, "2", window); err == nil {
+		t.Error("Expected index 2 to be out of range inside the window")
+	}
+	if _, err := (backupWindow{since: "yesterday"}).filter(time.Now()); err == nil {
+		t.Error("Expected an invalid --since to fail")
+	}
+}
+
+func TestFormatEntryChange(t *testing.T) {
+	tests := []struct {
+		change   hosts.EntryChange
+		expected string
+	}{
+		{hosts.EntryChange{Kind: hosts.ChangeAdded, Hostname: "new.dev", New: hosts.Entry{IP: "10.0.0.1"}}, "+ 10.0.0.1 new.dev"},
+		{hosts.EntryChange{Kind: hosts.ChangeRemoved, Hostname: "old.dev", Old: hosts.Entry{IP: "10.0.0.2"}}, "- 10.0.0.2 old.dev"},
+		{hosts.EntryChange{Kind: hosts.ChangeIP, Hostname: "api.dev", Old: hosts.Entry{IP: "10.0.0.3"}, New: hosts.Entry{IP: "10.0.0.4"}}, "~ api.dev: 10.0.0.3 -> 10.0.0.4"},
+		{hosts.EntryChange{Kind: hosts.ChangeDisabled, Hostname: "web.dev"}, "~ web.dev: disabled"},
+		{hosts.EntryChange{Kind: hosts.ChangeMoved, Hostname: "db.dev", Old: hosts.Entry{Category: "staging"}}, "~ db.dev: moved from staging"},
+	}
+
+	for _, tt := range tests {
+		if got := formatEntryChange(tt.change); got != tt.expected {
+			t.Errorf("formatEntryChange(%s) = %q, want %q", tt.change.Kind, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatProblem(t *testing.T) {
+	problem := hosts.Problem{LineNum: 3, Field: "hostname", Value: "my_host.dev", Reason: "invalid hostname format"}
+	expected := `line 3: hostname "my_host.dev": invalid hostname format`
+	if got := formatProblem(problem); got != expected {
+		t.Errorf("formatProblem() = %q, want %q", got, expected)
+	}
+}
+
+func TestWriteBugReportRedactsHostsData(t *testing.T) {
+	hostsFile, err := hosts.NewParser("").ParseReader(strings.NewReader(`127.0.0.1 localhost
+
+# @category development
+192.168.77.10 secret-api.corp.example
+# 10.20.30.40 internal-db.corp.example # payroll
+`))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	testCfg := config.DefaultConfig()
+	testCfg.Blocklists.URLs = []string{"https://lists.corp.example/hosts"}
+
+	events := []audit.AuditEvent{
+		{
+			Timestamp: time.Now(),
+			EventType: audit.EventHostsAdd,
+			Severity:  audit.SeverityError,
+			Operation: "add",
+			Resource:  "hosts_file",
+			ErrorMsg:  "hostname secret-api.corp.example already exists at 192.168.77.10",
+			Username:  "alice",
+			Details:   map[string]interface{}{"hostnames": []string{"internal-db.corp.example"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeBugReport(&buf, testCfg, hostsFile, nil, events); err != nil {
+		t.Fatalf("writeBugReport() error = %v", err)
+	}
+	report := buf.String()
+
+	for _, section := range []string{"## Version", "## Configuration", "## Hosts File Summary", "## Recent Audit Events (1)"} {
+		if !strings.Contains(report, section) {
+			t.Errorf("Expected bug report to contain section %q", section)
+		}
+	}
+	for _, expected := range []string{"entries: 3", "disabled: 1", "development: 2", "op=add", "[hostname]"} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("Expected bug report to contain %q, got:\n%s", expected, report)
+		}
+	}
+	for _, leaked := range []string{"secret-api", "internal-db", "corp.example", "192.168.77.10", "10.20.30.40", "payroll", "alice"} {
+		if strings.Contains(report, leaked) {
+			t.Errorf("Bug report leaked %q:\n%s", leaked, report)
+		}
+	}
+}
+
+func TestHighlightMatch(t *testing.T) {
+	mark := func(s string) string { return "[" + s + "]" }
+
+	tests := []struct {
+		result   search.Result
+		expected string
+	}{
+		{search.Result{Match: "myapi.dev", MatchPositions: []int{2, 3, 4}}, "my[api].dev"},
+		{search.Result{Match: "myapi.dev", MatchPositions: []int{0, 1, 6, 7, 8}}, "[my]api.[dev]"},
+		{search.Result{Match: "myapi.dev"}, "myapi.dev"},
+	}
+
+	for _, tt := range tests {
+		if got := highlightMatch(tt.result, mark); got != tt.expected {
+			t.Errorf("highlightMatch(%v) = %q, want %q", tt.result.MatchPositions, got, tt.expected)
+		}
+	}
+}
+
+func TestCommandEvent(t *testing.T) {
+	root := &cobra.Command{Use: "hosts-manager"}
+	add := &cobra.Command{Use: "add", RunE: func(*cobra.Command, []string) error { return nil }}
+	root.AddCommand(add)
+	if err := add.Flags().Parse([]string{"192.168.1.10", "api.dev"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	event := commandEvent(add, nil)
+	if event.EventType != audit.EventCommand || event.Operation != "hosts-manager add" || event.Resource != "192.168.1.10 api.dev" || !event.Success {
+		t.Errorf("Unexpected event for a successful command: %+v", event)
+	}
+
+	event = commandEvent(add, fmt.Errorf("failed to add entry"))
+	if event.Success || event.Severity != audit.SeverityError || event.ErrorMsg != "failed to add entry" {
+		t.Errorf("Unexpected event for a failed command: %+v", event)
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	origMode := colorMode
+	defer func() { colorMode = origMode }()
+
+	// A regular file is never a terminal, so auto leaves it plain
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatalf("CreateTemp() error = %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	tests := []struct {
+		mode    string
+		noColor string
+		want    bool
+	}{
+		{mode: "auto", want: false},
+		{mode: "always", want: true},
+		{mode: "always", noColor: "1", want: true},
+		{mode: "never", want: false},
+	}
+	for _, tt := range tests {
+		colorMode = tt.mode
+		t.Setenv("NO_COLOR", tt.noColor)
+		if got := colorEnabled(f); got != tt.want {
+			t.Errorf("colorEnabled() with --color %s and NO_COLOR=%q = %v, want %v", tt.mode, tt.noColor, got, tt.want)
+		}
+	}
+
+	colorMode = "sometimes"
+	if err := applyColorMode(); err == nil {
+		t.Error("Expected an invalid --color to fail")
+	}
+}
+
+func TestMoveHostname(t *testing.T) {
+	parse := func(t *testing.T) *hosts.HostsFile {
+		t.Helper()
+		hostsFile, err := hosts.NewParser("").ParseReader(strings.NewReader(`# @category development
+192.168.1.10 api.dev
+192.168.1.11 shared.dev
+
+# @category staging
+10.0.0.5 db.stage
+10.0.0.6 shared.dev
+`))
+		if err != nil {
+			t.Fatalf("ParseReader() error = %v", err)
+		}
+		return hostsFile
+	}
+
+	hostsFile := parse(t)
+	before, err := moveHostname(hostsFile, "api.dev", "staging", false)
+	if err != nil {
+		t.Fatalf("moveHostname() error = %v", err)
+	}
+	if before.Category != "development" {
+		t.Errorf("Expected previous category development, got %s", before.Category)
+	}
+	moved := hostsFile.FindEntryByHostname("api.dev")
+	if len(moved) != 1 || moved[0].Category != "staging" || hostsFile.GetCategory("staging").Entries[2].Hostnames[0] != "api.dev" {
+		t.Errorf("Expected api.dev appended to staging, got %+v", moved)
+	}
+
+	if _, err := moveHostname(parse(t), "api.dev", "production", false); err == nil || !strings.Contains(err.Error(), "--create") {
+		t.Errorf("Expected missing category error, got %v", err)
+	}
+
+	hostsFile = parse(t)
+	if _, err := moveHostname(hostsFile, "api.dev", "production", true); err != nil {
+		t.Fatalf("moveHostname() with create error = %v", err)
+	}
+	if entries := hostsFile.GetCategory("production").Entries; len(entries) != 1 || entries[0].Hostnames[0] != "api.dev" {
+		t.Errorf("Expected api.dev in created production category, got %+v", entries)
+	}
+
+	_, err = moveHostname(parse(t), "shared.dev", "staging", false)
+	if err == nil || !strings.Contains(err.Error(), "[development]") || !strings.Contains(err.Error(), "[staging]") {
+		t.Errorf("Expected ambiguity error listing both categories, got %v", err)
+	}
+}
+
+func TestSetLineEnabled(t *testing.T) {
+	hostsFile, err := hosts.NewParser("").ParseReader(strings.NewReader(`127.0.0.1 localhost
+192.168.1.10 api.dev
+# 192.168.1.20 api.dev
+`))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	// Line 3 shares its hostname with line 2; only line 3 should change
+	entry, err := setLineEnabled(hostsFile, 3, true)
+	if err != nil {
+		t.Fatalf("setLineEnabled() error = %v", err)
+	}
+	if !entry.Enabled || entry.IP != "192.168.1.20" {
+		t.Errorf("Expected line 3 entry to be enabled, got %+v", entry)
+	}
+	if line2 := hostsFile.FindEntryByLine(2); line2 == nil || !line2.Enabled {
+		t.Errorf("Expected line 2 to be untouched, got %+v", line2)
+	}
+
+	if _, err := setLineEnabled(hostsFile, 2, false); err != nil || hostsFile.FindEntryByLine(2).Enabled {
+		t.Errorf("Expected line 2 to be disabled, err = %v", err)
+	}
+
+	if _, err := setLineEnabled(hostsFile, 4, false); err == nil || !strings.Contains(err.Error(), "no entry on line 4") {
+		t.Errorf("Expected missing line error, got %v", err)
+	}
+}
+
+func TestFilterEventsAndWriteHistory(t *testing.T) {
+	now := time.Now()
+	events := []audit.AuditEvent{
+		{Timestamp: now.Add(-48 * time.Hour), EventType: audit.EventHostsAdd, Operation: "add", Resource: "/etc/hosts", Success: true},
+		{Timestamp: now.Add(-2 * time.Hour), EventType: audit.EventHostsDelete, Operation: "delete", Resource: "/etc/hosts", Success: false},
+		{Timestamp: now.Add(-1 * time.Hour), EventType: audit.EventHostsAdd, Operation: "add", Resource: "/etc/hosts", Success: true},
+	}
+
+	if got := filterEvents(events, audit.EventHostsAdd, time.Time{}); len(got) != 2 {
+		t.Errorf("Expected 2 hosts_add events, got %d", len(got))
+	}
+	if got := filterEvents(events, "", now.Add(-24*time.Hour)); len(got) != 2 {
+		t.Errorf("Expected 2 events in the last day, got %d", len(got))
+	}
+	recent := filterEvents(events, audit.EventHostsAdd, now.Add(-24*time.Hour))
+	if len(recent) != 1 || !recent[0].Timestamp.Equal(events[2].Timestamp) {
+		t.Fatalf("Expected only the recent hosts_add event, got %+v", recent)
+	}
+
+	var buf bytes.Buffer
+	if err := writeHistory(&buf, events[1:]); err != nil {
+		t.Fatalf("writeHistory() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "TIMESTAMP") {
+		t.Fatalf("Expected a header and 2 rows, got:\n%s", buf.String())
+	}
+	if !strings.Contains(lines[1], "hosts_delete") || !strings.HasSuffix(lines[1], "FAILED") {
+		t.Errorf("Expected failed delete row, got %q", lines[1])
+	}
+	if !strings.HasSuffix(lines[2], "ok") {
+		t.Errorf("Expected successful add row, got %q", lines[2])
+	}
+}
+
+func TestWriteStats(t *testing.T) {
+	hostsFile, err := hosts.NewParser("").ParseReader(strings.NewReader(`127.0.0.1 localhost
+
+# @category development
+192.168.1.10 api.dev
+# 192.168.1.11 web.dev
+`))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeStats(&buf, hostsFile.Stats(), "json"); err != nil {
+		t.Fatalf("writeStats(json) error = %v", err)
+	}
+	var decoded hosts.Stats
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Invalid JSON output: %v\n%s", err, buf.String())
+	}
+	if decoded.Total != 3 || decoded.Disabled != 1 || len(decoded.Categories) != 2 {
+		t.Errorf("Unexpected JSON stats: %+v", decoded)
+	}
+
+	buf.Reset()
+	if err := writeStats(&buf, hostsFile.Stats(), "table"); err != nil {
+		t.Fatalf("writeStats(table) error = %v", err)
+	}
+	for _, want := range []string{"Total entries:", "development", "(1 enabled, 1 disabled)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected table output to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+
+	if err := writeStats(&buf, hosts.Stats{}, "xml"); err == nil {
+		t.Error("Expected unsupported format to be rejected")
+	}
+}
+
+func TestApplyHostsFileOverride(t *testing.T) {
+	t.Cleanup(func() {
+		hostsPath = ""
+		_ = platform.SetHostsFileOverride("")
+	})
+
+	tempDir := t.TempDir()
+	fromEnv := filepath.Join(tempDir, "env-hosts")
+	fromFlag := filepath.Join(tempDir, "flag-hosts")
+	t.Setenv(platform.HostsFileEnv, fromEnv)
+
+	if err := applyHostsFileOverride(); err != nil {
+		t.Fatalf("applyHostsFileOverride() error = %v", err)
+	}
+	if got := platform.New().GetHostsFilePath(); got != fromEnv {
+		t.Errorf("Expected environment override %s, got %s", fromEnv, got)
+	}
+
+	// The flag wins over the environment
+	hostsPath = fromFlag
+	if err := applyHostsFileOverride(); err != nil {
+		t.Fatalf("applyHostsFileOverride() error = %v", err)
+	}
+	if got := platform.New().GetHostsFilePath(); got != fromFlag {
+		t.Errorf("Expected flag override %s, got %s", fromFlag, got)
+	}
+
+	hostsPath = "relative/hosts"
+	if err := applyHostsFileOverride(); err == nil {
+		t.Error("Expected a relative override to be rejected")
+	}
+
+	t.Cleanup(func() { windows = false })
+	windows = true
+	hostsPath = fromFlag
+	if err := applyHostsFileOverride(); err == nil || !strings.Contains(err.Error(), "cannot be used together") {
+		t.Errorf("Expected --windows with --hosts-file to be rejected, got %v", err)
+	}
+
+	hostsPath = ""
+	if !platform.New().IsWSL() {
+		if err := applyHostsFileOverride(); err == nil || !strings.Contains(err.Error(), "only supported under WSL") {
+			t.Errorf("Expected --windows outside WSL to be rejected, got %v", err)
+		}
+	}
+}
+
+func TestExportFilters(t *testing.T) {
+	parse := func(t *testing.T) *hosts.HostsFile {
+		t.Helper()
+		hostsFile, err := hosts.NewParser("").ParseReader(strings.NewReader(`127.0.0.1 localhost
+
+# @category development
+192.168.1.10 api.dev
+# 192.168.1.11 old.dev
+
+# @category staging
+# 10.0.0.5 db.stage
+
+# @category production
+# 203.0.113.10 app.example.com
+`))
+		if err != nil {
+			t.Fatalf("ParseReader() error = %v", err)
+		}
+		return hostsFile
+	}
+	hostnames := func(hostsFile *hosts.HostsFile) string {
+		var names []string
+		for _, category := range hostsFile.Categories {
+			for _, entry := range category.Entries {
+				names = append(names, category.Name+":"+entry.Hostnames[0])
+			}
+		}
+		return strings.Join(names, ",")
+	}
+
+	hostsFile := parse(t)
+	filterEnabled(hostsFile)
+	if got := hostnames(hostsFile); got != "default:localhost,development:api.dev" {
+		t.Errorf("filterEnabled() left %s", got)
+	}
+
+	hostsFile = parse(t)
+	applyProfile(hostsFile, config.Profile{Categories: []string{"production"}})
+	filterEnabled(hostsFile)
+	if got := hostnames(hostsFile); got != "production:app.example.com" {
+		t.Errorf("Expected only the production profile subset, got %s", got)
+	}
+}
+
+func TestExportToDnsmasq(t *testing.T) {
+	hostsFile, err := hosts.NewParser("").ParseReader(strings.NewReader(`127.0.0.1 localhost
+
+# @category development
+192.168.1.10 api.dev web.dev
+# 192.168.1.11 old.dev
+::1 ip6.dev
+
+# @category staging
+10.0.0.5 db.stage
+`))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	hostsFile.DisableCategory("staging")
+
+	want := `address=/localhost/127.0.0.1
+address=/api.dev/192.168.1.10
+address=/web.dev/192.168.1.10
+address=/ip6.dev/::1
+`
+	if got := string(exportToDnsmasq(hostsFile)); got != want {
+		t.Errorf("exportToDnsmasq() = %q, want %q", got, want)
+	}
+
+	// The default config's template for the format renders the same lines
+	data, err := exportToTemplate(hostsFile, "dnsmasq", config.DefaultConfig().Export.Formats["dnsmasq"])
+	if err != nil || string(data) != want {
+		t.Errorf("dnsmasq template = %q, %v, want %q", data, err, want)
+	}
+}
+
+func TestExportToTemplate(t *testing.T) {
+	hostsFile, err := hosts.NewParser("").ParseReader(strings.NewReader(`# @category development
+192.168.1.10 api.dev web.dev
+# 192.168.1.11 old.dev
+`))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	dnsmasq := config.Format{
+		Extension: ".conf",
+		Template:  "{{range .Categories}}{{range $entry := .Entries}}{{if .Enabled}}{{range .Hostnames}}address=/{{.}}/{{$entry.IP}}\n{{end}}{{end}}{{end}}{{end}}",
+	}
+	data, err := exportToTemplate(hostsFile, "dnsmasq", dnsmasq)
+	if err != nil {
+		t.Fatalf("exportToTemplate() error = %v", err)
+	}
+	if want := "address=/api.dev/192.168.1.10\naddress=/web.dev/192.168.1.10\n"; string(data) != want {
+		t.Errorf("exportToTemplate() = %q, want %q", data, want)
+	}
+
+	data, err = exportToTemplate(hostsFile, "hosts", config.DefaultConfig().Export.Formats["hosts"])
+	if err != nil || !strings.Contains(string(data), "192.168.1.10 api.dev web.dev\n") {
+		t.Errorf("exportToTemplate() with the default hosts template = %q, %v", data, err)
+	}
+
+	for name, tmpl := range map[string]string{
+		"suspicious": `{{.Categories}}{{exec "id"}}`,
+		"invalid":    `{{range .Categories}}`,
+		"missing":    `{{.Nope}}`,
+	} {
+		if _, err := exportToTemplate(hostsFile, name, config.Format{Extension: ".txt", Template: tmpl}); err == nil {
+			t.Errorf("exportToTemplate() with %s template expected error", name)
+		}
+	}
+}
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"", time.Time{}},
+		{"30d", now.AddDate(0, 0, -30)},
+		{"12h", now.Add(-12 * time.Hour)},
+		{"2026-09-01T08:00:00Z", time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)},
+		{"2026-09-01", time.Date(2026, 9, 1, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeBound(tt.value, now)
+		if err != nil {
+			t.Errorf("parseTimeBound(%q) error = %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.expected) {
+			t.Errorf("parseTimeBound(%q) = %v, want %v", tt.value, got, tt.expected)
+		}
+	}
+
+	for _, invalid := range []string{"yesterday", "-3d", "30x"} {
+		if _, err := parseTimeBound(invalid, now); err == nil {
+			t.Errorf("parseTimeBound(%q) expected error", invalid)
+		}
+	}
+}
+
+func TestWriteAuditEvents(t *testing.T) {
+	events := []audit.AuditEvent{
+		{
+			Timestamp: time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC),
+			EventType: audit.EventSecurityViol,
+			Severity:  audit.SeverityCritical,
+			Operation: "path_validation",
+			Resource:  "../etc/passwd",
+			Details:   map[string]interface{}{"operation": "import"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeAuditEvents(&buf, events, "csv"); err != nil {
+		t.Fatalf("writeAuditEvents(csv) error = %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV output: %v", err)
+	}
+	if len(records) != 2 || records[0][0] != "timestamp" {
+		t.Fatalf("Expected a header and 1 row, got %v", records)
+	}
+	row := records[1]
+	if row[1] != "security_violation" || row[2] != "critical" || row[7] != "false" || row[9] != `{"operation":"import"}` {
+		t.Errorf("Unexpected CSV row %v", row)
+	}
+
+	buf.Reset()
+	if err := writeAuditEvents(&buf, events, "json"); err != nil {
+		t.Fatalf("writeAuditEvents(json) error = %v", err)
+	}
+	var decoded []audit.AuditEvent
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded) != 1 || decoded[0].Severity != audit.SeverityCritical {
+		t.Errorf("Expected one critical event in JSON output, got %v (%v)", decoded, err)
+	}
+
+	if err := writeAuditEvents(&buf, events, "xml"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestAutoBackupEnabled(t *testing.T) {
+	origCfg := cfg
+	defer func() {
+		cfg = origCfg
+		backupNow, noBackup = false, false
+		audit.SetDefaultConfig(origCfg)
+	}()
+	cfg = config.DefaultConfig()
+	// Keep the --no-backup audit record out of the user's real log
+	cfg.Audit.Enabled = false
+	audit.SetDefaultConfig(cfg)
+
+	tests := []struct {
+		name       string
+		autoBackup bool
+		backupNow  bool
+		noBackup   bool
+		want       bool
+	}{
+		{"config on", true, false, false, true},
+		{"config off", false, false, false, false},
+		{"--backup overrides config", false, true, false, true},
+		{"--no-backup overrides config", true, false, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.General.AutoBackup = tt.autoBackup
+			backupNow, noBackup = tt.backupNow, tt.noBackup
+			if got := autoBackupEnabled("add"); got != tt.want {
+				t.Errorf("autoBackupEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckProfileCategories(t *testing.T) {
+	known := []string{"development", "staging"}
+
+	if err := checkProfileCategories([]string{"development"}, known); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	err := checkProfileCategories([]string{"development", "prod"}, known)
+	if err == nil || !strings.Contains(err.Error(), "prod") {
+		t.Errorf("Expected error naming prod, got %v", err)
+	}
+}
+
+func TestEditProfile(t *testing.T) {
+	profile := config.Profile{Description: "Dev", Categories: []string{"development", "staging"}}
+
+	got, err := editProfile(profile, []string{"custom", "staging"}, []string{"development"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"staging", "custom"}; !slices.Equal(got.Categories, want) {
+		t.Errorf("Categories = %v, want %v", got.Categories, want)
+	}
+	if !slices.Equal(profile.Categories, []string{"development", "staging"}) {
+		t.Error("editProfile should not modify the original profile")
+	}
+
+	if _, err := editProfile(profile, nil, []string{"missing"}); err == nil {
+		t.Error("Expected error removing a category the profile lacks")
+	}
+	if _, err := editProfile(profile, nil, []string{"development", "staging"}); err == nil {
+		t.Error("Expected error removing every category")
+	}
+}
+
+func TestDeleteProfile(t *testing.T) {
+	newConfig := func() *config.Config {
+		c := config.DefaultConfig()
+		c.Profiles = map[string]config.Profile{
+			"full": {Categories: []string{"custom"}, Default: true},
+			"dev":  {Categories: []string{"development"}},
+		}
+		return c
+	}
+
+	c := newConfig()
+	if err := deleteProfile(c, "dev", ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, exists := c.Profiles["dev"]; exists {
+		t.Error("dev profile should be deleted")
+	}
+	if err := deleteProfile(c, "full", ""); err == nil {
+		t.Error("Expected error deleting the only profile")
+	}
+
+	c = newConfig()
+	if err := deleteProfile(c, "full", ""); err == nil {
+		t.Error("Expected error deleting the default profile without --default")
+	}
+	if err := deleteProfile(c, "full", "missing"); err == nil {
+		t.Error("Expected error for an unknown replacement default")
+	}
+	if err := deleteProfile(c, "dev", "full"); err == nil {
+		t.Error("Expected error for --default when deleting a non-default profile")
+	}
+	if err := deleteProfile(c, "full", "dev"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !c.Profiles["dev"].Default {
+		t.Error("dev should become the default profile")
+	}
+	if err := config.NewValidator().Validate(c); err != nil {
+		t.Errorf("Config should stay valid after deletion: %v", err)
+	}
+
+	if err := deleteProfile(newConfig(), "missing", ""); err == nil {
+		t.Error("Expected error deleting an unknown profile")
+	}
+}
+
+func TestPreviewProfile(t *testing.T) {
+	content := `# @category development
+192.168.1.10 api.dev
+# 192.168.1.11 old.dev
+
+# @category production
+10.0.0.1 api.prod
+10.0.0.2 web.prod
+`
+	hostsFile, err := hosts.NewParser("").ParseReader(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	simulated, changed := previewProfile(hostsFile, config.Profile{Categories: []string{"development"}})
+
+	// old.dev is enabled and both production entries are disabled
+	if changed != 3 {
+		t.Errorf("changed = %d, want 3", changed)
+	}
+	if !simulated.Categories[0].Enabled || simulated.Categories[1].Enabled {
+		t.Errorf("Unexpected simulated category states: %+v", simulated.Categories)
+	}
+	if !hostsFile.Categories[1].Entries[0].Enabled || hostsFile.Categories[0].Entries[1].Enabled {
+		t.Error("previewProfile should not modify the parsed file")
+	}
+}
+
+func TestWriteSearchResults(t *testing.T) {
+	hostsFile, err := hosts.NewParser("").ParseReader(strings.NewReader("10.0.0.1 api.dev # API\n# 10.0.0.2 api.old\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	results := search.NewSearcher(false, false).Search(hostsFile, "api")
+
+	var buf bytes.Buffer
+	if err := writeSearchResults(&buf, results); err != nil {
+		t.Fatalf("writeSearchResults() error = %v", err)
+	}
+	var decoded []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Failed to decode JSON output: %v", err)
+	}
+	if len(decoded) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(decoded))
+	}
+	for _, key := range []string{"ip", "hostnames", "comment", "category", "enabled", "score", "match"} {
+		if _, ok := decoded[0][key]; !ok {
+			t.Errorf("JSON output missing %q field", key)
+		}
+	}
+	if decoded[0]["match"] != "api.dev" || decoded[1]["enabled"] != false {
+		t.Errorf("Unexpected results: %v", decoded)
+	}
+
+	buf.Reset()
+	if err := writeSearchResults(&buf, nil); err != nil {
+		t.Fatalf("writeSearchResults(nil) error = %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("Expected [] for no results, got %q", buf.String())
+	}
+}
+
+func TestDescribeWatchChange(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   watch.Change
+		dryRun   bool
+		expected string
+	}{
+		{"nothing missing", watch.Change{}, false, "all managed categories present"},
+		{"restored", watch.Change{Missing: []string{"dev", "vpn"}, Restored: true}, false, "removed: dev, vpn; restored"},
+		{"dry run", watch.Change{Missing: []string{"dev"}}, true, "(dry run, not restored)"},
+		{"failed", watch.Change{Missing: []string{"dev"}, Err: fmt.Errorf("permission denied")}, false, "Failed to reconcile external change: permission denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeWatchChange(tt.change, tt.dryRun); !strings.Contains(got, tt.expected) {
+				t.Errorf("describeWatchChange() = %q, want it to contain %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAddEntriesFrom(t *testing.T) {
+	input := `# Team services
+10.0.0.1 api.team.local # shared API
+
+not-an-entry
+# 10.0.0.2 old.team.local
+10.0.0.3 bad_host!
+10.0.0.4 db.team.local
+`
+	template := hosts.Entry{Category: "development", Comment: "bulk", Tags: []string{"team"}}
+
+	newHostsFile := func(t *testing.T) *hosts.HostsFile {
+		t.Helper()
+		hostsFile, err := hosts.NewParser("").ParseReader(strings.NewReader("127.0.0.1 localhost\n"))
+		if err != nil {
+			t.Fatalf("ParseReader() error = %v", err)
+		}
+		return hostsFile
+	}
+
+	hostsFile := newHostsFile(t)
+	var out bytes.Buffer
+	added, failed, err := addEntriesFrom(hostsFile, hosts.NewParser("stdin"), strings.NewReader(input), template, false, &out)
+	if err != nil {
+		t.Fatalf("addEntriesFrom() error = %v", err)
+	}
+	if len(added) != 3 || failed != 2 {
+		t.Fatalf("addEntriesFrom() added %d, failed %d; want 3 and 2\n%s", len(added), failed, out.String())
+	}
+	for _, want := range []string{"line 2: 10.0.0.1", "line 4: error: not a valid hosts entry", "line 6: error:", "line 7: 10.0.0.4"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	category := hostsFile.GetCategory("development")
+	if category == nil || len(category.Entries) != 3 {
+		t.Fatalf("Expected 3 entries in development, got %+v", category)
+	}
+	if first := category.Entries[0]; first.Comment != "shared API" || !slices.Equal(first.Tags, []string{"team"}) {
+		t.Errorf("Line comment and template tags should be kept, got %+v", first)
+	}
+	if disabled := category.Entries[1]; disabled.Enabled || disabled.Comment != "bulk" {
+		t.Errorf("Commented-out entry should be added disabled with the template comment, got %+v", disabled)
+	}
+
+	hostsFile = newHostsFile(t)
+	_, _, err = addEntriesFrom(hostsFile, hosts.NewParser("stdin"), strings.NewReader(input), template, true, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("strict addEntriesFrom() error = %v, want the failing line", err)
+	}
+}
+
+func TestPromptEntry(t *testing.T) {
+	input := strings.Join([]string{
+		"999.1.1.1",         // invalid IP, asked again
+		"192.168.1.10",      //
+		"",                  // no hostnames, asked again
+		"web.dev bad_host!", // invalid hostname, asked again
+		"web.dev api.dev",   //
+		"",                  // keep the default comment
+		"bad category",      // invalid category, asked again
+		"",                  // keep the default category
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	entry, err := promptEntry(strings.NewReader(input), &out, hosts.Entry{Comment: "team", Category: "development"}, hosts.IDNReject, hosts.HostnameStrict)
+	if err != nil {
+		t.Fatalf("promptEntry() error = %v\n%s", err, out.String())
+	}
+
+	if entry.IP != "192.168.1.10" || !slices.Equal(entry.Hostnames, []string{"web.dev", "api.dev"}) ||
+		entry.Comment != "team" || entry.Category != "development" || !entry.Enabled {
+		t.Errorf("promptEntry() = %+v", entry)
+	}
+	if got := strings.Count(out.String(), "Invalid:"); got != 4 {
+		t.Errorf("Expected 4 re-prompts, got %d:\n%s", got, out.String())
+	}
+	if !strings.Contains(out.String(), "Category [development]: ") {
+		t.Errorf("Default category should be offered:\n%s", out.String())
+	}
+
+	if _, err := promptEntry(strings.NewReader("10.0.0.1\n"), io.Discard, hosts.Entry{}, hosts.IDNReject, hosts.HostnameStrict); err == nil {
+		t.Error("Expected an error when input ends early")
+	}
+
+	entry, err = promptEntry(strings.NewReader("10.0.0.1\nmünchen.de\n\ncustom\n"), io.Discard, hosts.Entry{}, hosts.IDNPunycode, hosts.HostnameStrict)
+	if err != nil || entry.Hostnames[0] != "münchen.de" {
+		t.Errorf("International hostname should be accepted when converted, got %+v, %v", entry, err)
+	}
+}
+
+func TestValidationFindingsSkippedLines(t *testing.T) {
+	content := "127.0.0.1 localhost\n::1 ip6-localhost\n999.1.1.1 broken.dev\n10.0.0.6 ok.dev\n  not a hosts line\n"
+	hostsFile, err := hosts.NewParser("").ParseReader(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	problems, _ := validationFindings(hostsFile, false)
+	if len(problems) != 2 {
+		t.Fatalf("validationFindings() = %+v, want 2 problems", problems)
+	}
+	if problems[0].LineNum != 3 || problems[0].Field != "ip" || problems[0].Value != "999.1.1.1" {
+		t.Errorf("Invalid IP line should be reported once as an ip problem, got %+v", problems[0])
+	}
+	if formatted := formatProblem(problems[1]); formatted != `line 5: line "not a hosts line": not an entry, category marker or comment` {
+		t.Errorf("formatProblem() = %s", formatted)
+	}
+}
+
+func TestValidationFindingsStrict(t *testing.T) {
+	hostsFile, err := hosts.NewParser("").ParseReader(strings.NewReader("10.0.0.5 localhost\n10.0.0.6 bad_host\n"))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	problems, warnings := validationFindings(hostsFile, false)
+	if len(problems) != 1 || problems[0].Value != "bad_host" {
+		t.Errorf("validationFindings() problems = %+v, want only bad_host", problems)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("validationFindings() warnings = %+v, want missing and misdirected localhost", warnings)
+	}
+	if formatted := formatProblem(warnings[0]); formatted != `hostname "localhost": localhost is not mapped to 127.0.0.1 or ::1` {
+		t.Errorf("formatProblem() = %s", formatted)
+	}
+
+	problems, warnings = validationFindings(hostsFile, true)
+	if len(warnings) != 0 {
+		t.Errorf("validationFindings() with strict should not return warnings, got %+v", warnings)
+	}
+	if len(problems) != 3 || problems[0].LineNum != 0 || problems[1].Value != "10.0.0.5" || problems[2].Value != "bad_host" {
+		t.Errorf("validationFindings() with strict = %+v, want warnings merged in line order", problems)
+	}
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	configData, err := yaml.Marshal(config.DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostsData := []byte("categories:\n  - name: development\n    enabled: true\n")
+
+	var buf bytes.Buffer
+	if err := writeBundle(&buf, configData, hostsData); err != nil {
+		t.Fatalf("writeBundle() error = %v", err)
+	}
+
+	gotConfig, gotHosts, err := readBundle(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("readBundle() error = %v", err)
+	}
+	if !bytes.Equal(gotConfig, configData) || !bytes.Equal(gotHosts, hostsData) {
+		t.Error("readBundle() should return the bundled files unchanged")
+	}
+	if _, err := parseBundledConfig(gotConfig); err != nil {
+		t.Errorf("parseBundledConfig() error = %v", err)
+	}
+
+	if _, _, err := readBundle(strings.NewReader("not a bundle")); err == nil {
+		t.Error("readBundle() should reject data that is not a bundle")
+	}
+}
+
+func TestParseBundledConfigValidates(t *testing.T) {
+	_, err := parseBundledConfig([]byte("backup:\n  max_backups: 0\n"))
+	if err == nil || !strings.Contains(err.Error(), "max backups") {
+		t.Errorf("parseBundledConfig() error = %v, want a validation error", err)
+	}
+
+	cfg, err := parseBundledConfig([]byte("general:\n  default_category: development\n"))
+	if err != nil {
+		t.Fatalf("parseBundledConfig() error = %v", err)
+	}
+	if cfg.General.DefaultCategory != "development" || cfg.Backup.MaxBackups != 10 {
+		t.Errorf("parseBundledConfig() should apply the bundle over the defaults, got %+v", cfg.General)
+	}
+}
+
+func TestToggleTarget(t *testing.T) {
+	hostsFile, err := hosts.NewParser("").ParseReader(strings.NewReader(`127.0.0.1 localhost
+::1 localhost
+
+# @category development
+10.0.0.1 api.dev
+
+# @category staging
+10.0.0.2 api.dev
+`))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	_, err = toggleTarget(hostsFile, "api.dev", "", false)
+	if err == nil || !strings.Contains(err.Error(), "[development]") || !strings.Contains(err.Error(), "[staging]") || !strings.Contains(err.Error(), "--category") {
+		t.Errorf("toggleTarget() error = %v, want both matches and a --category hint", err)
+	}
+
+	target, err := toggleTarget(hostsFile, "api.dev", "staging", false)
+	if err != nil || target.IP != "10.0.0.2" {
+		t.Errorf("toggleTarget() with --category staging = %+v, %v", target, err)
+	}
+
+	if _, err := toggleTarget(hostsFile, "api.dev", "production", false); err == nil || !strings.Contains(err.Error(), "not found in category production") {
+		t.Errorf("toggleTarget() with a category not listing the hostname error = %v", err)
+	}
+
+	// Matches in one category can only be told apart by line
+	if _, err := toggleTarget(hostsFile, "localhost", "", true); err == nil || !strings.Contains(err.Error(), "uncomment <line>") {
+		t.Errorf("toggleTarget() error = %v, want an uncomment hint", err)
+	}
+}
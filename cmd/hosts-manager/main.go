@@ -1,9 +1,15 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/brandonhon/hosts-manager/internal/audit"
 	"github.com/brandonhon/hosts-manager/internal/backup"
@@ -13,13 +19,27 @@ import (
 	"github.com/brandonhon/hosts-manager/pkg/platform"
 	"github.com/brandonhon/hosts-manager/pkg/search"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var (
-	cfg     *config.Config
-	verbose bool
-	dryRun  bool
+	cfg       *config.Config
+	verbose   bool
+	dryRun    bool
+	force     bool
+	hostsPath string
+	windows   bool
+	// backupNow and noBackup override general.auto_backup for one run
+	backupNow bool
+	noBackup  bool
+	// colorMode is --color: auto, always or never
+	colorMode string
+	// logJSON writes operations to stderr as JSON lines; quiet silences stdout
+	logJSON bool
+	quiet   bool
 	// version is set via ldflags during build: -X main.version=<version>
 	// Defaults to "dev" for local development builds
 	version = "dev"
@@ -32,6 +52,7 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
 		os.Exit(1)
 	}
+	audit.SetDefaultConfig(cfg)
 
 	// Initialize secure directories at startup to ensure they exist
 	// This prevents runtime errors and provides clear user guidance
@@ -50,52 +71,244 @@ func main() {
 		Long: `hosts-manager is a cross-platform CLI tool for managing your hosts file.
 It provides a template system, backup/restore, interactive TUI mode, and more.`,
 		Version: version,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyOutputMode(cmd); err != nil {
+				return err
+			}
+			if err := applyColorMode(); err != nil {
+				return err
+			}
+			return applyHostsFileOverride()
+		},
 	}
 	// Ensure proper initialization and configuration validation
 
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", cfg.General.Verbose, "Enable verbose output")
 	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", cfg.General.DryRun, "Show what would be done without making changes")
+	rootCmd.PersistentFlags().BoolVar(&force, "force", false, "Bypass safety checks that would otherwise refuse a write")
+	rootCmd.PersistentFlags().BoolVar(&windows, "windows", false, "Under WSL, manage the Windows hosts file instead of the Linux one")
+	rootCmd.PersistentFlags().StringVar(&hostsPath, "hosts-file", "", "Use this hosts file instead of the system one (also "+platform.HostsFileEnv+")")
+	rootCmd.PersistentFlags().BoolVar(&backupNow, "backup", false, "Take an automatic backup before changing the hosts file even if auto_backup is off")
+	rootCmd.PersistentFlags().BoolVar(&noBackup, "no-backup", false, "Skip the automatic backup before changing the hosts file")
+	rootCmd.MarkFlagsMutuallyExclusive("backup", "no-backup")
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", "auto", "Colorize output: auto (terminals only, honoring NO_COLOR), always, or never")
+	_ = rootCmd.RegisterFlagCompletionFunc("color", cobra.FixedCompletions(colorModes, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.PersistentFlags().BoolVar(&logJSON, "log-json", false, "Write each operation and the command's result to standard error as JSON lines")
+	rootCmd.PersistentFlags().BoolVar(&quiet, "quiet", false, "Suppress normal output on standard output")
 
 	rootCmd.AddCommand(
 		addCmd(),
 		listCmd(),
+		updateCmd(),
+		moveCmd(),
 		deleteCmd(),
 		enableCmd(),
 		disableCmd(),
+		commentCmd(),
+		uncommentCmd(),
 		searchCmd(),
+		validateCmd(),
+		statsCmd(),
+		syncCmd(),
+		bugreportCmd(),
+		dedupeCmd(),
+		expireCmd(),
+		sortCmd(),
 		backupCmd(),
 		restoreCmd(),
+		rollbackCmd(),
+		undoCmd(),
+		historyCmd(),
+		auditCmd(),
+		diffCmd(),
 		tuiCmd(),
 		configCmd(),
 		exportCmd(),
 		importCmd(),
 		categoryCmd(),
 		profileCmd(),
+		flushDNSCmd(),
+		watchCmd(),
 	)
 
-	if err := rootCmd.Execute(); err != nil {
+	executed, err := rootCmd.ExecuteC()
+	if logJSON {
+		audit.SetConsole(os.Stderr)
+		_ = audit.WriteConsole(commandEvent(executed, err))
+	}
+	if err != nil {
 		// Sanitize error for user display while logging full error for debugging
 		if logger, logErr := audit.NewLogger(); logErr == nil && errors.IsSecuritySensitive(err) {
 			logger.LogSecurityViolation("command_execution", "root_command", err.Error(), nil)
 		}
 
-		sanitizedErr := errors.SanitizeError(err)
-		fmt.Fprintf(os.Stderr, "Error: %v\n", sanitizedErr)
+		// With --log-json the command event above carries the error
+		if !logJSON {
+			sanitizedErr := errors.SanitizeError(err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", sanitizedErr)
+		}
 		os.Exit(1)
 	}
 }
 
+// applyOutputMode applies --log-json and --quiet. With --log-json, audit
+// events are copied to stderr as JSON lines and cobra's own error and usage
+// text is silenced, leaving the command event as the error report.
+func applyOutputMode(cmd *cobra.Command) error {
+	if logJSON {
+		audit.SetConsole(os.Stderr)
+		cmd.Root().SilenceErrors = true
+		cmd.SilenceUsage = true
+	}
+	if quiet {
+		devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("failed to open %s for --quiet: %w", os.DevNull, err)
+		}
+		os.Stdout = devNull
+	}
+	return nil
+}
+
+// commandEvent describes a finished command for --log-json: the command path
+// as the operation, its arguments as the resource, and the error, sanitized
+// as it is for display, if it failed
+func commandEvent(cmd *cobra.Command, err error) audit.AuditEvent {
+	event := audit.AuditEvent{
+		EventType: audit.EventCommand,
+		Severity:  audit.SeverityInfo,
+		Success:   err == nil,
+	}
+	if cmd != nil {
+		event.Operation = cmd.CommandPath()
+		event.Resource = strings.Join(cmd.Flags().Args(), " ")
+	}
+	if err != nil {
+		event.Severity = audit.SeverityError
+		event.ErrorMsg = errors.SanitizeError(err).Error()
+	}
+	return event
+}
+
+// applyHostsFileOverride points every platform at the hosts file named by
+// --hosts-file or --windows or, failing that, the HOSTS_MANAGER_HOSTS_FILE
+// environment variable
+func applyHostsFileOverride() error {
+	path := hostsPath
+	if windows {
+		if hostsPath != "" {
+			return fmt.Errorf("--windows and --hosts-file cannot be used together")
+		}
+		p := platform.New()
+		if !p.IsWSL() {
+			return fmt.Errorf("--windows is only supported under WSL")
+		}
+		path = p.GetWindowsHostsPathFromWSL()
+	}
+	if path == "" {
+		path = os.Getenv(platform.HostsFileEnv)
+	}
+	if path == "" {
+		return nil
+	}
+
+	return platform.SetHostsFileOverride(path)
+}
+
 func addCmd() *cobra.Command {
 	var category, comment string
+	var merge bool
+	var ttl time.Duration
+	var tags []string
+	var before string
+	var fromFile string
+	var fromStdin, strict bool
+	var interactive bool
+	var lenient, strictHostnames bool
+	var disabled bool
 
 	cmd := &cobra.Command{
 		Use:   "add <ip> <hostname> [hostname...]",
 		Short: "Add a new hosts entry",
-		Args:  cobra.MinimumNArgs(2),
+		Long: `Add a new hosts entry.
+
+With --from-file or --stdin, entries are read in hosts file format instead,
+one per line, and added with a single backup and write. Blank lines and
+comments are skipped and commented-out entries are added disabled. Lines
+that fail are reported with their line number and skipped, unless --strict
+is given, in which case nothing is added. --category, --comment (for lines
+without one), --tag and --ttl apply to every entry read.
+
+With --interactive, the IP, hostnames, comment and category are prompted
+for instead, re-prompting until each is valid. It is ignored when standard
+input is not a terminal, so scripts never wait for input.
+
+--lenient accepts underscores in hostnames for this command, as
+general.allow_underscore_hostnames does for all of them; --strict-hostnames
+rejects them even when that option is set.
+
+--disabled adds the entry commented out, so an override can be staged and
+switched on later with enable. It is validated like any other entry.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if (interactive && isTerminal(os.Stdin)) || fromFile != "" || fromStdin {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.MinimumNArgs(2)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if category == "" {
 				category = cfg.General.DefaultCategory
 			}
+			hostnameMode := resolveHostnameMode(lenient, hosts.HostnameUnderscores, strictHostnames)
+			if interactive {
+				if !isTerminal(os.Stdin) {
+					fmt.Fprintln(os.Stderr, "Warning: standard input is not a terminal; ignoring --interactive")
+				} else {
+					idnMode, err := hosts.ParseIDNMode(cfg.General.IDN)
+					if err != nil {
+						return err
+					}
+					prompted, err := promptEntry(cmd.InOrStdin(), cmd.OutOrStdout(), hosts.Entry{Comment: comment, Category: category}, idnMode, hostnameMode)
+					if err != nil {
+						return err
+					}
+					args = append([]string{prompted.IP}, prompted.Hostnames...)
+					comment, category = prompted.Comment, prompted.Category
+				}
+			}
+			if ttl < 0 {
+				return fmt.Errorf("--ttl must be positive")
+			}
+			if ttl > 0 && merge {
+				return fmt.Errorf("--ttl cannot be combined with --merge, which would make the existing entry expire")
+			}
+			if len(tags) > 0 && merge {
+				return fmt.Errorf("--tag cannot be combined with --merge, which keeps the existing entry's tags")
+			}
+			if before != "" && merge {
+				return fmt.Errorf("--before cannot be combined with --merge")
+			}
+			if disabled && merge {
+				return fmt.Errorf("--disabled cannot be combined with --merge, which adds to an enabled entry")
+			}
+			for _, tag := range tags {
+				if err := hosts.ValidateTag(tag); err != nil {
+					return err
+				}
+			}
+			if fromFile != "" || fromStdin {
+				if merge || before != "" {
+					return fmt.Errorf("--merge and --before cannot be combined with --from-file or --stdin")
+				}
+				if disabled {
+					return fmt.Errorf("--disabled cannot be combined with --from-file or --stdin; comment out the lines to add them disabled")
+				}
+				template := hosts.Entry{Comment: comment, Category: category, Tags: hosts.NormalizeTags(tags)}
+				if ttl > 0 {
+					template.ExpiresAt = time.Now().Add(ttl).UTC().Truncate(time.Second)
+				}
+				return addBulk(cmd, fromFile, template, strict, hostnameMode)
+			}
 
 			p := platform.New()
 			if err := p.ElevateIfNeeded(); err != nil {
@@ -103,8 +316,8 @@ func addCmd() *cobra.Command {
 			}
 
 			backupMgr := backup.NewManager(cfg)
-			if cfg.General.AutoBackup {
-				if _, err := backupMgr.CreateBackup(); err != nil {
+			if autoBackupEnabled("add") {
+				if _, err := backupMgr.CreateAutoBackup(); err != nil {
 					return fmt.Errorf("failed to create backup: %w", err)
 				}
 				if verbose {
@@ -112,34 +325,68 @@ func addCmd() *cobra.Command {
 				}
 			}
 
-			parser := hosts.NewParser(p.GetHostsFilePath())
-			hostsFile, err := parser.Parse()
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
 			if err != nil {
 				return fmt.Errorf("failed to parse hosts file: %w", err)
 			}
+			hostsFile.SetHostnameMode(hostnameMode)
 
 			entry := hosts.Entry{
 				IP:        args[0],
 				Hostnames: args[1:],
 				Comment:   comment,
 				Category:  category,
-				Enabled:   true,
+				Enabled:   !disabled,
+				Tags:      hosts.NormalizeTags(tags),
+			}
+			if ttl > 0 {
+				entry.ExpiresAt = time.Now().Add(ttl).UTC().Truncate(time.Second)
+			}
+			// Convert up front so the messages below show the stored hostnames
+			if entry, err = hostsFile.ConvertIDNs(entry); err != nil {
+				return fmt.Errorf("failed to add entry: %w", err)
 			}
 
-			if err := hostsFile.AddEntry(entry); err != nil {
+			merged := false
+			switch {
+			case merge:
+				merged, err = hostsFile.AddOrMergeEntry(entry, hostnameMode)
+			case before != "":
+				var found bool
+				found, err = hostsFile.InsertEntry(entry, before)
+				if err == nil && !found {
+					fmt.Fprintf(os.Stderr, "Warning: %s is not in category %s; appending to the end\n", before, category)
+				}
+			default:
+				err = hostsFile.AddEntry(entry)
+			}
+			if err != nil {
 				return fmt.Errorf("failed to add entry: %w", err)
 			}
 
 			if dryRun {
+				if merged {
+					fmt.Printf("Would merge into existing entry: %s %s\n", entry.IP, entry.Hostnames)
+					return nil
+				}
 				fmt.Printf("Would add: %s %s", entry.IP, entry.Hostnames)
 				if entry.Comment != "" {
 					fmt.Printf(" # %s", entry.Comment)
 				}
+				if len(entry.Tags) > 0 {
+					fmt.Printf(" [tags: %s]", strings.Join(entry.Tags, ","))
+				}
+				if !entry.ExpiresAt.IsZero() {
+					fmt.Printf(" (expires %s)", entry.ExpiresAt.Local().Format(time.RFC3339))
+				}
+				if disabled {
+					fmt.Print(" (disabled)")
+				}
 				fmt.Println()
 				return nil
 			}
 
-			if err := hostsFile.Write(p.GetHostsFilePath()); err != nil {
+			if err := writeHostsFile(hostsFile, p.GetHostsFilePath()); err != nil {
 				// Log failed operation
 				if logger, logErr := audit.NewLogger(); logErr == nil {
 					logger.LogHostsOperation("add", entry.IP, entry.Hostnames, false, err.Error())
@@ -152,76 +399,637 @@ func addCmd() *cobra.Command {
 				logger.LogHostsOperation("add", entry.IP, entry.Hostnames, true, "")
 			}
 
-			fmt.Printf("Added entry: %s -> %v\n", entry.IP, entry.Hostnames)
+			if merged {
+				fmt.Printf("Merged into existing entry: %s -> %v\n", entry.IP, entry.Hostnames)
+				return nil
+			}
+			fmt.Printf("Added entry: %s -> %v", entry.IP, entry.Hostnames)
+			if !entry.ExpiresAt.IsZero() {
+				fmt.Printf(" (expires %s)", entry.ExpiresAt.Local().Format(time.RFC3339))
+			}
+			if disabled {
+				fmt.Print(" (disabled)")
+			}
+			fmt.Println()
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVarP(&category, "category", "c", "", "Category for the entry")
 	cmd.Flags().StringVar(&comment, "comment", "", "Comment for the entry")
+	cmd.Flags().BoolVar(&merge, "merge", false, "Append hostnames to an existing enabled entry with the same IP in the category")
+	cmd.Flags().DurationVar(&ttl, "ttl", 0, "Expire the entry after this long (e.g. 24h); remove it with the expire command")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "Tag the entry (repeatable or comma-separated)")
+	cmd.Flags().StringVar(&before, "before", "", "Insert the entry before the one declaring this hostname in the category")
+	cmd.Flags().StringVar(&fromFile, "from-file", "", "Add entries read from a file in hosts format")
+	cmd.Flags().BoolVar(&fromStdin, "stdin", false, "Add entries read from standard input in hosts format")
+	cmd.Flags().BoolVar(&strict, "strict", false, "With --from-file or --stdin, add nothing if any line fails")
+	cmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Prompt for the entry's fields")
+	cmd.Flags().BoolVar(&lenient, "lenient", false, "Accept underscores in hostnames, keeping all other checks")
+	cmd.Flags().BoolVar(&strictHostnames, "strict-hostnames", false, "Reject underscores in hostnames even if allow_underscore_hostnames is set")
+	cmd.Flags().BoolVar(&disabled, "disabled", false, "Add the entry commented out")
+	cmd.MarkFlagsMutuallyExclusive("lenient", "strict-hostnames")
+	cmd.MarkFlagsMutuallyExclusive("from-file", "stdin", "interactive")
+	_ = cmd.RegisterFlagCompletionFunc("category", completeCategories)
 
 	return cmd
 }
 
+// promptEntry asks for an entry's IP, hostnames, comment and category,
+// repeating each question until the answer is valid. The comment and
+// category in defaults are offered as defaults. International hostnames are
+// accepted when idn converts them.
+func promptEntry(in io.Reader, out io.Writer, defaults hosts.Entry, idn hosts.IDNMode, hostnameMode hosts.HostnameValidationMode) (hosts.Entry, error) {
+	reader := bufio.NewReader(in)
+	entry := hosts.Entry{Enabled: true}
+
+	ip, err := promptField(reader, out, "IP address", "", hosts.ValidateIP)
+	if err != nil {
+		return entry, err
+	}
+	entry.IP = ip
+
+	hostnames, err := promptField(reader, out, "Hostnames (space separated)", "", func(value string) error {
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			return fmt.Errorf("at least one hostname is required")
+		}
+		for _, hostname := range fields {
+			if idn != hosts.IDNReject {
+				ascii, err := hosts.ToASCIIHostname(hostname)
+				if err != nil {
+					return err
+				}
+				hostname = ascii
+			}
+			if err := hosts.ValidateHostnameWithMode(hostname, hostnameMode); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return entry, err
+	}
+	entry.Hostnames = strings.Fields(hostnames)
+
+	if entry.Comment, err = promptField(reader, out, "Comment (optional)", defaults.Comment, hosts.ValidateComment); err != nil {
+		return entry, err
+	}
+	if entry.Category, err = promptField(reader, out, "Category", defaults.Category, hosts.ValidateCategoryName); err != nil {
+		return entry, err
+	}
+
+	return entry, nil
+}
+
+// promptField asks for one value, showing def in brackets and using it for
+// an empty answer, until validate accepts the answer
+func promptField(reader *bufio.Reader, out io.Writer, label, def string, validate func(string) error) (string, error) {
+	for {
+		if def != "" {
+			_, _ = fmt.Fprintf(out, "%s [%s]: ", label, def)
+		} else {
+			_, _ = fmt.Fprintf(out, "%s: ", label)
+		}
+
+		answer, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || answer == "") {
+			if err == io.EOF {
+				return "", fmt.Errorf("input ended before the entry was complete")
+			}
+			return "", fmt.Errorf("failed to read %s: %w", strings.ToLower(label), err)
+		}
+
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			answer = def
+		}
+		if err := validate(answer); err != nil {
+			_, _ = fmt.Fprintf(out, "  Invalid: %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// addBulk implements add --from-file and add --stdin
+func addBulk(cmd *cobra.Command, fromFile string, template hosts.Entry, strict bool, hostnameMode hosts.HostnameValidationMode) error {
+	source := "stdin"
+	input := cmd.InOrStdin()
+	if fromFile != "" {
+		if err := ensureSecureDirectories(); err != nil {
+			return fmt.Errorf("failed to initialize secure directories: %w", err)
+		}
+		filePath, err := validateFilePathStrict(fromFile, getAllowedDirectories(), "add")
+		if err != nil {
+			return fmt.Errorf("add path validation failed: %w", err)
+		}
+		file, err := os.Open(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", filePath, err)
+		}
+		defer func() { _ = file.Close() }()
+		source, input = filePath, file
+	}
+
+	p := platform.New()
+	if !dryRun {
+		if err := p.ElevateIfNeeded(); err != nil {
+			return err
+		}
+	}
+
+	hostsFile, err := parseHostsFile(p.GetHostsFilePath())
+	if err != nil {
+		return fmt.Errorf("failed to parse hosts file: %w", err)
+	}
+	hostsFile.SetHostnameMode(hostnameMode)
+
+	ipMode, err := hosts.ParseIPMode(cfg.General.IPFormat)
+	if err != nil {
+		return err
+	}
+	reversedMode, err := hosts.ParseReversedLineMode(cfg.General.ReversedLines)
+	if err != nil {
+		return err
+	}
+	parser := hosts.NewParser(source)
+	parser.SetIPMode(ipMode)
+	parser.SetReversedLineMode(reversedMode)
+
+	added, failed, err := addEntriesFrom(hostsFile, parser, input, template, strict, cmd.OutOrStdout())
+	if err != nil {
+		return err
+	}
+
+	var hostnames []string
+	for _, entry := range added {
+		hostnames = append(hostnames, entry.Hostnames...)
+	}
+
+	switch {
+	case len(added) == 0:
+		fmt.Println("No entries added")
+	case dryRun:
+		fmt.Printf("Would add %d entries from %s\n", len(added), source)
+	default:
+		backupMgr := backup.NewManager(cfg)
+		if autoBackupEnabled("add") {
+			if _, err := backupMgr.CreateAutoBackup(); err != nil {
+				return fmt.Errorf("failed to create backup: %w", err)
+			}
+			if verbose {
+				fmt.Println("Backup created successfully")
+			}
+		}
+
+		if err := writeHostsFile(hostsFile, p.GetHostsFilePath()); err != nil {
+			if logger, logErr := audit.NewLogger(); logErr == nil {
+				logger.LogHostsOperation("add", "", hostnames, false, err.Error())
+			}
+			return fmt.Errorf("failed to write hosts file: %w", err)
+		}
+
+		if logger, err := audit.NewLogger(); err == nil {
+			logger.LogHostsOperation("add", "", hostnames, true, "")
+		}
+		fmt.Printf("Added %d entries from %s\n", len(added), source)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d lines could not be added", failed)
+	}
+	return nil
+}
+
+// addEntriesFrom adds each entry line read from r to hostsFile, taking the
+// category, tags and expiry from template, and its comment for lines without
+// one. Blank lines and comments are skipped. Each line's outcome is written to
+// out. Lines that fail are counted and skipped, or with strict the first
+// failure is returned and nothing should be written.
+func addEntriesFrom(hostsFile *hosts.HostsFile, parser *hosts.Parser, r io.Reader, template hosts.Entry, strict bool, out io.Writer) ([]hosts.Entry, int, error) {
+	var added []hosts.Entry
+	failed := 0
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		entry, ok := parser.ParseLine(line, lineNum)
+		var err error
+		switch {
+		case !ok && strings.HasPrefix(line, "#"):
+			continue
+		case !ok:
+			err = fmt.Errorf("not a valid hosts entry: %s", line)
+		default:
+			entry.LineNum = 0
+			entry.Category = template.Category
+			entry.Tags = slices.Clone(template.Tags)
+			entry.ExpiresAt = template.ExpiresAt
+			if entry.Comment == "" {
+				entry.Comment = template.Comment
+			}
+			if entry, err = hostsFile.ConvertIDNs(entry); err == nil {
+				err = hostsFile.AddEntry(entry)
+			}
+		}
+
+		if err != nil {
+			if strict {
+				return nil, 0, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			_, _ = fmt.Fprintf(out, "line %d: error: %v\n", lineNum, err)
+			failed++
+			continue
+		}
+
+		added = append(added, entry)
+		_, _ = fmt.Fprintf(out, "line %d: %s %v\n", lineNum, entry.IP, entry.Hostnames)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read entries: %w", err)
+	}
+
+	return added, failed, nil
+}
+
 func listCmd() *cobra.Command {
 	var categoryFilter string
 	var showDisabled bool
+	var format string
+	var lineNumbers bool
+	var tagFilter string
+	var sortBy string
+	var output string
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all hosts entries",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			p := platform.New()
-			parser := hosts.NewParser(p.GetHostsFilePath())
-			hostsFile, err := parser.Parse()
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
 			if err != nil {
 				return fmt.Errorf("failed to parse hosts file: %w", err)
 			}
 
-			for _, category := range hostsFile.Categories {
-				if categoryFilter != "" && category.Name != categoryFilter {
-					continue
+			// Sorting only reorders the parsed copy; the file is never written
+			if sortBy != "" {
+				key, err := hosts.ParseSortKey(sortBy)
+				if err != nil {
+					return err
 				}
+				if err := hostsFile.SortEntries(key, false); err != nil {
+					return err
+				}
+			}
 
-				fmt.Printf("\n=== %s ===\n", category.Name)
-				if category.Description != "" {
-					fmt.Printf("Description: %s\n", category.Description)
+			// The listing is rendered the same way whether it goes to stdout or a file
+			var out io.Writer = os.Stdout
+			var buf bytes.Buffer
+			outputPath := ""
+			if output != "" {
+				if err := ensureSecureDirectories(); err != nil {
+					return fmt.Errorf("failed to initialize secure directories: %w", err)
 				}
-				fmt.Printf("Status: ")
-				if category.Enabled {
-					fmt.Println("Enabled")
-				} else {
-					fmt.Println("Disabled")
+				outputPath, err = validateFilePathStrict(output, getAllowedDirectories(), "list")
+				if err != nil {
+					return fmt.Errorf("list output path validation failed: %w", err)
 				}
+				out = &buf
+			}
 
-				for _, entry := range category.Entries {
-					if !entry.Enabled && !showDisabled {
-						continue
-					}
+			switch format {
+			case "table":
+				writeEntryTable(out, hostsFile, categoryFilter, tagFilter, showDisabled, lineNumbers)
+			case "json", "yaml":
+				err = writeEntryList(out, listEntries(hostsFile, categoryFilter, tagFilter, showDisabled), format)
+			default:
+				return fmt.Errorf("unsupported list format: %s", format)
+			}
+			if err != nil || outputPath == "" {
+				return err
+			}
 
-					status := "✓"
-					if !entry.Enabled {
-						status = "✗"
-					}
+			if err := os.WriteFile(outputPath, buf.Bytes(), 0600); err != nil {
+				return err
+			}
+			fmt.Printf("Listed to: %s\n", outputPath)
+			return nil
+		},
+	}
 
-					fmt.Printf("  %s %s -> %v", status, entry.IP, entry.Hostnames)
-					if entry.Comment != "" {
-						fmt.Printf(" # %s", entry.Comment)
-					}
-					fmt.Println()
+	cmd.Flags().StringVarP(&categoryFilter, "category", "c", "", "Filter by category")
+	cmd.Flags().BoolVar(&showDisabled, "show-disabled", false, "Show disabled entries")
+	cmd.Flags().StringVarP(&format, "format", "f", "table", "Output format (table, json, yaml)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the listing to a file instead of stdout")
+	cmd.Flags().BoolVarP(&lineNumbers, "line-numbers", "n", false, "Show each entry's line number in the hosts file")
+	cmd.Flags().StringVar(&tagFilter, "tag", "", "Only show entries carrying this tag")
+	cmd.Flags().StringVar(&sortBy, "sort", "", "Sort entries within each category for display (ip, hostname, status, comment)")
+	_ = cmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions(
+		[]string{string(hosts.SortByIP), string(hosts.SortByHostname), string(hosts.SortByStatus), string(hosts.SortByComment)},
+		cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+// writeEntryTable writes the human-readable listing printed by list
+func writeEntryTable(w io.Writer, hostsFile *hosts.HostsFile, categoryFilter, tagFilter string, showDisabled, lineNumbers bool) {
+	for _, category := range hostsFile.Categories {
+		if categoryFilter != "" && category.Name != categoryFilter {
+			continue
+		}
+
+		fmt.Fprintf(w, "\n=== %s ===\n", category.Name)
+		if category.Description != "" {
+			fmt.Fprintf(w, "Description: %s\n", category.Description)
+		}
+		fmt.Fprintf(w, "Status: ")
+		if category.Enabled {
+			fmt.Fprintln(w, "Enabled")
+		} else {
+			fmt.Fprintln(w, "Disabled")
+		}
+
+		for _, entry := range category.Entries {
+			if !entry.Enabled && !showDisabled {
+				continue
+			}
+			if tagFilter != "" && !entry.HasTag(tagFilter) {
+				continue
+			}
+
+			status := "✓"
+			if !entry.Enabled {
+				status = "✗"
+			}
+
+			if lineNumbers {
+				fmt.Fprintf(w, "%4d", entry.LineNum)
+			}
+			fmt.Fprintf(w, "  %s %s -> %v", status, entry.IP, entry.Hostnames)
+			if entry.Comment != "" {
+				fmt.Fprintf(w, " # %s", entry.Comment)
+			}
+			if len(entry.Tags) > 0 {
+				fmt.Fprintf(w, " [tags: %s]", strings.Join(entry.Tags, ","))
+			}
+			if !entry.ExpiresAt.IsZero() {
+				fmt.Fprintf(w, " (expires %s)", entry.ExpiresAt.Local().Format(time.RFC3339))
+			}
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// listedEntry is the machine-readable form of an entry printed by list
+type listedEntry struct {
+	IP        string    `json:"ip" yaml:"ip"`
+	Hostnames []string  `json:"hostnames" yaml:"hostnames"`
+	Comment   string    `json:"comment" yaml:"comment"`
+	Category  string    `json:"category" yaml:"category"`
+	Enabled   bool      `json:"enabled" yaml:"enabled"`
+	LineNum   int       `json:"line_num" yaml:"line_num"`
+	ExpiresAt time.Time `json:"expires_at,omitzero" yaml:"expires_at,omitempty"`
+	Tags      []string  `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// listEntries applies the list command's category, tag and disabled filters
+func listEntries(hostsFile *hosts.HostsFile, categoryFilter, tagFilter string, showDisabled bool) []listedEntry {
+	entries := []listedEntry{}
+	for _, category := range hostsFile.Categories {
+		if categoryFilter != "" && category.Name != categoryFilter {
+			continue
+		}
+
+		for _, entry := range category.Entries {
+			if !entry.Enabled && !showDisabled {
+				continue
+			}
+			if tagFilter != "" && !entry.HasTag(tagFilter) {
+				continue
+			}
+
+			entries = append(entries, listedEntry{
+				IP:        entry.IP,
+				Hostnames: entry.Hostnames,
+				Comment:   entry.Comment,
+				Category:  category.Name,
+				Enabled:   entry.Enabled,
+				LineNum:   entry.LineNum,
+				ExpiresAt: entry.ExpiresAt,
+				Tags:      entry.Tags,
+			})
+		}
+	}
+	return entries
+}
+
+// writeEntryList marshals entries to w in json or yaml format
+func writeEntryList(w io.Writer, entries []listedEntry, format string) error {
+	var data []byte
+	var err error
+
+	switch format {
+	case "json":
+		data, err = json.MarshalIndent(entries, "", "  ")
+		data = append(data, '\n')
+	case "yaml":
+		data, err = yaml.Marshal(entries)
+	default:
+		return fmt.Errorf("unsupported list format: %s", format)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to marshal entries: %w", err)
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// entryUpdate holds the fields to change on an existing entry; nil fields are left unchanged
+type entryUpdate struct {
+	ip       *string
+	comment  *string
+	category *string
+	enabled  *bool
+}
+
+func updateCmd() *cobra.Command {
+	var ip, comment, category string
+	var enable, disable bool
+
+	cmd := &cobra.Command{
+		Use:   "update <hostname>",
+		Short: "Update an existing hosts entry in place",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var changes entryUpdate
+			if cmd.Flags().Changed("ip") {
+				changes.ip = &ip
+			}
+			if cmd.Flags().Changed("comment") {
+				changes.comment = &comment
+			}
+			if cmd.Flags().Changed("category") {
+				changes.category = &category
+			}
+			if enable || disable {
+				changes.enabled = &enable
+			}
+			if changes == (entryUpdate{}) {
+				return fmt.Errorf("nothing to update: specify --ip, --comment, --category, --enabled, or --disabled")
+			}
+
+			p := platform.New()
+			if err := p.ElevateIfNeeded(); err != nil {
+				return err
+			}
+
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+
+			hostname := args[0]
+			if err := checkReadOnly(hostsFile, hostname); err != nil {
+				return err
+			}
+
+			before, after, err := applyEntryUpdate(hostsFile, hostname, changes)
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				fmt.Printf("Would update %s:\n", hostname)
+				fmt.Printf("  before: %s\n", describeEntry(before))
+				fmt.Printf("  after:  %s\n", describeEntry(after))
+				return nil
+			}
+
+			backupMgr := backup.NewManager(cfg)
+			if autoBackupEnabled("update") {
+				if _, err := backupMgr.CreateAutoBackup(); err != nil {
+					return fmt.Errorf("failed to create backup: %w", err)
+				}
+				if verbose {
+					fmt.Println("Backup created successfully")
 				}
 			}
 
+			if err := writeHostsFile(hostsFile, p.GetHostsFilePath()); err != nil {
+				if logger, logErr := audit.NewLogger(); logErr == nil {
+					logger.LogHostsOperation("update", after.IP, after.Hostnames, false, err.Error())
+				}
+				return fmt.Errorf("failed to write hosts file: %w", err)
+			}
+
+			if logger, err := audit.NewLogger(); err == nil {
+				logger.LogHostsOperation("update", after.IP, after.Hostnames, true, "")
+			}
+
+			fmt.Printf("Updated entry: %s\n", describeEntry(after))
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&categoryFilter, "category", "c", "", "Filter by category")
-	cmd.Flags().BoolVar(&showDisabled, "show-disabled", false, "Show disabled entries")
+	cmd.Flags().StringVar(&ip, "ip", "", "New IP address for the entry")
+	cmd.Flags().StringVar(&comment, "comment", "", "New comment for the entry (empty string clears it)")
+	cmd.Flags().StringVarP(&category, "category", "c", "", "Move the entry to this category")
+	cmd.Flags().BoolVar(&enable, "enabled", false, "Enable the entry")
+	cmd.Flags().BoolVar(&disable, "disabled", false, "Disable the entry, keeping it commented out")
+	cmd.MarkFlagsMutuallyExclusive("enabled", "disabled")
+	_ = cmd.RegisterFlagCompletionFunc("category", completeCategories)
 
 	return cmd
 }
 
+// applyEntryUpdate applies changes to the single entry listing hostname and
+// returns the entry before and after the update. The updated entry is validated
+// before hostsFile is modified.
+func applyEntryUpdate(hostsFile *hosts.HostsFile, hostname string, changes entryUpdate) (hosts.Entry, hosts.Entry, error) {
+	matches := hostsFile.FindEntryByHostname(hostname)
+	if len(matches) == 0 {
+		return hosts.Entry{}, hosts.Entry{}, fmt.Errorf("hostname not found: %s", hostname)
+	}
+	if len(matches) > 1 {
+		return hosts.Entry{}, hosts.Entry{}, ambiguousHostnameError(hostname, matches)
+	}
+
+	entry := matches[0]
+	before := *entry
+	after := *entry
+	if changes.ip != nil {
+		after.IP = *changes.ip
+	}
+	if changes.comment != nil {
+		after.Comment = *changes.comment
+	}
+	if changes.category != nil {
+		after.Category = *changes.category
+	}
+	if changes.enabled != nil {
+		after.Enabled = *changes.enabled
+	}
+
+	if err := hosts.ValidateEntryWithMode(after, hostsFile.HostnameMode()); err != nil {
+		return before, after, fmt.Errorf("invalid update: %w", err)
+	}
+
+	entry.IP = after.IP
+	entry.Comment = after.Comment
+	entry.Enabled = after.Enabled
+
+	if after.Category != before.Category {
+		if hostsFile.GetCategory(after.Category) == nil {
+			if err := hostsFile.AddCategory(after.Category, ""); err != nil {
+				return before, after, fmt.Errorf("failed to create category: %w", err)
+			}
+		}
+		if err := hostsFile.MoveEntry(entry, after.Category); err != nil {
+			return before, after, fmt.Errorf("failed to move entry: %w", err)
+		}
+	}
+
+	return before, after, nil
+}
+
+// ambiguousHostnameError lists every entry declaring hostname so the user can disambiguate
+func ambiguousHostnameError(hostname string, matches []*hosts.Entry) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "hostname %s matches %d entries:", hostname, len(matches))
+	for _, entry := range matches {
+		fmt.Fprintf(&b, "\n  line %d: %s", entry.LineNum, describeEntry(*entry))
+	}
+	return fmt.Errorf("%s", b.String())
+}
+
+// describeEntry renders an entry on a single line for command output
+func describeEntry(entry hosts.Entry) string {
+	status := "✓"
+	if !entry.Enabled {
+		status = "✗"
+	}
+
+	line := fmt.Sprintf("%s [%s] %s -> %v", status, entry.Category, entry.IP, entry.Hostnames)
+	if entry.Comment != "" {
+		line += " # " + entry.Comment
+	}
+	if len(entry.Tags) > 0 {
+		line += " [tags: " + strings.Join(entry.Tags, ",") + "]"
+	}
+	if !entry.ExpiresAt.IsZero() {
+		line += " (expires " + entry.ExpiresAt.Local().Format(time.RFC3339) + ")"
+	}
+	return line
+}
+
 func deleteCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "delete <hostname>",
@@ -234,8 +1042,8 @@ func deleteCmd() *cobra.Command {
 			}
 
 			backupMgr := backup.NewManager(cfg)
-			if cfg.General.AutoBackup {
-				if _, err := backupMgr.CreateBackup(); err != nil {
+			if autoBackupEnabled("delete") {
+				if _, err := backupMgr.CreateAutoBackup(); err != nil {
 					return fmt.Errorf("failed to create backup: %w", err)
 				}
 				if verbose {
@@ -243,13 +1051,16 @@ func deleteCmd() *cobra.Command {
 				}
 			}
 
-			parser := hosts.NewParser(p.GetHostsFilePath())
-			hostsFile, err := parser.Parse()
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
 			if err != nil {
 				return fmt.Errorf("failed to parse hosts file: %w", err)
 			}
 
 			hostname := args[0]
+			if err := checkReadOnly(hostsFile, hostname); err != nil {
+				return err
+			}
+
 			if dryRun {
 				fmt.Printf("Would delete hostname: %s\n", hostname)
 				return nil
@@ -259,7 +1070,7 @@ func deleteCmd() *cobra.Command {
 				return fmt.Errorf("hostname not found: %s", hostname)
 			}
 
-			if err := hostsFile.Write(p.GetHostsFilePath()); err != nil {
+			if err := writeHostsFile(hostsFile, p.GetHostsFilePath()); err != nil {
 				return fmt.Errorf("failed to write hosts file: %w", err)
 			}
 
@@ -272,40 +1083,94 @@ func deleteCmd() *cobra.Command {
 }
 
 func enableCmd() *cobra.Command {
+	var category string
+
 	cmd := &cobra.Command{
 		Use:   "enable <hostname>",
 		Short: "Enable a hosts entry",
-		Args:  cobra.ExactArgs(1),
+		Long: `Enable the hosts entry containing hostname.
+
+If hostname is listed by more than one entry, nothing is changed and the
+matches are listed; use --category to pick the entry in that category.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return toggleEntry(args[0], true)
+			return toggleEntry(args[0], category, true, false)
 		},
 	}
 
+	cmd.Flags().StringVarP(&category, "category", "c", "", "Only enable the entry in this category")
+	_ = cmd.RegisterFlagCompletionFunc("category", completeCategories)
+
 	return cmd
 }
 
 func disableCmd() *cobra.Command {
+	var hostnameOnly bool
+	var category string
+
 	cmd := &cobra.Command{
 		Use:   "disable <hostname>",
 		Short: "Disable a hosts entry",
-		Args:  cobra.ExactArgs(1),
+		Long: `Disable the hosts entry containing hostname.
+
+If hostname is listed by more than one entry, nothing is changed and the
+matches are listed; use --category to pick the entry in that category.
+
+With --hostname-only, a hostname that shares its line with others is split
+into its own disabled entry and the remaining hostnames stay enabled.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return toggleEntry(args[0], false)
+			return toggleEntry(args[0], category, false, hostnameOnly)
 		},
 	}
 
+	cmd.Flags().BoolVar(&hostnameOnly, "hostname-only", false, "Disable only this hostname, splitting it out of a shared entry")
+	cmd.Flags().StringVarP(&category, "category", "c", "", "Only disable the entry in this category")
+	_ = cmd.RegisterFlagCompletionFunc("category", completeCategories)
+
 	return cmd
 }
 
-func toggleEntry(hostname string, enable bool) error {
+// toggleTarget returns the single entry listing hostname, in category unless
+// it is empty. Several matches are an error listing each of them, with a
+// hint on how to pick one, rather than a guess.
+func toggleTarget(hostsFile *hosts.HostsFile, hostname, category string, enable bool) (*hosts.Entry, error) {
+	var matches []*hosts.Entry
+	for _, entry := range hostsFile.FindEntryByHostname(hostname) {
+		if category == "" || entry.Category == category {
+			matches = append(matches, entry)
+		}
+	}
+
+	switch {
+	case len(matches) == 1:
+		return matches[0], nil
+	case len(matches) == 0 && category != "":
+		return nil, fmt.Errorf("hostname %s not found in category %s", hostname, category)
+	case len(matches) == 0:
+		return nil, fmt.Errorf("hostname not found: %s", hostname)
+	}
+
+	hint := "use --category to choose one"
+	if category != "" || !slices.ContainsFunc(matches, func(e *hosts.Entry) bool { return e.Category != matches[0].Category }) {
+		lineCmd := "comment"
+		if enable {
+			lineCmd = "uncomment"
+		}
+		hint = fmt.Sprintf("use %s <line> to choose one", lineCmd)
+	}
+	return nil, fmt.Errorf("%w\n%s", ambiguousHostnameError(hostname, matches), hint)
+}
+
+func toggleEntry(hostname, category string, enable, hostnameOnly bool) error {
 	p := platform.New()
 	if err := p.ElevateIfNeeded(); err != nil {
 		return err
 	}
 
 	backupMgr := backup.NewManager(cfg)
-	if cfg.General.AutoBackup {
-		if _, err := backupMgr.CreateBackup(); err != nil {
+	if autoBackupEnabled(toggleOperation(enable)) {
+		if _, err := backupMgr.CreateAutoBackup(); err != nil {
 			return fmt.Errorf("failed to create backup: %w", err)
 		}
 		if verbose {
@@ -313,40 +1178,53 @@ func toggleEntry(hostname string, enable bool) error {
 		}
 	}
 
-	parser := hosts.NewParser(p.GetHostsFilePath())
-	hostsFile, err := parser.Parse()
+	hostsFile, err := parseHostsFile(p.GetHostsFilePath())
 	if err != nil {
 		return fmt.Errorf("failed to parse hosts file: %w", err)
 	}
 
+	if err := checkReadOnly(hostsFile, hostname); err != nil {
+		return err
+	}
+
+	target, err := toggleTarget(hostsFile, hostname, category, enable)
+	if err != nil {
+		return err
+	}
+	// Splitting with --hostname-only may move the entry, so keep its category
+	category = target.Category
+
 	action := "disable"
 	if enable {
 		action = "enable"
 	}
 
 	if dryRun {
-		fmt.Printf("Would %s hostname: %s\n", action, hostname)
+		fmt.Printf("Would %s hostname: %s [%s]\n", action, hostname, category)
 		return nil
 	}
 
 	var success bool
-	if enable {
-		success = hostsFile.EnableEntry(hostname)
-	} else {
-		success = hostsFile.DisableEntry(hostname)
+	switch {
+	case enable:
+		success = hostsFile.EnableEntryInCategory(hostname, category)
+	case hostnameOnly:
+		success = hostsFile.DisableHostnameInCategory(hostname, category)
+	default:
+		success = hostsFile.DisableEntryInCategory(hostname, category)
 	}
 
 	if !success {
 		return fmt.Errorf("hostname not found: %s", hostname)
 	}
 
-	if err := hostsFile.Write(p.GetHostsFilePath()); err != nil {
+	if err := writeHostsFile(hostsFile, p.GetHostsFilePath()); err != nil {
 		return fmt.Errorf("failed to write hosts file: %w", err)
 	}
 
 	// Capitalize first letter manually (strings.Title is deprecated)
 	actionCapitalized := strings.ToUpper(action[:1]) + action[1:]
-	fmt.Printf("%sd hostname: %s\n", actionCapitalized, hostname)
+	fmt.Printf("%sd hostname: %s [%s]\n", actionCapitalized, hostname, category)
 	return nil
 }
 
@@ -354,33 +1232,113 @@ func searchCmd() *cobra.Command {
 	var fuzzy bool
 	var caseSensitive bool
 	var categoryFilter string
+	var wholeWord bool
+	var cidr string
+	var byIP string
+	var tagFilter string
+	var minScore float64
+	var limit int
+	var enabledOnly bool
+	var disabledOnly bool
+	var jsonOutput bool
 
 	cmd := &cobra.Command{
-		Use:   "search <query>",
+		Use:   "search [query]",
 		Short: "Search hosts entries",
-		Args:  cobra.ExactArgs(1),
+		Long: `Search hosts entries by hostname, IP, tag, or comment.
+
+With --cidr, list every entry whose IP falls inside an IPv4 or IPv6 range
+instead of matching a text query. With --by-ip, list every entry mapped to
+exactly that address; unlike a text query, 10.0.0.1 does not match 10.0.0.10.
+
+With --tag, only entries carrying that tag are shown; the query may then be
+omitted to list every tagged entry.
+
+Text matches are scored from 0 to 1 and shown best first; --min-score drops
+weak fuzzy matches and --limit keeps only the top results.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if minScore < 0 || minScore > 1 {
+				return fmt.Errorf("--min-score must be between 0 and 1")
+			}
+			if limit < 0 {
+				return fmt.Errorf("--limit cannot be negative")
+			}
+			if cidr != "" || byIP != "" {
+				if len(args) > 0 {
+					return fmt.Errorf("--cidr and --by-ip cannot be combined with a search query")
+				}
+				return nil
+			}
+			if tagFilter != "" {
+				return cobra.MaximumNArgs(1)(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			p := platform.New()
-			parser := hosts.NewParser(p.GetHostsFilePath())
-			hostsFile, err := parser.Parse()
+			hostsFile, err := parseHostsFile(p.GetHostsFilePath())
 			if err != nil {
 				return fmt.Errorf("failed to parse hosts file: %w", err)
 			}
 
 			searcher := search.NewSearcher(caseSensitive, fuzzy)
+			searcher.SetWholeWord(wholeWord)
+			searcher.SetMinScore(minScore)
 			var results []search.Result
 
-			if categoryFilter != "" {
+			switch {
+			case cidr != "":
+				results, err = searcher.SearchByCIDR(hostsFile, cidr)
+				if err != nil {
+					return err
+				}
+				if categoryFilter != "" {
+					results = filterResultsByCategory(results, categoryFilter)
+				}
+			case byIP != "":
+				if err := hosts.ValidateIP(byIP); err != nil {
+					return err
+				}
+				results = searcher.SearchByIP(hostsFile, byIP)
+				if categoryFilter != "" {
+					results = filterResultsByCategory(results, categoryFilter)
+				}
+			case len(args) == 0:
+				results = searcher.SearchByTag(hostsFile, tagFilter)
+				if categoryFilter != "" {
+					results = filterResultsByCategory(results, categoryFilter)
+				}
+			case categoryFilter != "":
 				results = searcher.SearchByCategory(hostsFile, args[0], categoryFilter)
-			} else {
+			default:
 				results = searcher.Search(hostsFile, args[0])
 			}
+			if tagFilter != "" {
+				results = search.FilterByTag(results, tagFilter)
+			}
+			if enabledOnly || disabledOnly {
+				results = search.FilterByEnabled(results, enabledOnly)
+			}
+			if limit > 0 && len(results) > limit {
+				results = results[:limit]
+			}
+
+			if jsonOutput {
+				return writeSearchResults(os.Stdout, results)
+			}
 
 			if len(results) == 0 {
 				fmt.Println("No entries found")
 				return nil
 			}
 
+			// Highlighting follows --color, so by default it is skipped when
+			// output is redirected to a file or pipe
+			render := func(s string) string { return s }
+			if colorEnabled(os.Stdout) {
+				render = func(s string) string { return matchStyle.Render(s) }
+			}
+
 			fmt.Printf("Found %d entries:\n\n", len(results))
 			for _, result := range results {
 				entry := result.Entry
@@ -390,10 +1348,13 @@ func searchCmd() *cobra.Command {
 				}
 
 				fmt.Printf("  %s [%s] %s -> %v (score: %.2f, match: %s)",
-					status, entry.Category, entry.IP, entry.Hostnames, result.Score, result.Match)
+					status, entry.Category, entry.IP, entry.Hostnames, result.Score, highlightMatch(result, render))
 				if entry.Comment != "" {
 					fmt.Printf(" # %s", entry.Comment)
 				}
+				if len(entry.Tags) > 0 {
+					fmt.Printf(" [tags: %s]", strings.Join(entry.Tags, ","))
+				}
 				fmt.Println()
 			}
 
@@ -404,6 +1365,130 @@ func searchCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&fuzzy, "fuzzy", true, "Enable fuzzy matching")
 	cmd.Flags().BoolVar(&caseSensitive, "case-sensitive", false, "Enable case-sensitive search")
 	cmd.Flags().StringVarP(&categoryFilter, "category", "c", "", "Filter by category")
+	cmd.Flags().BoolVarP(&wholeWord, "whole-word", "w", false, "Match only complete hostname labels (overrides --fuzzy)")
+	cmd.Flags().StringVar(&cidr, "cidr", "", "List entries whose IP is inside this range (e.g. 192.168.1.0/24)")
+	cmd.Flags().StringVar(&byIP, "by-ip", "", "List entries mapped to exactly this IP address")
+	cmd.MarkFlagsMutuallyExclusive("cidr", "by-ip")
+	cmd.Flags().StringVar(&tagFilter, "tag", "", "Only show entries carrying this tag")
+	cmd.Flags().Float64Var(&minScore, "min-score", 0.3, "Drop matches scoring below this (0-1)")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Show only the N best matches (0 for all)")
+	cmd.Flags().BoolVar(&enabledOnly, "enabled-only", false, "Only show enabled entries")
+	cmd.Flags().BoolVar(&disabledOnly, "disabled-only", false, "Only show disabled entries")
+	cmd.MarkFlagsMutuallyExclusive("enabled-only", "disabled-only")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print results as a JSON array")
 
 	return cmd
 }
+
+// searchResult is the machine-readable form of a result printed by search
+type searchResult struct {
+	IP        string   `json:"ip"`
+	Hostnames []string `json:"hostnames"`
+	Comment   string   `json:"comment"`
+	Category  string   `json:"category"`
+	Enabled   bool     `json:"enabled"`
+	Tags      []string `json:"tags,omitempty"`
+	Score     float64  `json:"score"`
+	Match     string   `json:"match"`
+}
+
+// writeSearchResults writes results as a JSON array, which is empty rather
+// than null when nothing matched
+func writeSearchResults(w io.Writer, results []search.Result) error {
+	out := make([]searchResult, 0, len(results))
+	for _, result := range results {
+		out = append(out, searchResult{
+			IP:        result.Entry.IP,
+			Hostnames: result.Entry.Hostnames,
+			Comment:   result.Entry.Comment,
+			Category:  result.Entry.Category,
+			Enabled:   result.Entry.Enabled,
+			Tags:      result.Entry.Tags,
+			Score:     result.Score,
+			Match:     result.Match,
+		})
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal results: %w", err)
+	}
+	data = append(data, '\n')
+
+	_, err = w.Write(data)
+	return err
+}
+
+var matchStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFD700"))
+
+// highlightMatch returns result.Match with each run of matched characters
+// passed through render
+func highlightMatch(result search.Result, render func(string) string) string {
+	matched := make(map[int]bool, len(result.MatchPositions))
+	for _, pos := range result.MatchPositions {
+		matched[pos] = true
+	}
+
+	var b strings.Builder
+	for start := 0; start < len(result.Match); {
+		end := start + 1
+		for end < len(result.Match) && matched[end] == matched[start] {
+			end++
+		}
+		if matched[start] {
+			b.WriteString(render(result.Match[start:end]))
+		} else {
+			b.WriteString(result.Match[start:end])
+		}
+		start = end
+	}
+	return b.String()
+}
+
+// colorModes are the values --color accepts
+var colorModes = []string{"auto", "always", "never"}
+
+// applyColorMode validates --color. With always, lipgloss is told to emit
+// colors even when stdout is not a terminal, which it would otherwise strip.
+func applyColorMode() error {
+	if !slices.Contains(colorModes, colorMode) {
+		return fmt.Errorf("invalid --color %q (must be auto, always or never)", colorMode)
+	}
+	if colorMode == "always" && lipgloss.ColorProfile() == termenv.Ascii {
+		lipgloss.SetColorProfile(termenv.ANSI256)
+	}
+	return nil
+}
+
+// colorEnabled reports whether output written to f should be styled.
+// always and never decide on their own; auto styles terminals unless the
+// NO_COLOR environment variable is set to a non-empty value.
+func colorEnabled(f *os.File) bool {
+	switch colorMode {
+	case "always":
+		return true
+	case "never":
+		return false
+	default:
+		return os.Getenv("NO_COLOR") == "" && isTerminal(f)
+	}
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func filterResultsByCategory(results []search.Result, category string) []search.Result {
+	var filtered []search.Result
+	for _, result := range results {
+		if result.Entry.Category == category {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
@@ -1,6 +1,7 @@
 package audit
 
 import (
+	"bufio"
 	"compress/gzip"
 	"encoding/json"
 	"fmt"
@@ -8,9 +9,11 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 
+	"github.com/brandonhon/hosts-manager/internal/config"
 	"github.com/brandonhon/hosts-manager/pkg/platform"
 )
 
@@ -26,6 +29,7 @@ const (
 	EventBackupCreate   EventType = "backup_create"
 	EventBackupRestore  EventType = "backup_restore"
 	EventBackupDelete   EventType = "backup_delete"
+	EventBackupSkip     EventType = "backup_skip"
 	EventConfigEdit     EventType = "config_edit"
 	EventImportFile     EventType = "import_file"
 	EventExportFile     EventType = "export_file"
@@ -33,6 +37,9 @@ const (
 	EventValidationFail EventType = "validation_failure"
 	EventSecurityViol   EventType = "security_violation"
 	EventFileAccess     EventType = "file_access"
+	EventDNSFlush       EventType = "dns_flush"
+	EventWatchReconcile EventType = "watch_reconcile"
+	EventCommand        EventType = "command"
 )
 
 // Severity represents the severity level of an audit event
@@ -45,6 +52,15 @@ const (
 	SeverityCritical Severity = "critical"
 )
 
+// severityRank orders severities from info to critical, for minimum-level
+// checks. Unknown severities rank as info.
+var severityRank = map[Severity]int{
+	SeverityInfo:     0,
+	SeverityWarning:  1,
+	SeverityError:    2,
+	SeverityCritical: 3,
+}
+
 // AuditEvent represents a single audit event
 type AuditEvent struct {
 	Timestamp time.Time              `json:"timestamp"`
@@ -72,51 +88,104 @@ type Logger struct {
 	maxLogs    int   // Maximum number of rotated logs to keep
 }
 
-// NewLogger creates a new audit logger
-func NewLogger() (*Logger, error) {
-	p := platform.New()
-	logDir := filepath.Join(p.GetDataDir(), "audit")
+// defaultConfig is the configuration NewLogger applies; see SetDefaultConfig
+var defaultConfig *config.Config
+
+// SetDefaultConfig makes NewLogger apply cfg's audit section, so loggers
+// created anywhere in the program honor it. A nil cfg restores the defaults.
+func SetDefaultConfig(cfg *config.Config) {
+	defaultConfig = cfg
+}
 
-	// Create audit log directory with secure permissions
-	if err := os.MkdirAll(logDir, 0700); err != nil {
-		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+// console receives a copy of every event as a JSON line; see SetConsole
+var (
+	console   io.Writer
+	consoleMu sync.Mutex
+)
+
+// SetConsole makes every logger also write each event as a JSON line to w,
+// usually standard error, so a log pipeline can follow operations. Events
+// are written whether or not the audit log is enabled and whatever its
+// minimum level. A nil w turns the copy off.
+func SetConsole(w io.Writer) {
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+	console = w
+}
+
+// WriteConsole writes event to the writer set with SetConsole without
+// recording it in the audit log. It does nothing when no writer is set.
+func WriteConsole(event AuditEvent) error {
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+	if console == nil {
+		return nil
 	}
 
-	logPath := filepath.Join(logDir, "audit.log")
+	eventJSON, err := json.Marshal(completeEvent(event))
+	if err != nil {
+		return fmt.Errorf("failed to serialize audit event: %w", err)
+	}
+	_, err = console.Write(append(eventJSON, '\n'))
+	return err
+}
+
+// NewLogger creates a new audit logger using the configuration set with
+// SetDefaultConfig, or the built-in defaults if none was set
+func NewLogger() (*Logger, error) {
+	return NewLoggerWithConfig(defaultConfig)
+}
+
+// NewLoggerWithConfig creates an audit logger from cfg's audit section. A nil
+// cfg uses the defaults: enabled, info level, 10MB logs, 5 rotated logs.
+// Unset size and count fields also fall back to the defaults.
+func NewLoggerWithConfig(cfg *config.Config) (*Logger, error) {
+	p := platform.New()
+	logDir := filepath.Join(p.GetDataDir(), "audit")
 
-	return &Logger{
-		logPath:    logPath,
+	logger := &Logger{
+		logPath:    filepath.Join(logDir, "audit.log"),
 		enabled:    true,
 		minLevel:   SeverityInfo,
 		maxLogSize: 10 * 1024 * 1024, // 10MB default
 		maxLogs:    5,                // Keep 5 rotated logs
-	}, nil
+	}
+	if cfg != nil {
+		logger.enabled = cfg.Audit.Enabled
+		if cfg.Audit.MinLevel != "" {
+			logger.minLevel = Severity(cfg.Audit.MinLevel)
+		}
+		if cfg.Audit.MaxLogSize > 0 {
+			logger.maxLogSize = cfg.Audit.MaxLogSize
+		}
+		if cfg.Audit.MaxLogs > 0 {
+			logger.maxLogs = cfg.Audit.MaxLogs
+		}
+	}
+
+	// A disabled logger never writes, so it leaves no directory behind either
+	if logger.enabled {
+		// Create audit log directory with secure permissions
+		if err := os.MkdirAll(logDir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+		}
+	}
+
+	return logger, nil
 }
 
-// Log records an audit event
+// Log records an audit event, copying it to the console set with SetConsole
 func (l *Logger) Log(event AuditEvent) error {
+	event = completeEvent(event)
+	_ = WriteConsole(event) // The console copy must not stop the audit log
+
 	if !l.enabled {
 		return nil
 	}
 
-	// Set default timestamp if not provided
-	if event.Timestamp.IsZero() {
-		event.Timestamp = time.Now().UTC()
-	}
-
-	// Set user information if not provided
-	if event.UserID == 0 {
-		event.UserID = os.Getuid()
-	}
-	if event.Username == "" {
-		if user := os.Getenv("USER"); user != "" {
-			event.Username = user
-		} else if user := os.Getenv("USERNAME"); user != "" {
-			event.Username = user
-		}
-	}
-	if event.ProcessID == 0 {
-		event.ProcessID = os.Getpid()
+	// Skip events below the configured minimum severity
+	if severityRank[event.Severity] < severityRank[l.minLevel] {
+		return nil
 	}
 
 	// Serialize event to JSON
@@ -157,6 +226,31 @@ func (l *Logger) Log(event AuditEvent) error {
 	return nil
 }
 
+// completeEvent fills in the timestamp, user and process when not provided
+func completeEvent(event AuditEvent) AuditEvent {
+	// Set default timestamp if not provided
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	// Set user information if not provided
+	if event.UserID == 0 {
+		event.UserID = os.Getuid()
+	}
+	if event.Username == "" {
+		if user := os.Getenv("USER"); user != "" {
+			event.Username = user
+		} else if user := os.Getenv("USERNAME"); user != "" {
+			event.Username = user
+		}
+	}
+	if event.ProcessID == 0 {
+		event.ProcessID = os.Getpid()
+	}
+
+	return event
+}
+
 // LogSecurityViolation logs a security violation event
 func (l *Logger) LogSecurityViolation(operation, resource, reason string, details map[string]interface{}) {
 	event := AuditEvent{
@@ -318,6 +412,73 @@ func (l *Logger) LogBackupOperation(operation, backupPath string, success bool,
 	_ = l.Log(event) // Intentionally ignore error for audit logging
 }
 
+// LogBackupSkipped records that the automatic backup before operation was
+// deliberately skipped, and why
+func (l *Logger) LogBackupSkipped(operation, reason string) {
+	event := AuditEvent{
+		EventType: EventBackupSkip,
+		Severity:  SeverityWarning,
+		Operation: operation,
+		Resource:  "backup_system",
+		Success:   true,
+		Details: map[string]interface{}{
+			"reason": reason,
+		},
+	}
+
+	_ = l.Log(event) // Intentionally ignore error for audit logging
+}
+
+// LogDNSFlush records an attempt to flush the OS resolver cache and the
+// commands it ran
+func (l *Logger) LogDNSFlush(commands []string, success bool, errorMsg string) {
+	severity := SeverityInfo
+	if !success {
+		severity = SeverityWarning
+	}
+
+	event := AuditEvent{
+		EventType: EventDNSFlush,
+		Severity:  severity,
+		Operation: "flush_dns",
+		Resource:  "dns_cache",
+		Success:   success,
+		ErrorMsg:  errorMsg,
+		Details: map[string]interface{}{
+			"commands": strings.Join(commands, "; "),
+		},
+	}
+
+	_ = l.Log(event) // Intentionally ignore error for audit logging
+}
+
+// LogWatchReconcile records an external hosts file change seen by the watch
+// command, the managed categories it removed, and whether they were restored
+func (l *Logger) LogWatchReconcile(missing []string, restored bool, errorMsg string) {
+	severity := SeverityInfo
+	if len(missing) > 0 {
+		severity = SeverityWarning
+	}
+	if errorMsg != "" {
+		severity = SeverityError
+	}
+
+	event := AuditEvent{
+		EventType: EventWatchReconcile,
+		Severity:  severity,
+		Operation: "watch",
+		Resource:  "hosts_file",
+		Success:   errorMsg == "",
+		ErrorMsg:  errorMsg,
+		Details: map[string]interface{}{
+			"missing_categories": strings.Join(missing, ", "),
+			"restored":           restored,
+		},
+	}
+
+	_ = l.Log(event) // Intentionally ignore error for audit logging
+}
+
 // GetLogPath returns the path to the audit log file
 func (l *Logger) GetLogPath() string {
 	return l.logPath
@@ -349,16 +510,21 @@ func (l *Logger) GetRecentEvents(limit int) ([]AuditEvent, error) {
 	}()
 
 	var events []AuditEvent
-	decoder := json.NewDecoder(file)
+	// One event per line, so a malformed line can be skipped without losing the rest
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
 
-	for decoder.More() && len(events) < limit {
+	for scanner.Scan() && len(events) < limit {
 		var event AuditEvent
-		if err := decoder.Decode(&event); err != nil {
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
 			continue // Skip malformed entries
 		}
 		events = append(events, event)
 	}
 
+	if err := scanner.Err(); err != nil {
+		return events, fmt.Errorf("failed to read audit log: %w", err)
+	}
 	return events, nil
 }
 
@@ -387,22 +553,25 @@ func (l *Logger) rotateLog() error {
 	logDir := filepath.Dir(l.logPath)
 	logBasename := filepath.Base(l.logPath)
 
-	// Remove the oldest log if we have too many
-	oldestLog := filepath.Join(logDir, fmt.Sprintf("%s.%d", logBasename, l.maxLogs))
-	if _, err := os.Stat(oldestLog); err == nil {
-		if err := os.Remove(oldestLog); err != nil {
-			return fmt.Errorf("failed to remove oldest log: %w", err)
+	// Rotated logs are normally compressed, but keep uncompressed ones in step too
+	for _, ext := range []string{"", ".gz"} {
+		// Remove the oldest log if we have too many
+		oldestLog := filepath.Join(logDir, fmt.Sprintf("%s.%d%s", logBasename, l.maxLogs, ext))
+		if _, err := os.Stat(oldestLog); err == nil {
+			if err := os.Remove(oldestLog); err != nil {
+				return fmt.Errorf("failed to remove oldest log: %w", err)
+			}
 		}
-	}
 
-	// Shift existing rotated logs
-	for i := l.maxLogs - 1; i >= 1; i-- {
-		oldName := filepath.Join(logDir, fmt.Sprintf("%s.%d", logBasename, i))
-		newName := filepath.Join(logDir, fmt.Sprintf("%s.%d", logBasename, i+1))
+		// Shift existing rotated logs
+		for i := l.maxLogs - 1; i >= 1; i-- {
+			oldName := filepath.Join(logDir, fmt.Sprintf("%s.%d%s", logBasename, i, ext))
+			newName := filepath.Join(logDir, fmt.Sprintf("%s.%d%s", logBasename, i+1, ext))
 
-		if _, err := os.Stat(oldName); err == nil {
-			if err := os.Rename(oldName, newName); err != nil {
-				return fmt.Errorf("failed to rotate log %s to %s: %w", oldName, newName, err)
+			if _, err := os.Stat(oldName); err == nil {
+				if err := os.Rename(oldName, newName); err != nil {
+					return fmt.Errorf("failed to rotate log %s to %s: %w", oldName, newName, err)
+				}
 			}
 		}
 	}
@@ -1,12 +1,15 @@
 package audit
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/brandonhon/hosts-manager/internal/config"
 )
 
 func TestEventTypeConstants(t *testing.T) {
@@ -19,6 +22,7 @@ func TestEventTypeConstants(t *testing.T) {
 		EventBackupCreate:   "backup_create",
 		EventBackupRestore:  "backup_restore",
 		EventBackupDelete:   "backup_delete",
+		EventBackupSkip:     "backup_skip",
 		EventConfigEdit:     "config_edit",
 		EventImportFile:     "import_file",
 		EventExportFile:     "export_file",
@@ -26,6 +30,9 @@ func TestEventTypeConstants(t *testing.T) {
 		EventValidationFail: "validation_failure",
 		EventSecurityViol:   "security_violation",
 		EventFileAccess:     "file_access",
+		EventDNSFlush:       "dns_flush",
+		EventWatchReconcile: "watch_reconcile",
+		EventCommand:        "command",
 	}
 
 	for eventType, expected := range expectedEvents {
@@ -99,6 +106,48 @@ func TestNewLogger(t *testing.T) {
 	}
 }
 
+func TestNewLoggerWithConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tempDir)
+	t.Setenv("HOME", tempDir)
+	t.Setenv("APPDATA", "")
+
+	cfg := config.DefaultConfig()
+	cfg.Audit = config.Audit{Enabled: true, MinLevel: "warning", MaxLogSize: 4096, MaxLogs: 2}
+
+	logger, err := NewLoggerWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewLoggerWithConfig() error = %v", err)
+	}
+	if !logger.enabled || logger.minLevel != SeverityWarning || logger.maxLogSize != 4096 || logger.maxLogs != 2 {
+		t.Errorf("Expected configured logger, got %+v", logger)
+	}
+
+	// SetDefaultConfig makes NewLogger honor the same settings
+	SetDefaultConfig(cfg)
+	defer SetDefaultConfig(nil)
+	if logger, err := NewLogger(); err != nil || logger.maxLogs != 2 {
+		t.Errorf("Expected NewLogger to use the default config, got %+v (%v)", logger, err)
+	}
+
+	// Disabling auditing writes nothing, not even the log directory
+	disabledHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", disabledHome)
+	t.Setenv("HOME", disabledHome)
+	cfg.Audit.Enabled = false
+	logger, err = NewLoggerWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewLoggerWithConfig() error = %v", err)
+	}
+	if logger.IsEnabled() {
+		t.Error("Expected logger to be disabled")
+	}
+	logger.LogSecurityViolation("test", "resource", "reason", nil)
+	if _, err := os.Stat(filepath.Dir(logger.logPath)); !os.IsNotExist(err) {
+		t.Errorf("Expected no audit directory when disabled, stat error = %v", err)
+	}
+}
+
 func TestLoggerBasicOperations(t *testing.T) {
 	tempDir := t.TempDir()
 	logPath := filepath.Join(tempDir, "audit.log")
@@ -244,6 +293,84 @@ func TestLogEventDisabled(t *testing.T) {
 	}
 }
 
+func TestConsole(t *testing.T) {
+	var buf bytes.Buffer
+	SetConsole(&buf)
+	defer SetConsole(nil)
+
+	logPath := filepath.Join(t.TempDir(), "audit.log")
+	logger := &Logger{
+		logPath:  logPath,
+		enabled:  false,
+		minLevel: SeverityInfo,
+	}
+
+	// Disabled audit logs are still copied to the console
+	logger.LogHostsOperation("add", "192.168.1.10", []string{"api.dev"}, true, "")
+	if err := WriteConsole(AuditEvent{EventType: EventCommand, Severity: SeverityError, Operation: "hosts-manager add", ErrorMsg: "failed"}); err != nil {
+		t.Fatalf("WriteConsole() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 console lines, got:\n%s", buf.String())
+	}
+	var events []AuditEvent
+	for _, line := range lines {
+		var event AuditEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Console line is not JSON: %q", line)
+		}
+		events = append(events, event)
+	}
+	if events[0].EventType != EventHostsAdd || !events[0].Success || events[0].Timestamp.IsZero() {
+		t.Errorf("Unexpected hosts event %+v", events[0])
+	}
+	if events[1].EventType != EventCommand || events[1].ErrorMsg != "failed" || events[1].ProcessID == 0 {
+		t.Errorf("Unexpected command event %+v", events[1])
+	}
+	if _, err := os.Stat(logPath); !os.IsNotExist(err) {
+		t.Error("Console events should not be written to the audit log")
+	}
+
+	SetConsole(nil)
+	logger.LogHostsOperation("add", "192.168.1.11", []string{"web.dev"}, true, "")
+	if len(strings.Split(strings.TrimSpace(buf.String()), "\n")) != 2 {
+		t.Error("Events should not be copied after SetConsole(nil)")
+	}
+}
+
+func TestLogEventBelowMinLevel(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "audit.log")
+
+	logger := &Logger{
+		logPath:    logPath,
+		enabled:    true,
+		minLevel:   SeverityWarning,
+		maxLogSize: 10 * 1024 * 1024,
+		maxLogs:    5,
+	}
+
+	if err := logger.Log(AuditEvent{EventType: EventHostsAdd, Severity: SeverityInfo, Operation: "info_operation"}); err != nil {
+		t.Fatalf("Failed to log info event: %v", err)
+	}
+	if _, err := os.Stat(logPath); !os.IsNotExist(err) {
+		t.Fatal("Info event should not be written with a warning minimum level")
+	}
+
+	if err := logger.Log(AuditEvent{EventType: EventSecurityViol, Severity: SeverityCritical, Operation: "critical_operation"}); err != nil {
+		t.Fatalf("Failed to log critical event: %v", err)
+	}
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], "critical_operation") {
+		t.Errorf("Expected only the critical event to be logged, got:\n%s", content)
+	}
+}
+
 func TestLogSecurityViolation(t *testing.T) {
 	tempDir := t.TempDir()
 	logPath := filepath.Join(tempDir, "audit.log")
@@ -544,6 +671,122 @@ func TestLogBackupOperation(t *testing.T) {
 	}
 }
 
+func TestLogBackupSkipped(t *testing.T) {
+	tempDir := t.TempDir()
+	logPath := filepath.Join(tempDir, "audit.log")
+
+	logger := &Logger{
+		logPath:    logPath,
+		enabled:    true,
+		minLevel:   SeverityInfo,
+		maxLogSize: 10 * 1024 * 1024,
+		maxLogs:    5,
+	}
+
+	logger.LogBackupSkipped("add", "--no-backup")
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+
+	var loggedEvent AuditEvent
+	if err := json.Unmarshal(content[:len(content)-1], &loggedEvent); err != nil {
+		t.Fatalf("Failed to unmarshal logged event: %v", err)
+	}
+
+	if loggedEvent.EventType != EventBackupSkip {
+		t.Errorf("Expected event type %s, got %s", EventBackupSkip, loggedEvent.EventType)
+	}
+	if loggedEvent.Operation != "add" || loggedEvent.Severity != SeverityWarning {
+		t.Errorf("Unexpected event: %+v", loggedEvent)
+	}
+	if loggedEvent.Details["reason"] != "--no-backup" {
+		t.Errorf("Expected reason --no-backup, got %v", loggedEvent.Details["reason"])
+	}
+}
+
+func TestLogDNSFlush(t *testing.T) {
+	tempDir := t.TempDir()
+	logPath := filepath.Join(tempDir, "audit.log")
+
+	logger := &Logger{
+		logPath:    logPath,
+		enabled:    true,
+		minLevel:   SeverityInfo,
+		maxLogSize: 10 * 1024 * 1024,
+		maxLogs:    5,
+	}
+
+	logger.LogDNSFlush([]string{"resolvectl flush-caches"}, true, "")
+	logger.LogDNSFlush(nil, false, "no supported DNS cache flush command found")
+
+	events, err := logger.GetRecentEvents(10)
+	if err != nil {
+		t.Fatalf("GetRecentEvents() error = %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+
+	var ok, failed AuditEvent
+	for _, event := range events {
+		if event.Success {
+			ok = event
+		} else {
+			failed = event
+		}
+	}
+	if ok.EventType != EventDNSFlush || ok.Severity != SeverityInfo || ok.Details["commands"] != "resolvectl flush-caches" {
+		t.Errorf("Unexpected successful flush event: %+v", ok)
+	}
+	if failed.Severity != SeverityWarning || failed.ErrorMsg == "" {
+		t.Errorf("Unexpected failed flush event: %+v", failed)
+	}
+}
+
+func TestLogWatchReconcile(t *testing.T) {
+	tempDir := t.TempDir()
+	logPath := filepath.Join(tempDir, "audit.log")
+
+	logger := &Logger{
+		logPath:    logPath,
+		enabled:    true,
+		minLevel:   SeverityInfo,
+		maxLogSize: 10 * 1024 * 1024,
+		maxLogs:    5,
+	}
+
+	logger.LogWatchReconcile(nil, false, "")
+	logger.LogWatchReconcile([]string{"development", "blocked"}, true, "")
+	logger.LogWatchReconcile([]string{"development"}, false, "permission denied")
+
+	events, err := logger.GetRecentEvents(10)
+	if err != nil {
+		t.Fatalf("GetRecentEvents() error = %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(events))
+	}
+
+	severities := make(map[Severity]AuditEvent)
+	for _, event := range events {
+		if event.EventType != EventWatchReconcile {
+			t.Errorf("EventType = %s, want %s", event.EventType, EventWatchReconcile)
+		}
+		severities[event.Severity] = event
+	}
+	if restored := severities[SeverityWarning]; restored.Details["missing_categories"] != "development, blocked" || restored.Details["restored"] != true {
+		t.Errorf("Unexpected restore event: %+v", restored)
+	}
+	if failed := severities[SeverityError]; failed.Success || failed.ErrorMsg == "" {
+		t.Errorf("Unexpected failed event: %+v", failed)
+	}
+	if _, ok := severities[SeverityInfo]; !ok {
+		t.Error("A change with nothing missing should be logged at info level")
+	}
+}
+
 func TestGetRecentEvents(t *testing.T) {
 	tempDir := t.TempDir()
 	logPath := filepath.Join(tempDir, "audit.log")
@@ -632,14 +875,24 @@ func TestGetRecentEventsWithMalformedEntries(t *testing.T) {
 		},
 	}
 
-	for _, event := range validEvents {
+	for i, event := range validEvents {
 		err := logger.Log(event)
 		if err != nil {
 			t.Fatalf("Failed to log valid event: %v", err)
 		}
+
+		// A malformed line between valid ones must not hide the events after it
+		if i == 0 {
+			file, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0600)
+			if err != nil {
+				t.Fatalf("Failed to open log: %v", err)
+			}
+			_, _ = file.WriteString("{\"event_type\": \"hosts_add\", truncated\n")
+			_ = file.Close()
+		}
 	}
 
-	// Test that GetRecentEvents works with valid entries
+	// Test that GetRecentEvents skips the malformed entry
 	events, err := logger.GetRecentEvents(10)
 	if err != nil {
 		t.Fatalf("Failed to get events: %v", err)
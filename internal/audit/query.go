@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseSeverity validates a severity name such as "critical"
+func ParseSeverity(s string) (Severity, error) {
+	severity := Severity(strings.ToLower(s))
+	if _, ok := severityRank[severity]; !ok {
+		return "", fmt.Errorf("invalid severity %q (must be info, warning, error, or critical)", s)
+	}
+	return severity, nil
+}
+
+// EventFilter selects events for QueryEvents. Zero fields match every event.
+type EventFilter struct {
+	// MinSeverity keeps events at or above this severity
+	MinSeverity Severity
+	EventType   EventType
+	// Since and Until bound the event timestamp, inclusive
+	Since time.Time
+	Until time.Time
+	// Success, when set, keeps only successful or only failed events
+	Success *bool
+}
+
+// Matches reports whether event passes every condition of the filter
+func (f EventFilter) Matches(event AuditEvent) bool {
+	if f.MinSeverity != "" && severityRank[event.Severity] < severityRank[f.MinSeverity] {
+		return false
+	}
+	if f.EventType != "" && event.EventType != f.EventType {
+		return false
+	}
+	if !f.Since.IsZero() && event.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && event.Timestamp.After(f.Until) {
+		return false
+	}
+	if f.Success != nil && event.Success != *f.Success {
+		return false
+	}
+	return true
+}
+
+// QueryEvents returns the events matching filter from the current log and
+// every rotated log, oldest first
+func (l *Logger) QueryEvents(filter EventFilter) ([]AuditEvent, error) {
+	files, err := l.logFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	events := []AuditEvent{}
+	for _, file := range files {
+		if err := readEvents(file, func(event AuditEvent) {
+			if filter.Matches(event) {
+				events = append(events, event)
+			}
+		}); err != nil {
+			return events, err
+		}
+	}
+	return events, nil
+}
+
+// logFiles lists the rotated logs from oldest (highest number) to newest,
+// followed by the current log. Rotated logs may or may not be compressed.
+func (l *Logger) logFiles() ([]string, error) {
+	base := filepath.Base(l.logPath)
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(l.logPath), base+".*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+
+	type rotated struct {
+		path string
+		num  int
+	}
+	var logs []rotated
+	for _, match := range matches {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(match), base+"."), ".gz")
+		if num, err := strconv.Atoi(suffix); err == nil && num > 0 {
+			logs = append(logs, rotated{match, num})
+		}
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].num > logs[j].num })
+
+	files := make([]string, 0, len(logs)+1)
+	for _, log := range logs {
+		files = append(files, log.path)
+	}
+	return append(files, l.logPath), nil
+}
+
+// readEvents calls fn for each well-formed event in the log at path,
+// decompressing .gz logs. A missing log has no events.
+func readEvents(path string, fn func(AuditEvent)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to decompress %s: %w", filepath.Base(path), err)
+		}
+		defer func() { _ = gzipReader.Close() }()
+		r = gzipReader
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue // Skip malformed entries
+		}
+		fn(event)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
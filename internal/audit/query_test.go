@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQueryEventsAcrossRotatedLogs(t *testing.T) {
+	logger := &Logger{
+		logPath:    filepath.Join(t.TempDir(), "audit.log"),
+		enabled:    true,
+		minLevel:   SeverityInfo,
+		maxLogSize: 10 * 1024 * 1024,
+		maxLogs:    5,
+	}
+
+	base := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	logged := []AuditEvent{
+		{Timestamp: base, EventType: EventSecurityViol, Severity: SeverityCritical, Operation: "path_validation"},
+		{Timestamp: base.Add(time.Hour), EventType: EventHostsAdd, Severity: SeverityInfo, Operation: "add", Success: true},
+		{Timestamp: base.Add(2 * time.Hour), EventType: EventValidationFail, Severity: SeverityWarning, Operation: "ip"},
+		{Timestamp: base.Add(48 * time.Hour), EventType: EventSecurityViol, Severity: SeverityCritical, Operation: "import"},
+	}
+
+	// Rotate after the first and third events so they end up in .2.gz and .1.gz
+	for i, event := range logged {
+		if err := logger.Log(event); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+		if i == 0 || i == 2 {
+			if err := logger.rotateLog(); err != nil {
+				t.Fatalf("rotateLog() error = %v", err)
+			}
+		}
+	}
+
+	all, err := logger.QueryEvents(EventFilter{})
+	if err != nil {
+		t.Fatalf("QueryEvents() error = %v", err)
+	}
+	if len(all) != len(logged) {
+		t.Fatalf("Expected %d events across rotated logs, got %d", len(logged), len(all))
+	}
+	for i := range all {
+		if all[i].Operation != logged[i].Operation {
+			t.Errorf("Event %d = %s, want %s (chronological order)", i, all[i].Operation, logged[i].Operation)
+		}
+	}
+
+	failed := false
+	tests := []struct {
+		name     string
+		filter   EventFilter
+		expected []string
+	}{
+		{"critical", EventFilter{MinSeverity: SeverityCritical}, []string{"path_validation", "import"}},
+		{"warning and above", EventFilter{MinSeverity: SeverityWarning}, []string{"path_validation", "ip", "import"}},
+		{"event type", EventFilter{EventType: EventHostsAdd}, []string{"add"}},
+		{"time range", EventFilter{Since: base.Add(time.Hour), Until: base.Add(2 * time.Hour)}, []string{"add", "ip"}},
+		{"failed only", EventFilter{Success: &failed, EventType: EventSecurityViol, Since: base.Add(24 * time.Hour)}, []string{"import"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := logger.QueryEvents(tt.filter)
+			if err != nil {
+				t.Fatalf("QueryEvents() error = %v", err)
+			}
+			var got []string
+			for _, event := range events {
+				got = append(got, event.Operation)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("QueryEvents() = %v, want %v", got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("QueryEvents()[%d] = %s, want %s", i, got[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	if got, err := ParseSeverity("Critical"); err != nil || got != SeverityCritical {
+		t.Errorf("ParseSeverity(Critical) = %v, %v", got, err)
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Error("Expected error for unknown severity")
+	}
+}
@@ -1,30 +1,69 @@
 package backup
 
 import (
+	"bytes"
 	"compress/gzip"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/brandonhon/hosts-manager/internal/config"
 	"github.com/brandonhon/hosts-manager/pkg/platform"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 type Manager struct {
 	config   *config.Config
 	platform *platform.Platform
+	keys     map[string][]byte // derived encryption keys by passphrase and salt
 }
 
+const backupTimestampFormat = "2006-01-02T15-04-05"
+
+// hashSuffix marks the sidecar file recording a backup's hash at creation
+const hashSuffix = ".sha256"
+
+// ErrNoStoredHash is returned when verifying a backup that has no recorded
+// hash, such as one taken before hashes were recorded
+var ErrNoStoredHash = errors.New("no stored hash")
+
+// Compression suffixes, following the timestamp and any note
+const (
+	gzipSuffix = ".gz"
+	zstdSuffix = ".zst"
+)
+
+// autoBackupMarker distinguishes backups taken automatically before a
+// managed change (hosts.backup.auto.<timestamp>) from manual ones
+const autoBackupMarker = "auto."
+
+// noteSeparator joins a backup's timestamp to its note, as in
+// hosts.backup.<timestamp>_<note>
+const noteSeparator = "_"
+
+// maxNoteLength caps the note part of a backup filename
+const maxNoteLength = 64
+
+// noteUnsafeChars matches runs of characters not allowed in a backup note
+var noteUnsafeChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
 type BackupInfo struct {
 	Timestamp time.Time `json:"timestamp"`
 	FilePath  string    `json:"file_path"`
 	Hash      string    `json:"hash"`
 	Size      int64     `json:"size"`
+	Auto      bool      `json:"auto"`
+	// Note is the label given when the backup was created, if any
+	Note string `json:"note,omitempty"`
 }
 
 func NewManager(cfg *config.Config) *Manager {
@@ -34,37 +73,92 @@ func NewManager(cfg *config.Config) *Manager {
 	}
 }
 
-func (m *Manager) CreateBackup() (string, error) {
+// CreateBackup creates a manual backup. A non-empty note labels the backup;
+// it is sanitized into the filename and reported by ListBackups.
+func (m *Manager) CreateBackup(note string) (string, error) {
+	return m.createBackup("", note)
+}
+
+// CreateAutoBackup creates a backup marked as taken automatically before a
+// managed change, so that Rollback can find it
+func (m *Manager) CreateAutoBackup() (string, error) {
+	return m.createBackup(autoBackupMarker, "")
+}
+
+func (m *Manager) createBackup(marker, note string) (string, error) {
 	hostsPath := m.platform.GetHostsFilePath()
 
+	if note != "" {
+		sanitized := sanitizeNote(note)
+		if sanitized == "" {
+			return "", fmt.Errorf("backup note %q has no letters or digits", note)
+		}
+		note = noteSeparator + sanitized
+	}
+
 	if _, err := os.Stat(hostsPath); os.IsNotExist(err) {
 		return "", fmt.Errorf("hosts file does not exist: %s", hostsPath)
 	}
 
-	backupDir := m.config.Backup.Directory
+	now := time.Now()
+	backupDir := filepath.Join(m.config.Backup.Directory, m.layoutSubdir(now))
 	if err := os.MkdirAll(backupDir, 0700); err != nil {
 		return "", fmt.Errorf("failed to create backup directory: %w", err)
 	}
 
-	timestamp := time.Now().Format("2006-01-02T15-04-05")
-	backupName := fmt.Sprintf("hosts.backup.%s", timestamp)
+	backupName := fmt.Sprintf("hosts.backup.%s%s%s", marker, now.Format(backupTimestampFormat), note)
 
-	if m.config.Backup.CompressionType == "gzip" {
-		backupName += ".gz"
+	compression := m.compressionSuffix()
+	backupName += compression
+	if m.encryptionEnabled() {
+		backupName += encryptedSuffix
 	}
 
 	backupPath := filepath.Join(backupDir, backupName)
 
-	if err := m.copyFile(hostsPath, backupPath, m.config.Backup.CompressionType == "gzip"); err != nil {
+	if err := m.copyFile(hostsPath, backupPath, compression != ""); err != nil {
 		return "", fmt.Errorf("failed to create backup: %w", err)
 	}
+	if err := m.recordHash(backupPath); err != nil {
+		return "", fmt.Errorf("failed to record backup hash: %w", err)
+	}
 
 	_ = m.cleanupOldBackups()
 
 	return backupPath, nil
 }
 
+// sanitizeNote turns a backup note into a filename-safe label: runs of
+// characters other than letters, digits, '-' and '_' become a single '-',
+// and the result is trimmed and capped at 64 characters
+func sanitizeNote(note string) string {
+	note = noteUnsafeChars.ReplaceAllString(note, "-")
+	if len(note) > maxNoteLength {
+		note = note[:maxNoteLength]
+	}
+	return strings.Trim(note, "-_")
+}
+
+// compressionSuffix returns the extension for the configured compression type
+func (m *Manager) compressionSuffix() string {
+	switch m.config.Backup.CompressionType {
+	case "gzip":
+		return gzipSuffix
+	case "zstd":
+		return zstdSuffix
+	default:
+		return ""
+	}
+}
+
+// copyFile copies src to dst, compressing when compress is set: with zstd
+// when dst ends in .zst (before any .enc), gzip otherwise. A dst ending in
+// .enc is encrypted with the configured passphrase.
 func (m *Manager) copyFile(src, dst string, compress bool) error {
+	if strings.HasSuffix(dst, encryptedSuffix) {
+		return m.copyFileEncrypted(src, dst, compress)
+	}
+
 	srcFile, err := os.Open(src)
 	if err != nil {
 		return err
@@ -77,15 +171,86 @@ func (m *Manager) copyFile(src, dst string, compress bool) error {
 	}
 	defer func() { _ = dstFile.Close() }()
 
-	if compress {
-		gzipWriter := gzip.NewWriter(dstFile)
-		defer func() { _ = gzipWriter.Close() }()
-		_, err = io.Copy(gzipWriter, srcFile)
-	} else {
+	if !compress {
 		_, err = io.Copy(dstFile, srcFile)
+		return err
 	}
 
-	return err
+	writer, err := m.newCompressWriter(dstFile, dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(writer, srcFile); err != nil {
+		_ = writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+func (m *Manager) copyFileEncrypted(src, dst string, compress bool) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	if compress {
+		var buf bytes.Buffer
+		writer, err := m.newCompressWriter(&buf, dst)
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(data); err != nil {
+			return err
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	}
+
+	encrypted, err := m.encrypt(data)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, encrypted, 0600)
+}
+
+// newCompressWriter returns a writer compressing into w with the codec for
+// path's extension, at the configured compression level
+func (m *Manager) newCompressWriter(w io.Writer, path string) (io.WriteCloser, error) {
+	if isZstdBackup(path) {
+		return m.newZstdWriter(w)
+	}
+	return m.newGzipWriter(w)
+}
+
+// newGzipWriter returns a gzip writer at the configured compression level
+func (m *Manager) newGzipWriter(w io.Writer) (*gzip.Writer, error) {
+	level := m.config.Backup.CompressionLevel
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzip.NewWriterLevel(w, level)
+}
+
+// newZstdWriter returns a zstd writer at the configured compression level.
+// The encoder has four speeds, so the 1 to 9 scale is spread across them:
+// 1-2 fastest, 3-5 default, 6-8 better compression, 9 best.
+func (m *Manager) newZstdWriter(w io.Writer) (*zstd.Encoder, error) {
+	var level zstd.EncoderLevel
+	switch l := m.config.Backup.CompressionLevel; {
+	case l == 0:
+		level = zstd.SpeedDefault
+	case l <= 2:
+		level = zstd.SpeedFastest
+	case l <= 5:
+		level = zstd.SpeedDefault
+	case l <= 8:
+		level = zstd.SpeedBetterCompression
+	default:
+		level = zstd.SpeedBestCompression
+	}
+	return zstd.NewWriter(w, zstd.WithEncoderLevel(level))
 }
 
 func (m *Manager) RestoreBackup(backupPath string) error {
@@ -93,16 +258,19 @@ func (m *Manager) RestoreBackup(backupPath string) error {
 		return fmt.Errorf("backup file does not exist: %s", backupPath)
 	}
 
+	// Fail on an unreadable backup (e.g. a wrong passphrase) before touching anything
+	if _, err := m.ReadBackup(backupPath); err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+
 	hostsPath := m.platform.GetHostsFilePath()
 
-	currentBackupPath, err := m.CreateBackup()
+	currentBackupPath, err := m.CreateBackup("")
 	if err != nil {
 		return fmt.Errorf("failed to create current backup before restore: %w", err)
 	}
 
-	isCompressed := strings.HasSuffix(backupPath, ".gz")
-
-	if err := m.restoreFile(backupPath, hostsPath, isCompressed); err != nil {
+	if err := m.restoreFile(backupPath, hostsPath, isCompressedBackup(backupPath)); err != nil {
 		return fmt.Errorf("failed to restore backup: %w", err)
 	}
 
@@ -110,12 +278,122 @@ func (m *Manager) RestoreBackup(backupPath string) error {
 	return nil
 }
 
+// RestoreBackupTo writes the decompressed, decrypted contents of a backup to
+// dst instead of the live hosts file, which is left untouched
+func (m *Manager) RestoreBackupTo(backupPath, dst string) error {
+	if _, err := os.Stat(backupPath); os.IsNotExist(err) {
+		return fmt.Errorf("backup file does not exist: %s", backupPath)
+	}
+
+	data, err := m.ReadBackup(backupPath)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	if err := os.WriteFile(dst, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dst, err)
+	}
+	return nil
+}
+
+// LatestAutoBackup returns the most recent backup taken automatically before a managed change
+func (m *Manager) LatestAutoBackup() (BackupInfo, error) {
+	return m.LatestAutoBackupFiltered(Filter{})
+}
+
+// LatestAutoBackupFiltered returns the most recent automatic backup taken
+// inside filter's time window
+func (m *Manager) LatestAutoBackupFiltered(filter Filter) (BackupInfo, error) {
+	backups, err := m.ListBackupsFiltered(filter)
+	if err != nil {
+		return BackupInfo{}, err
+	}
+
+	for _, backup := range backups {
+		if backup.Auto {
+			return backup, nil
+		}
+	}
+
+	return BackupInfo{}, fmt.Errorf("no automatic backups found to roll back to")
+}
+
+// ReadBackup returns the decrypted, decompressed contents of a backup file
+func (m *Manager) ReadBackup(backupPath string) ([]byte, error) {
+	return m.readPlaintext(backupPath, isCompressedBackup(backupPath))
+}
+
+// readPlaintext reads a backup file, decrypting it if it ends in .enc and
+// then decompressing it when decompress is set, with zstd for .zst files
+// and gzip otherwise
+func (m *Manager) readPlaintext(path string, decompress bool) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(path, encryptedSuffix) {
+		if data, err = m.decrypt(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if decompress && isZstdBackup(path) {
+		zstdReader, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zstdReader.Close()
+		return io.ReadAll(zstdReader)
+	}
+	if decompress {
+		gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = gzipReader.Close() }()
+		return io.ReadAll(gzipReader)
+	}
+
+	return data, nil
+}
+
+// isCompressedBackup reports whether a backup's content is gzip- or
+// zstd-compressed
+func isCompressedBackup(path string) bool {
+	path = strings.TrimSuffix(path, encryptedSuffix)
+	return strings.HasSuffix(path, gzipSuffix) || strings.HasSuffix(path, zstdSuffix)
+}
+
+// isZstdBackup reports whether a backup's content is zstd-compressed
+func isZstdBackup(path string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(path, encryptedSuffix), zstdSuffix)
+}
+
+// Rollback restores the given automatic backup and then removes it, so that
+// repeated rollbacks step further back through the managed changes
+func (m *Manager) Rollback(info BackupInfo) error {
+	if !info.Auto {
+		return fmt.Errorf("not an automatic backup: %s", info.FilePath)
+	}
+
+	if err := m.RestoreBackup(info.FilePath); err != nil {
+		return err
+	}
+
+	if err := m.deleteBackupFile(info.FilePath); err != nil {
+		return fmt.Errorf("rolled back but failed to remove used backup: %w", err)
+	}
+
+	return nil
+}
+
 func (m *Manager) restoreFile(src, dst string, decompress bool) error {
-	srcFile, err := os.Open(src)
+	// Read fully before truncating dst so a failed read leaves it intact
+	data, err := m.readPlaintext(src, decompress)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = srcFile.Close() }()
 
 	// Get the original destination file permissions to preserve them
 	var fileMode os.FileMode = 0644 // Default fallback
@@ -129,35 +407,76 @@ func (m *Manager) restoreFile(src, dst string, decompress bool) error {
 	}
 	defer func() { _ = dstFile.Close() }()
 
-	var reader io.Reader = srcFile
-	if decompress {
-		gzipReader, err := gzip.NewReader(srcFile)
-		if err != nil {
-			return err
-		}
-		defer func() { _ = gzipReader.Close() }()
-		reader = gzipReader
-	}
-
-	_, err = io.Copy(dstFile, reader)
+	_, err = dstFile.Write(data)
 	return err
 }
 
 func (m *Manager) ListBackups() ([]BackupInfo, error) {
+	return m.listBackups(true)
+}
+
+// Filter selects backups by when they were taken. A zero bound is open.
+type Filter struct {
+	// Since keeps backups taken at or after this time
+	Since time.Time
+	// Before keeps backups taken strictly before this time
+	Before time.Time
+}
+
+// Matches reports whether info was taken inside the filter's time window
+func (f Filter) Matches(info BackupInfo) bool {
+	if !f.Since.IsZero() && info.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Before.IsZero() && !info.Timestamp.Before(f.Before) {
+		return false
+	}
+	return true
+}
+
+// ListBackupsFiltered lists the backups matching filter, newest first
+func (m *Manager) ListBackupsFiltered(filter Filter) ([]BackupInfo, error) {
+	backups, err := m.ListBackups()
+	if err != nil {
+		return nil, err
+	}
+
+	matching := backups[:0]
+	for _, info := range backups {
+		if filter.Matches(info) {
+			matching = append(matching, info)
+		}
+	}
+	return matching, nil
+}
+
+// listBackups lists backups newest first. Hashing is skipped when withHash
+// is false, which avoids decrypting every encrypted backup during cleanup.
+func (m *Manager) listBackups(withHash bool) ([]BackupInfo, error) {
 	backupDir := m.config.Backup.Directory
 
 	if _, err := os.Stat(backupDir); os.IsNotExist(err) {
 		return []BackupInfo{}, nil
 	}
 
-	files, err := filepath.Glob(filepath.Join(backupDir, "hosts.backup.*"))
+	// Walk the whole tree so backups made under a previous layout are still found
+	var files []string
+	err := filepath.WalkDir(backupDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasPrefix(d.Name(), "hosts.backup.") && !strings.HasSuffix(d.Name(), hashSuffix) {
+			files = append(files, path)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list backup files: %w", err)
 	}
 
 	var backups []BackupInfo
 	for _, file := range files {
-		info, err := m.getBackupInfo(file)
+		info, err := m.backupInfo(file, withHash)
 		if err != nil {
 			continue
 		}
@@ -172,26 +491,40 @@ func (m *Manager) ListBackups() ([]BackupInfo, error) {
 }
 
 func (m *Manager) getBackupInfo(filePath string) (BackupInfo, error) {
+	return m.backupInfo(filePath, true)
+}
+
+func (m *Manager) backupInfo(filePath string, withHash bool) (BackupInfo, error) {
 	stat, err := os.Stat(filePath)
 	if err != nil {
 		return BackupInfo{}, err
 	}
 
-	hash, err := m.calculateFileHash(filePath)
-	if err != nil {
-		return BackupInfo{}, err
+	var hash string
+	if withHash {
+		hash, err = m.calculateFileHash(filePath)
+		// Encrypted backups stay listed, without a hash, when the passphrase is unavailable
+		if err != nil && !strings.HasSuffix(filePath, encryptedSuffix) {
+			return BackupInfo{}, err
+		}
 	}
 
-	filename := filepath.Base(filePath)
-	var timestampStr string
+	filename := strings.TrimSuffix(filepath.Base(filePath), encryptedSuffix)
+	timestampStr := strings.TrimPrefix(filename, "hosts.backup.")
+	for _, suffix := range []string{gzipSuffix, zstdSuffix} {
+		timestampStr = strings.TrimSuffix(timestampStr, suffix)
+	}
+
+	auto := strings.HasPrefix(timestampStr, autoBackupMarker)
+	timestampStr = strings.TrimPrefix(timestampStr, autoBackupMarker)
 
-	if strings.HasSuffix(filename, ".gz") {
-		timestampStr = strings.TrimSuffix(strings.TrimPrefix(filename, "hosts.backup."), ".gz")
-	} else {
-		timestampStr = strings.TrimPrefix(filename, "hosts.backup.")
+	var note string
+	if len(timestampStr) > len(backupTimestampFormat) && strings.HasPrefix(timestampStr[len(backupTimestampFormat):], noteSeparator) {
+		note = timestampStr[len(backupTimestampFormat)+len(noteSeparator):]
+		timestampStr = timestampStr[:len(backupTimestampFormat)]
 	}
 
-	timestamp, err := time.Parse("2006-01-02T15-04-05", timestampStr)
+	timestamp, err := time.Parse(backupTimestampFormat, timestampStr)
 	if err != nil {
 		timestamp = stat.ModTime()
 	}
@@ -201,82 +534,154 @@ func (m *Manager) getBackupInfo(filePath string) (BackupInfo, error) {
 		FilePath:  filePath,
 		Hash:      hash,
 		Size:      stat.Size(),
+		Auto:      auto,
+		Note:      note,
 	}, nil
 }
 
-func (m *Manager) calculateFileHash(filePath string) (string, error) {
-	file, err := os.Open(filePath)
+// hashFilePath returns the sidecar file holding a backup's recorded hash
+func hashFilePath(backupPath string) string {
+	return backupPath + hashSuffix
+}
+
+// recordHash stores the hash of a backup's plaintext alongside it so that
+// later verification has a value from creation time to compare against
+func (m *Manager) recordHash(backupPath string) error {
+	hash, err := m.calculateFileHash(backupPath)
 	if err != nil {
-		return "", err
+		return err
 	}
-	defer func() { _ = file.Close() }()
+	return os.WriteFile(hashFilePath(backupPath), []byte(hash+"\n"), 0600)
+}
 
-	var reader io.Reader = file
-	if strings.HasSuffix(filePath, ".gz") {
-		gzipReader, err := gzip.NewReader(file)
-		if err != nil {
-			return "", err
+// storedHash returns the hash recorded when a backup was created
+func (m *Manager) storedHash(backupPath string) (string, error) {
+	data, err := os.ReadFile(hashFilePath(backupPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			if _, statErr := os.Stat(backupPath); statErr != nil {
+				return "", fmt.Errorf("backup file does not exist: %s", backupPath)
+			}
+			return "", fmt.Errorf("%w for %s", ErrNoStoredHash, backupPath)
 		}
-		defer func() { _ = gzipReader.Close() }()
-		reader = gzipReader
+		return "", fmt.Errorf("failed to read backup hash: %w", err)
 	}
+	return strings.TrimSpace(string(data)), nil
+}
 
-	hasher := sha256.New()
-	if _, err := io.Copy(hasher, reader); err != nil {
+// calculateFileHash hashes a backup's plaintext, so compressed and encrypted
+// backups compare equal to the original content
+func (m *Manager) calculateFileHash(filePath string) (string, error) {
+	data, err := m.ReadBackup(filePath)
+	if err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf("%x", hasher.Sum(nil)), nil
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
 }
 
-func (m *Manager) cleanupOldBackups() error {
-	backups, err := m.ListBackups()
+// PruneReason explains why a backup is selected for pruning
+type PruneReason string
+
+const (
+	PruneExceedsMax       PruneReason = "exceeds max backups"
+	PruneExceedsRetention PruneReason = "exceeds retention period"
+)
+
+// PruneCandidate is a backup selected for deletion by the retention policy
+type PruneCandidate struct {
+	BackupInfo
+	Reason PruneReason
+}
+
+// PruneBackups applies the retention policy: backups beyond the newest
+// MaxBackups, and backups older than RetentionDays, are deleted. A backup
+// matching both is reported as exceeding the max. With dryRun set nothing is
+// deleted. It returns the backups that would be or were deleted, newest first.
+func (m *Manager) PruneBackups(dryRun bool) ([]PruneCandidate, error) {
+	backups, err := m.listBackups(false)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	maxBackups := m.config.Backup.MaxBackups
-	retentionDays := m.config.Backup.RetentionDays
-	cutoffTime := time.Now().AddDate(0, 0, -retentionDays)
+	cutoffTime := time.Now().AddDate(0, 0, -m.config.Backup.RetentionDays)
+
+	var candidates []PruneCandidate
+	for i, backup := range backups {
+		switch {
+		case i >= maxBackups:
+			candidates = append(candidates, PruneCandidate{BackupInfo: backup, Reason: PruneExceedsMax})
+		case backup.Timestamp.Before(cutoffTime):
+			candidates = append(candidates, PruneCandidate{BackupInfo: backup, Reason: PruneExceedsRetention})
+		}
+	}
 
-	var toDelete []string
+	if dryRun {
+		return candidates, nil
+	}
 
-	if len(backups) > maxBackups {
-		for i := maxBackups; i < len(backups); i++ {
-			toDelete = append(toDelete, backups[i].FilePath)
+	for _, candidate := range candidates {
+		if err := m.deleteBackupFile(candidate.FilePath); err != nil {
+			fmt.Printf("Warning: failed to securely remove old backup %s: %v\n", candidate.FilePath, err)
 		}
 	}
 
-	for _, backup := range backups {
-		if backup.Timestamp.Before(cutoffTime) {
-			found := false
-			for _, path := range toDelete {
-				if path == backup.FilePath {
-					found = true
-					break
-				}
-			}
-			if !found {
-				toDelete = append(toDelete, backup.FilePath)
-			}
+	m.removeEmptyDirs()
+
+	return candidates, nil
+}
+
+func (m *Manager) cleanupOldBackups() error {
+	_, err := m.PruneBackups(false)
+	return err
+}
+
+// removeEmptyDirs removes layout subdirectories left empty after cleanup.
+// The backup directory itself is kept.
+func (m *Manager) removeEmptyDirs() {
+	backupDir := m.config.Backup.Directory
+
+	var dirs []string
+	_ = filepath.WalkDir(backupDir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && path != backupDir {
+			dirs = append(dirs, path)
 		}
-	}
+		return nil
+	})
 
-	for _, filePath := range toDelete {
-		if err := m.secureDelete(filePath); err != nil {
-			fmt.Printf("Warning: failed to securely remove old backup %s: %v\n", filePath, err)
+	// Deepest directories first so parents are empty by the time they are visited
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if entries, err := os.ReadDir(dirs[i]); err == nil && len(entries) == 0 {
+			_ = os.Remove(dirs[i])
 		}
 	}
+}
 
-	return nil
+// layoutSubdir returns the subdirectory a backup taken at t belongs in
+// according to the configured directory layout
+func (m *Manager) layoutSubdir(t time.Time) string {
+	switch m.config.Backup.DirectoryLayout {
+	case "daily":
+		return t.Format("2006-01-02")
+	case "monthly":
+		return t.Format("2006-01")
+	default:
+		return ""
+	}
 }
 
 func (m *Manager) GetBackupPath(timestamp string) string {
-	backupName := fmt.Sprintf("hosts.backup.%s", timestamp)
-	if m.config.Backup.CompressionType == "gzip" {
-		backupName += ".gz"
+	backupName := fmt.Sprintf("hosts.backup.%s", timestamp) + m.compressionSuffix()
+	if m.encryptionEnabled() {
+		backupName += encryptedSuffix
 	}
-	return filepath.Join(m.config.Backup.Directory, backupName)
+
+	backupDir := m.config.Backup.Directory
+	if t, err := time.Parse(backupTimestampFormat, timestamp); err == nil {
+		backupDir = filepath.Join(backupDir, m.layoutSubdir(t))
+	}
+	return filepath.Join(backupDir, backupName)
 }
 
 func (m *Manager) DeleteBackup(filePath string) error {
@@ -284,7 +689,18 @@ func (m *Manager) DeleteBackup(filePath string) error {
 		return fmt.Errorf("backup file does not exist: %s", filePath)
 	}
 
-	return m.secureDelete(filePath)
+	return m.deleteBackupFile(filePath)
+}
+
+// deleteBackupFile securely deletes a backup along with its stored hash
+func (m *Manager) deleteBackupFile(filePath string) error {
+	if err := m.secureDelete(filePath); err != nil {
+		return err
+	}
+	if err := os.Remove(hashFilePath(filePath)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove backup hash: %w", err)
+	}
+	return nil
 }
 
 // secureDelete overwrites file content before deletion for security
@@ -344,12 +760,14 @@ func min(a, b int) int {
 	return b
 }
 
-// VerifyBackupIntegrity verifies the integrity of a backup file
+// VerifyBackupIntegrity compares a backup's current content hash against the
+// hash recorded when it was created. Backups without a recorded hash fail
+// with ErrNoStoredHash.
 func (m *Manager) VerifyBackupIntegrity(filePath string) error {
 	// Get expected hash from our records
-	backupInfo, err := m.getBackupInfo(filePath)
+	storedHash, err := m.storedHash(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to get backup info: %w", err)
+		return err
 	}
 
 	// Calculate current hash
@@ -359,7 +777,7 @@ func (m *Manager) VerifyBackupIntegrity(filePath string) error {
 	}
 
 	// Compare hashes
-	if currentHash != backupInfo.Hash {
+	if currentHash != storedHash {
 		return fmt.Errorf("backup integrity check failed: hash mismatch for %s", filePath)
 	}
 
@@ -369,7 +787,7 @@ func (m *Manager) VerifyBackupIntegrity(filePath string) error {
 // CreateSecureBackup creates a backup with enhanced security features
 func (m *Manager) CreateSecureBackup() (string, error) {
 	// First create the backup normally
-	backupPath, err := m.CreateBackup()
+	backupPath, err := m.CreateBackup("")
 	if err != nil {
 		return "", err
 	}
@@ -377,7 +795,7 @@ func (m *Manager) CreateSecureBackup() (string, error) {
 	// Verify the backup integrity immediately after creation
 	if err := m.VerifyBackupIntegrity(backupPath); err != nil {
 		// If verification fails, securely delete the bad backup
-		_ = m.secureDelete(backupPath)
+		_ = m.deleteBackupFile(backupPath)
 		return "", fmt.Errorf("backup verification failed: %w", err)
 	}
 
@@ -2,16 +2,22 @@ package backup
 
 import (
 	"compress/gzip"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/brandonhon/hosts-manager/internal/config"
+	"github.com/brandonhon/hosts-manager/internal/hosts"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 func createTestConfig(tempDir string) *config.Config {
@@ -109,7 +115,10 @@ func (m *Manager) copyFileToBackup(srcPath, backupDir string, compress bool) (st
 	}
 
 	backupPath := filepath.Join(backupDir, backupName)
-	return backupPath, m.copyFile(srcPath, backupPath, compress)
+	if err := m.copyFile(srcPath, backupPath, compress); err != nil {
+		return "", err
+	}
+	return backupPath, m.recordHash(backupPath)
 }
 
 func TestCreateBackupWithCompression(t *testing.T) {
@@ -230,6 +239,177 @@ func TestCopyFile(t *testing.T) {
 	}
 }
 
+func TestCopyFileCompressionLevel(t *testing.T) {
+	tempDir := t.TempDir()
+	srcPath := filepath.Join(tempDir, "hosts")
+	var content strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&content, "0.0.0.0 ads%d.tracker-%d.example.com\n", i, i*7919%1000)
+	}
+	if err := os.WriteFile(srcPath, []byte(content.String()), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+
+	sizes := make(map[int]int64)
+	for _, level := range []int{1, 9} {
+		cfg := createTestConfigWithCompression(tempDir)
+		cfg.Backup.CompressionLevel = level
+		dstPath := filepath.Join(tempDir, fmt.Sprintf("level%d.gz", level))
+		if err := NewManager(cfg).copyFile(srcPath, dstPath, true); err != nil {
+			t.Fatalf("copyFile() at level %d error = %v", level, err)
+		}
+
+		data, err := NewManager(cfg).readPlaintext(dstPath, true)
+		if err != nil {
+			t.Fatalf("readPlaintext() at level %d error = %v", level, err)
+		}
+		if string(data) != content.String() {
+			t.Errorf("Level %d backup does not round-trip", level)
+		}
+		info, err := os.Stat(dstPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes[level] = info.Size()
+	}
+
+	if sizes[9] >= sizes[1] {
+		t.Errorf("Level 9 backup (%d bytes) should be smaller than level 1 (%d bytes)", sizes[9], sizes[1])
+	}
+}
+
+func TestZstdBackupRoundTrip(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := createTestConfig(tempDir)
+	cfg.Backup.CompressionType = "zstd"
+	manager := NewManager(cfg)
+
+	hostsPath := filepath.Join(tempDir, "hosts")
+	testContent := "127.0.0.1 localhost\n192.168.1.1 example.com\n"
+	if err := os.WriteFile(hostsPath, []byte(testContent), 0644); err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	manager.platform.HostsDir = hostsPath
+
+	backupPath, err := manager.CreateBackup("")
+	if err != nil {
+		t.Fatalf("CreateBackup() error = %v", err)
+	}
+	if !strings.HasSuffix(backupPath, ".zst") {
+		t.Errorf("zstd backup should have .zst extension, got %s", backupPath)
+	}
+
+	// The file is a zstd frame, readable by any zstd decoder
+	file, err := os.Open(backupPath)
+	if err != nil {
+		t.Fatalf("Failed to open compressed backup: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+	zstdReader, err := zstd.NewReader(file)
+	if err != nil {
+		t.Fatalf("Failed to create zstd reader: %v", err)
+	}
+	defer zstdReader.Close()
+	decompressedContent, err := io.ReadAll(zstdReader)
+	if err != nil {
+		t.Fatalf("Failed to read decompressed content: %v", err)
+	}
+	if string(decompressedContent) != testContent {
+		t.Errorf("Decompressed content = %q, want %q", decompressedContent, testContent)
+	}
+
+	// Hashes cover the plaintext, so they match the original file
+	hash, err := manager.calculateFileHash(backupPath)
+	if err != nil {
+		t.Fatalf("calculateFileHash() error = %v", err)
+	}
+	if expected := fmt.Sprintf("%x", sha256.Sum256([]byte(testContent))); hash != expected {
+		t.Errorf("calculateFileHash() = %s, want plaintext hash %s", hash, expected)
+	}
+	if err := manager.VerifyBackupIntegrity(backupPath); err != nil {
+		t.Errorf("VerifyBackupIntegrity() error = %v", err)
+	}
+
+	backups, err := manager.ListBackups()
+	if err != nil {
+		t.Fatalf("ListBackups() error = %v", err)
+	}
+	if len(backups) != 1 || backups[0].FilePath != backupPath || backups[0].Hash != hash {
+		t.Fatalf("Expected the zstd backup to be listed with its hash, got %+v", backups)
+	}
+	timestamp := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(backupPath), "hosts.backup."), ".zst")
+	if got := backups[0].Timestamp.Format(backupTimestampFormat); got != timestamp {
+		t.Errorf("Listed timestamp = %s, want %s", got, timestamp)
+	}
+	if path := manager.GetBackupPath(timestamp); path != backupPath {
+		t.Errorf("GetBackupPath() = %s, want %s", path, backupPath)
+	}
+
+	restoredPath := filepath.Join(tempDir, "restored")
+	if err := manager.restoreFile(backupPath, restoredPath, isCompressedBackup(backupPath)); err != nil {
+		t.Fatalf("restoreFile() error = %v", err)
+	}
+	restored, err := os.ReadFile(restoredPath)
+	if err != nil {
+		t.Fatalf("Failed to read restored file: %v", err)
+	}
+	if string(restored) != testContent {
+		t.Errorf("Restored content = %q, want %q", restored, testContent)
+	}
+
+	// Replacing the compressed content is caught against the recorded hash
+	tamperedPath := filepath.Join(tempDir, "tampered")
+	if err := os.WriteFile(tamperedPath, []byte("10.0.0.1 example.com\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.copyFile(tamperedPath, backupPath, true); err != nil {
+		t.Fatalf("copyFile() error = %v", err)
+	}
+	if err := manager.VerifyBackupIntegrity(backupPath); err == nil {
+		t.Error("Expected VerifyBackupIntegrity to fail after the content changed")
+	}
+}
+
+func TestZstdCompressionLevel(t *testing.T) {
+	tempDir := t.TempDir()
+	srcPath := filepath.Join(tempDir, "hosts")
+	var content strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&content, "0.0.0.0 ads%d.tracker-%d.example.com\n", i, i*7919%1000)
+	}
+	if err := os.WriteFile(srcPath, []byte(content.String()), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+
+	sizes := make(map[int]int64)
+	for _, level := range []int{1, 9} {
+		cfg := createTestConfig(tempDir)
+		cfg.Backup.CompressionType = "zstd"
+		cfg.Backup.CompressionLevel = level
+		dstPath := filepath.Join(tempDir, fmt.Sprintf("level%d.zst", level))
+		if err := NewManager(cfg).copyFile(srcPath, dstPath, true); err != nil {
+			t.Fatalf("copyFile() at level %d error = %v", level, err)
+		}
+
+		data, err := NewManager(cfg).readPlaintext(dstPath, true)
+		if err != nil {
+			t.Fatalf("readPlaintext() at level %d error = %v", level, err)
+		}
+		if string(data) != content.String() {
+			t.Errorf("Level %d backup does not round-trip", level)
+		}
+		info, err := os.Stat(dstPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes[level] = info.Size()
+	}
+
+	if sizes[9] >= sizes[1] {
+		t.Errorf("Level 9 backup (%d bytes) should be smaller than level 1 (%d bytes)", sizes[9], sizes[1])
+	}
+}
+
 func TestCopyFileErrors(t *testing.T) {
 	tempDir := t.TempDir()
 	cfg := createTestConfig(tempDir)
@@ -674,6 +854,180 @@ func TestGetBackupPath(t *testing.T) {
 	}
 }
 
+func TestGetBackupPathWithLayout(t *testing.T) {
+	tempDir := t.TempDir()
+	timestamp := "2023-12-01T10-30-00"
+
+	tests := []struct {
+		layout   string
+		expected string
+	}{
+		{"flat", "hosts.backup.2023-12-01T10-30-00"},
+		{"daily", filepath.Join("2023-12-01", "hosts.backup.2023-12-01T10-30-00")},
+		{"monthly", filepath.Join("2023-12", "hosts.backup.2023-12-01T10-30-00")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			cfg := createTestConfig(tempDir)
+			cfg.Backup.DirectoryLayout = tt.layout
+			manager := NewManager(cfg)
+
+			expectedPath := filepath.Join(cfg.Backup.Directory, tt.expected)
+			if path := manager.GetBackupPath(timestamp); path != expectedPath {
+				t.Errorf("Expected path %s, got %s", expectedPath, path)
+			}
+		})
+	}
+}
+
+func TestDailyLayoutListAndCleanup(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := createTestConfig(tempDir)
+	cfg.Backup.DirectoryLayout = "daily"
+	cfg.Backup.MaxBackups = 2
+	cfg.Backup.RetentionDays = 30
+
+	manager := NewManager(cfg)
+
+	now := time.Now()
+	backupTimes := []time.Time{
+		now.AddDate(0, 0, -2),
+		now.AddDate(0, 0, -1),
+		now.Add(-1 * time.Hour),
+	}
+
+	var paths []string
+	for i, backupTime := range backupTimes {
+		backupPath := manager.GetBackupPath(backupTime.Format("2006-01-02T15-04-05"))
+		if err := os.MkdirAll(filepath.Dir(backupPath), 0700); err != nil {
+			t.Fatalf("Failed to create layout directory: %v", err)
+		}
+		if err := os.WriteFile(backupPath, []byte(fmt.Sprintf("backup content %d", i)), 0600); err != nil {
+			t.Fatalf("Failed to create backup file: %v", err)
+		}
+		paths = append(paths, backupPath)
+	}
+
+	// A backup left over from a flat layout should still be listed
+	flatPath := filepath.Join(cfg.Backup.Directory, "hosts.backup."+now.Add(-2*time.Hour).Format("2006-01-02T15-04-05"))
+	if err := os.WriteFile(flatPath, []byte("flat backup"), 0600); err != nil {
+		t.Fatalf("Failed to create flat backup file: %v", err)
+	}
+
+	backups, err := manager.ListBackups()
+	if err != nil {
+		t.Fatalf("Failed to list backups: %v", err)
+	}
+	if len(backups) != 4 {
+		t.Fatalf("Expected 4 backups across subdirectories, got %d", len(backups))
+	}
+
+	if err := manager.cleanupOldBackups(); err != nil {
+		t.Fatalf("Failed to cleanup old backups: %v", err)
+	}
+
+	remaining, err := manager.ListBackups()
+	if err != nil {
+		t.Fatalf("Failed to list remaining backups: %v", err)
+	}
+	if len(remaining) != 2 {
+		t.Fatalf("Expected 2 backups after cleanup, got %d", len(remaining))
+	}
+	if remaining[0].FilePath != paths[2] || remaining[1].FilePath != flatPath {
+		t.Errorf("Unexpected backups kept: %s, %s", remaining[0].FilePath, remaining[1].FilePath)
+	}
+
+	// Subdirectories emptied by cleanup are removed
+	for _, removed := range paths[:2] {
+		if filepath.Dir(removed) == filepath.Dir(paths[2]) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Dir(removed)); !os.IsNotExist(err) {
+			t.Errorf("Expected empty directory %s to be removed", filepath.Dir(removed))
+		}
+	}
+}
+
+func TestRollbackAfterAdd(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := createTestConfigWithCompression(tempDir)
+	manager := NewManager(cfg)
+
+	hostsPath := filepath.Join(tempDir, "hosts")
+	original := "127.0.0.1 localhost\n192.168.1.10 api.dev\n"
+	if err := os.WriteFile(hostsPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	manager.platform.HostsDir = hostsPath
+
+	if _, err := manager.LatestAutoBackup(); err == nil {
+		t.Error("Expected error when no automatic backups exist")
+	}
+
+	// A manual backup must not be picked up by rollback
+	if _, err := manager.CreateBackup(""); err != nil {
+		t.Fatalf("Failed to create manual backup: %v", err)
+	}
+
+	// Simulate the add command: automatic backup, then write
+	if _, err := manager.CreateAutoBackup(); err != nil {
+		t.Fatalf("Failed to create automatic backup: %v", err)
+	}
+	hostsFile, err := hosts.NewParser(hostsPath).Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse hosts file: %v", err)
+	}
+	if err := hostsFile.AddEntry(hosts.Entry{IP: "192.168.1.20", Hostnames: []string{"new.dev"}, Enabled: true}); err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	if err := hostsFile.Write(hostsPath); err != nil {
+		t.Fatalf("Failed to write hosts file: %v", err)
+	}
+
+	latest, err := manager.LatestAutoBackup()
+	if err != nil {
+		t.Fatalf("LatestAutoBackup() error = %v", err)
+	}
+	if !latest.Auto {
+		t.Error("Expected latest automatic backup to be marked Auto")
+	}
+
+	if err := manager.Rollback(latest); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+
+	restored, err := os.ReadFile(hostsPath)
+	if err != nil {
+		t.Fatalf("Failed to read restored hosts file: %v", err)
+	}
+	if string(restored) != original {
+		t.Errorf("Expected hosts file to match pre-add state.\nExpected: %q\nGot: %q", original, string(restored))
+	}
+	if strings.Contains(string(restored), "new.dev") {
+		t.Error("Expected added entry to be gone after rollback")
+	}
+
+	// The used backup is consumed; only manual backups remain
+	if _, err := os.Stat(latest.FilePath); !os.IsNotExist(err) {
+		t.Error("Expected used automatic backup to be removed")
+	}
+	if _, err := manager.LatestAutoBackup(); err == nil {
+		t.Error("Expected no automatic backups after rollback")
+	}
+
+	backups, err := manager.ListBackups()
+	if err != nil {
+		t.Fatalf("Failed to list backups: %v", err)
+	}
+	if len(backups) == 0 {
+		t.Error("Expected manual backups to be kept")
+	}
+	if err := manager.Rollback(backups[0]); err == nil {
+		t.Error("Expected rollback to a manual backup to be refused")
+	}
+}
+
 func TestDeleteBackup(t *testing.T) {
 	tempDir := t.TempDir()
 	cfg := createTestConfig(tempDir)
@@ -767,9 +1121,15 @@ func TestVerifyBackupIntegrity(t *testing.T) {
 		t.Fatalf("Failed to create test backup: %v", err)
 	}
 
-	// Verify integrity (should always pass for valid files with current implementation)
-	// Note: The current implementation recalculates hash each time, so it will always "pass"
-	// This tests that the function works without errors for valid files
+	// Without a recorded hash there is nothing to verify against
+	err = manager.VerifyBackupIntegrity(backupPath)
+	if !errors.Is(err, ErrNoStoredHash) {
+		t.Fatalf("Expected ErrNoStoredHash before a hash is recorded, got %v", err)
+	}
+
+	if err := manager.recordHash(backupPath); err != nil {
+		t.Fatalf("Failed to record hash: %v", err)
+	}
 	err = manager.VerifyBackupIntegrity(backupPath)
 	if err != nil {
 		t.Fatalf("Integrity verification should pass for valid file: %v", err)
@@ -798,6 +1158,12 @@ func TestVerifyBackupIntegrity(t *testing.T) {
 		t.Error("Hash should be different after content change")
 	}
 
+	// Verification compares against the hash recorded before the corruption
+	err = manager.VerifyBackupIntegrity(backupPath)
+	if err == nil || !strings.Contains(err.Error(), "hash mismatch") {
+		t.Errorf("Integrity verification should fail for a corrupted file, got %v", err)
+	}
+
 	// Test with non-existent file
 	nonExistentPath := filepath.Join(tempDir, "nonexistent.backup")
 	err = manager.VerifyBackupIntegrity(nonExistentPath)
@@ -902,6 +1268,44 @@ func BenchmarkCreateCompressedBackup(b *testing.B) {
 	}
 }
 
+// BenchmarkCompressionLevels compares backup speed and size across gzip
+// levels; the size is reported as the bytes/backup metric
+func BenchmarkCompressionLevels(b *testing.B) {
+	tempDir := b.TempDir()
+	hostsPath := filepath.Join(tempDir, "hosts")
+	var content strings.Builder
+	for i := 0; i < 50000; i++ {
+		fmt.Fprintf(&content, "0.0.0.0 ads%d.tracker-%d.example.com\n", i, i*7919%1000)
+	}
+	if err := os.WriteFile(hostsPath, []byte(content.String()), 0644); err != nil {
+		b.Fatalf("Failed to create test hosts file: %v", err)
+	}
+
+	for _, level := range []int{1, 6, 9} {
+		b.Run(fmt.Sprintf("level-%d", level), func(b *testing.B) {
+			cfg := createTestConfigWithCompression(tempDir)
+			cfg.Backup.CompressionLevel = level
+			manager := NewManager(cfg)
+			backupPath := filepath.Join(tempDir, fmt.Sprintf("bench_level_%d.gz", level))
+
+			b.SetBytes(int64(content.Len()))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := manager.copyFile(hostsPath, backupPath, true); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+
+			info, err := os.Stat(backupPath)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportMetric(float64(info.Size()), "bytes/backup")
+		})
+	}
+}
+
 // BenchmarkCalculateFileHash benchmarks file hash calculation
 func BenchmarkCalculateFileHash(b *testing.B) {
 	tempDir := b.TempDir()
@@ -930,3 +1334,288 @@ func BenchmarkCalculateFileHash(b *testing.B) {
 		})
 	}
 }
+
+func writeTestBackups(t *testing.T, dir string, times []time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatalf("Failed to create backup directory: %v", err)
+	}
+	for i, backupTime := range times {
+		backupPath := filepath.Join(dir, "hosts.backup."+backupTime.Format(backupTimestampFormat))
+		if err := os.WriteFile(backupPath, []byte(fmt.Sprintf("backup content %d", i)), 0600); err != nil {
+			t.Fatalf("Failed to create backup file: %v", err)
+		}
+	}
+}
+
+func TestPruneBackupsMaxCount(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := createTestConfig(tempDir)
+	cfg.Backup.MaxBackups = 2
+	cfg.Backup.RetentionDays = 30
+
+	now := time.Now()
+	writeTestBackups(t, cfg.Backup.Directory, []time.Time{
+		now.Add(-3 * time.Hour),
+		now.Add(-2 * time.Hour),
+		now.Add(-1 * time.Hour),
+	})
+
+	manager := NewManager(cfg)
+	candidates, err := manager.PruneBackups(true)
+	if err != nil {
+		t.Fatalf("PruneBackups(true) error = %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].Reason != PruneExceedsMax {
+		t.Fatalf("Expected the oldest backup to exceed max, got %+v", candidates)
+	}
+	oldest := candidates[0].FilePath
+
+	// Dry run deletes nothing
+	if backups, _ := manager.ListBackups(); len(backups) != 3 {
+		t.Errorf("Expected dry run to keep all 3 backups, got %d", len(backups))
+	}
+
+	deleted, err := manager.PruneBackups(false)
+	if err != nil {
+		t.Fatalf("PruneBackups(false) error = %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].FilePath != oldest {
+		t.Errorf("Expected %s to be deleted, got %+v", oldest, deleted)
+	}
+	if _, err := os.Stat(oldest); !os.IsNotExist(err) {
+		t.Error("Expected oldest backup to be removed")
+	}
+}
+
+func TestPruneBackupsRetention(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := createTestConfig(tempDir)
+	cfg.Backup.MaxBackups = 10
+	cfg.Backup.RetentionDays = 7
+
+	now := time.Now()
+	writeTestBackups(t, cfg.Backup.Directory, []time.Time{
+		now.AddDate(0, 0, -10),
+		now.AddDate(0, 0, -8),
+		now.AddDate(0, 0, -1),
+	})
+
+	manager := NewManager(cfg)
+	candidates, err := manager.PruneBackups(true)
+	if err != nil {
+		t.Fatalf("PruneBackups(true) error = %v", err)
+	}
+	if len(candidates) != 2 {
+		t.Fatalf("Expected 2 backups past retention, got %+v", candidates)
+	}
+	for _, candidate := range candidates {
+		if candidate.Reason != PruneExceedsRetention {
+			t.Errorf("Expected %s to exceed retention, got %q", candidate.FilePath, candidate.Reason)
+		}
+	}
+
+	if _, err := manager.PruneBackups(false); err != nil {
+		t.Fatalf("PruneBackups(false) error = %v", err)
+	}
+	if backups, _ := manager.ListBackups(); len(backups) != 1 {
+		t.Errorf("Expected 1 backup within retention to remain, got %d", len(backups))
+	}
+}
+
+func TestBackupHashSidecar(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := createTestConfig(tempDir)
+	manager := NewManager(cfg)
+
+	hostsPath := filepath.Join(tempDir, "hosts")
+	if err := os.WriteFile(hostsPath, []byte("127.0.0.1 localhost\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	if err := os.MkdirAll(cfg.Backup.Directory, 0700); err != nil {
+		t.Fatalf("Failed to create backup directory: %v", err)
+	}
+
+	backupPath, err := manager.copyFileToBackup(hostsPath, cfg.Backup.Directory, false)
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+
+	// The sidecar is not listed as a backup of its own
+	backups, err := manager.ListBackups()
+	if err != nil {
+		t.Fatalf("ListBackups() error = %v", err)
+	}
+	if len(backups) != 1 || backups[0].FilePath != backupPath {
+		t.Fatalf("Expected only %s to be listed, got %+v", backupPath, backups)
+	}
+
+	if err := manager.DeleteBackup(backupPath); err != nil {
+		t.Fatalf("DeleteBackup() error = %v", err)
+	}
+	if _, err := os.Stat(hashFilePath(backupPath)); !os.IsNotExist(err) {
+		t.Error("Expected the hash sidecar to be deleted with the backup")
+	}
+}
+
+func TestCreateBackupWithNote(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := createTestConfigWithCompression(tempDir)
+	manager := NewManager(cfg)
+
+	hostsPath := filepath.Join(tempDir, "hosts")
+	if err := os.WriteFile(hostsPath, []byte("127.0.0.1 localhost\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	manager.platform.HostsDir = hostsPath
+
+	backupPath, err := manager.CreateBackup("pre firewall/migration!")
+	if err != nil {
+		t.Fatalf("CreateBackup() error = %v", err)
+	}
+	if !strings.Contains(filepath.Base(backupPath), "_pre-firewall-migration") {
+		t.Errorf("Expected sanitized note in filename, got %s", backupPath)
+	}
+
+	info, err := manager.getBackupInfo(backupPath)
+	if err != nil {
+		t.Fatalf("getBackupInfo() error = %v", err)
+	}
+	if info.Note != "pre-firewall-migration" {
+		t.Errorf("Expected note pre-firewall-migration, got %q", info.Note)
+	}
+	if time.Since(info.Timestamp) > time.Minute {
+		t.Errorf("Expected timestamp parsed from filename, got %v", info.Timestamp)
+	}
+
+	if _, err := manager.CreateBackup("!!!"); err == nil {
+		t.Error("Expected a note without usable characters to be rejected")
+	}
+
+	tests := []struct {
+		note     string
+		expected string
+	}{
+		{"pre-firewall-migration", "pre-firewall-migration"},
+		{"  before upgrade  ", "before-upgrade"},
+		{"../../etc/passwd", "etc-passwd"},
+		{strings.Repeat("a", 100), strings.Repeat("a", maxNoteLength)},
+	}
+	for _, tt := range tests {
+		if got := sanitizeNote(tt.note); got != tt.expected {
+			t.Errorf("sanitizeNote(%q) = %q, want %q", tt.note, got, tt.expected)
+		}
+	}
+}
+
+func TestRestoreBackupTo(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := createTestConfigWithCompression(tempDir)
+	manager := NewManager(cfg)
+
+	hostsPath := filepath.Join(tempDir, "hosts")
+	original := "127.0.0.1 localhost\n192.168.1.10 api.dev\n"
+	if err := os.WriteFile(hostsPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	manager.platform.HostsDir = hostsPath
+
+	backupPath, err := manager.CreateBackup("")
+	if err != nil {
+		t.Fatalf("CreateBackup() error = %v", err)
+	}
+
+	if err := os.WriteFile(hostsPath, []byte("127.0.0.1 localhost\n"), 0644); err != nil {
+		t.Fatalf("Failed to modify hosts file: %v", err)
+	}
+
+	dst := filepath.Join(tempDir, "inspect", "hosts.restored")
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := manager.RestoreBackupTo(backupPath, dst); err != nil {
+		t.Fatalf("RestoreBackupTo() error = %v", err)
+	}
+
+	restored, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("Failed to read restored file: %v", err)
+	}
+	if string(restored) != original {
+		t.Errorf("Expected decompressed backup contents %q, got %q", original, restored)
+	}
+
+	live, err := os.ReadFile(hostsPath)
+	if err != nil {
+		t.Fatalf("Failed to read hosts file: %v", err)
+	}
+	if string(live) != "127.0.0.1 localhost\n" {
+		t.Errorf("Expected live hosts file to be untouched, got %q", live)
+	}
+
+	if err := manager.RestoreBackupTo(filepath.Join(tempDir, "missing"), dst); err == nil {
+		t.Error("Expected error for a missing backup")
+	}
+}
+
+func TestListBackupsFiltered(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := createTestConfig(tempDir)
+	manager := NewManager(cfg)
+	if err := os.MkdirAll(cfg.Backup.Directory, 0700); err != nil {
+		t.Fatalf("Failed to create backup directory: %v", err)
+	}
+
+	names := []string{
+		"hosts.backup.2026-10-10T09-00-00",
+		"hosts.backup.auto.2026-10-12T09-00-00",
+		"hosts.backup.2026-10-13T09-00-00",
+		"hosts.backup.auto.2026-10-14T09-00-00",
+		"hosts.backup.2026-10-15T09-00-00",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(cfg.Backup.Directory, name), []byte("127.0.0.1 localhost\n"), 0600); err != nil {
+			t.Fatalf("Failed to write backup: %v", err)
+		}
+	}
+
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 9, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{name: "no bounds", filter: Filter{}, want: []string{names[4], names[3], names[2], names[1], names[0]}},
+		{name: "since is inclusive", filter: Filter{Since: day(13)}, want: []string{names[4], names[3], names[2]}},
+		{name: "before is exclusive", filter: Filter{Before: day(13)}, want: []string{names[1], names[0]}},
+		{name: "window", filter: Filter{Since: day(11), Before: day(15)}, want: []string{names[3], names[2], names[1]}},
+		{name: "empty window", filter: Filter{Since: day(16)}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backups, err := manager.ListBackupsFiltered(tt.filter)
+			if err != nil {
+				t.Fatalf("ListBackupsFiltered() error = %v", err)
+			}
+			var got []string
+			for _, info := range backups {
+				got = append(got, filepath.Base(info.FilePath))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ListBackupsFiltered() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	latest, err := manager.LatestAutoBackupFiltered(Filter{Before: day(14)})
+	if err != nil {
+		t.Fatalf("LatestAutoBackupFiltered() error = %v", err)
+	}
+	if filepath.Base(latest.FilePath) != names[1] {
+		t.Errorf("LatestAutoBackupFiltered() = %s, want %s", latest.FilePath, names[1])
+	}
+	if _, err := manager.LatestAutoBackupFiltered(Filter{Since: day(15)}); err == nil {
+		t.Error("Expected no automatic backups after the last one")
+	}
+}
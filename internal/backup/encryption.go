@@ -0,0 +1,140 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"os"
+)
+
+// encryptedSuffix marks backups encrypted with the configured passphrase.
+// It follows any compression suffix (hosts.backup.<timestamp>.gz.enc).
+const encryptedSuffix = ".enc"
+
+const (
+	// encryptionMagic identifies the encrypted backup format and its version
+	encryptionMagic = "HMENC1"
+	saltSize        = 16
+	keySize         = 32 // AES-256
+
+	// PBKDF2-HMAC-SHA256 work factor, following current OWASP guidance
+	keyDerivationIterations = 600000
+)
+
+// encryptionEnabled reports whether new backups should be encrypted
+func (m *Manager) encryptionEnabled() bool {
+	return m.config.Backup.Encryption.Enabled
+}
+
+// passphrase returns the backup passphrase from the configured environment variable
+func (m *Manager) passphrase() (string, error) {
+	envVar := m.config.Backup.Encryption.PassphraseEnv
+	if envVar == "" {
+		return "", fmt.Errorf("no backup passphrase environment variable configured")
+	}
+
+	passphrase := os.Getenv(envVar)
+	if passphrase == "" {
+		return "", fmt.Errorf("backup passphrase not set: export %s", envVar)
+	}
+	return passphrase, nil
+}
+
+// deriveKey derives the AES key for salt, caching it so listing many
+// backups does not repeat the deliberately slow derivation for one file
+func (m *Manager) deriveKey(salt []byte) ([]byte, error) {
+	passphrase, err := m.passphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	cacheKey := passphrase + "\x00" + string(salt)
+	if key, ok := m.keys[cacheKey]; ok {
+		return key, nil
+	}
+
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, keyDerivationIterations, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive backup key: %w", err)
+	}
+	if m.keys == nil {
+		m.keys = make(map[string][]byte)
+	}
+	m.keys[cacheKey] = key
+	return key, nil
+}
+
+// encrypt seals plaintext with AES-GCM under a key derived from the
+// passphrase and a fresh salt. The output is magic | salt | nonce | ciphertext.
+func (m *Manager) encrypt(plaintext []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := m.newGCM(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	out := make([]byte, 0, len(encryptionMagic)+saltSize+len(nonce)+len(plaintext)+gcm.Overhead())
+	out = append(out, encryptionMagic...)
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	// The header is authenticated so it cannot be swapped between files
+	return gcm.Seal(out, nonce, plaintext, out), nil
+}
+
+// decrypt reverses encrypt. A wrong passphrase or tampered file fails
+// authentication rather than yielding garbage.
+func (m *Manager) decrypt(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(encryptionMagic)) {
+		return nil, fmt.Errorf("not an encrypted backup")
+	}
+
+	headerSize := len(encryptionMagic) + saltSize
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("encrypted backup is truncated")
+	}
+	salt := data[len(encryptionMagic):headerSize]
+
+	gcm, err := m.newGCM(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	headerSize += gcm.NonceSize()
+	if len(data) < headerSize+gcm.Overhead() {
+		return nil, fmt.Errorf("encrypted backup is truncated")
+	}
+	header := data[:headerSize]
+	nonce := data[headerSize-gcm.NonceSize() : headerSize]
+
+	plaintext, err := gcm.Open(nil, nonce, data[headerSize:], header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt backup: wrong passphrase or corrupted file")
+	}
+	return plaintext, nil
+}
+
+func (m *Manager) newGCM(salt []byte) (cipher.AEAD, error) {
+	key, err := m.deriveKey(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
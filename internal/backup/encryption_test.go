@@ -0,0 +1,138 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPassphraseEnv = "HOSTS_MANAGER_TEST_BACKUP_PASSPHRASE"
+
+func newEncryptedTestManager(t *testing.T, tempDir string, compress bool) *Manager {
+	t.Helper()
+	t.Setenv(testPassphraseEnv, "correct horse battery staple")
+
+	cfg := createTestConfig(tempDir)
+	if compress {
+		cfg.Backup.CompressionType = "gzip"
+	}
+	cfg.Backup.Encryption.Enabled = true
+	cfg.Backup.Encryption.PassphraseEnv = testPassphraseEnv
+
+	if err := os.MkdirAll(cfg.Backup.Directory, 0700); err != nil {
+		t.Fatalf("Failed to create backup directory: %v", err)
+	}
+	return NewManager(cfg)
+}
+
+func TestEncryptedBackupRoundTrip(t *testing.T) {
+	for _, compression := range []string{"", gzipSuffix, zstdSuffix} {
+		t.Run(fmt.Sprintf("compression=%q", compression), func(t *testing.T) {
+			tempDir := t.TempDir()
+			compress := compression != ""
+			manager := newEncryptedTestManager(t, tempDir, compress)
+
+			hostsPath := filepath.Join(tempDir, "hosts")
+			testContent := "127.0.0.1 localhost\n192.168.1.10 internal.corp.example\n"
+			if err := os.WriteFile(hostsPath, []byte(testContent), 0644); err != nil {
+				t.Fatalf("Failed to create test hosts file: %v", err)
+			}
+
+			backupPath := filepath.Join(manager.config.Backup.Directory, "hosts.backup.2023-12-01T10-30-00"+compression+encryptedSuffix)
+			if err := manager.copyFile(hostsPath, backupPath, compress); err != nil {
+				t.Fatalf("copyFile() error = %v", err)
+			}
+
+			raw, err := os.ReadFile(backupPath)
+			if err != nil {
+				t.Fatalf("Failed to read backup: %v", err)
+			}
+			if bytes.Contains(raw, []byte("internal.corp.example")) {
+				t.Error("Encrypted backup contains plaintext hostnames")
+			}
+
+			// Hashing covers the plaintext, so it matches the original file
+			hash, err := manager.calculateFileHash(backupPath)
+			if err != nil {
+				t.Fatalf("calculateFileHash() error = %v", err)
+			}
+			if expected := fmt.Sprintf("%x", sha256.Sum256([]byte(testContent))); hash != expected {
+				t.Errorf("calculateFileHash() = %s, want plaintext hash %s", hash, expected)
+			}
+			if err := manager.recordHash(backupPath); err != nil {
+				t.Fatalf("recordHash() error = %v", err)
+			}
+			if err := manager.VerifyBackupIntegrity(backupPath); err != nil {
+				t.Errorf("VerifyBackupIntegrity() error = %v", err)
+			}
+
+			restoredPath := filepath.Join(tempDir, "restored")
+			if err := manager.restoreFile(backupPath, restoredPath, isCompressedBackup(backupPath)); err != nil {
+				t.Fatalf("restoreFile() error = %v", err)
+			}
+			restored, err := os.ReadFile(restoredPath)
+			if err != nil {
+				t.Fatalf("Failed to read restored file: %v", err)
+			}
+			if string(restored) != testContent {
+				t.Errorf("Restored content = %q, want %q", restored, testContent)
+			}
+
+			backups, err := manager.ListBackups()
+			if err != nil {
+				t.Fatalf("ListBackups() error = %v", err)
+			}
+			if len(backups) != 1 || backups[0].Timestamp.Format(backupTimestampFormat) != "2023-12-01T10-30-00" {
+				t.Errorf("Expected encrypted backup to be listed with its timestamp, got %+v", backups)
+			}
+		})
+	}
+}
+
+func TestEncryptedBackupWrongPassphrase(t *testing.T) {
+	tempDir := t.TempDir()
+	manager := newEncryptedTestManager(t, tempDir, true)
+
+	hostsPath := filepath.Join(tempDir, "hosts")
+	if err := os.WriteFile(hostsPath, []byte("127.0.0.1 localhost\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	backupPath := filepath.Join(manager.config.Backup.Directory, "hosts.backup.2023-12-01T10-30-00.gz.enc")
+	if err := manager.copyFile(hostsPath, backupPath, true); err != nil {
+		t.Fatalf("copyFile() error = %v", err)
+	}
+
+	// A fresh manager so no key derived from the right passphrase is cached
+	t.Setenv(testPassphraseEnv, "wrong passphrase")
+	wrong := NewManager(manager.config)
+
+	if _, err := wrong.ReadBackup(backupPath); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("Expected wrong passphrase to be rejected, got %v", err)
+	}
+
+	// The restore target is left untouched on failure
+	targetPath := filepath.Join(tempDir, "target")
+	if err := os.WriteFile(targetPath, []byte("original\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := wrong.restoreFile(backupPath, targetPath, true); err == nil {
+		t.Error("Expected restoreFile to fail with the wrong passphrase")
+	}
+	if data, _ := os.ReadFile(targetPath); string(data) != "original\n" {
+		t.Errorf("Expected restore target to be unchanged, got %q", data)
+	}
+
+	// Without any passphrase the backup is still listed, just without a hash
+	t.Setenv(testPassphraseEnv, "")
+	backups, err := NewManager(manager.config).ListBackups()
+	if err != nil {
+		t.Fatalf("ListBackups() error = %v", err)
+	}
+	if len(backups) != 1 || backups[0].Hash != "" {
+		t.Errorf("Expected encrypted backup listed without a hash, got %+v", backups)
+	}
+}
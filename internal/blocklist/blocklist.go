@@ -0,0 +1,363 @@
+// Package blocklist downloads community hosts-format blocklists and merges
+// their blocked hostnames into a single managed category.
+package blocklist
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/brandonhon/hosts-manager/internal/hosts"
+)
+
+const (
+	// StateFileName is the file in the config directory recording what was
+	// last fetched from each blocklist URL
+	StateFileName = "blocklists.json"
+
+	// SinkIP is the address blocked hostnames are pointed at. Lists usually
+	// use 0.0.0.0, which hosts-manager rejects as an unspecified address.
+	SinkIP = "127.0.0.1"
+
+	// maxListSize bounds a single download so a misbehaving server cannot
+	// exhaust memory
+	maxListSize = 64 << 20
+)
+
+// hostnames that hosts-format lists map to loopback for the system's own use
+var reservedHostnames = map[string]bool{
+	"localhost":             true,
+	"localhost.localdomain": true,
+	"local":                 true,
+	"broadcasthost":         true,
+	"ip6-localhost":         true,
+	"ip6-loopback":          true,
+	"0.0.0.0":               true,
+}
+
+// Source is what was last fetched from one blocklist URL
+type Source struct {
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Fetched      time.Time `json:"fetched"`
+	Hostnames    []string  `json:"hostnames"`
+}
+
+// State maps blocklist URLs to their last fetched Source
+type State struct {
+	Sources map[string]*Source `json:"sources"`
+}
+
+// LoadState reads the blocklist state file. A missing file yields an empty state.
+func LoadState(filePath string) (*State, error) {
+	state := &State{Sources: make(map[string]*Source)}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("failed to read blocklist state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse blocklist state: %w", err)
+	}
+	if state.Sources == nil {
+		state.Sources = make(map[string]*Source)
+	}
+	return state, nil
+}
+
+// Save writes the state file atomically
+func (s *State) Save(filePath string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode blocklist state: %w", err)
+	}
+
+	return hosts.AtomicWrite(filePath, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// Fetcher downloads blocklists with conditional requests
+type Fetcher struct {
+	client *http.Client
+}
+
+// NewFetcher returns a Fetcher whose requests time out after timeout
+func NewFetcher(timeout time.Duration) *Fetcher {
+	return &Fetcher{client: &http.Client{Timeout: timeout}}
+}
+
+// Fetch downloads rawURL. If previous is set, its ETag and Last-Modified
+// values are sent and, when the server reports the list unchanged, previous
+// is returned with changed set to false.
+func (f *Fetcher) Fetch(rawURL string, previous *Source) (source *Source, changed bool, err error) {
+	if err := ValidateURL(rawURL); err != nil {
+		return nil, false, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "hosts-manager")
+	if previous != nil {
+		if previous.ETag != "" {
+			req.Header.Set("If-None-Match", previous.ETag)
+		}
+		if previous.LastModified != "" {
+			req.Header.Set("If-Modified-Since", previous.LastModified)
+		}
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to download %s: %w", rawURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && previous != nil:
+		return previous, false, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, false, fmt.Errorf("failed to download %s: %s", rawURL, resp.Status)
+	}
+
+	body := io.LimitReader(resp.Body, maxListSize+1)
+	hostnames, err := ParseHosts(body)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	if lr, ok := body.(*io.LimitedReader); ok && lr.N <= 0 {
+		return nil, false, fmt.Errorf("blocklist %s exceeds %d bytes", rawURL, maxListSize)
+	}
+
+	return &Source{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Fetched:      time.Now(),
+		Hostnames:    hostnames,
+	}, true, nil
+}
+
+// Sync fetches every URL and returns the next state along with the union of
+// blocked hostnames in URL order. changed reports whether anything differs
+// from state, including URLs added or dropped since the last sync. Any failed
+// download aborts the sync so a partial set is never applied.
+func (f *Fetcher) Sync(urls []string, state *State) (next *State, hostnames []string, changed bool, err error) {
+	next = &State{Sources: make(map[string]*Source, len(urls))}
+	seen := make(map[string]bool)
+
+	for _, rawURL := range urls {
+		if _, duplicate := next.Sources[rawURL]; duplicate {
+			continue
+		}
+
+		previous := state.Sources[rawURL]
+		source, fetched, err := f.Fetch(rawURL, previous)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if fetched {
+			changed = true
+		}
+		next.Sources[rawURL] = source
+
+		for _, hostname := range source.Hostnames {
+			if !seen[hostname] {
+				seen[hostname] = true
+				hostnames = append(hostnames, hostname)
+			}
+		}
+	}
+
+	for rawURL := range state.Sources {
+		if _, kept := next.Sources[rawURL]; !kept {
+			changed = true
+		}
+	}
+
+	return next, hostnames, changed, nil
+}
+
+// ValidateURL accepts only absolute http and https URLs
+func ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid blocklist URL %q: %w", rawURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid blocklist URL %q: must be an http or https URL", rawURL)
+	}
+	return nil
+}
+
+// ParseHosts reads a flat hosts-format list and returns the hostnames mapped
+// to 0.0.0.0 or 127.0.0.1, in order and without duplicates. Other addresses,
+// reserved loopback names, and invalid hostnames are skipped.
+func ParseHosts(r io.Reader) ([]string, error) {
+	var hostnames []string
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !isSinkAddress(fields[0]) {
+			continue
+		}
+
+		for _, hostname := range fields[1:] {
+			hostname = strings.ToLower(hostname)
+			if seen[hostname] || reservedHostnames[hostname] {
+				continue
+			}
+			if !validHostname(hostname) {
+				continue
+			}
+			seen[hostname] = true
+			hostnames = append(hostnames, hostname)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return hostnames, nil
+}
+
+func isSinkAddress(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && (parsed.Equal(net.IPv4zero) || parsed.Equal(net.IPv4(127, 0, 0, 1)))
+}
+
+// validHostname validates without the audit logging ValidateHostname does,
+// since community lists can contain thousands of unusual names
+func validHostname(hostname string) bool {
+	if len(hostname) > 253 || strings.HasPrefix(hostname, ".") || strings.HasSuffix(hostname, ".") {
+		return false
+	}
+	for _, label := range strings.Split(hostname, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// applyBatchSize is how many blocked hostnames Apply adds between progress
+// updates
+const applyBatchSize = 1000
+
+// Result summarizes how Apply changed the blocklist category
+type Result struct {
+	Added    int
+	Removed  int
+	Skipped  int // hostnames already declared in another category
+	Rejected []hosts.RejectedEntry
+}
+
+// Apply replaces the entries of category with one entry per blocked
+// hostname, pointing at SinkIP. Hostnames already declared in
+// other categories are skipped so user entries win. Read-only entries in the
+// category are kept as they are. The category is created if missing.
+//
+// Entries are added with HostsFile.AddEntries in batches, so they are
+// validated under the file's hostname mode and invalid ones are rejected
+// rather than written. progress, when set, is called after each batch.
+func Apply(hostsFile *hosts.HostsFile, category string, hostnames []string, progress func(done, total int)) Result {
+	declaredElsewhere := make(map[string]bool)
+	previous := make(map[string]bool)
+	var kept []hosts.Entry
+	for _, cat := range hostsFile.Categories {
+		for _, entry := range cat.Entries {
+			for _, hostname := range entry.Hostnames {
+				if cat.Name != category || entry.ReadOnly {
+					declaredElsewhere[hostname] = true
+				} else {
+					previous[hostname] = true
+				}
+			}
+			if cat.Name == category && entry.ReadOnly {
+				kept = append(kept, entry)
+			}
+		}
+	}
+
+	if target := hostsFile.GetCategory(category); target != nil {
+		target.Entries = kept
+	} else {
+		hostsFile.Categories = append(hostsFile.Categories, hosts.Category{
+			Name:        category,
+			Description: "Hostnames imported from remote blocklists",
+			Enabled:     true,
+			Entries:     kept,
+		})
+	}
+
+	var result Result
+	var entries []hosts.Entry
+	queued := make(map[string]bool, len(hostnames))
+	for _, hostname := range hostnames {
+		if declaredElsewhere[hostname] {
+			result.Skipped++
+			continue
+		}
+		if queued[hostname] {
+			continue
+		}
+		queued[hostname] = true
+		entries = append(entries, hosts.Entry{
+			IP:        SinkIP,
+			Hostnames: []string{hostname},
+			Category:  category,
+			Enabled:   true,
+		})
+	}
+
+	for start := 0; start < len(entries); start += applyBatchSize {
+		end := min(start+applyBatchSize, len(entries))
+		result.Rejected = append(result.Rejected, hostsFile.AddEntries(entries[start:end]).Rejected...)
+		if progress != nil {
+			progress(end, len(entries))
+		}
+	}
+
+	// What remains queued is what the category now blocks
+	for _, rejection := range result.Rejected {
+		delete(queued, rejection.Entry.Hostnames[0])
+	}
+	for hostname := range queued {
+		if !previous[hostname] {
+			result.Added++
+		}
+	}
+	for hostname := range previous {
+		if !queued[hostname] {
+			result.Removed++
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,162 @@
+package blocklist
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonhon/hosts-manager/internal/hosts"
+)
+
+const sampleList = `# Title: StevenBlack/hosts
+127.0.0.1 localhost
+127.0.0.1 localhost.localdomain
+0.0.0.0 0.0.0.0
+
+# [ads]
+0.0.0.0 ads.example.com
+0.0.0.0 tracker.example.net # inline comment
+127.0.0.1 Metrics.Example.org beacon.example.org
+0.0.0.0 ads.example.com
+192.168.1.1 router.lan
+0.0.0.0 bad..name
+`
+
+func TestParseHosts(t *testing.T) {
+	hostnames, err := ParseHosts(strings.NewReader(sampleList))
+	if err != nil {
+		t.Fatalf("ParseHosts() error = %v", err)
+	}
+
+	expected := []string{"ads.example.com", "tracker.example.net", "metrics.example.org", "beacon.example.org"}
+	if strings.Join(hostnames, ",") != strings.Join(expected, ",") {
+		t.Errorf("ParseHosts() = %v, want %v", hostnames, expected)
+	}
+}
+
+func TestFetcherSyncConditional(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(sampleList))
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(5 * time.Second)
+	empty := &State{Sources: map[string]*Source{}}
+
+	state, hostnames, changed, err := fetcher.Sync([]string{server.URL}, empty)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if !changed || len(hostnames) != 4 {
+		t.Fatalf("First Sync() changed = %v, hostnames = %v", changed, hostnames)
+	}
+	if state.Sources[server.URL].ETag != `"v1"` {
+		t.Errorf("Expected ETag to be recorded, got %+v", state.Sources[server.URL])
+	}
+
+	// Persist and reload the state to confirm the ETag survives a round trip
+	statePath := filepath.Join(t.TempDir(), StateFileName)
+	if err := state.Save(statePath); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := LoadState(statePath)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+
+	_, hostnames, changed, err = fetcher.Sync([]string{server.URL}, loaded)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if changed {
+		t.Error("Expected unchanged list to report no changes")
+	}
+	if len(hostnames) != 4 {
+		t.Errorf("Expected cached hostnames on 304, got %v", hostnames)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+
+	// Dropping a URL from the configuration is a change
+	_, hostnames, changed, err = fetcher.Sync(nil, loaded)
+	if err != nil || !changed || len(hostnames) != 0 {
+		t.Errorf("Sync(nil) = %v, %v, %v; want no hostnames and a change", hostnames, changed, err)
+	}
+}
+
+func TestFetcherSyncFailure(t *testing.T) {
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(sampleList))
+	}))
+	defer good.Close()
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusNotFound)
+	}))
+	defer bad.Close()
+
+	fetcher := NewFetcher(5 * time.Second)
+	_, _, _, err := fetcher.Sync([]string{good.URL, bad.URL}, &State{Sources: map[string]*Source{}})
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected download failure to abort the sync, got %v", err)
+	}
+
+	if _, _, err := fetcher.Fetch("ftp://example.com/hosts", nil); err == nil {
+		t.Error("Expected non-http URL to be rejected")
+	}
+}
+
+func TestApply(t *testing.T) {
+	hostsFile, err := hosts.NewParser("").ParseReader(strings.NewReader(`127.0.0.1 localhost
+
+# @category development
+192.168.1.10 tracker.example.net
+
+# @category blocklist
+127.0.0.1 stale.example.com
+127.0.0.1 ads.example.com
+`))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	var progress [][2]int
+	result := Apply(hostsFile, "blocklist", []string{"ads.example.com", "tracker.example.net", "new.example.org", "bad_name.example.com"}, func(done, total int) {
+		progress = append(progress, [2]int{done, total})
+	})
+
+	if result.Added != 1 || result.Removed != 1 || result.Skipped != 1 {
+		t.Errorf("Apply() = %+v, want 1 added, 1 removed, 1 skipped", result)
+	}
+	if len(result.Rejected) != 1 || result.Rejected[0].Entry.Hostnames[0] != "bad_name.example.com" {
+		t.Errorf("Expected the underscore hostname to be rejected, got %+v", result.Rejected)
+	}
+	if len(progress) != 1 || progress[0] != [2]int{3, 3} {
+		t.Errorf("Expected one progress update for 3 entries, got %v", progress)
+	}
+
+	var got []string
+	for _, entry := range hostsFile.GetCategory("blocklist").Entries {
+		if entry.IP != SinkIP {
+			t.Errorf("Expected blocked entry to use %s, got %s", SinkIP, entry.IP)
+		}
+		got = append(got, entry.Hostnames...)
+	}
+	if strings.Join(got, ",") != "ads.example.com,new.example.org" {
+		t.Errorf("Expected blocklist category to hold ads.example.com,new.example.org, got %v", got)
+	}
+
+	if entries := hostsFile.GetCategory("development").Entries; len(entries) != 1 || entries[0].IP != "192.168.1.10" {
+		t.Errorf("Expected user entry to be left alone, got %+v", entries)
+	}
+}
@@ -17,6 +17,8 @@ type Config struct {
 	UI         UI                 `yaml:"ui"`
 	Backup     Backup             `yaml:"backup"`
 	Export     Export             `yaml:"export"`
+	Blocklists Blocklists         `yaml:"blocklists"`
+	Audit      Audit              `yaml:"audit"`
 }
 
 type General struct {
@@ -25,6 +27,38 @@ type General struct {
 	DryRun          bool   `yaml:"dry_run"`
 	Verbose         bool   `yaml:"verbose"`
 	Editor          string `yaml:"editor"`
+	// WriteGuardThreshold is the maximum number of enabled entries a single
+	// write may remove before it is refused without --force (0 disables).
+	WriteGuardThreshold int `yaml:"write_guard_threshold"`
+	// IPFormat controls how IPv4 addresses with leading zeros are read:
+	// "normalize" strips the zeros, "preserve" keeps the original text.
+	IPFormat string `yaml:"ip_format"`
+	// BannerStyle selects how categories are marked in the hosts file and
+	// hosts exports: "equals", "dashes", or a template containing {name} for a
+	// marker and banner, "minimal" for the marker alone, or "none" for no
+	// category lines (membership is kept in <hosts file>.categories).
+	BannerStyle string `yaml:"banner_style"`
+	// NormalizeIPs rewrites entry IPs in canonical form when they are added
+	// or written, e.g. 2001:DB8::0001 becomes 2001:db8::1.
+	NormalizeIPs bool `yaml:"normalize_ips"`
+	// FlushDNSAfterWrite flushes the OS resolver cache after every successful
+	// write to the hosts file.
+	FlushDNSAfterWrite bool `yaml:"flush_dns_after_write"`
+	// IDN controls international hostnames: "reject" refuses them, "punycode"
+	// stores the xn-- form, and "annotate" also keeps the Unicode form in the
+	// entry's comment.
+	IDN string `yaml:"idn"`
+	// ReversedLines controls lines written hostname first, such as
+	// "localhost 127.0.0.1": "report" skips them and lists them in validate,
+	// "fix" reads them as entries and logs a warning.
+	ReversedLines string `yaml:"reversed_lines"`
+	// AllowUnderscoreHostnames accepts underscores in hostnames, such as
+	// _sip._tcp.corp or build_01.internal, keeping every other check.
+	AllowUnderscoreHostnames bool `yaml:"allow_underscore_hostnames"`
+	// ManagedBlockOnly limits hosts-manager to the lines between
+	// "# BEGIN hosts-manager" and "# END hosts-manager", leaving the rest of
+	// the hosts file to other tools.
+	ManagedBlockOnly bool `yaml:"managed_block_only"`
 }
 
 type Profile struct {
@@ -37,6 +71,7 @@ type UI struct {
 	ColorScheme     string            `yaml:"color_scheme"`
 	ShowLineNumbers bool              `yaml:"show_line_numbers"`
 	PageSize        int               `yaml:"page_size"`
+	AutoSave        bool              `yaml:"auto_save"`
 	KeyBindings     map[string]string `yaml:"key_bindings"`
 }
 
@@ -45,6 +80,37 @@ type Backup struct {
 	MaxBackups      int    `yaml:"max_backups"`
 	RetentionDays   int    `yaml:"retention_days"`
 	CompressionType string `yaml:"compression_type"`
+	// CompressionLevel is the level from 1 (fastest) to 9 (smallest) for
+	// gzip or zstd; 0 uses the codec's default
+	CompressionLevel int        `yaml:"compression_level"`
+	DirectoryLayout  string     `yaml:"directory_layout"`
+	Encryption       Encryption `yaml:"encryption"`
+}
+
+// Encryption configures passphrase-based encryption of backups at rest. The
+// passphrase is read from the PassphraseEnv environment variable so it is
+// never stored in the config file.
+type Encryption struct {
+	Enabled       bool   `yaml:"enabled"`
+	PassphraseEnv string `yaml:"passphrase_env"`
+}
+
+// Blocklists configures the remote hosts-format lists imported by sync
+type Blocklists struct {
+	URLs           []string `yaml:"urls"`
+	Category       string   `yaml:"category"`
+	TimeoutSeconds int      `yaml:"timeout_seconds"`
+}
+
+// Audit configures the security audit log
+type Audit struct {
+	Enabled bool `yaml:"enabled"`
+	// MinLevel is the lowest severity recorded: info, warning, error, or critical
+	MinLevel string `yaml:"min_level"`
+	// MaxLogSize is the size in bytes at which the log is rotated
+	MaxLogSize int64 `yaml:"max_log_size"`
+	// MaxLogs is the number of rotated logs kept
+	MaxLogs int `yaml:"max_logs"`
 }
 
 type Export struct {
@@ -65,6 +131,10 @@ func DefaultConfig() *Config {
 			DryRun:          false,
 			Verbose:         false,
 			Editor:          getDefaultEditor(),
+			IPFormat:        "normalize",
+			BannerStyle:     "equals",
+			IDN:             "reject",
+			ReversedLines:   "report",
 		},
 		Categories: map[string]string{
 			"development": "Development environments and local services",
@@ -113,6 +183,20 @@ func DefaultConfig() *Config {
 			MaxBackups:      10,
 			RetentionDays:   30,
 			CompressionType: "gzip",
+			DirectoryLayout: "flat",
+			Encryption: Encryption{
+				PassphraseEnv: "HOSTS_MANAGER_BACKUP_PASSPHRASE",
+			},
+		},
+		Blocklists: Blocklists{
+			Category:       "blocklist",
+			TimeoutSeconds: 30,
+		},
+		Audit: Audit{
+			Enabled:    true,
+			MinLevel:   "info",
+			MaxLogSize: 10 * 1024 * 1024,
+			MaxLogs:    5,
 		},
 		Export: Export{
 			DefaultFormat: "yaml",
@@ -129,6 +213,10 @@ func DefaultConfig() *Config {
 					Extension: ".hosts",
 					Template:  "# Generated by hosts-manager\n{{range .Categories}}{{if .Enabled}}# {{.Name}}\n{{range .Entries}}{{if .Enabled}}{{.IP}} {{join .Hostnames \" \"}}{{if .Comment}} # {{.Comment}}{{end}}\n{{end}}{{end}}\n{{end}}{{end}}",
 				},
+				"dnsmasq": {
+					Extension: ".conf",
+					Template:  "{{range .Categories}}{{if .Enabled}}{{range $entry := .Entries}}{{if .Enabled}}{{range .Hostnames}}address=/{{.}}/{{$entry.IP}}\n{{end}}{{end}}{{end}}{{end}}{{end}}",
+				},
 			},
 		},
 	}
@@ -149,28 +237,31 @@ func Load() (*Config, error) {
 	configDir := p.GetConfigDir()
 	configPath := filepath.Join(configDir, "config.yaml")
 
+	config := DefaultConfig()
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		config := DefaultConfig()
 		if err := Save(config); err != nil {
 			return config, fmt.Errorf("failed to create default config: %w", err)
 		}
-		return config, nil
-	}
-
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
+	} else {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
 
-	config := DefaultConfig()
-	if err := yaml.Unmarshal(data, config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
 	}
 
 	if config.Backup.Directory == "" {
 		config.Backup.Directory = filepath.Join(p.GetDataDir(), "backups")
 	}
 
+	// Environment overrides apply on top of the file and are validated with it
+	if err := applyEnvOverrides(config); err != nil {
+		return nil, err
+	}
+
 	// Validate the loaded configuration
 	validator := NewValidator()
 	if err := validator.Validate(config); err != nil {
@@ -23,6 +23,9 @@ func TestDefaultConfig(t *testing.T) {
 	if config.General.Verbose {
 		t.Error("Expected verbose to be disabled by default")
 	}
+	if config.General.AllowUnderscoreHostnames {
+		t.Error("Expected underscore hostnames to be rejected by default")
+	}
 
 	// Test Categories
 	expectedCategories := []string{"development", "staging", "production", "custom", "vpn", "blocked"}
@@ -73,7 +76,7 @@ func TestDefaultConfig(t *testing.T) {
 	if config.Export.DefaultFormat != "yaml" {
 		t.Errorf("Expected default format to be 'yaml', got %s", config.Export.DefaultFormat)
 	}
-	expectedFormats := []string{"yaml", "json", "hosts"}
+	expectedFormats := []string{"yaml", "json", "hosts", "dnsmasq"}
 	if len(config.Export.Formats) != len(expectedFormats) {
 		t.Errorf("Expected %d formats, got %d", len(expectedFormats), len(config.Export.Formats))
 	}
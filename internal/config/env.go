@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// EnvPrefix starts the environment variables that override config values
+const EnvPrefix = "HOSTS_MANAGER_"
+
+// EnvOverrides maps each supported environment variable to the dotted config
+// key it overrides. Keys in the general section drop the section name, so
+// HOSTS_MANAGER_AUTO_BACKUP sets general.auto_backup, while other sections
+// keep it: HOSTS_MANAGER_BACKUP_MAX_BACKUPS sets backup.max_backups. Maps
+// such as categories and profiles cannot be overridden.
+func EnvOverrides() map[string]string {
+	overrides := make(map[string]string)
+	collectEnvKeys(reflect.TypeOf(Config{}), "", overrides)
+	return overrides
+}
+
+func collectEnvKeys(t reflect.Type, prefix string, overrides map[string]string) {
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		switch ft := t.Field(i).Type; ft.Kind() {
+		case reflect.Struct:
+			collectEnvKeys(ft, key, overrides)
+		case reflect.Map:
+			continue
+		case reflect.Slice:
+			if ft.Elem().Kind() == reflect.String {
+				overrides[envName(key)] = key
+			}
+		default:
+			overrides[envName(key)] = key
+		}
+	}
+}
+
+func envName(key string) string {
+	key = strings.TrimPrefix(key, "general.")
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// applyEnvOverrides sets every config value whose environment variable is
+// present. Values are parsed as by Set, so lists are comma-separated.
+func applyEnvOverrides(c *Config) error {
+	overrides := EnvOverrides()
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := c.Set(overrides[name], value); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnvOverrides(t *testing.T) {
+	overrides := EnvOverrides()
+
+	want := map[string]string{
+		"HOSTS_MANAGER_AUTO_BACKUP":                      "general.auto_backup",
+		"HOSTS_MANAGER_DEFAULT_CATEGORY":                 "general.default_category",
+		"HOSTS_MANAGER_BACKUP_MAX_BACKUPS":               "backup.max_backups",
+		"HOSTS_MANAGER_BACKUP_ENCRYPTION_ENABLED":        "backup.encryption.enabled",
+		"HOSTS_MANAGER_BLOCKLISTS_URLS":                  "blocklists.urls",
+		"HOSTS_MANAGER_AUDIT_MIN_LEVEL":                  "audit.min_level",
+		"HOSTS_MANAGER_UI_COLOR_SCHEME":                  "ui.color_scheme",
+		"HOSTS_MANAGER_EXPORT_DEFAULT_FORMAT":            "export.default_format",
+		"HOSTS_MANAGER_BACKUP_ENCRYPTION_PASSPHRASE_ENV": "backup.encryption.passphrase_env",
+	}
+	for name, key := range want {
+		if overrides[name] != key {
+			t.Errorf("EnvOverrides()[%s] = %q, want %q", name, overrides[name], key)
+		}
+	}
+
+	for name, key := range overrides {
+		if strings.HasPrefix(key, "categories") || strings.HasPrefix(key, "profiles") || strings.Contains(key, "key_bindings") {
+			t.Errorf("map key %s should not be overridable (%s)", key, name)
+		}
+	}
+}
+
+func TestApplyEnvOverrides(t *testing.T) {
+	t.Setenv("HOSTS_MANAGER_AUTO_BACKUP", "false")
+	t.Setenv("HOSTS_MANAGER_DEFAULT_CATEGORY", "ci")
+	t.Setenv("HOSTS_MANAGER_BACKUP_MAX_BACKUPS", "3")
+	t.Setenv("HOSTS_MANAGER_BLOCKLISTS_URLS", "https://a.example/hosts,https://b.example/hosts")
+
+	c := DefaultConfig()
+	if err := applyEnvOverrides(c); err != nil {
+		t.Fatalf("applyEnvOverrides() error = %v", err)
+	}
+
+	if c.General.AutoBackup {
+		t.Error("AutoBackup should be overridden to false")
+	}
+	if c.General.DefaultCategory != "ci" {
+		t.Errorf("DefaultCategory = %q, want ci", c.General.DefaultCategory)
+	}
+	if c.Backup.MaxBackups != 3 {
+		t.Errorf("MaxBackups = %d, want 3", c.Backup.MaxBackups)
+	}
+	if want := []string{"https://a.example/hosts", "https://b.example/hosts"}; !reflect.DeepEqual(c.Blocklists.URLs, want) {
+		t.Errorf("URLs = %v, want %v", c.Blocklists.URLs, want)
+	}
+}
+
+func TestApplyEnvOverridesInvalid(t *testing.T) {
+	t.Setenv("HOSTS_MANAGER_AUTO_BACKUP", "sometimes")
+
+	err := applyEnvOverrides(DefaultConfig())
+	if err == nil || !strings.Contains(err.Error(), "HOSTS_MANAGER_AUTO_BACKUP") {
+		t.Errorf("applyEnvOverrides() error = %v, want it to name the variable", err)
+	}
+}
+
+func TestEnvOverrideValidated(t *testing.T) {
+	t.Setenv("HOSTS_MANAGER_BACKUP_MAX_BACKUPS", "1000")
+
+	c := DefaultConfig()
+	if err := applyEnvOverrides(c); err != nil {
+		t.Fatalf("applyEnvOverrides() error = %v", err)
+	}
+	if err := NewValidator().Validate(c); err == nil {
+		t.Error("out-of-range override should fail validation")
+	}
+}
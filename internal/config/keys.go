@@ -0,0 +1,148 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Get returns the value at a dotted key such as general.default_category,
+// using the same names as the config file. Scalars are formatted plainly;
+// sections, lists and maps are returned as YAML.
+func (c *Config) Get(key string) (string, error) {
+	parts := splitKey(key)
+	if len(parts) == 0 {
+		return "", fmt.Errorf("unknown config key: %s", key)
+	}
+	v := reflect.ValueOf(c).Elem()
+	for _, part := range parts {
+		next, err := child(v, part)
+		if err != nil {
+			return "", fmt.Errorf("unknown config key: %s", key)
+		}
+		v = next
+	}
+
+	switch v.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64:
+		return fmt.Sprint(v.Interface()), nil
+	}
+	data, err := yaml.Marshal(v.Interface())
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s: %w", key, err)
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// Set parses value for the type of the dotted key and stores it. Lists take
+// comma-separated values. Map keys that do not exist yet are created, so
+// categories.lab can add a category. Set does not validate the result.
+func (c *Config) Set(key, value string) error {
+	parts := splitKey(key)
+	if len(parts) == 0 {
+		return fmt.Errorf("unknown config key: %s", key)
+	}
+	return setPath(reflect.ValueOf(c).Elem(), parts, key, value)
+}
+
+func splitKey(key string) []string {
+	if strings.TrimSpace(key) == "" {
+		return nil
+	}
+	return strings.Split(key, ".")
+}
+
+// child returns the struct field with the given yaml name, or the map entry
+// with the given key
+func child(v reflect.Value, name string) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Struct:
+		if i, ok := fieldIndex(v.Type(), name); ok {
+			return v.Field(i), nil
+		}
+	case reflect.Map:
+		if e := v.MapIndex(reflect.ValueOf(name)); e.IsValid() {
+			return e, nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("no such key: %s", name)
+}
+
+func fieldIndex(t reflect.Type, name string) (int, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if tag == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func setPath(v reflect.Value, parts []string, key, value string) error {
+	if len(parts) == 0 {
+		return setScalar(v, key, value)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		i, ok := fieldIndex(v.Type(), parts[0])
+		if !ok {
+			return fmt.Errorf("unknown config key: %s", key)
+		}
+		return setPath(v.Field(i), parts[1:], key, value)
+	case reflect.Map:
+		// Map values are not addressable, so update a copy and store it back
+		mapKey := reflect.ValueOf(parts[0])
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(mapKey); existing.IsValid() {
+			elem.Set(existing)
+		} else if len(parts) > 1 {
+			return fmt.Errorf("unknown config key: %s", key)
+		}
+		if err := setPath(elem, parts[1:], key, value); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		v.SetMapIndex(mapKey, elem)
+		return nil
+	}
+	return fmt.Errorf("unknown config key: %s", key)
+}
+
+func setScalar(v reflect.Value, key, value string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for %s: expected true or false", value, key)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid value %q for %s: expected an integer", value, key)
+		}
+		v.SetInt(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("%s cannot be set from the command line", key)
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("%s is a section; set one of its keys instead", key)
+	}
+	return nil
+}
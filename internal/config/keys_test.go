@@ -0,0 +1,119 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigGet(t *testing.T) {
+	c := DefaultConfig()
+	c.General.DefaultCategory = "custom"
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"general.default_category", "custom"},
+		{"general.auto_backup", "true"},
+		{"backup.max_backups", "10"},
+		{"audit.max_log_size", "10485760"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := c.Get(tt.key)
+			if err != nil {
+				t.Fatalf("Get(%q) error = %v", tt.key, err)
+			}
+			if got != tt.want {
+				t.Errorf("Get(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+
+	section, err := c.Get("audit")
+	if err != nil {
+		t.Fatalf("Get(audit) error = %v", err)
+	}
+	if !strings.Contains(section, "min_level: info") {
+		t.Errorf("Get(audit) = %q, want YAML section", section)
+	}
+
+	for _, key := range []string{"", "general.missing", "nope", "general.editor.extra", "categories.missing"} {
+		if _, err := c.Get(key); err == nil {
+			t.Errorf("Get(%q) should fail", key)
+		}
+	}
+}
+
+func TestConfigSet(t *testing.T) {
+	c := DefaultConfig()
+
+	if err := c.Set("general.default_category", "development"); err != nil {
+		t.Fatal(err)
+	}
+	if c.General.DefaultCategory != "development" {
+		t.Errorf("DefaultCategory = %q", c.General.DefaultCategory)
+	}
+
+	if err := c.Set("backup.max_backups", "25"); err != nil {
+		t.Fatal(err)
+	}
+	if c.Backup.MaxBackups != 25 {
+		t.Errorf("MaxBackups = %d", c.Backup.MaxBackups)
+	}
+
+	if err := c.Set("general.auto_backup", "false"); err != nil {
+		t.Fatal(err)
+	}
+	if c.General.AutoBackup {
+		t.Error("AutoBackup should be false")
+	}
+
+	if err := c.Set("blocklists.urls", "https://a.example/hosts, https://b.example/hosts"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"https://a.example/hosts", "https://b.example/hosts"}; !reflect.DeepEqual(c.Blocklists.URLs, want) {
+		t.Errorf("URLs = %v, want %v", c.Blocklists.URLs, want)
+	}
+
+	if err := c.Set("categories.lab", "Lab machines"); err != nil {
+		t.Fatal(err)
+	}
+	if c.Categories["lab"] != "Lab machines" {
+		t.Errorf("Categories[lab] = %q", c.Categories["lab"])
+	}
+
+	c.Profiles = map[string]Profile{"work": {Description: "Work"}}
+	if err := c.Set("profiles.work.description", "Office"); err != nil {
+		t.Fatal(err)
+	}
+	if c.Profiles["work"].Description != "Office" {
+		t.Errorf("Profiles[work].Description = %q", c.Profiles["work"].Description)
+	}
+}
+
+func TestConfigSetErrors(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		wantErr string
+	}{
+		{"general.missing", "x", "unknown config key"},
+		{"profiles.missing.description", "x", "unknown config key"},
+		{"backup.max_backups", "ten", "expected an integer"},
+		{"general.auto_backup", "maybe", "expected true or false"},
+		{"backup", "x", "is a section"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			c := DefaultConfig()
+			err := c.Set(tt.key, tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Set(%q, %q) error = %v, want %q", tt.key, tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"regexp"
 	"strings"
 )
@@ -51,6 +52,12 @@ func (v *ConfigValidator) Validate(config *Config) error {
 	// Validate Export section
 	v.validateExport(&config.Export)
 
+	// Validate Blocklists section
+	v.validateBlocklists(&config.Blocklists)
+
+	// Validate Audit section
+	v.validateAudit(&config.Audit)
+
 	// Return combined errors if any
 	if len(v.errors) > 0 {
 		return fmt.Errorf("configuration validation failed with %d errors: %v", len(v.errors), v.errors)
@@ -72,6 +79,39 @@ func (v *ConfigValidator) validateGeneral(general *General) {
 	if general.Editor != "" && !isValidEditor(general.Editor) {
 		v.addError("general.editor", general.Editor, "invalid or potentially unsafe editor")
 	}
+
+	// Validate write guard threshold
+	if general.WriteGuardThreshold < 0 {
+		v.addError("general.write_guard_threshold", general.WriteGuardThreshold, "write guard threshold cannot be negative")
+	}
+
+	// Validate IP format (empty means normalize)
+	validIPFormats := []string{"normalize", "preserve"}
+	if general.IPFormat != "" && !contains(validIPFormats, general.IPFormat) {
+		v.addError("general.ip_format", general.IPFormat, "ip format must be normalize or preserve")
+	}
+
+	// Validate IDN handling (empty means reject)
+	validIDNModes := []string{"reject", "punycode", "annotate"}
+	if general.IDN != "" && !contains(validIDNModes, general.IDN) {
+		v.addError("general.idn", general.IDN, "idn must be reject, punycode or annotate")
+	}
+
+	// Validate reversed line handling (empty means report)
+	validReversedModes := []string{"report", "fix"}
+	if general.ReversedLines != "" && !contains(validReversedModes, general.ReversedLines) {
+		v.addError("general.reversed_lines", general.ReversedLines, "reversed lines must be report or fix")
+	}
+
+	// Validate banner style (empty means equals)
+	validBannerStyles := []string{"equals", "dashes", "minimal", "none"}
+	if general.BannerStyle != "" && !contains(validBannerStyles, general.BannerStyle) {
+		if !strings.Contains(general.BannerStyle, "{name}") {
+			v.addError("general.banner_style", general.BannerStyle, "banner style must be equals, dashes, minimal, none, or a template containing {name}")
+		} else if strings.ContainsAny(general.BannerStyle, "\r\n") {
+			v.addError("general.banner_style", general.BannerStyle, "banner template must be a single line")
+		}
+	}
 }
 
 // validateCategories validates the Categories configuration
@@ -183,10 +223,65 @@ func (v *ConfigValidator) validateBackup(backup *Backup) {
 	}
 
 	// Validate compression type
-	validCompressionTypes := []string{"none", "gzip"}
+	validCompressionTypes := []string{"none", "gzip", "zstd"}
 	if !contains(validCompressionTypes, backup.CompressionType) {
 		v.addError("backup.compression_type", backup.CompressionType, "invalid compression type")
 	}
+
+	// Validate compression level (0 means the codec's default)
+	if backup.CompressionLevel < 0 || backup.CompressionLevel > 9 {
+		v.addError("backup.compression_level", backup.CompressionLevel, "compression level must be between 1 and 9, or 0 for the default")
+	}
+
+	// Validate directory layout (empty means flat)
+	validLayouts := []string{"flat", "daily", "monthly"}
+	if backup.DirectoryLayout != "" && !contains(validLayouts, backup.DirectoryLayout) {
+		v.addError("backup.directory_layout", backup.DirectoryLayout, "directory layout must be flat, daily, or monthly")
+	}
+
+	// Validate encryption passphrase source
+	encryption := backup.Encryption
+	if encryption.Enabled && encryption.PassphraseEnv == "" {
+		v.addError("backup.encryption.passphrase_env", encryption.PassphraseEnv, "passphrase environment variable is required when encryption is enabled")
+	} else if encryption.PassphraseEnv != "" && !isValidEnvVarName(encryption.PassphraseEnv) {
+		v.addError("backup.encryption.passphrase_env", encryption.PassphraseEnv, "invalid environment variable name")
+	}
+}
+
+// validateBlocklists validates the Blocklists configuration section
+func (v *ConfigValidator) validateBlocklists(blocklists *Blocklists) {
+	for i, rawURL := range blocklists.URLs {
+		u, err := url.Parse(rawURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.addError(fmt.Sprintf("blocklists.urls[%d]", i), rawURL, "blocklist URL must be an http or https URL")
+		}
+	}
+
+	// Empty category means "blocklist"
+	if blocklists.Category != "" && !isValidCategoryName(blocklists.Category) {
+		v.addError("blocklists.category", blocklists.Category, "invalid category name format")
+	}
+
+	if blocklists.TimeoutSeconds < 0 || blocklists.TimeoutSeconds > 600 {
+		v.addError("blocklists.timeout_seconds", blocklists.TimeoutSeconds, "timeout must be between 0 and 600 seconds")
+	}
+}
+
+// validateAudit validates the Audit configuration section
+func (v *ConfigValidator) validateAudit(audit *Audit) {
+	// Empty min level means info
+	validLevels := []string{"info", "warning", "error", "critical"}
+	if audit.MinLevel != "" && !contains(validLevels, audit.MinLevel) {
+		v.addError("audit.min_level", audit.MinLevel, "min level must be info, warning, error, or critical")
+	}
+
+	if audit.MaxLogSize < 1024 || audit.MaxLogSize > 1024*1024*1024 {
+		v.addError("audit.max_log_size", audit.MaxLogSize, "max log size must be between 1024 bytes and 1 GiB")
+	}
+
+	if audit.MaxLogs < 1 || audit.MaxLogs > 100 {
+		v.addError("audit.max_logs", audit.MaxLogs, "max logs must be between 1 and 100")
+	}
 }
 
 // validateExport validates the Export configuration section
@@ -232,6 +327,11 @@ func isValidCategoryName(name string) bool {
 	return matched
 }
 
+func isValidEnvVarName(name string) bool {
+	matched, _ := regexp.MatchString(`^[A-Za-z_][A-Za-z0-9_]*$`, name)
+	return matched
+}
+
 func isValidProfileName(name string) bool {
 	if len(name) == 0 || len(name) > 50 {
 		return false
@@ -343,6 +443,12 @@ func containsSuspiciousPath(path string) bool {
 	return false
 }
 
+// IsSuspiciousTemplate reports whether an export template contains content
+// the validator rejects, so templates can be checked again right before use
+func IsSuspiciousTemplate(template string) bool {
+	return containsSuspiciousTemplate(template)
+}
+
 func containsSuspiciousTemplate(template string) bool {
 	// Allow basic Go template syntax but check for dangerous patterns
 	suspiciousPatterns := []string{
@@ -94,6 +94,92 @@ func TestValidateGeneral(t *testing.T) {
 			},
 			expectError: false, // code with safe arguments should be valid
 		},
+		{
+			name: "negative write guard threshold",
+			general: General{
+				DefaultCategory:     "custom",
+				Editor:              "nano",
+				WriteGuardThreshold: -1,
+			},
+			expectError:   true,
+			errorContains: "write guard threshold cannot be negative",
+		},
+		{
+			name: "preserve ip format",
+			general: General{
+				DefaultCategory: "custom",
+				Editor:          "nano",
+				IPFormat:        "preserve",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid ip format",
+			general: General{
+				DefaultCategory: "custom",
+				Editor:          "nano",
+				IPFormat:        "octal",
+			},
+			expectError:   true,
+			errorContains: "ip format must be normalize or preserve",
+		},
+		{
+			name: "punycode idn",
+			general: General{
+				DefaultCategory: "custom",
+				Editor:          "nano",
+				IDN:             "punycode",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid idn",
+			general: General{
+				DefaultCategory: "custom",
+				Editor:          "nano",
+				IDN:             "unicode",
+			},
+			expectError:   true,
+			errorContains: "idn must be reject, punycode or annotate",
+		},
+		{
+			name: "fix reversed lines",
+			general: General{
+				DefaultCategory: "custom",
+				Editor:          "nano",
+				ReversedLines:   "fix",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid reversed lines",
+			general: General{
+				DefaultCategory: "custom",
+				Editor:          "nano",
+				ReversedLines:   "swap",
+			},
+			expectError:   true,
+			errorContains: "reversed lines must be report or fix",
+		},
+		{
+			name: "custom banner template",
+			general: General{
+				DefaultCategory: "custom",
+				Editor:          "nano",
+				BannerStyle:     "# ### {name} ###",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid banner style",
+			general: General{
+				DefaultCategory: "custom",
+				Editor:          "nano",
+				BannerStyle:     "stars",
+			},
+			expectError:   true,
+			errorContains: "banner style must be equals, dashes, minimal, none, or a template containing {name}",
+		},
 	}
 
 	for _, tt := range tests {
@@ -479,6 +565,109 @@ func TestValidateBackup(t *testing.T) {
 			expectError:   true,
 			errorContains: "invalid compression type",
 		},
+		{
+			name: "zstd compression type",
+			backup: Backup{
+				Directory:       "/safe/path",
+				MaxBackups:      10,
+				RetentionDays:   30,
+				CompressionType: "zstd",
+			},
+			expectError: false,
+		},
+		{
+			name: "compression level in range",
+			backup: Backup{
+				Directory:        "/safe/path",
+				MaxBackups:       10,
+				RetentionDays:    30,
+				CompressionType:  "gzip",
+				CompressionLevel: 9,
+			},
+			expectError: false,
+		},
+		{
+			name: "compression level too high",
+			backup: Backup{
+				Directory:        "/safe/path",
+				MaxBackups:       10,
+				RetentionDays:    30,
+				CompressionType:  "gzip",
+				CompressionLevel: 10,
+			},
+			expectError:   true,
+			errorContains: "compression level must be between 1 and 9",
+		},
+		{
+			name: "negative compression level",
+			backup: Backup{
+				Directory:        "/safe/path",
+				MaxBackups:       10,
+				RetentionDays:    30,
+				CompressionType:  "gzip",
+				CompressionLevel: -1,
+			},
+			expectError:   true,
+			errorContains: "compression level must be between 1 and 9",
+		},
+		{
+			name: "encryption with passphrase env",
+			backup: Backup{
+				Directory:       "/safe/path",
+				MaxBackups:      10,
+				RetentionDays:   30,
+				CompressionType: "gzip",
+				Encryption:      Encryption{Enabled: true, PassphraseEnv: "BACKUP_PASSPHRASE"},
+			},
+			expectError: false,
+		},
+		{
+			name: "encryption without passphrase env",
+			backup: Backup{
+				Directory:       "/safe/path",
+				MaxBackups:      10,
+				RetentionDays:   30,
+				CompressionType: "gzip",
+				Encryption:      Encryption{Enabled: true},
+			},
+			expectError:   true,
+			errorContains: "passphrase environment variable is required",
+		},
+		{
+			name: "invalid passphrase env name",
+			backup: Backup{
+				Directory:       "/safe/path",
+				MaxBackups:      10,
+				RetentionDays:   30,
+				CompressionType: "gzip",
+				Encryption:      Encryption{PassphraseEnv: "BAD-NAME"},
+			},
+			expectError:   true,
+			errorContains: "invalid environment variable name",
+		},
+		{
+			name: "daily directory layout",
+			backup: Backup{
+				Directory:       "/safe/path",
+				MaxBackups:      10,
+				RetentionDays:   30,
+				CompressionType: "gzip",
+				DirectoryLayout: "daily",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid directory layout",
+			backup: Backup{
+				Directory:       "/safe/path",
+				MaxBackups:      10,
+				RetentionDays:   30,
+				CompressionType: "gzip",
+				DirectoryLayout: "weekly",
+			},
+			expectError:   true,
+			errorContains: "directory layout must be flat, daily, or monthly",
+		},
 	}
 
 	for _, tt := range tests {
@@ -593,6 +782,125 @@ func TestValidateExport(t *testing.T) {
 	}
 }
 
+func TestValidateBlocklists(t *testing.T) {
+	tests := []struct {
+		name          string
+		blocklists    Blocklists
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name: "valid blocklists config",
+			blocklists: Blocklists{
+				URLs:           []string{"https://raw.githubusercontent.com/StevenBlack/hosts/master/hosts"},
+				Category:       "blocklist",
+				TimeoutSeconds: 30,
+			},
+			expectError: false,
+		},
+		{
+			name: "non-http url",
+			blocklists: Blocklists{
+				URLs:     []string{"file:///etc/passwd"},
+				Category: "blocklist",
+			},
+			expectError:   true,
+			errorContains: "blocklist URL must be an http or https URL",
+		},
+		{
+			name: "invalid category",
+			blocklists: Blocklists{
+				Category: "block list",
+			},
+			expectError:   true,
+			errorContains: "invalid category name format",
+		},
+		{
+			name: "negative timeout",
+			blocklists: Blocklists{
+				Category:       "blocklist",
+				TimeoutSeconds: -1,
+			},
+			expectError:   true,
+			errorContains: "timeout must be between 0 and 600 seconds",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Blocklists = tt.blocklists
+			validator := NewValidator()
+			err := validator.Validate(config)
+
+			if tt.expectError && err == nil {
+				t.Error("Expected validation error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+			if tt.expectError && err != nil && !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Expected error containing %q, got: %v", tt.errorContains, err)
+			}
+		})
+	}
+}
+
+func TestValidateAudit(t *testing.T) {
+	tests := []struct {
+		name          string
+		audit         Audit
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:  "valid audit config",
+			audit: Audit{Enabled: true, MinLevel: "warning", MaxLogSize: 1024 * 1024, MaxLogs: 3},
+		},
+		{
+			name:  "disabled with empty level",
+			audit: Audit{MaxLogSize: 1024 * 1024, MaxLogs: 3},
+		},
+		{
+			name:          "invalid level",
+			audit:         Audit{Enabled: true, MinLevel: "debug", MaxLogSize: 1024 * 1024, MaxLogs: 3},
+			expectError:   true,
+			errorContains: "min level must be info, warning, error, or critical",
+		},
+		{
+			name:          "log size too small",
+			audit:         Audit{Enabled: true, MaxLogSize: 100, MaxLogs: 3},
+			expectError:   true,
+			errorContains: "max log size must be between 1024 bytes and 1 GiB",
+		},
+		{
+			name:          "too many logs",
+			audit:         Audit{Enabled: true, MaxLogSize: 1024 * 1024, MaxLogs: 500},
+			expectError:   true,
+			errorContains: "max logs must be between 1 and 100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Audit = tt.audit
+			validator := NewValidator()
+			err := validator.Validate(config)
+
+			if tt.expectError && err == nil {
+				t.Error("Expected validation error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+			if tt.expectError && err != nil && !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Expected error containing %q, got: %v", tt.errorContains, err)
+			}
+		})
+	}
+}
+
 func TestHelperFunctions(t *testing.T) {
 	// Test isValidCategoryName
 	validCategoryNames := []string{"development", "test_category", "prod-env", "cat1"}
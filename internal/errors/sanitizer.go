@@ -2,7 +2,9 @@ package errors
 
 import (
 	"fmt"
+	"net"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -237,6 +239,31 @@ func sanitizeCommonPatterns(message string) string {
 	return message
 }
 
+// RedactHostnames replaces every occurrence of the given hostnames and IP
+// addresses in message with a placeholder. Longer values are replaced first
+// so a hostname is never partially redacted by one of its suffixes.
+func RedactHostnames(message string, values []string) string {
+	sorted := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			sorted = append(sorted, value)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return len(sorted[i]) > len(sorted[j])
+	})
+
+	for _, value := range sorted {
+		placeholder := "[hostname]"
+		if net.ParseIP(value) != nil {
+			placeholder = "[ip-address]"
+		}
+		message = strings.ReplaceAll(message, value, placeholder)
+	}
+
+	return message
+}
+
 // WrapWithSanitization wraps a function to automatically sanitize errors
 func WrapWithSanitization(fn func() error) error {
 	err := fn()
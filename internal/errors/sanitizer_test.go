@@ -323,6 +323,26 @@ func TestSanitizeUserInfo(t *testing.T) {
 	}
 }
 
+func TestRedactHostnames(t *testing.T) {
+	values := []string{"api.dev", "v2.api.dev", "192.168.1.10", "fd00::1", ""}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"failed to add api.dev", "failed to add [hostname]"},
+		{"v2.api.dev -> 192.168.1.10", "[hostname] -> [ip-address]"},
+		{"duplicate fd00::1 entry", "duplicate [ip-address] entry"},
+		{"nothing to redact", "nothing to redact"},
+	}
+
+	for _, tt := range tests {
+		if got := RedactHostnames(tt.input, values); got != tt.expected {
+			t.Errorf("RedactHostnames(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
 func TestSanitizeCommonPatterns(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -5,9 +5,17 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// StaleLockTimeout is how old a lock file must be before it is treated as
+// abandoned when its holder cannot be identified. Raise it when long-running
+// writes, such as imports of very large blocklists, legitimately hold the lock
+// for longer.
+var StaleLockTimeout = 5 * time.Minute
+
 // AtomicFileWriter provides atomic file writing with locking
 type AtomicFileWriter struct {
 	targetPath string
@@ -25,30 +33,21 @@ func NewAtomicFileWriter(targetPath string) (*AtomicFileWriter, error) {
 	// Create lock file first with O_EXCL for atomic creation
 	lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
 	if err != nil {
-		if os.IsExist(err) {
-			// Check if lock file is stale (older than 5 minutes)
-			if info, statErr := os.Stat(lockPath); statErr == nil {
-				age := time.Since(info.ModTime())
-				if age > 5*time.Minute {
-					// Attempt to clean up stale lock file
-					if rmErr := os.Remove(lockPath); rmErr == nil {
-						// Retry lock file creation
-						lockFile, err = os.OpenFile(lockPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
-						if err != nil {
-							return nil, fmt.Errorf("failed to create lock file after cleanup: %w", err)
-						}
-					} else {
-						return nil, fmt.Errorf("file is locked by another process (stale lock cleanup failed): %s", targetPath)
-					}
-				} else {
-					return nil, fmt.Errorf("file is locked by another process: %s", targetPath)
-				}
-			} else {
-				return nil, fmt.Errorf("file is locked by another process: %s", targetPath)
-			}
-		} else {
+		if !os.IsExist(err) {
 			return nil, fmt.Errorf("failed to create lock file: %w", err)
 		}
+		if !lockIsStale(lockPath) {
+			return nil, fmt.Errorf("file is locked by another process: %s", targetPath)
+		}
+		// Attempt to clean up stale lock file
+		if rmErr := os.Remove(lockPath); rmErr != nil {
+			return nil, fmt.Errorf("file is locked by another process (stale lock cleanup failed): %s", targetPath)
+		}
+		// Retry lock file creation
+		lockFile, err = os.OpenFile(lockPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create lock file after cleanup: %w", err)
+		}
 	}
 
 	// Write PID and timestamp to lock file for debugging and stale detection
@@ -99,6 +98,34 @@ func NewAtomicFileWriter(targetPath string) (*AtomicFileWriter, error) {
 	}, nil
 }
 
+// lockIsStale reports whether an existing lock file may be removed. A lock
+// whose recorded PID is still running is never stale, and one whose PID has
+// exited always is; otherwise the lock's age is compared to StaleLockTimeout.
+func lockIsStale(lockPath string) bool {
+	info, err := os.Stat(lockPath)
+	if err != nil {
+		return false
+	}
+	if pid, ok := readLockPID(lockPath); ok {
+		return !platformProcessAlive(pid)
+	}
+	return time.Since(info.ModTime()) > StaleLockTimeout
+}
+
+// readLockPID returns the PID recorded on the first line of a lock file
+func readLockPID(lockPath string) (int, bool) {
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return 0, false
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, true
+}
+
 // Write writes data to the temporary file
 func (aw *AtomicFileWriter) Write(data []byte) (int, error) {
 	if aw.tempFile == nil {
@@ -137,6 +164,11 @@ func (aw *AtomicFileWriter) Commit() error {
 		return fmt.Errorf("failed to commit file: %w", err)
 	}
 
+	// Sync the parent directory so the rename itself survives a crash
+	if err := platformSyncDir(filepath.Dir(aw.targetPath)); err != nil {
+		return fmt.Errorf("failed to sync directory: %w", err)
+	}
+
 	return nil
 }
 
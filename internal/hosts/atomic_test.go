@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -668,6 +669,96 @@ func TestStaleLockCleanup(t *testing.T) {
 	}
 }
 
+// TestLiveLockNotCleaned tests that a lock held by a running process is kept
+// even when it is older than StaleLockTimeout
+func TestLiveLockNotCleaned(t *testing.T) {
+	tmpDir := createTestDir(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	testFile := filepath.Join(tmpDir, "test.txt")
+	lockFile := testFile + ".lock"
+
+	err := os.WriteFile(lockFile, []byte(fmt.Sprintf("%d\n%s\n", os.Getpid(), time.Now().Format(time.RFC3339))), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldTime := time.Now().Add(-10 * time.Minute)
+	if err := os.Chtimes(lockFile, oldTime, oldTime); err != nil {
+		t.Fatal(err)
+	}
+
+	writer, err := NewAtomicFileWriter(testFile)
+	if err == nil {
+		_ = writer.Close()
+		t.Fatal("lock held by a live process should not be cleaned up")
+	}
+	if _, err := os.Stat(lockFile); err != nil {
+		t.Errorf("live lock file should still exist: %v", err)
+	}
+}
+
+// TestDeadPIDLockCleaned tests that a lock whose holder has exited is cleaned
+// up without waiting for StaleLockTimeout
+func TestDeadPIDLockCleaned(t *testing.T) {
+	tmpDir := createTestDir(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	// Run a short-lived process to obtain a PID that is no longer running
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to run helper process: %v", err)
+	}
+	deadPID := cmd.Process.Pid
+
+	testFile := filepath.Join(tmpDir, "test.txt")
+	lockFile := testFile + ".lock"
+	if err := os.WriteFile(lockFile, []byte(fmt.Sprintf("%d\n", deadPID)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	writer, err := NewAtomicFileWriter(testFile)
+	if err != nil {
+		t.Fatalf("lock held by an exited process should be cleaned up: %v", err)
+	}
+	defer func() { _ = writer.Close() }()
+
+	if pid, ok := readLockPID(lockFile); !ok || pid != os.Getpid() {
+		t.Errorf("lock PID = %d, %v; want %d", pid, ok, os.Getpid())
+	}
+}
+
+// TestStaleLockTimeout tests that StaleLockTimeout controls age-based cleanup
+func TestStaleLockTimeout(t *testing.T) {
+	tmpDir := createTestDir(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	orig := StaleLockTimeout
+	defer func() { StaleLockTimeout = orig }()
+
+	testFile := filepath.Join(tmpDir, "test.txt")
+	lockFile := testFile + ".lock"
+	if err := os.WriteFile(lockFile, []byte("old lock"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	oldTime := time.Now().Add(-10 * time.Minute)
+	if err := os.Chtimes(lockFile, oldTime, oldTime); err != nil {
+		t.Fatal(err)
+	}
+
+	StaleLockTimeout = time.Hour
+	if writer, err := NewAtomicFileWriter(testFile); err == nil {
+		_ = writer.Close()
+		t.Fatal("lock younger than StaleLockTimeout should not be cleaned up")
+	}
+
+	StaleLockTimeout = time.Minute
+	writer, err := NewAtomicFileWriter(testFile)
+	if err != nil {
+		t.Fatalf("lock older than StaleLockTimeout should be cleaned up: %v", err)
+	}
+	_ = writer.Close()
+}
+
 // TestAtomicWritePreservesPermissions tests that atomic writes preserve file permissions
 func TestAtomicWritePreservesPermissions(t *testing.T) {
 	if runtime.GOOS == "windows" {
@@ -725,6 +816,37 @@ func TestAtomicWritePreservesPermissions(t *testing.T) {
 }
 
 // Benchmark tests
+// TestPlatformSyncDir tests that directory syncing after commit succeeds
+func TestPlatformSyncDir(t *testing.T) {
+	tmpDir := createTestDir(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := platformSyncDir(tmpDir); err != nil {
+		t.Fatalf("platformSyncDir() error = %v", err)
+	}
+
+	if runtime.GOOS != "windows" {
+		if err := platformSyncDir(filepath.Join(tmpDir, "missing")); err == nil {
+			t.Error("platformSyncDir() on a missing directory should fail")
+		}
+	}
+
+	targetPath := filepath.Join(tmpDir, "hosts")
+	if err := AtomicWrite(targetPath, func(w io.Writer) error {
+		_, err := io.WriteString(w, "127.0.0.1 localhost\n")
+		return err
+	}); err != nil {
+		t.Fatalf("AtomicWrite() error = %v", err)
+	}
+	data, err := os.ReadFile(targetPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "127.0.0.1 localhost\n" {
+		t.Errorf("content = %q", string(data))
+	}
+}
+
 func BenchmarkAtomicWrite(b *testing.B) {
 	tmpDir := createTestDirB(b)
 	defer func() { _ = os.RemoveAll(tmpDir) }()
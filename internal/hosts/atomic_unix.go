@@ -3,6 +3,7 @@
 package hosts
 
 import (
+	"os"
 	"syscall"
 )
 
@@ -20,3 +21,19 @@ func platformReleaseLock(fd int) error {
 func platformAcquireSharedLock(fd int) error {
 	return syscall.Flock(fd, syscall.LOCK_SH|syscall.LOCK_NB)
 }
+
+// platformSyncDir fsyncs a directory so that renames into it are durable
+func platformSyncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = d.Close() }()
+	return d.Sync()
+}
+
+// platformProcessAlive reports whether a process with the given PID exists
+func platformProcessAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
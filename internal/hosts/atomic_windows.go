@@ -3,6 +3,7 @@
 package hosts
 
 import (
+	"os"
 	"syscall"
 	"unsafe"
 )
@@ -76,3 +77,19 @@ func platformAcquireSharedLock(fd int) error {
 	}
 	return nil
 }
+
+// platformSyncDir is a no-op on Windows, where directories cannot be fsynced
+// and NTFS journals the rename itself
+func platformSyncDir(dir string) error {
+	return nil
+}
+
+// platformProcessAlive reports whether a process with the given PID exists
+func platformProcessAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = p.Release()
+	return true
+}
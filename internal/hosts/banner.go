@@ -0,0 +1,90 @@
+package hosts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BannerStyle controls how categories are marked in the hosts file and in
+// hosts exports, and is the only setting that does. The equals and dashes
+// styles, and any template containing {name}, write the "# @category" marker
+// followed by a banner; {name} is replaced with the upper-cased category name.
+type BannerStyle string
+
+const (
+	BannerEquals BannerStyle = "equals"
+	BannerDashes BannerStyle = "dashes"
+	// BannerMinimal writes the "# @category" marker without a banner
+	BannerMinimal BannerStyle = "minimal"
+	// BannerNone writes no category lines. Category membership is kept in a
+	// sidecar file next to the hosts file instead (see CategorySidecarPath).
+	BannerNone BannerStyle = "none"
+
+	bannerPlaceholder = "{name}"
+)
+
+// ParseBannerStyle validates a configured banner style. An empty value
+// selects the default equals style.
+func ParseBannerStyle(style string) (BannerStyle, error) {
+	switch BannerStyle(style) {
+	case "":
+		return BannerEquals, nil
+	case BannerEquals, BannerDashes, BannerMinimal, BannerNone:
+		return BannerStyle(style), nil
+	}
+
+	if !strings.Contains(style, bannerPlaceholder) {
+		return "", fmt.Errorf("invalid banner style %q (must be equals, dashes, minimal, none, or a template containing %s)", style, bannerPlaceholder)
+	}
+	if strings.ContainsAny(style, "\r\n") {
+		return "", fmt.Errorf("invalid banner style %q: template must be a single line", style)
+	}
+	return BannerStyle(style), nil
+}
+
+// SetBannerStyle selects how Write marks categories
+func (hf *HostsFile) SetBannerStyle(style BannerStyle) {
+	hf.bannerStyle = style
+}
+
+// renderBanner returns the banner line for a category, or "" if none is written
+func (style BannerStyle) renderBanner(categoryName string) string {
+	name := strings.ToUpper(categoryName)
+
+	switch style {
+	case "", BannerEquals:
+		return fmt.Sprintf("# =============== %s ===============", name)
+	case BannerDashes:
+		return fmt.Sprintf("# --------------- %s ---------------", name)
+	case BannerMinimal, BannerNone:
+		return ""
+	}
+
+	banner := strings.ReplaceAll(string(style), bannerPlaceholder, name)
+	// Keep custom banners commented out so other tools ignore them
+	if !strings.HasPrefix(strings.TrimSpace(banner), "#") {
+		banner = "# " + banner
+	}
+	return banner
+}
+
+// SetBannerStyle tells the parser which banner template to recognize under
+// category markers, so custom banners written by Write are not read back as
+// comments. The equals and dashes banners are always recognized.
+func (p *Parser) SetBannerStyle(style BannerStyle) {
+	p.bannerStyle = style
+}
+
+// isCategoryBanner reports whether a line directly after a category marker is
+// exactly the banner Write renders for that category, in the equals or dashes
+// style or the parser's template. Any other comment, even one mentioning the
+// category, is kept.
+func (p *Parser) isCategoryBanner(line, categoryName string) bool {
+	line = strings.TrimSpace(line)
+	for _, style := range []BannerStyle{BannerEquals, BannerDashes, p.bannerStyle} {
+		if banner := style.renderBanner(categoryName); banner != "" && line == strings.TrimSpace(banner) {
+			return true
+		}
+	}
+	return false
+}
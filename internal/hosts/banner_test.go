@@ -0,0 +1,154 @@
+package hosts
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseBannerStyle(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    BannerStyle
+		expectError bool
+	}{
+		{"", BannerEquals, false},
+		{"equals", BannerEquals, false},
+		{"dashes", BannerDashes, false},
+		{"minimal", BannerMinimal, false},
+		{"none", BannerNone, false},
+		{"# ### {name} ###", BannerStyle("# ### {name} ###"), false},
+		{"stars", "", true},
+		{"# {name}\n127.0.0.1 evil.com", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseBannerStyle(tt.input)
+		if (err != nil) != tt.expectError {
+			t.Errorf("ParseBannerStyle(%q) error = %v, expectError %v", tt.input, err, tt.expectError)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("ParseBannerStyle(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestBannerStyleRoundTrip(t *testing.T) {
+	tests := []struct {
+		style  BannerStyle
+		banner string
+	}{
+		{BannerEquals, "# =============== DEVELOPMENT ==============="},
+		{BannerDashes, "# --------------- DEVELOPMENT ---------------"},
+		{BannerMinimal, ""},
+		{BannerStyle("### {name} ###"), "### DEVELOPMENT ###"},
+		{BannerStyle("** {name} **"), "# ** DEVELOPMENT **"},
+		// A template that looks like a disabled entry must not be parsed as one
+		{BannerStyle("# 10.0.0.1 {name}"), "# 10.0.0.1 DEVELOPMENT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			hostsFile, err := NewParser("").ParseReader(strings.NewReader(`127.0.0.1 localhost
+
+# @category development
+192.168.1.10 api.dev
+# 192.168.1.11 web.dev
+
+# @category staging
+10.0.0.5 db.stage
+`))
+			if err != nil {
+				t.Fatalf("ParseReader() error = %v", err)
+			}
+
+			path := filepath.Join(t.TempDir(), "hosts")
+			hostsFile.SetBannerStyle(tt.style)
+			if err := hostsFile.Write(path); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			written := string(data)
+
+			if tt.banner != "" && !strings.Contains(written, "# @category development\n"+tt.banner+"\n") {
+				t.Errorf("Expected banner %q after category marker, got:\n%s", tt.banner, written)
+			}
+			if tt.style == BannerMinimal && !strings.Contains(written, "# @category development\n192.168.1.10 api.dev\n") {
+				t.Errorf("Expected no banner with style minimal, got:\n%s", written)
+			}
+
+			parser := NewParser(path)
+			parser.SetBannerStyle(tt.style)
+			reparsed, err := parser.Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			// Rewriting keeps a single banner rather than reading it back as a comment
+			reparsed.SetBannerStyle(tt.style)
+			if err := reparsed.Write(path); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if rewritten, err := os.ReadFile(path); err != nil || string(rewritten) != written {
+				t.Errorf("Rewriting changed the file (err %v):\n%s\nwant:\n%s", err, rewritten, written)
+			}
+
+			var names []string
+			for _, category := range reparsed.Categories {
+				names = append(names, category.Name)
+			}
+			if strings.Join(names, ",") != "default,development,staging" {
+				t.Fatalf("Expected categories default,development,staging, got %v", names)
+			}
+			if got := len(reparsed.GetCategory("development").Entries); got != 2 {
+				t.Errorf("Expected 2 development entries, got %d", got)
+			}
+			if got := len(reparsed.GetCategory("staging").Entries); got != 1 {
+				t.Errorf("Expected 1 staging entry, got %d", got)
+			}
+		})
+	}
+}
+
+func TestCategoryCommentMentioningNameSurvives(t *testing.T) {
+	content := `127.0.0.1 localhost
+
+# @category dev
+# dev box for alice
+192.168.1.10 alice.dev
+`
+	for _, style := range []BannerStyle{BannerEquals, BannerNone, BannerStyle("### {name} ###")} {
+		t.Run(string(style), func(t *testing.T) {
+			parser := NewParser("")
+			parser.SetBannerStyle(style)
+			hostsFile, err := parser.ParseReader(strings.NewReader(content))
+			if err != nil {
+				t.Fatalf("ParseReader() error = %v", err)
+			}
+			entry := hostsFile.FindEntryByHostname("alice.dev")
+			if len(entry) != 1 || !slices.Equal(entry[0].LeadingComments, []string{"# dev box for alice"}) {
+				t.Fatalf("Expected the comment kept on alice.dev, got %+v", entry)
+			}
+
+			path := filepath.Join(t.TempDir(), "hosts")
+			hostsFile.SetBannerStyle(style)
+			if err := hostsFile.Write(path); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if !strings.Contains(string(data), "# dev box for alice\n192.168.1.10 alice.dev\n") {
+				t.Errorf("Comment mentioning the category was lost:\n%s", data)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package hosts
+
+import "strings"
+
+// RejectedEntry is an entry AddEntries could not add and the reason
+type RejectedEntry struct {
+	Entry Entry
+	Err   error
+}
+
+// AddSummary counts what AddEntries did with the entries it was given
+type AddSummary struct {
+	Added int
+	// Duplicates were skipped because every hostname was already mapped to
+	// the entry's IP, by the file or an earlier entry of the same call
+	Duplicates int
+	Rejected   []RejectedEntry
+}
+
+// AddEntries adds entries in bulk, validating hostnames under the file's
+// hostname mode. See AddEntriesWithMode.
+func (hf *HostsFile) AddEntries(entries []Entry) AddSummary {
+	return hf.AddEntriesWithMode(entries, hf.hostnameMode)
+}
+
+// AddEntriesWithMode validates and appends entries as AddEntryWithMode does,
+// but under a single lock and with one pass over the existing entries, so
+// large imports do not rescan the file for every entry. Invalid entries are
+// rejected and duplicates skipped instead of stopping the whole batch.
+func (hf *HostsFile) AddEntriesWithMode(entries []Entry, mode HostnameValidationMode) AddSummary {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.invalidateIndex()
+
+	categories := make(map[string]int, len(hf.Categories))
+	mapped := make(map[string]map[string]bool)
+	index := func(entry Entry) {
+		ip := NormalizeIP(entry.IP)
+		for _, hostname := range entry.Hostnames {
+			hostname = strings.ToLower(hostname)
+			if mapped[hostname] == nil {
+				mapped[hostname] = make(map[string]bool)
+			}
+			mapped[hostname][ip] = true
+		}
+	}
+	for i, category := range hf.Categories {
+		if _, ok := categories[category.Name]; !ok {
+			categories[category.Name] = i
+		}
+		for _, entry := range category.Entries {
+			index(entry)
+		}
+	}
+
+	var summary AddSummary
+	for _, original := range entries {
+		entry, err := hf.ConvertIDNs(original)
+		if err == nil {
+			err = ValidateEntryWithMode(entry, mode)
+		}
+		if err != nil {
+			summary.Rejected = append(summary.Rejected, RejectedEntry{Entry: original, Err: err})
+			continue
+		}
+		if hf.normalizeIPs {
+			entry.IP = CanonicalIP(entry.IP)
+		}
+
+		ip := NormalizeIP(entry.IP)
+		duplicate := true
+		for _, hostname := range entry.Hostnames {
+			if !mapped[strings.ToLower(hostname)][ip] {
+				duplicate = false
+				break
+			}
+		}
+		if duplicate {
+			summary.Duplicates++
+			continue
+		}
+
+		if entry.Category == "" {
+			entry.Category = CategoryDefault
+		}
+		i, ok := categories[entry.Category]
+		if !ok {
+			hf.Categories = append(hf.Categories, Category{Name: entry.Category, Enabled: true})
+			i = len(hf.Categories) - 1
+			categories[entry.Category] = i
+		}
+		hf.Categories[i].Entries = append(hf.Categories[i].Entries, entry)
+		index(entry)
+		summary.Added++
+	}
+
+	return summary
+}
@@ -0,0 +1,75 @@
+package hosts
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAddEntries(t *testing.T) {
+	hf, err := NewParser("").ParseReader(strings.NewReader("# @category development\n192.168.1.10 api.dev\n"))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	summary := hf.AddEntries([]Entry{
+		{IP: "192.168.1.10", Hostnames: []string{"API.dev"}, Category: "development", Enabled: true},
+		{IP: "192.168.1.11", Hostnames: []string{"web.dev"}, Category: "development", Enabled: true},
+		{IP: "192.168.1.11", Hostnames: []string{"web.dev"}, Category: "staging", Enabled: true},
+		{IP: "192.168.1.12", Hostnames: []string{"ads_tracker.dev"}, Enabled: true},
+		{IP: "not-an-ip", Hostnames: []string{"db.dev"}, Enabled: true},
+	})
+
+	if summary.Added != 1 || summary.Duplicates != 2 || len(summary.Rejected) != 2 {
+		t.Fatalf("AddEntries() = %+v, want 1 added, 2 duplicates, 2 rejected", summary)
+	}
+	if summary.Rejected[0].Entry.Hostnames[0] != "ads_tracker.dev" || summary.Rejected[1].Err == nil {
+		t.Errorf("AddEntries() rejected = %+v", summary.Rejected)
+	}
+	if entries := hf.FindEntryByHostname("web.dev"); len(entries) != 1 || entries[0].Category != "development" {
+		t.Errorf("web.dev entries = %+v", entries)
+	}
+	if hf.GetCategory("staging") != nil {
+		t.Error("A category should not be created for a skipped duplicate")
+	}
+
+	hf.SetHostnameMode(HostnameUnderscores)
+	summary = hf.AddEntries([]Entry{{IP: "192.168.1.12", Hostnames: []string{"ads_tracker.dev"}, Enabled: true}})
+	if summary.Added != 1 {
+		t.Errorf("AddEntries() under HostnameUnderscores = %+v", summary)
+	}
+	if entries := hf.FindEntryByHostname("ads_tracker.dev"); len(entries) != 1 || entries[0].Category != CategoryDefault {
+		t.Errorf("ads_tracker.dev entries = %+v", entries)
+	}
+}
+
+func bulkEntries(n int) []Entry {
+	entries := make([]Entry, n)
+	for i := range entries {
+		entries[i] = Entry{
+			IP:        fmt.Sprintf("10.%d.%d.%d", i>>16&255, i>>8&255, i&255),
+			Hostnames: []string{fmt.Sprintf("host%d.example.com", i)},
+			Category:  CategoryCustom,
+			Enabled:   true,
+		}
+	}
+	return entries
+}
+
+func BenchmarkAddEntries(b *testing.B) {
+	entries := bulkEntries(5000)
+	for b.Loop() {
+		hf := &HostsFile{}
+		hf.AddEntries(entries)
+	}
+}
+
+func BenchmarkAddEntryRepeated(b *testing.B) {
+	entries := bulkEntries(5000)
+	for b.Loop() {
+		hf := &HostsFile{}
+		for _, entry := range entries {
+			_ = hf.AddEntry(entry)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package hosts
+
+import (
+	"net"
+	"strings"
+)
+
+// CanonicalIP returns ip in the form net.IP.String produces, so IPv6
+// addresses are lower-cased and compressed (2001:DB8::0001 -> 2001:db8::1).
+// IPv4 leading zeros are stripped first and zones are kept. IPv4-mapped IPv6
+// addresses and anything that does not parse are returned unchanged.
+func CanonicalIP(ip string) string {
+	addr, zone := SplitZone(ip)
+	addr = NormalizeIP(addr)
+
+	parsed := net.ParseIP(addr)
+	if parsed == nil || (strings.Contains(addr, ":") && parsed.To4() != nil) {
+		return ip
+	}
+
+	if zone != "" {
+		return parsed.String() + "%" + zone
+	}
+	return parsed.String()
+}
+
+// SetNormalizeIPs makes AddEntry and Write canonicalize entry IPs with
+// CanonicalIP. It is off by default so files round-trip exactly as typed.
+func (hf *HostsFile) SetNormalizeIPs(normalize bool) {
+	hf.normalizeIPs = normalize
+}
+
+// canonicalizeIPs rewrites every managed entry's IP in canonical form.
+// Read-only entries are left as they were read.
+func (hf *HostsFile) canonicalizeIPs() {
+	hf.invalidateIndex()
+	for i := range hf.Categories {
+		for j := range hf.Categories[i].Entries {
+			entry := &hf.Categories[i].Entries[j]
+			if !entry.ReadOnly {
+				entry.IP = CanonicalIP(entry.IP)
+			}
+		}
+	}
+}
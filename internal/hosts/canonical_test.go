@@ -0,0 +1,83 @@
+package hosts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalIP(t *testing.T) {
+	tests := []struct {
+		ip       string
+		expected string
+	}{
+		{"2001:DB8::0001", "2001:db8::1"},
+		{"2001:0db8:0000:0000:0000:0000:0000:0001", "2001:db8::1"},
+		{"FE80::1%eth0", "fe80::1%eth0"},
+		{"192.168.001.010", "192.168.1.10"},
+		{"127.0.0.1", "127.0.0.1"},
+		{"::FFFF:192.0.2.1", "::FFFF:192.0.2.1"},
+		{"not-an-ip", "not-an-ip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := CanonicalIP(tt.ip); got != tt.expected {
+				t.Errorf("CanonicalIP(%q) = %q, want %q", tt.ip, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeIPsOnWrite(t *testing.T) {
+	const content = `# @category development
+2001:DB8::0001 v6.dev
+10.0.0.1 api.dev
+`
+
+	tests := []struct {
+		name      string
+		normalize bool
+		written   []string
+	}{
+		{"off keeps IPs as typed", false, []string{"2001:DB8::0001 v6.dev\n", "2001:DB8::00AA new.dev\n"}},
+		{"on canonicalizes IPs", true, []string{"2001:db8::1 v6.dev\n", "2001:db8::aa new.dev\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hostsFile, err := NewParser("").ParseReader(strings.NewReader(content))
+			if err != nil {
+				t.Fatalf("ParseReader() error = %v", err)
+			}
+			hostsFile.SetNormalizeIPs(tt.normalize)
+
+			entry := Entry{IP: "2001:DB8::00AA", Hostnames: []string{"new.dev"}, Category: "development", Enabled: true}
+			if err := hostsFile.AddEntry(entry); err != nil {
+				t.Fatalf("AddEntry() error = %v", err)
+			}
+			added := hostsFile.FindEntries("new.dev")
+			if len(added) != 1 {
+				t.Fatalf("FindEntries(new.dev) = %d entries, want 1", len(added))
+			}
+			if wantIP := strings.Fields(tt.written[1])[0]; added[0].IP != wantIP {
+				t.Errorf("AddEntry stored IP %q, want %q", added[0].IP, wantIP)
+			}
+
+			path := filepath.Join(t.TempDir(), "hosts")
+			if err := hostsFile.Write(path); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			for _, line := range append(tt.written, "10.0.0.1 api.dev\n") {
+				if !strings.Contains(string(data), line) {
+					t.Errorf("Expected %q in written file, got:\n%s", line, data)
+				}
+			}
+		})
+	}
+}
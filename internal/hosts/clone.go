@@ -0,0 +1,51 @@
+package hosts
+
+import "slices"
+
+// Clone returns a deep copy of hf, so categories, entries, hostnames, tags,
+// comments, header and footer can be changed on the copy without touching hf
+func (hf *HostsFile) Clone() *HostsFile {
+	clone := HostsFile{
+		Header:       slices.Clone(hf.Header),
+		Footer:       slices.Clone(hf.Footer),
+		Modified:     hf.Modified,
+		FilePath:     hf.FilePath,
+		ParseIssues:  slices.Clone(hf.ParseIssues),
+		bannerStyle:  hf.bannerStyle,
+		normalizeIPs: hf.normalizeIPs,
+		idnMode:      hf.idnMode,
+		hostnameMode: hf.hostnameMode,
+	}
+
+	if hf.managed != nil {
+		managed := *hf.managed
+		clone.managed = &managed
+	}
+
+	if hf.Categories != nil {
+		clone.Categories = make([]Category, len(hf.Categories))
+	}
+	for i, category := range hf.Categories {
+		clone.Categories[i] = category.clone()
+	}
+
+	return &clone
+}
+
+func (c Category) clone() Category {
+	if c.Entries != nil {
+		entries := make([]Entry, len(c.Entries))
+		for i, entry := range c.Entries {
+			entries[i] = entry.clone()
+		}
+		c.Entries = entries
+	}
+	return c
+}
+
+func (e Entry) clone() Entry {
+	e.Hostnames = slices.Clone(e.Hostnames)
+	e.Tags = slices.Clone(e.Tags)
+	e.LeadingComments = slices.Clone(e.LeadingComments)
+	return e
+}
@@ -0,0 +1,47 @@
+package hosts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	source, err := NewParser("").ParseReader(strings.NewReader(`# local header
+127.0.0.1 localhost
+
+# @category development
+192.168.1.10 api.dev web.dev # API @tags web
+`))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	source.Footer = []string{"# footer"}
+
+	clone := source.Clone()
+
+	clone.Header[0] = "# changed"
+	clone.Footer[0] = "# changed"
+	dev := clone.GetCategory("development")
+	dev.Enabled = false
+	dev.Entries[0].Enabled = false
+	dev.Entries[0].Hostnames[0] = "changed.dev"
+	dev.Entries[0].Tags[0] = "changed"
+	dev.Entries = append(dev.Entries, Entry{IP: "10.0.0.1", Hostnames: []string{"new.dev"}})
+	clone.Categories = append(clone.Categories, Category{Name: "extra"})
+
+	if source.Header[0] != "# local header" || source.Footer[0] != "# footer" {
+		t.Errorf("Header/footer changed on source: %v %v", source.Header, source.Footer)
+	}
+	if len(source.Categories) != 2 {
+		t.Errorf("Expected source to keep 2 categories, got %d", len(source.Categories))
+	}
+
+	original := source.GetCategory("development")
+	if !original.Enabled || len(original.Entries) != 1 {
+		t.Fatalf("Development category changed on source: %+v", original)
+	}
+	entry := original.Entries[0]
+	if !entry.Enabled || entry.Hostnames[0] != "api.dev" || entry.Tags[0] != "web" {
+		t.Errorf("Entry changed on source: %+v", entry)
+	}
+}
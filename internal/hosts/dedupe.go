@@ -0,0 +1,113 @@
+package hosts
+
+import (
+	"math"
+	"sort"
+)
+
+// HostnameOccurrence records one place a hostname is declared
+type HostnameOccurrence struct {
+	Entry   *Entry
+	LineNum int
+}
+
+// FindDuplicateHostnames returns every hostname declared more than once,
+// mapped to its occurrences in file order. A hostname repeated within a
+// single entry is reported once per repetition. The entry pointers are only
+// valid until the hosts file is next modified.
+func (hf *HostsFile) FindDuplicateHostnames() map[string][]HostnameOccurrence {
+	occurrences := make(map[string][]HostnameOccurrence)
+	for i := range hf.Categories {
+		for j := range hf.Categories[i].Entries {
+			entry := &hf.Categories[i].Entries[j]
+			for _, hostname := range entry.Hostnames {
+				occurrences[hostname] = append(occurrences[hostname], HostnameOccurrence{
+					Entry:   entry,
+					LineNum: entry.LineNum,
+				})
+			}
+		}
+	}
+
+	for hostname, found := range occurrences {
+		if len(found) < 2 {
+			delete(occurrences, hostname)
+			continue
+		}
+		// Entries added in memory have no line number and sort last
+		sort.SliceStable(found, func(i, j int) bool {
+			return lineOrder(found[i].LineNum) < lineOrder(found[j].LineNum)
+		})
+	}
+	return occurrences
+}
+
+// RemoveDuplicateHostnames keeps the first enabled occurrence of each
+// duplicated hostname (or the first occurrence if none are enabled) and
+// removes the rest. Entries left without hostnames are removed. When
+// protectReadOnly is set, read-only entries are never modified. It returns
+// the number of hostname declarations removed.
+func (hf *HostsFile) RemoveDuplicateHostnames(protectReadOnly bool) int {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.invalidateIndex()
+
+	duplicates := hf.FindDuplicateHostnames()
+	if len(duplicates) == 0 {
+		return 0
+	}
+
+	keep := make(map[string]*Entry, len(duplicates))
+	for hostname, found := range duplicates {
+		keep[hostname] = found[0].Entry
+		for _, occurrence := range found {
+			if occurrence.Entry.Enabled {
+				keep[hostname] = occurrence.Entry
+				break
+			}
+		}
+	}
+
+	removed := 0
+	for i := range hf.Categories {
+		for j := range hf.Categories[i].Entries {
+			entry := &hf.Categories[i].Entries[j]
+			if protectReadOnly && entry.ReadOnly {
+				continue
+			}
+
+			seen := make(map[string]bool, len(entry.Hostnames))
+			hostnames := make([]string, 0, len(entry.Hostnames))
+			for _, hostname := range entry.Hostnames {
+				keeper, duplicated := keep[hostname]
+				if duplicated && (keeper != entry || seen[hostname]) {
+					removed++
+					continue
+				}
+				seen[hostname] = true
+				hostnames = append(hostnames, hostname)
+			}
+			entry.Hostnames = hostnames
+		}
+	}
+
+	// Drop entries emptied above; done separately so the keeper pointers stay valid while filtering
+	for i := range hf.Categories {
+		entries := hf.Categories[i].Entries[:0]
+		for _, entry := range hf.Categories[i].Entries {
+			if len(entry.Hostnames) > 0 {
+				entries = append(entries, entry)
+			}
+		}
+		hf.Categories[i].Entries = entries
+	}
+
+	return removed
+}
+
+func lineOrder(lineNum int) int {
+	if lineNum == 0 {
+		return math.MaxInt
+	}
+	return lineNum
+}
@@ -0,0 +1,139 @@
+package hosts
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func parseDedupeFixture(t *testing.T, content string) *HostsFile {
+	t.Helper()
+	hostsPath := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(hostsPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test hosts file: %v", err)
+	}
+	hostsFile, err := NewParser(hostsPath).Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse test hosts file: %v", err)
+	}
+	return hostsFile
+}
+
+func TestFindDuplicateHostnames(t *testing.T) {
+	hostsFile := parseDedupeFixture(t, `127.0.0.1 localhost
+192.168.1.10 api.dev web.dev
+192.168.1.11 api.dev
+192.168.1.12 db.dev db.dev
+`)
+
+	duplicates := hostsFile.FindDuplicateHostnames()
+	if len(duplicates) != 2 {
+		t.Fatalf("Expected 2 duplicated hostnames, got %d: %v", len(duplicates), duplicates)
+	}
+
+	api := duplicates["api.dev"]
+	if len(api) != 2 || api[0].LineNum != 2 || api[1].LineNum != 3 {
+		t.Errorf("Unexpected occurrences for api.dev: %+v", api)
+	}
+	if len(duplicates["db.dev"]) != 2 {
+		t.Errorf("Expected hostname repeated within one entry to be reported, got %+v", duplicates["db.dev"])
+	}
+	if _, ok := duplicates["web.dev"]; ok {
+		t.Error("Expected unique hostname not to be reported")
+	}
+}
+
+func TestRemoveDuplicateHostnames(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		protectReadOnly bool
+		ignore          []string
+		expectedRemoved int
+		expected        map[string][]string // IP -> remaining hostnames
+	}{
+		{
+			name: "keeps first occurrence",
+			content: `192.168.1.10 api.dev web.dev
+192.168.1.11 api.dev
+`,
+			expectedRemoved: 1,
+			expected: map[string][]string{
+				"192.168.1.10": {"api.dev", "web.dev"},
+			},
+		},
+		{
+			name: "prefers enabled over earlier disabled",
+			content: `# 192.168.1.10 api.dev
+192.168.1.11 api.dev
+`,
+			expectedRemoved: 1,
+			expected: map[string][]string{
+				"192.168.1.11": {"api.dev"},
+			},
+		},
+		{
+			name: "duplicate within single entry",
+			content: `192.168.1.12 db.dev cache.dev db.dev
+`,
+			expectedRemoved: 1,
+			expected: map[string][]string{
+				"192.168.1.12": {"db.dev", "cache.dev"},
+			},
+		},
+		{
+			name: "read-only entries are left alone",
+			content: `192.168.1.10 api.dev
+192.168.1.11 api.dev
+`,
+			protectReadOnly: true,
+			ignore:          []string{"api.dev"},
+			expectedRemoved: 0,
+			expected: map[string][]string{
+				"192.168.1.10": {"api.dev"},
+				"192.168.1.11": {"api.dev"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hostsPath := filepath.Join(t.TempDir(), "hosts")
+			if err := os.WriteFile(hostsPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test hosts file: %v", err)
+			}
+			parser := NewParser(hostsPath)
+			if tt.ignore != nil {
+				ignore, err := NewIgnoreList(tt.ignore)
+				if err != nil {
+					t.Fatalf("Failed to build ignore list: %v", err)
+				}
+				parser.SetIgnoreList(ignore)
+			}
+			hostsFile, err := parser.Parse()
+			if err != nil {
+				t.Fatalf("Failed to parse test hosts file: %v", err)
+			}
+
+			if removed := hostsFile.RemoveDuplicateHostnames(tt.protectReadOnly); removed != tt.expectedRemoved {
+				t.Errorf("RemoveDuplicateHostnames() removed %d, want %d", removed, tt.expectedRemoved)
+			}
+
+			remaining := make(map[string][]string)
+			for _, category := range hostsFile.Categories {
+				for _, entry := range category.Entries {
+					remaining[entry.IP] = entry.Hostnames
+				}
+			}
+			if len(remaining) != len(tt.expected) {
+				t.Fatalf("Expected %d entries to remain, got %v", len(tt.expected), remaining)
+			}
+			for ip, hostnames := range tt.expected {
+				if !slices.Equal(remaining[ip], hostnames) {
+					t.Errorf("Entry %s: got hostnames %v, want %v", ip, remaining[ip], hostnames)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package hosts
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ChangeKind describes how a hostname differs between two hosts files
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeIP       ChangeKind = "ip-changed"
+	ChangeEnabled  ChangeKind = "enabled"
+	ChangeDisabled ChangeKind = "disabled"
+	ChangeMoved    ChangeKind = "moved"
+)
+
+// EntryChange is a single per-hostname difference. Old is unset for added
+// hostnames and New is unset for removed ones.
+type EntryChange struct {
+	Kind     ChangeKind
+	Hostname string
+	Category string
+	Old      Entry
+	New      Entry
+}
+
+// DiffHostsFiles compares two hosts files hostname by hostname and returns
+// the changes that turn from into to, ordered by category then hostname.
+// When a hostname is declared more than once, its first declaration is used.
+func DiffHostsFiles(from, to *HostsFile) []EntryChange {
+	oldEntries := firstEntryByHostname(from)
+	newEntries := firstEntryByHostname(to)
+
+	var changes []EntryChange
+	for hostname, newEntry := range newEntries {
+		oldEntry, existed := oldEntries[hostname]
+		if !existed {
+			changes = append(changes, EntryChange{Kind: ChangeAdded, Hostname: hostname, Category: newEntry.Category, New: newEntry})
+			continue
+		}
+
+		change := EntryChange{Hostname: hostname, Category: newEntry.Category, Old: oldEntry, New: newEntry}
+		if NormalizeIP(oldEntry.IP) != NormalizeIP(newEntry.IP) {
+			change.Kind = ChangeIP
+			changes = append(changes, change)
+		}
+		if oldEntry.Enabled != newEntry.Enabled {
+			change.Kind = ChangeDisabled
+			if newEntry.Enabled {
+				change.Kind = ChangeEnabled
+			}
+			changes = append(changes, change)
+		}
+		if oldEntry.Category != newEntry.Category {
+			change.Kind = ChangeMoved
+			changes = append(changes, change)
+		}
+	}
+
+	for hostname, oldEntry := range oldEntries {
+		if _, exists := newEntries[hostname]; !exists {
+			changes = append(changes, EntryChange{Kind: ChangeRemoved, Hostname: hostname, Category: oldEntry.Category, Old: oldEntry})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Category != changes[j].Category {
+			return changes[i].Category < changes[j].Category
+		}
+		if changes[i].Hostname != changes[j].Hostname {
+			return changes[i].Hostname < changes[j].Hostname
+		}
+		return changes[i].Kind < changes[j].Kind
+	})
+
+	return changes
+}
+
+func firstEntryByHostname(hostsFile *HostsFile) map[string]Entry {
+	entries := make(map[string]Entry)
+	for _, category := range hostsFile.Categories {
+		for _, entry := range category.Entries {
+			entry.Category = category.Name
+			for _, hostname := range entry.Hostnames {
+				if _, exists := entries[hostname]; !exists {
+					entries[hostname] = entry
+				}
+			}
+		}
+	}
+	return entries
+}
+
+// MergeStatus classifies an entry about to be merged into a hosts file
+type MergeStatus string
+
+const (
+	MergeNew      MergeStatus = "new"
+	MergeExisting MergeStatus = "existing"
+	MergeConflict MergeStatus = "conflict"
+)
+
+// MergeResult is how one incoming entry relates to the current file.
+// ConflictIPs lists the addresses the current file maps the conflicting
+// hostnames to.
+type MergeResult struct {
+	Status      MergeStatus
+	Entry       Entry
+	ConflictIPs []string
+}
+
+// CompareForMerge classifies entries against hf without changing it. An entry
+// is existing when every hostname is already mapped to its IP, a conflict
+// when any hostname is mapped only to other IPs, and new otherwise. Disabled
+// entries in hf count. Results keep the order of entries.
+func (hf *HostsFile) CompareForMerge(entries []Entry) []MergeResult {
+	current := make(map[string][]string)
+	for _, category := range hf.Categories {
+		for _, entry := range category.Entries {
+			for _, hostname := range entry.Hostnames {
+				hostname = strings.ToLower(hostname)
+				current[hostname] = append(current[hostname], entry.IP)
+			}
+		}
+	}
+
+	results := make([]MergeResult, 0, len(entries))
+	for _, entry := range entries {
+		result := MergeResult{Status: MergeExisting, Entry: entry}
+		ip := NormalizeIP(entry.IP)
+
+		for _, hostname := range entry.Hostnames {
+			ips, declared := current[strings.ToLower(hostname)]
+			if !declared {
+				if result.Status == MergeExisting {
+					result.Status = MergeNew
+				}
+				continue
+			}
+			if slices.ContainsFunc(ips, func(existing string) bool { return NormalizeIP(existing) == ip }) {
+				continue
+			}
+
+			result.Status = MergeConflict
+			for _, existing := range ips {
+				if !slices.Contains(result.ConflictIPs, existing) {
+					result.ConflictIPs = append(result.ConflictIPs, existing)
+				}
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
@@ -0,0 +1,123 @@
+package hosts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffHostsFiles(t *testing.T) {
+	parse := func(t *testing.T, content string) *HostsFile {
+		t.Helper()
+		hostsFile, err := NewParser("").ParseReader(strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("ParseReader() error = %v", err)
+		}
+		return hostsFile
+	}
+
+	from := parse(t, `127.0.0.1 localhost
+
+# @category development
+192.168.1.10 api.dev
+192.168.1.11 web.dev
+192.168.1.12 old.dev
+
+# @category staging
+10.0.0.5 db.stage
+`)
+	to := parse(t, `127.0.0.1 localhost
+
+# @category development
+192.168.1.20 api.dev
+# 192.168.1.11 web.dev
+192.168.1.13 new.dev
+10.0.0.5 db.stage
+`)
+
+	changes := DiffHostsFiles(from, to)
+
+	expected := []struct {
+		kind     ChangeKind
+		hostname string
+		category string
+	}{
+		{ChangeIP, "api.dev", "development"},
+		{ChangeMoved, "db.stage", "development"},
+		{ChangeAdded, "new.dev", "development"},
+		{ChangeRemoved, "old.dev", "development"},
+		{ChangeDisabled, "web.dev", "development"},
+	}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d: %+v", len(expected), len(changes), changes)
+	}
+	for i, want := range expected {
+		got := changes[i]
+		if got.Kind != want.kind || got.Hostname != want.hostname || got.Category != want.category {
+			t.Errorf("change %d = {%s %s %s}, want {%s %s %s}", i, got.Kind, got.Hostname, got.Category, want.kind, want.hostname, want.category)
+		}
+	}
+
+	if changes[0].Old.IP != "192.168.1.10" || changes[0].New.IP != "192.168.1.20" {
+		t.Errorf("Expected IP change to record old and new IPs, got %+v", changes[0])
+	}
+
+	if got := DiffHostsFiles(from, from); len(got) != 0 {
+		t.Errorf("Expected no changes comparing a file with itself, got %+v", got)
+	}
+}
+
+func TestDiffHostsFilesFlatFormat(t *testing.T) {
+	// An old-style hosts file without category markers is treated as the default category
+	flat, err := NewParser("").ParseReader(strings.NewReader("127.0.0.1 localhost\n192.168.1.10 api.dev\n"))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	managed, err := NewParser("").ParseReader(strings.NewReader("127.0.0.1 localhost\n\n# @category development\n192.168.1.10 api.dev\n"))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	changes := DiffHostsFiles(flat, managed)
+	if len(changes) != 1 || changes[0].Kind != ChangeMoved || changes[0].Old.Category != CategoryDefault {
+		t.Errorf("Expected api.dev moved from default, got %+v", changes)
+	}
+}
+
+func TestCompareForMerge(t *testing.T) {
+	current, err := NewParser("").ParseReader(strings.NewReader(`127.0.0.1 localhost
+
+# @category development
+192.168.1.10 api.dev web.dev
+# 192.168.1.11 old.dev
+`))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	results := current.CompareForMerge([]Entry{
+		{IP: "192.168.1.10", Hostnames: []string{"API.dev"}},
+		{IP: "192.168.1.11", Hostnames: []string{"old.dev"}},
+		{IP: "192.168.1.20", Hostnames: []string{"new.dev"}},
+		{IP: "192.168.1.10", Hostnames: []string{"web.dev", "extra.dev"}},
+		{IP: "10.0.0.1", Hostnames: []string{"api.dev", "other.dev"}},
+	})
+
+	expected := []MergeStatus{MergeExisting, MergeExisting, MergeNew, MergeNew, MergeConflict}
+	if len(results) != len(expected) {
+		t.Fatalf("CompareForMerge() returned %d results, want %d", len(results), len(expected))
+	}
+	for i, result := range results {
+		if result.Status != expected[i] {
+			t.Errorf("Entry %v: status %s, want %s", result.Entry.Hostnames, result.Status, expected[i])
+		}
+	}
+	if ips := results[4].ConflictIPs; len(ips) != 1 || ips[0] != "192.168.1.10" {
+		t.Errorf("Expected conflict with 192.168.1.10, got %v", ips)
+	}
+
+	// Comparing must not change the current file
+	if got := len(current.GetCategory("development").Entries); got != 2 {
+		t.Errorf("Expected current file unchanged, got %d development entries", got)
+	}
+}
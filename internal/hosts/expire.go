@@ -0,0 +1,68 @@
+package hosts
+
+import "time"
+
+// expiresDateLayout is also accepted for hand-written tags; the entry then
+// expires at the start of that day, UTC
+const expiresDateLayout = "2006-01-02"
+
+func parseExpiry(value string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(expiresDateLayout, value); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// Expired reports whether the entry has an expiry at or before now
+func (e Entry) Expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// ExpiredEntries returns the entries past their expiry at now, in file order.
+// Read-only entries are skipped unless includeReadOnly is set.
+func (hf *HostsFile) ExpiredEntries(now time.Time, includeReadOnly bool) []Entry {
+	var expired []Entry
+	for _, category := range hf.Categories {
+		for _, entry := range category.Entries {
+			if entry.Expired(now) && (includeReadOnly || !entry.ReadOnly) {
+				expired = append(expired, entry)
+			}
+		}
+	}
+	return expired
+}
+
+// RemoveExpired removes, or when disable is set disables, the entries
+// ExpiredEntries would return and reports how many changed. Disabled
+// entries past their expiry are only counted when removing.
+func (hf *HostsFile) RemoveExpired(now time.Time, disable, includeReadOnly bool) int {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.invalidateIndex()
+
+	changed := 0
+	for i := range hf.Categories {
+		category := &hf.Categories[i]
+		kept := category.Entries[:0]
+		for _, entry := range category.Entries {
+			if !entry.Expired(now) || (entry.ReadOnly && !includeReadOnly) {
+				kept = append(kept, entry)
+				continue
+			}
+			if !disable {
+				changed++
+				continue
+			}
+			if entry.Enabled {
+				entry.Enabled = false
+				changed++
+			}
+			kept = append(kept, entry)
+		}
+		category.Entries = kept
+	}
+	return changed
+}
@@ -0,0 +1,123 @@
+package hosts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpiresRoundTrip(t *testing.T) {
+	content := `127.0.0.1 localhost
+
+# @category development
+192.168.1.10 api.dev # API server
+192.168.1.11 demo.dev # demo @expires 2024-06-01
+# 192.168.1.12 old.dev # @expires 2024-01-01T08:30:00Z
+`
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	hostsFile, err := NewParser(path).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	entries := hostsFile.GetCategory("development").Entries
+	if !entries[0].ExpiresAt.IsZero() || entries[0].Comment != "API server" {
+		t.Errorf("Expected untagged entry unchanged, got %+v", entries[0])
+	}
+	if entries[1].Comment != "demo" || !entries[1].ExpiresAt.Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected date tag parsed, got %+v", entries[1])
+	}
+	if entries[2].Enabled || entries[2].ExpiresAt.IsZero() {
+		t.Errorf("Expected tag parsed on disabled entry, got %+v", entries[2])
+	}
+
+	// Untouched entries are written exactly as read
+	if err := hostsFile.Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	for _, line := range []string{
+		"192.168.1.10 api.dev # API server\n",
+		"192.168.1.11 demo.dev # demo @expires 2024-06-01\n",
+		"# 192.168.1.12 old.dev # @expires 2024-01-01T08:30:00Z\n",
+	} {
+		if !strings.Contains(string(data), line) {
+			t.Errorf("Expected %q preserved, got:\n%s", line, data)
+		}
+	}
+
+	// A new entry gets its expiry emitted as a tag
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := hostsFile.AddEntry(Entry{IP: "192.168.1.20", Hostnames: []string{"temp.dev"}, Category: "development", Enabled: true, ExpiresAt: expiresAt}); err != nil {
+		t.Fatalf("AddEntry() error = %v", err)
+	}
+	if err := hostsFile.Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if !strings.Contains(string(data), "192.168.1.20 temp.dev # @expires 2030-01-02T03:04:05Z\n") {
+		t.Errorf("Expected expiry tag written, got:\n%s", data)
+	}
+
+	reparsed, err := NewParser(path).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	found := reparsed.FindEntryByHostname("temp.dev")
+	if len(found) != 1 || !found[0].ExpiresAt.Equal(expiresAt) || found[0].Comment != "" {
+		t.Errorf("Expected expiry to survive a round trip, got %+v", found)
+	}
+}
+
+func TestRemoveExpired(t *testing.T) {
+	parse := func(t *testing.T) *HostsFile {
+		t.Helper()
+		hostsFile, err := NewParser("").ParseReader(strings.NewReader(`# @category development
+192.168.1.10 api.dev
+192.168.1.11 past.dev # @expires 2024-06-01
+192.168.1.12 future.dev # @expires 2999-01-01
+# 192.168.1.13 disabled.dev # @expires 2024-01-01
+`))
+		if err != nil {
+			t.Fatalf("ParseReader() error = %v", err)
+		}
+		return hostsFile
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	hostsFile := parse(t)
+	if expired := hostsFile.ExpiredEntries(now, false); len(expired) != 2 {
+		t.Fatalf("ExpiredEntries() = %d entries, want 2", len(expired))
+	}
+
+	if removed := hostsFile.RemoveExpired(now, false, false); removed != 2 {
+		t.Errorf("RemoveExpired() = %d, want 2", removed)
+	}
+	var left []string
+	for _, entry := range hostsFile.GetCategory("development").Entries {
+		left = append(left, entry.Hostnames[0])
+	}
+	if strings.Join(left, ",") != "api.dev,future.dev" {
+		t.Errorf("Expected api.dev,future.dev to remain, got %v", left)
+	}
+
+	hostsFile = parse(t)
+	if disabled := hostsFile.RemoveExpired(now, true, false); disabled != 1 {
+		t.Errorf("RemoveExpired(disable) = %d, want 1", disabled)
+	}
+	if entry := hostsFile.FindEntryByHostname("past.dev"); len(entry) != 1 || entry[0].Enabled {
+		t.Errorf("Expected past.dev to be disabled, got %+v", entry)
+	}
+
+	// Protected entries are only expired when included
+	hostsFile = parse(t)
+	hostsFile.GetCategory("development").Entries[1].ReadOnly = true
+	if removed := hostsFile.RemoveExpired(now, false, false); removed != 1 {
+		t.Errorf("Expected read-only entry to be skipped, removed %d", removed)
+	}
+}
@@ -0,0 +1,70 @@
+package hosts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// WriteGuardError is returned when a pending write looks like it would
+// accidentally wipe out the hosts file.
+type WriteGuardError struct {
+	Previous int    // Enabled entries currently on disk
+	Proposed int    // Enabled entries that would be written
+	Reason   string // Human readable explanation
+}
+
+func (e *WriteGuardError) Error() string {
+	return fmt.Sprintf("refusing to write hosts file: %s (enabled entries %d -> %d)", e.Reason, e.Previous, e.Proposed)
+}
+
+// EnabledEntryCount returns the number of enabled entries across all categories
+func (hf *HostsFile) EnabledEntryCount() int {
+	count := 0
+	for _, category := range hf.Categories {
+		for _, entry := range category.Entries {
+			if entry.Enabled {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// CheckWriteSafety compares hf against the hosts file currently stored at
+// filePath, only its managed block when hf was parsed in managed block mode,
+// and returns a *WriteGuardError if writing hf would leave no enabled
+// entries, or would drop more than maxDrop enabled entries. A maxDrop of 0
+// disables the threshold check. A missing file on disk is always safe to write.
+func (hf *HostsFile) CheckWriteSafety(filePath string, maxDrop int) error {
+	parser := NewParser(filePath)
+	parser.SetManagedBlockOnly(hf.managed != nil)
+	current, err := parser.Parse()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read current hosts file for safety check: %w", err)
+	}
+
+	previous := current.EnabledEntryCount()
+	proposed := hf.EnabledEntryCount()
+
+	if proposed == 0 && previous > 0 {
+		return &WriteGuardError{
+			Previous: previous,
+			Proposed: proposed,
+			Reason:   "the result would contain no enabled entries",
+		}
+	}
+
+	if maxDrop > 0 && previous-proposed > maxDrop {
+		return &WriteGuardError{
+			Previous: previous,
+			Proposed: proposed,
+			Reason:   fmt.Sprintf("more than %d enabled entries would be removed", maxDrop),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package hosts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnabledEntryCount(t *testing.T) {
+	hf := &HostsFile{
+		Categories: []Category{
+			{Name: CategoryDefault, Enabled: true, Entries: []Entry{
+				{IP: "127.0.0.1", Hostnames: []string{"localhost"}, Enabled: true},
+				{IP: "10.0.0.1", Hostnames: []string{"off.local"}, Enabled: false},
+			}},
+			{Name: "development", Enabled: true, Entries: []Entry{
+				{IP: "192.168.1.10", Hostnames: []string{"api.dev"}, Enabled: true},
+			}},
+		},
+	}
+
+	if got := hf.EnabledEntryCount(); got != 2 {
+		t.Errorf("EnabledEntryCount() = %d, want 2", got)
+	}
+
+	empty := &HostsFile{}
+	if got := empty.EnabledEntryCount(); got != 0 {
+		t.Errorf("EnabledEntryCount() on empty file = %d, want 0", got)
+	}
+}
+
+func TestCheckWriteSafety(t *testing.T) {
+	onDisk := `127.0.0.1 localhost
+192.168.1.10 api.dev
+192.168.1.11 web.dev
+192.168.1.12 db.dev
+`
+
+	tests := []struct {
+		name      string
+		enabled   int
+		maxDrop   int
+		expectErr bool
+	}{
+		{name: "same entry count", enabled: 4, maxDrop: 0, expectErr: false},
+		{name: "one entry removed without threshold", enabled: 3, maxDrop: 0, expectErr: false},
+		{name: "zero enabled entries", enabled: 0, maxDrop: 0, expectErr: true},
+		{name: "drop within threshold", enabled: 2, maxDrop: 2, expectErr: false},
+		{name: "drop exceeds threshold", enabled: 1, maxDrop: 2, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := createTestHostsFile(t, onDisk)
+			defer func() { _ = os.Remove(filePath) }()
+
+			hf, err := NewParser(filePath).Parse()
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			entries := hf.Categories[0].Entries
+			for i := range entries {
+				entries[i].Enabled = i < tt.enabled
+			}
+
+			err = hf.CheckWriteSafety(filePath, tt.maxDrop)
+			if tt.expectErr {
+				var guardErr *WriteGuardError
+				if !errors.As(err, &guardErr) {
+					t.Fatalf("expected WriteGuardError, got %v", err)
+				}
+				if guardErr.Previous != 4 || guardErr.Proposed != tt.enabled {
+					t.Errorf("unexpected counts in guard error: %+v", guardErr)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckWriteSafetyMissingFile(t *testing.T) {
+	hf := &HostsFile{Categories: []Category{{Name: CategoryDefault, Enabled: true}}}
+	missing := filepath.Join(t.TempDir(), "does-not-exist")
+
+	if err := hf.CheckWriteSafety(missing, 0); err != nil {
+		t.Errorf("expected missing file to be safe to write, got %v", err)
+	}
+}
+
+func TestCheckWriteSafetyEmptyOnDisk(t *testing.T) {
+	filePath := createTestHostsFile(t, "# only comments\n")
+	defer func() { _ = os.Remove(filePath) }()
+
+	hf := &HostsFile{Categories: []Category{{Name: CategoryDefault, Enabled: true}}}
+	if err := hf.CheckWriteSafety(filePath, 0); err != nil {
+		t.Errorf("writing an empty file over an empty file should be allowed, got %v", err)
+	}
+}
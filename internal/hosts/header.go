@@ -0,0 +1,146 @@
+package hosts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// CategoryHeader returns the lines written before category's entries under
+// the file's banner style
+func (hf *HostsFile) CategoryHeader(category Category) []string {
+	if hf.bannerStyle == BannerNone {
+		return nil
+	}
+
+	marker := "# @category " + category.Name
+	if category.Description != "" {
+		marker += " " + category.Description
+	}
+	lines := []string{marker}
+	if banner := hf.bannerStyle.renderBanner(category.Name); banner != "" {
+		lines = append(lines, banner)
+	}
+	return lines
+}
+
+// CategorySidecarPath returns the file that records category membership for
+// a hosts file written with BannerNone
+func CategorySidecarPath(hostsPath string) string {
+	return hostsPath + ".categories"
+}
+
+// categorySidecar is the JSON form of the sidecar file. Entries are keyed by
+// IP and hostnames, as written on their hosts file line.
+type categorySidecar struct {
+	Categories []sidecarCategory `json:"categories"`
+}
+
+type sidecarCategory struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Entries     []string `json:"entries"`
+}
+
+func sidecarKey(entry Entry) string {
+	return NormalizeIP(entry.IP) + " " + strings.ToLower(strings.Join(entry.Hostnames, " "))
+}
+
+// writeCategorySidecar records category membership next to hostsPath when
+// categories are not written inline. Otherwise the markers just written carry
+// the membership, so a sidecar left by an earlier BannerNone write is removed.
+func (hf *HostsFile) writeCategorySidecar(hostsPath string) error {
+	path := CategorySidecarPath(hostsPath)
+	if hf.bannerStyle != BannerNone {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove category sidecar: %w", err)
+		}
+		return nil
+	}
+
+	var sidecar categorySidecar
+	for _, category := range hf.Categories {
+		if len(category.Entries) == 0 {
+			continue
+		}
+		record := sidecarCategory{Name: category.Name, Description: category.Description}
+		for _, entry := range category.Entries {
+			record.Entries = append(record.Entries, sidecarKey(entry))
+		}
+		sidecar.Categories = append(sidecar.Categories, record)
+	}
+
+	return AtomicWrite(path, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(sidecar)
+	})
+}
+
+// applyCategorySidecar regroups entries read from a file without category
+// markers using the sidecar at path. Entries the sidecar does not list, such
+// as lines added by other tools, stay in the default category. A missing
+// sidecar leaves hf unchanged.
+func (hf *HostsFile) applyCategorySidecar(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read category sidecar: %w", err)
+	}
+
+	var sidecar categorySidecar
+	if err := json.Unmarshal(data, &sidecar); err != nil {
+		return fmt.Errorf("failed to parse category sidecar %s: %w", path, err)
+	}
+
+	// Keys can repeat, so each one maps to a queue of categories in file order
+	membership := make(map[string][]int)
+	categories := make([]Category, len(sidecar.Categories))
+	for i, record := range sidecar.Categories {
+		if err := ValidateCategoryName(record.Name); err != nil {
+			return fmt.Errorf("invalid category in sidecar %s: %w", path, err)
+		}
+		categories[i] = Category{Name: record.Name, Description: record.Description, Enabled: true, Entries: []Entry{}}
+		for _, key := range record.Entries {
+			membership[key] = append(membership[key], i)
+		}
+	}
+
+	var unlisted []Entry
+	for _, category := range hf.Categories {
+		for _, entry := range category.Entries {
+			key := sidecarKey(entry)
+			if queue := membership[key]; len(queue) > 0 {
+				membership[key] = queue[1:]
+				entry.Category = categories[queue[0]].Name
+				categories[queue[0]].Entries = append(categories[queue[0]].Entries, entry)
+				continue
+			}
+			entry.Category = CategoryDefault
+			unlisted = append(unlisted, entry)
+		}
+	}
+
+	if len(unlisted) > 0 {
+		placed := false
+		for i := range categories {
+			if categories[i].Name == CategoryDefault {
+				categories[i].Entries = append(categories[i].Entries, unlisted...)
+				placed = true
+			}
+		}
+		if !placed {
+			categories = append(categories, Category{Name: CategoryDefault, Enabled: true, Entries: unlisted})
+		}
+	}
+
+	if len(categories) > 0 {
+		hf.Categories = categories
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package hosts
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const headerStyleHosts = `127.0.0.1 localhost
+
+# @category development Local services
+192.168.1.10 api.dev
+# 192.168.1.11 web.dev
+
+# @category staging
+10.0.0.5 db.stage
+192.168.1.10 api.dev
+`
+
+// categoryMembership lists "category: hostnames (enabled)" for each entry
+func categoryMembership(hostsFile *HostsFile) []string {
+	var membership []string
+	for _, category := range hostsFile.Categories {
+		for _, entry := range category.Entries {
+			line := category.Name + ": " + strings.Join(entry.Hostnames, " ")
+			if !entry.Enabled {
+				line += " (disabled)"
+			}
+			membership = append(membership, line)
+		}
+	}
+	return membership
+}
+
+func TestCategoryHeaderRoundTrip(t *testing.T) {
+	tests := []struct {
+		style       BannerStyle
+		contains    []string
+		notContains []string
+	}{
+		{BannerEquals, []string{"# @category development Local services\n# =============== DEVELOPMENT ==============="}, nil},
+		{BannerMinimal, []string{"# @category development Local services\n192.168.1.10"}, []string{"====="}},
+		{BannerNone, nil, []string{"@category", "====="}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			original, err := NewParser("").ParseReader(strings.NewReader(headerStyleHosts))
+			if err != nil {
+				t.Fatalf("ParseReader() error = %v", err)
+			}
+
+			path := filepath.Join(t.TempDir(), "hosts")
+			original.SetBannerStyle(tt.style)
+			if err := original.Write(path); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("Expected %q in:\n%s", want, data)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(string(data), unwanted) {
+					t.Errorf("Did not expect %q in:\n%s", unwanted, data)
+				}
+			}
+
+			_, err = os.Stat(CategorySidecarPath(path))
+			if hasSidecar := err == nil; hasSidecar != (tt.style == BannerNone) {
+				t.Errorf("Sidecar present = %v for style %s", hasSidecar, tt.style)
+			}
+
+			reread, err := NewParser(path).Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got, want := categoryMembership(reread), categoryMembership(original); !slices.Equal(got, want) {
+				t.Errorf("Category membership after round trip = %v, want %v", got, want)
+			}
+
+			// A second write must produce the same file
+			reread.SetBannerStyle(tt.style)
+			if err := reread.Write(path); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			again, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if string(again) != string(data) {
+				t.Errorf("Second write changed the file:\n%s\nwant:\n%s", again, data)
+			}
+		})
+	}
+}
+
+func TestCategorySidecarUnlistedEntries(t *testing.T) {
+	original, err := NewParser("").ParseReader(strings.NewReader(headerStyleHosts))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "hosts")
+	original.SetBannerStyle(BannerNone)
+	if err := original.Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	// Another tool appends a line the sidecar does not know about
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	if _, err := file.WriteString("10.9.9.9 vpn.corp\n"); err != nil {
+		t.Fatalf("WriteString() error = %v", err)
+	}
+	_ = file.Close()
+
+	reread, err := NewParser(path).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	defaultCategory := reread.GetCategory(CategoryDefault)
+	if defaultCategory == nil || !slices.ContainsFunc(defaultCategory.Entries, func(e Entry) bool { return e.Hostnames[0] == "vpn.corp" }) {
+		t.Errorf("Unlisted entry should be in the default category, got %v", categoryMembership(reread))
+	}
+	if category := reread.GetCategory("development"); category == nil || category.Description != "Local services" {
+		t.Errorf("Sidecar should restore category descriptions, got %+v", category)
+	}
+
+	// Switching back to an inline style removes the sidecar
+	reread.SetBannerStyle(BannerMinimal)
+	if err := reread.Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if _, err := os.Stat(CategorySidecarPath(path)); !os.IsNotExist(err) {
+		t.Errorf("Expected the sidecar to be removed, got %v", err)
+	}
+}
+
+func TestSwitchingBannerStyleKeepsCategories(t *testing.T) {
+	original, err := NewParser("").ParseReader(strings.NewReader(headerStyleHosts))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	want := categoryMembership(original)
+
+	path := filepath.Join(t.TempDir(), "hosts")
+	hostsFile := original
+	for _, style := range []BannerStyle{BannerNone, BannerEquals, BannerNone, BannerMinimal, BannerStyle("### {name} ###"), BannerNone, BannerDashes} {
+		hostsFile.SetBannerStyle(style)
+		if err := hostsFile.Write(path); err != nil {
+			t.Fatalf("Write() with %s error = %v", style, err)
+		}
+
+		// Each write is read back by a parser configured with the new style
+		parser := NewParser(path)
+		parser.SetBannerStyle(style)
+		if hostsFile, err = parser.Parse(); err != nil {
+			t.Fatalf("Parse() after %s error = %v", style, err)
+		}
+		if got := categoryMembership(hostsFile); !slices.Equal(got, want) {
+			t.Errorf("Category membership after writing with %s = %v, want %v", style, got, want)
+		}
+		if category := hostsFile.GetCategory("development"); category == nil || category.Description != "Local services" {
+			t.Errorf("Description lost after writing with %s, got %+v", style, category)
+		}
+	}
+}
+
+func TestCategorySidecarInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte("127.0.0.1 localhost\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(CategorySidecarPath(path), []byte("{not json"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := NewParser(path).Parse(); err == nil {
+		t.Error("Expected an error for a corrupt sidecar")
+	}
+}
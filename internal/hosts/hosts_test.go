@@ -5,9 +5,11 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 // Test data and helpers
@@ -418,6 +420,30 @@ func TestParseEntry(t *testing.T) {
 			expectComment:   "",
 			expectEnabled:   true,
 		},
+		{
+			name:            "link-local IPv6 with zone",
+			line:            "fe80::1%eth0 router.local",
+			lineNum:         6,
+			expectOK:        true,
+			expectIP:        "fe80::1%eth0",
+			expectHostnames: []string{"router.local"},
+			expectEnabled:   true,
+		},
+		{
+			name:            "disabled link-local IPv6 with zone",
+			line:            "# fe80::1%en0 router.local",
+			lineNum:         7,
+			expectOK:        true,
+			expectIP:        "fe80::1%en0",
+			expectHostnames: []string{"router.local"},
+			expectEnabled:   false,
+		},
+		{
+			name:     "zone on global IPv6",
+			line:     "2001:db8::1%eth0 ipv6.test",
+			lineNum:  8,
+			expectOK: false,
+		},
 		{
 			name:     "comment line",
 			line:     "# This is just a comment",
@@ -726,9 +752,9 @@ func TestFormatEntry(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := formatEntry(tt.entry)
+			result := FormatEntry(tt.entry)
 			if result != tt.expected {
-				t.Errorf("formatEntry() = %q, want %q", result, tt.expected)
+				t.Errorf("FormatEntry() = %q, want %q", result, tt.expected)
 			}
 		})
 	}
@@ -858,6 +884,45 @@ func TestHostsFileAddEntry(t *testing.T) {
 	}
 }
 
+// TestAddDisabledEntry tests that an entry added disabled is written
+// commented out and reads back disabled
+func TestAddDisabledEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	hf, err := NewParser("").ParseReader(strings.NewReader("127.0.0.1 localhost\n"))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	if err := hf.AddEntry(Entry{IP: "10.0.0.300", Hostnames: []string{"staged.local"}, Category: "development"}); err == nil {
+		t.Error("AddEntry() should validate disabled entries")
+	}
+
+	entry := Entry{IP: "192.168.1.50", Hostnames: []string{"staged.local"}, Comment: "override", Category: "development"}
+	if err := hf.AddEntry(entry); err != nil {
+		t.Fatalf("AddEntry() error = %v", err)
+	}
+	if err := hf.Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read hosts file: %v", err)
+	}
+	if line := FormatEntry(entry); !slices.Contains(strings.Split(string(data), "\n"), line) || !strings.HasPrefix(line, "# ") {
+		t.Errorf("Expected commented line %q in:\n%s", line, data)
+	}
+
+	reparsed, err := NewParser(path).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	entries := reparsed.FindEntryByHostname("staged.local")
+	if len(entries) != 1 || entries[0].Enabled || entries[0].IP != entry.IP || entries[0].Category != "development" {
+		t.Errorf("staged.local entries = %+v, want one disabled entry in development", entries)
+	}
+}
+
 // TestHostsFileRemoveEntry tests removing entries
 func TestHostsFileRemoveEntry(t *testing.T) {
 
@@ -1021,6 +1086,54 @@ func TestHostsFileEnableDisableEntry(t *testing.T) {
 	})
 }
 
+// TestHostsFileToggleEntryInCategory tests enabling/disabling entries scoped by category
+func TestHostsFileToggleEntryInCategory(t *testing.T) {
+	content := `# @category development
+10.0.0.1 api.dev web.dev
+
+# @category staging
+# 10.0.0.2 api.dev web.dev
+`
+	parse := func(t *testing.T) *HostsFile {
+		t.Helper()
+		hf, err := NewParser("").ParseReader(strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("ParseReader() error = %v", err)
+		}
+		return hf
+	}
+
+	hf := parse(t)
+	if !hf.EnableEntryInCategory("api.dev", "staging") {
+		t.Fatal("EnableEntryInCategory() should find api.dev in staging")
+	}
+	if !hf.GetCategory("staging").Entries[0].Enabled || !hf.GetCategory("development").Entries[0].Enabled {
+		t.Error("EnableEntryInCategory() should enable only the staging entry")
+	}
+	if !hf.DisableEntryInCategory("api.dev", "development") || hf.GetCategory("development").Entries[0].Enabled {
+		t.Error("DisableEntryInCategory() should disable the development entry")
+	}
+	if !hf.GetCategory("staging").Entries[0].Enabled {
+		t.Error("DisableEntryInCategory() should leave the staging entry enabled")
+	}
+	if hf.EnableEntryInCategory("api.dev", "production") {
+		t.Error("EnableEntryInCategory() should not find api.dev in production")
+	}
+
+	hf = parse(t)
+	hf.EnableEntryInCategory("web.dev", "staging")
+	if !hf.DisableHostnameInCategory("web.dev", "staging") {
+		t.Fatal("DisableHostnameInCategory() should find web.dev in staging")
+	}
+	staging := hf.GetCategory("staging").Entries
+	if len(staging) != 2 || !staging[0].Enabled || staging[1].Enabled || staging[1].Hostnames[0] != "web.dev" {
+		t.Errorf("DisableHostnameInCategory() should split web.dev out of the staging entry, got %+v", staging)
+	}
+	if len(hf.GetCategory("development").Entries) != 1 {
+		t.Error("DisableHostnameInCategory() should leave the development entry alone")
+	}
+}
+
 // TestHostsFileFindEntries tests finding entries
 func TestHostsFileFindEntries(t *testing.T) {
 	hostsFile := &HostsFile{
@@ -1118,6 +1231,56 @@ func TestHostsFileFindEntries(t *testing.T) {
 	}
 }
 
+func TestHostsFileGetEntriesByIP(t *testing.T) {
+	hostsFile := &HostsFile{
+		Categories: []Category{
+			{
+				Name:    CategoryDefault,
+				Enabled: true,
+				Entries: []Entry{
+					{IP: "10.0.0.1", Hostnames: []string{"gateway.lan"}, Enabled: true},
+					{IP: "10.0.0.10", Hostnames: []string{"nas.lan"}, Enabled: true},
+					{IP: "::1", Hostnames: []string{"localhost"}, Enabled: true},
+					{IP: "fe80::1%eth0", Hostnames: []string{"router.link"}, Enabled: true},
+				},
+			},
+			{
+				Name:    "development",
+				Enabled: true,
+				Entries: []Entry{
+					{IP: "10.0.0.1", Hostnames: []string{"dev.lan"}, Enabled: false},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		ip       string
+		expected []string
+	}{
+		{"10.0.0.1", []string{"gateway.lan", "dev.lan"}},
+		{"10.0.0.10", []string{"nas.lan"}},
+		{"10.0.0.100", nil},
+		{"0:0:0:0:0:0:0:1", []string{"localhost"}},
+		{"fe80::1", []string{"router.link"}},
+		{"fe80::1%eth0", []string{"router.link"}},
+		{"fe80::1%eth1", nil},
+		{"not-an-ip", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			var got []string
+			for _, entry := range hostsFile.GetEntriesByIP(tt.ip) {
+				got = append(got, entry.Hostnames[0])
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("GetEntriesByIP(%q) = %v, want %v", tt.ip, got, tt.expected)
+			}
+		})
+	}
+}
+
 // TestHostsFileGetCategory tests getting categories
 func TestHostsFileGetCategory(t *testing.T) {
 	hostsFile := &HostsFile{
@@ -1455,10 +1618,50 @@ func TestHostsFileEdgeCases(t *testing.T) {
 			}
 		}
 
-		// Verify all entries were added (allowing for some potential validation failures in concurrent access)
+		// AddEntry is serialized, so no concurrent append may be lost
 		entryCount := len(hostsFile.Categories[0].Entries)
-		if entryCount < 9 || entryCount > 10 {
-			t.Errorf("expected 9-10 entries due to concurrent access, got %d", entryCount)
+		if entryCount != 10 {
+			t.Errorf("expected 10 entries after concurrent AddEntry calls, got %d", entryCount)
+		}
+	})
+
+	t.Run("concurrent bulk mutators", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("Skipping concurrency test in short mode")
+		}
+
+		hostsFile := &HostsFile{
+			Categories: []Category{
+				{Name: CategoryDefault, Enabled: true, Entries: []Entry{}},
+				{Name: "staging", Enabled: true, Entries: []Entry{}},
+			},
+		}
+
+		// Run with -race: every mutator below must take hf.mu
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(id int) {
+				defer wg.Done()
+				_ = hostsFile.AddEntry(Entry{
+					IP:        fmt.Sprintf("192.168.1.%d", id+1),
+					Hostnames: []string{fmt.Sprintf("host%d.local", id), "shared.local"},
+					Enabled:   true,
+				})
+				_ = hostsFile.SortEntries(SortByIP, false)
+				hostsFile.SortCategories()
+				hostsFile.RemoveDuplicateHostnames(false)
+				hostsFile.RemoveExpired(time.Now(), true, false)
+				hostsFile.DisableHostname(fmt.Sprintf("host%d.local", id))
+			}(i)
+		}
+		wg.Wait()
+
+		for i := 0; i < 10; i++ {
+			hostname := fmt.Sprintf("host%d.local", i)
+			if found := hostsFile.FindEntryByHostname(hostname); len(found) != 1 || found[0].Enabled {
+				t.Errorf("expected %s once and disabled after concurrent mutations, got %+v", hostname, found)
+			}
 		}
 	})
 }
@@ -1693,3 +1896,656 @@ func TestHostsFileAddCategoryAndWrite(t *testing.T) {
 		t.Errorf("Expected description 'Testing category for persistence', got '%s'", testingCategory.Description)
 	}
 }
+
+// TestHostsFileFindEntryByHostname tests exact hostname lookup
+func TestHostsFileFindEntryByHostname(t *testing.T) {
+	tmpFile := createTestHostsFile(t, sampleHostsContent)
+	defer func() { _ = os.Remove(tmpFile) }()
+
+	hostsFile, err := NewParser(tmpFile).Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse hosts file: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		hostname      string
+		expectedCount int
+	}{
+		{"single match", "db.dev", 1},
+		{"hostname in multi-host entry", "web.dev", 1},
+		{"multiple entries", "localhost", 2},
+		{"partial hostname does not match", "api", 0},
+		{"unknown hostname", "missing.dev", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := hostsFile.FindEntryByHostname(tt.hostname)
+			if len(matches) != tt.expectedCount {
+				t.Errorf("FindEntryByHostname(%q) returned %d entries, want %d", tt.hostname, len(matches), tt.expectedCount)
+			}
+		})
+	}
+
+	// Returned pointers refer to the entries held by the hosts file
+	matches := hostsFile.FindEntryByHostname("db.dev")
+	matches[0].IP = "192.168.1.201"
+	if got := hostsFile.FindEntries("192.168.1.201"); len(got) != 1 {
+		t.Errorf("Expected modification through pointer to be visible, found %d entries", len(got))
+	}
+}
+
+// TestHostsFileMoveEntry tests moving entries between categories
+func TestHostsFileMoveEntry(t *testing.T) {
+	tmpFile := createTestHostsFile(t, sampleHostsContent)
+	defer func() { _ = os.Remove(tmpFile) }()
+
+	hostsFile, err := NewParser(tmpFile).Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse hosts file: %v", err)
+	}
+
+	entry := hostsFile.FindEntryByHostname("db.dev")[0]
+	if err := hostsFile.MoveEntry(entry, "production"); err != nil {
+		t.Fatalf("MoveEntry() error = %v", err)
+	}
+
+	if got := len(hostsFile.GetCategory("development").Entries); got != 1 {
+		t.Errorf("Expected 1 entry left in development, got %d", got)
+	}
+	moved := hostsFile.FindEntryByHostname("db.dev")
+	if len(moved) != 1 || moved[0].Category != "production" {
+		t.Errorf("Expected db.dev to be in production, got %+v", moved)
+	}
+
+	if err := hostsFile.MoveEntry(moved[0], "production"); err != nil {
+		t.Errorf("Moving to the current category should be a no-op, got: %v", err)
+	}
+	if err := hostsFile.MoveEntry(moved[0], "nonexistent"); err == nil {
+		t.Error("Expected error moving to a missing category")
+	}
+	if err := hostsFile.MoveEntry(&Entry{}, "development"); err == nil {
+		t.Error("Expected error moving an entry not in the hosts file")
+	}
+}
+
+// TestLeadingZeroIPRoundTrip tests that the IP mode is applied on parse and write
+func TestLeadingZeroIPRoundTrip(t *testing.T) {
+	content := "10.0.0.001 zero.dev\n# 010.000.000.002 disabled.dev\n"
+
+	tests := []struct {
+		name     string
+		mode     IPMode
+		expected []string
+	}{
+		{"normalize", IPNormalize, []string{"10.0.0.1 zero.dev", "# 10.0.0.2 disabled.dev"}},
+		{"preserve", IPPreserve, []string{"10.0.0.001 zero.dev", "# 010.000.000.002 disabled.dev"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hostsPath := filepath.Join(t.TempDir(), "hosts")
+			if err := os.WriteFile(hostsPath, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test hosts file: %v", err)
+			}
+
+			parser := NewParser(hostsPath)
+			parser.SetIPMode(tt.mode)
+			hostsFile, err := parser.Parse()
+			if err != nil {
+				t.Fatalf("Failed to parse hosts file: %v", err)
+			}
+			if got := len(hostsFile.FindEntries("dev")); got != 2 {
+				t.Fatalf("Expected leading-zero entries to be parsed, got %d", got)
+			}
+
+			if err := hostsFile.Write(hostsPath); err != nil {
+				t.Fatalf("Failed to write hosts file: %v", err)
+			}
+			written, err := os.ReadFile(hostsPath)
+			if err != nil {
+				t.Fatalf("Failed to read written hosts file: %v", err)
+			}
+			for _, line := range tt.expected {
+				if !strings.Contains(string(written), line) {
+					t.Errorf("Expected written file to contain %q, got:\n%s", line, written)
+				}
+			}
+
+			// A second pass must be stable
+			reparser := NewParser(hostsPath)
+			reparser.SetIPMode(tt.mode)
+			reparsed, err := reparser.Parse()
+			if err != nil {
+				t.Fatalf("Failed to re-parse hosts file: %v", err)
+			}
+			if got := reparsed.FindEntryByHostname("zero.dev"); len(got) != 1 || got[0].IP != hostsFile.FindEntryByHostname("zero.dev")[0].IP {
+				t.Errorf("Round-trip changed IP: %+v", got)
+			}
+		})
+	}
+}
+
+// TestParsePreservesCategoryOrder tests that categories keep their file order
+func TestParsePreservesCategoryOrder(t *testing.T) {
+	content := `# @category zulu
+10.0.0.1 z.dev
+# @category alpha
+10.0.0.2 a.dev
+# @category mike
+10.0.0.3 m.dev
+`
+	for i := 0; i < 10; i++ {
+		hostsFile, err := NewParser("").ParseReader(strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("ParseReader() error = %v", err)
+		}
+		var names []string
+		for _, category := range hostsFile.Categories {
+			names = append(names, category.Name)
+		}
+		if strings.Join(names, ",") != "zulu,alpha,mike" {
+			t.Fatalf("Expected categories in file order, got %v", names)
+		}
+	}
+}
+
+func TestDisableHostnameSplitsSharedEntry(t *testing.T) {
+	hostsFile, err := NewParser("").ParseReader(strings.NewReader("# @category development\n192.168.1.100 api.dev web.dev # shared\n10.0.0.5 db.dev\n"))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	if !hostsFile.DisableHostname("web.dev") {
+		t.Fatal("DisableHostname() returned false")
+	}
+
+	entries := hostsFile.GetCategory("development").Entries
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries after split, got %d: %+v", len(entries), entries)
+	}
+
+	api, web, db := entries[0], entries[1], entries[2]
+	if !api.Enabled || len(api.Hostnames) != 1 || api.Hostnames[0] != "api.dev" {
+		t.Errorf("Expected enabled api.dev entry, got %+v", api)
+	}
+	if web.Enabled || len(web.Hostnames) != 1 || web.Hostnames[0] != "web.dev" {
+		t.Errorf("Expected disabled web.dev entry, got %+v", web)
+	}
+	if api.IP != "192.168.1.100" || web.IP != api.IP || web.Comment != "shared" || web.Category != "development" {
+		t.Errorf("Expected split entry to keep IP, comment, and category, got %+v", web)
+	}
+	if db.Hostnames[0] != "db.dev" || !db.Enabled {
+		t.Errorf("Expected following entry to be untouched, got %+v", db)
+	}
+
+	// A single-hostname entry is disabled in place
+	if !hostsFile.DisableHostname("db.dev") || len(hostsFile.GetCategory("development").Entries) != 3 {
+		t.Error("Expected single-hostname entry to be disabled without splitting")
+	}
+	if hostsFile.DisableHostname("missing.dev") {
+		t.Error("Expected DisableHostname() to return false for unknown hostname")
+	}
+}
+
+func TestRemoveCategory(t *testing.T) {
+	parse := func(t *testing.T) *HostsFile {
+		t.Helper()
+		hostsFile, err := NewParser("").ParseReader(strings.NewReader(`127.0.0.1 localhost
+
+# @category development
+192.168.1.10 api.dev
+192.168.1.11 web.dev
+
+# @category staging
+10.0.0.5 db.stage
+
+# @category empty
+`))
+		if err != nil {
+			t.Fatalf("ParseReader() error = %v", err)
+		}
+		return hostsFile
+	}
+
+	t.Run("refuses non-empty category", func(t *testing.T) {
+		hostsFile := parse(t)
+		if _, _, err := hostsFile.RemoveCategory("development", "", false); err == nil || !strings.Contains(err.Error(), "still has 2 entries") {
+			t.Errorf("Expected non-empty category error, got %v", err)
+		}
+		if hostsFile.GetCategory("development") == nil {
+			t.Error("Expected category to be kept after refusal")
+		}
+	})
+
+	t.Run("moves entries", func(t *testing.T) {
+		hostsFile := parse(t)
+		moved, deleted, err := hostsFile.RemoveCategory("development", "staging", false)
+		if err != nil || moved != 2 || deleted != 0 {
+			t.Fatalf("RemoveCategory() = %d, %d, %v; want 2 moved", moved, deleted, err)
+		}
+		if hostsFile.GetCategory("development") != nil {
+			t.Error("Expected development category to be removed")
+		}
+		staging := hostsFile.GetCategory("staging")
+		if len(staging.Entries) != 3 || staging.Entries[1].Category != "staging" {
+			t.Errorf("Expected moved entries in staging, got %+v", staging.Entries)
+		}
+	})
+
+	t.Run("deletes entries", func(t *testing.T) {
+		hostsFile := parse(t)
+		moved, deleted, err := hostsFile.RemoveCategory("development", "", true)
+		if err != nil || moved != 0 || deleted != 2 {
+			t.Fatalf("RemoveCategory() = %d, %d, %v; want 2 deleted", moved, deleted, err)
+		}
+		if len(hostsFile.FindEntryByHostname("api.dev")) != 0 {
+			t.Error("Expected api.dev to be deleted with its category")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		hostsFile := parse(t)
+		if _, _, err := hostsFile.RemoveCategory(CategoryDefault, "", true); err == nil {
+			t.Error("Expected default category deletion to be rejected")
+		}
+		if _, _, err := hostsFile.RemoveCategory("missing", "", false); err == nil {
+			t.Error("Expected unknown category error")
+		}
+		if _, _, err := hostsFile.RemoveCategory("development", "missing", false); err == nil {
+			t.Error("Expected unknown target category error")
+		}
+		if _, _, err := hostsFile.RemoveCategory("empty", "", false); err != nil {
+			t.Errorf("Expected empty category to be deleted, got %v", err)
+		}
+	})
+}
+
+func TestUnmodifiedWriteIsByteIdentical(t *testing.T) {
+	content := `# This file is currently managed by hosts-manager
+# See https://github.com/brandonhon/hosts-manager for usage
+
+# @category default
+# =============== DEFAULT ===============
+127.0.0.1       localhost
+::1             localhost ip6-localhost
+
+# @category development
+# =============== DEVELOPMENT ===============
+192.168.1.10    api.dev         # API server
+192.168.1.11	web.dev	www.dev
+#   192.168.1.12    old.dev     # retired
+`
+	filePath := createTestHostsFile(t, content)
+	defer func() { _ = os.Remove(filePath) }()
+
+	hostsFile, err := NewParser(filePath).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if err := hostsFile.Write(filePath); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Errorf("Unmodified round trip changed the file:\n--- want\n%s\n--- got\n%s", content, data)
+	}
+
+	// Only the modified entry is reformatted
+	hostsFile.EnableEntry("old.dev")
+	if err := hostsFile.Write(filePath); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	data, err = os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Replace(content, "#   192.168.1.12    old.dev     # retired", "192.168.1.12 old.dev # retired", 1)
+	if string(data) != expected {
+		t.Errorf("Expected only old.dev to be reformatted:\n--- want\n%s\n--- got\n%s", expected, data)
+	}
+}
+
+func TestIPv6ZoneRoundTrip(t *testing.T) {
+	hostsFile, err := NewParser("").ParseReader(strings.NewReader("fe80::1%eth0 router.local\nfe80::2 printer.local\n"))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	if len(hostsFile.ParseIssues) != 0 {
+		t.Fatalf("Expected no parse issues, got %+v", hostsFile.ParseIssues)
+	}
+
+	found := hostsFile.FindEntryByHostname("router.local")
+	if len(found) != 1 || found[0].IP != "fe80::1%eth0" {
+		t.Fatalf("Expected zone to be kept, got %+v", found)
+	}
+
+	entry := found[0]
+	entry.Comment = "gateway"
+	if got := FormatEntry(*entry); got != "fe80::1%eth0 router.local # gateway" {
+		t.Errorf("FormatEntry() = %q", got)
+	}
+	if got := FormatEntry(*hostsFile.FindEntryByHostname("printer.local")[0]); got != "fe80::2 printer.local" {
+		t.Errorf("FormatEntry() = %q", got)
+	}
+}
+
+func TestAddOrMergeEntry(t *testing.T) {
+	hostsFile, err := NewParser("").ParseReader(strings.NewReader(`127.0.0.1 localhost
+
+# @category development
+192.168.1.10 api.dev # API server
+
+# @category staging
+192.168.1.20 db.stage
+`))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	merged, err := hostsFile.AddOrMergeEntry(Entry{
+		IP:        "192.168.1.10",
+		Hostnames: []string{"web.dev", "api.dev"},
+		Category:  "development",
+		Enabled:   true,
+	}, HostnameStrict)
+	if err != nil || !merged {
+		t.Fatalf("AddOrMergeEntry() = %v, %v; want merged", merged, err)
+	}
+
+	entries := hostsFile.GetCategory("development").Entries
+	if len(entries) != 1 {
+		t.Fatalf("Expected a single development entry, got %+v", entries)
+	}
+	if strings.Join(entries[0].Hostnames, " ") != "api.dev web.dev" {
+		t.Errorf("Expected deduplicated hostnames api.dev web.dev, got %v", entries[0].Hostnames)
+	}
+	if entries[0].Comment != "API server" {
+		t.Errorf("Expected original comment to be kept, got %q", entries[0].Comment)
+	}
+
+	// A new comment replaces the old one
+	if _, err := hostsFile.AddOrMergeEntry(Entry{IP: "192.168.1.10", Hostnames: []string{"admin.dev"}, Comment: "Dev services", Category: "development", Enabled: true}, HostnameStrict); err != nil {
+		t.Fatalf("AddOrMergeEntry() error = %v", err)
+	}
+	if got := hostsFile.GetCategory("development").Entries[0].Comment; got != "Dev services" {
+		t.Errorf("Expected comment to be replaced, got %q", got)
+	}
+
+	// The same IP in another category is not merged into
+	merged, err = hostsFile.AddOrMergeEntry(Entry{
+		IP:        "192.168.1.10",
+		Hostnames: []string{"api.stage"},
+		Category:  "staging",
+		Enabled:   true,
+	}, HostnameStrict)
+	if err != nil || merged {
+		t.Fatalf("AddOrMergeEntry() = %v, %v; want a new entry", merged, err)
+	}
+	if got := len(hostsFile.GetCategory("staging").Entries); got != 2 {
+		t.Errorf("Expected 2 staging entries, got %d", got)
+	}
+	if got := len(hostsFile.GetCategory("development").Entries); got != 1 {
+		t.Errorf("Expected development to still have 1 entry, got %d", got)
+	}
+}
+
+func TestInsertEntry(t *testing.T) {
+	hostsFile, err := NewParser("").ParseReader(strings.NewReader(`# @category development
+192.168.1.10 api.dev
+192.168.1.20 web.dev
+
+# @category staging
+10.0.0.5 db.stage
+`))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	hostnames := func(category string) []string {
+		var names []string
+		for _, entry := range hostsFile.GetCategory(category).Entries {
+			names = append(names, entry.Hostnames[0])
+		}
+		return names
+	}
+
+	found, err := hostsFile.InsertEntry(Entry{IP: "192.168.1.11", Hostnames: []string{"api-v2.dev"}, Category: "development", Enabled: true}, "WEB.dev")
+	if err != nil || !found {
+		t.Fatalf("InsertEntry() = %v, %v; want inserted before web.dev", found, err)
+	}
+	if got := strings.Join(hostnames("development"), " "); got != "api.dev api-v2.dev web.dev" {
+		t.Errorf("Expected api-v2.dev before web.dev, got %s", got)
+	}
+
+	// The anchor is only looked up in the entry's own category
+	found, err = hostsFile.InsertEntry(Entry{IP: "192.168.1.12", Hostnames: []string{"admin.dev"}, Category: "development", Enabled: true}, "db.stage")
+	if err != nil || found {
+		t.Fatalf("InsertEntry() = %v, %v; want appended", found, err)
+	}
+	if got := strings.Join(hostnames("development"), " "); got != "api.dev api-v2.dev web.dev admin.dev" {
+		t.Errorf("Expected admin.dev appended, got %s", got)
+	}
+
+	if _, err := hostsFile.InsertEntry(Entry{IP: "not-an-ip", Hostnames: []string{"bad.dev"}, Category: "development", Enabled: true}, "api.dev"); err == nil {
+		t.Error("Expected invalid entry to be rejected")
+	}
+	if got := len(hostsFile.GetCategory("development").Entries); got != 4 {
+		t.Errorf("Expected 4 development entries, got %d", got)
+	}
+}
+
+func TestCommentBlocksRoundTrip(t *testing.T) {
+	content := `# This file is currently managed by hosts-manager
+# See https://github.com/brandonhon/hosts-manager for usage
+
+# @category development
+# =============== DEVELOPMENT ===============
+# Backend services
+192.168.1.10 api.dev
+
+# Frontend, ask the web team before changing
+# 192.168.1.20 web.dev
+192.168.1.21 app.dev
+
+# @category staging
+# =============== STAGING ===============
+10.0.0.5 db.stage
+
+# end of managed entries
+`
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	hostsFile, err := NewParser(path).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	app := hostsFile.FindEntryByHostname("app.dev")
+	if len(app) != 1 || len(app[0].LeadingComments) != 0 {
+		t.Fatalf("Expected app.dev without leading comments, got %+v", app)
+	}
+	web := hostsFile.FindEntryByHostname("web.dev")
+	if len(web) != 1 || !slices.Equal(web[0].LeadingComments, []string{"", "# Frontend, ask the web team before changing"}) {
+		t.Fatalf("Expected web.dev to keep its comment block, got %+v", web)
+	}
+	if !slices.Equal(hostsFile.Footer, []string{"# end of managed entries"}) {
+		t.Errorf("Expected trailing comment as footer, got %q", hostsFile.Footer)
+	}
+
+	// Writing twice must reproduce the original file exactly
+	for i := 0; i < 2; i++ {
+		if err := hostsFile.Write(path); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		if string(data) != content {
+			t.Fatalf("Write #%d changed the file:\n%s\nwant:\n%s", i+1, data, content)
+		}
+		if hostsFile, err = NewParser(path).Parse(); err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+	}
+
+	// Comments stay with their entry when it is edited
+	hostsFile.EnableEntry("web.dev")
+	if err := hostsFile.Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(data), "# Frontend, ask the web team before changing\n192.168.1.20 web.dev\n") {
+		t.Errorf("Expected comment block above the enabled entry, got:\n%s", data)
+	}
+}
+
+func TestParseWithReport(t *testing.T) {
+	content := `garbage before entries
+127.0.0.1 localhost
+
+# @category development
+# =============== DEVELOPMENT ===============
+192.168.1.10 api.dev
+# a plain comment
+999.1.1.1 broken.dev
+10.0.0.7
+not a hosts line
+# 10.0.0.8 disabled.dev
+`
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	parser := NewParser(path)
+	hostsFile, issues, err := parser.ParseWithReport()
+	if err != nil {
+		t.Fatalf("ParseWithReport() error = %v", err)
+	}
+
+	expected := []struct {
+		line   int
+		reason string
+	}{
+		{1, "not an entry"},
+		{8, "invalid IP address"},
+		{9, "IP address without hostnames"},
+		{10, "not an entry"},
+	}
+	if len(issues) != len(expected) {
+		t.Fatalf("ParseWithReport() issues = %+v, want %d", issues, len(expected))
+	}
+	for i, want := range expected {
+		if issues[i].LineNum != want.line || !strings.Contains(issues[i].Reason, want.reason) {
+			t.Errorf("issue %d = %+v, want line %d with %q", i, issues[i], want.line, want.reason)
+		}
+	}
+	if issues[2].Line != "10.0.0.7" {
+		t.Errorf("Issue should keep the original line, got %q", issues[2].Line)
+	}
+	if !slices.Equal(issues, hostsFile.ParseIssues) {
+		t.Errorf("ParseWithReport() issues = %+v, want the file's ParseIssues %+v", issues, hostsFile.ParseIssues)
+	}
+	if !issues[1].InvalidIP || issues[0].InvalidIP {
+		t.Errorf("Only the invalid IP line should be marked InvalidIP, got %+v", issues)
+	}
+
+	// Parse stays lenient and reads the same entries
+	plain, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(plain.GetCategory("development").Entries) != len(hostsFile.GetCategory("development").Entries) {
+		t.Error("Parse and ParseWithReport should read the same entries")
+	}
+
+	// Issues from an earlier parse are not carried over
+	if err := os.WriteFile(path, []byte("127.0.0.1 localhost\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, issues, _ := parser.ParseWithReport(); len(issues) != 0 {
+		t.Errorf("Expected no issues for a clean file, got %+v", issues)
+	}
+}
+
+func TestParseReversedLines(t *testing.T) {
+	content := "# @category development\nlocalhost 127.0.0.1\napp.local api.local 192.168.1.10 # staging @tags web\n"
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	t.Run("report", func(t *testing.T) {
+		hostsFile, issues, err := NewParser(path).ParseWithReport()
+		if err != nil {
+			t.Fatalf("ParseWithReport() error = %v", err)
+		}
+		if category := hostsFile.GetCategory("development"); category != nil && len(category.Entries) != 0 {
+			t.Errorf("Reversed lines should not be read as entries when reporting, got %+v", category.Entries)
+		}
+		if len(issues) != 2 {
+			t.Fatalf("ParseWithReport() issues = %+v, want 2", issues)
+		}
+		if issues[0].LineNum != 2 || !strings.Contains(issues[0].Reason, "hostname written before IP address") || !strings.Contains(issues[0].Reason, `"127.0.0.1 localhost"`) {
+			t.Errorf("issue = %+v, want localhost 127.0.0.1 reported as reversed", issues[0])
+		}
+	})
+
+	t.Run("fix", func(t *testing.T) {
+		parser := NewParser(path)
+		parser.SetReversedLineMode(ReversedFix)
+		hostsFile, issues, err := parser.ParseWithReport()
+		if err != nil {
+			t.Fatalf("ParseWithReport() error = %v", err)
+		}
+		if len(issues) != 0 {
+			t.Errorf("Fixed lines should not be reported, got %+v", issues)
+		}
+
+		category := hostsFile.GetCategory("development")
+		if category == nil || len(category.Entries) != 2 {
+			t.Fatalf("development = %+v, want 2 entries", category)
+		}
+		localhost := category.Entries[0]
+		if localhost.IP != "127.0.0.1" || !slices.Equal(localhost.Hostnames, []string{"localhost"}) || !localhost.Enabled {
+			t.Errorf("entry = %+v, want 127.0.0.1 localhost", localhost)
+		}
+		app := category.Entries[1]
+		if app.IP != "192.168.1.10" || app.Comment != "staging" || !slices.Equal(app.Tags, []string{"web"}) {
+			t.Errorf("entry = %+v, want comment and tags kept", app)
+		}
+
+		fixedPath := filepath.Join(t.TempDir(), "hosts")
+		if err := hostsFile.Write(fixedPath); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		data, err := os.ReadFile(fixedPath)
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		if strings.Contains(string(data), "localhost 127.0.0.1") || !strings.Contains(string(data), "127.0.0.1") {
+			t.Errorf("Fixed entries should be written IP first:\n%s", data)
+		}
+	})
+
+	t.Run("not reversed", func(t *testing.T) {
+		for _, line := range []string{"127.0.0.1", "127.0.0.1 localhost", "10.0.0.1 10.0.0.2", "localhost"} {
+			if _, _, _, ok := splitReversedLine(line); ok {
+				t.Errorf("splitReversedLine(%q) should not detect a reversed line", line)
+			}
+		}
+	})
+}
@@ -0,0 +1,221 @@
+package hosts
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// IDNMode controls how AddEntry treats internationalized (non-ASCII) hostnames
+type IDNMode int
+
+const (
+	// IDNReject rejects non-ASCII hostnames during validation
+	IDNReject IDNMode = iota
+	// IDNPunycode stores the ASCII (xn--) form of international hostnames
+	IDNPunycode
+	// IDNAnnotate stores the ASCII form and records the Unicode form in the
+	// entry's comment
+	IDNAnnotate
+)
+
+// ParseIDNMode converts a configuration value ("reject", "punycode" or
+// "annotate") to an IDNMode. An empty value selects IDNReject.
+func ParseIDNMode(mode string) (IDNMode, error) {
+	switch mode {
+	case "", "reject":
+		return IDNReject, nil
+	case "punycode":
+		return IDNPunycode, nil
+	case "annotate":
+		return IDNAnnotate, nil
+	default:
+		return IDNReject, fmt.Errorf("invalid idn mode %q (must be reject, punycode or annotate)", mode)
+	}
+}
+
+// SetIDNMode sets how AddEntry, AddOrMergeEntry and InsertEntry treat
+// international hostnames. It is IDNReject by default.
+func (hf *HostsFile) SetIDNMode(mode IDNMode) {
+	hf.idnMode = mode
+}
+
+// ConvertIDNs returns entry with its international hostnames converted to
+// ASCII according to the file's IDNMode. Hostnames that are already ASCII
+// are unchanged, so converting twice is harmless.
+func (hf *HostsFile) ConvertIDNs(entry Entry) (Entry, error) {
+	if hf.idnMode == IDNReject {
+		return entry, nil
+	}
+
+	var originals []string
+	hostnames := make([]string, len(entry.Hostnames))
+	for i, hostname := range entry.Hostnames {
+		ascii, err := ToASCIIHostname(hostname)
+		if err != nil {
+			return entry, err
+		}
+		if ascii != hostname {
+			originals = append(originals, hostname)
+		}
+		hostnames[i] = ascii
+	}
+	entry.Hostnames = hostnames
+
+	if hf.idnMode == IDNAnnotate && len(originals) > 0 {
+		unicodeNames := strings.Join(originals, " ")
+		if entry.Comment == "" {
+			entry.Comment = unicodeNames
+		} else {
+			entry.Comment += " (" + unicodeNames + ")"
+		}
+	}
+	return entry, nil
+}
+
+// ToASCIIHostname converts an internationalized hostname to its ASCII
+// form, e.g. münchen.de becomes xn--mnchen-3ya.de. Each non-ASCII label is
+// NFC normalized, lower-cased and punycode encoded (RFC 3492). Labels that
+// mix Latin, Cyrillic or Greek letters are rejected as likely homograph
+// attacks. ASCII hostnames are returned unchanged and still need
+// ValidateHostname.
+func ToASCIIHostname(hostname string) (string, error) {
+	if isASCII(hostname) {
+		return hostname, nil
+	}
+
+	// Ideographic and full-width full stops separate labels too
+	hostname = strings.NewReplacer("。", ".", "．", ".", "｡", ".").Replace(hostname)
+	hostname = strings.ToLower(norm.NFC.String(hostname))
+
+	labels := strings.Split(hostname, ".")
+	for i, label := range labels {
+		if isASCII(label) {
+			continue
+		}
+
+		if containsHomographs(label) {
+			logValidationFailure(hostname, "hostname", "mixed-script label (possible homograph attack)")
+			return "", fmt.Errorf("hostname label %q mixes scripts (possible homograph attack)", label)
+		}
+		for _, r := range label {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.In(r, unicode.Mn, unicode.Mc) && r != '-' {
+				logValidationFailure(hostname, "hostname", "invalid character in international hostname")
+				return "", fmt.Errorf("hostname label %q contains invalid character: %c", label, r)
+			}
+		}
+
+		labels[i] = "xn--" + punycodeEncode(label)
+	}
+
+	return strings.Join(labels, "."), nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// Punycode parameters from RFC 3492 section 5
+const (
+	punyBase        = 36
+	punyTMin        = 1
+	punyTMax        = 26
+	punySkew        = 38
+	punyDamp        = 700
+	punyInitialBias = 72
+	punyInitialN    = 128
+)
+
+// punycodeEncode encodes label as described in RFC 3492 section 6.3,
+// without the xn-- prefix
+func punycodeEncode(label string) string {
+	runes := []rune(label)
+
+	var out strings.Builder
+	for _, r := range runes {
+		if r <= unicode.MaxASCII {
+			out.WriteRune(r)
+		}
+	}
+	basic := out.Len()
+	handled := basic
+	if basic > 0 {
+		out.WriteByte('-')
+	}
+
+	n, delta, bias := punyInitialN, 0, punyInitialBias
+	for handled < len(runes) {
+		// The next code point to insert is the smallest one not yet handled
+		next := int(unicode.MaxRune) + 1
+		for _, r := range runes {
+			if int(r) >= n && int(r) < next {
+				next = int(r)
+			}
+		}
+		delta += (next - n) * (handled + 1)
+		n = next
+
+		for _, r := range runes {
+			if int(r) < n {
+				delta++
+			}
+			if int(r) != n {
+				continue
+			}
+
+			q := delta
+			for k := punyBase; ; k += punyBase {
+				t := k - bias
+				if t < punyTMin {
+					t = punyTMin
+				} else if t > punyTMax {
+					t = punyTMax
+				}
+				if q < t {
+					break
+				}
+				out.WriteByte(punyDigit(t + (q-t)%(punyBase-t)))
+				q = (q - t) / (punyBase - t)
+			}
+			out.WriteByte(punyDigit(q))
+
+			bias = punyAdapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+		delta++
+		n++
+	}
+
+	return out.String()
+}
+
+func punyAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punyDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+
+	k := 0
+	for delta > ((punyBase-punyTMin)*punyTMax)/2 {
+		delta /= punyBase - punyTMin
+		k += punyBase
+	}
+	return k + (punyBase-punyTMin+1)*delta/(delta+punySkew)
+}
+
+func punyDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
@@ -0,0 +1,111 @@
+package hosts
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestToASCIIHostname(t *testing.T) {
+	tests := []struct {
+		name      string
+		hostname  string
+		expected  string
+		expectErr bool
+	}{
+		{name: "ASCII unchanged", hostname: "Example.com", expected: "Example.com"},
+		{name: "German IDN", hostname: "münchen.de", expected: "xn--mnchen-3ya.de"},
+		{name: "upper case folded", hostname: "MÜNCHEN.de", expected: "xn--mnchen-3ya.de"},
+		{name: "decomposed form normalized", hostname: "mu\u0308nchen.de", expected: "xn--mnchen-3ya.de"},
+		{name: "Cyrillic", hostname: "пример.рф", expected: "xn--e1afmkfd.xn--p1ai"},
+		{name: "Japanese with ideographic full stop", hostname: "例え。テスト", expected: "xn--r8jz45g.xn--zckzah"},
+		{name: "mixed-script homograph", hostname: "gооgle.com", expectErr: true}, // Cyrillic 'о'
+		{name: "symbol", hostname: "☃.example", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToASCIIHostname(tt.hostname)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("ToASCIIHostname(%q) = %q, want error", tt.hostname, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ToASCIIHostname(%q) error = %v", tt.hostname, err)
+			}
+			if got != tt.expected {
+				t.Errorf("ToASCIIHostname(%q) = %q, want %q", tt.hostname, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseIDNMode(t *testing.T) {
+	for value, expected := range map[string]IDNMode{"": IDNReject, "reject": IDNReject, "punycode": IDNPunycode, "annotate": IDNAnnotate} {
+		if got, err := ParseIDNMode(value); err != nil || got != expected {
+			t.Errorf("ParseIDNMode(%q) = %v, %v; want %v", value, got, err, expected)
+		}
+	}
+	if _, err := ParseIDNMode("unicode"); err == nil {
+		t.Error("Expected an error for an unknown IDN mode")
+	}
+}
+
+func TestConvertIDNs(t *testing.T) {
+	entry := Entry{IP: "127.0.0.1", Hostnames: []string{"münchen.de", "app.local"}, Comment: "city", Enabled: true}
+
+	hf := &HostsFile{}
+	if got, err := hf.ConvertIDNs(entry); err != nil || !slices.Equal(got.Hostnames, entry.Hostnames) {
+		t.Errorf("IDNReject should leave hostnames alone, got %v, %v", got.Hostnames, err)
+	}
+
+	hf.SetIDNMode(IDNPunycode)
+	got, err := hf.ConvertIDNs(entry)
+	if err != nil {
+		t.Fatalf("ConvertIDNs() error = %v", err)
+	}
+	if !slices.Equal(got.Hostnames, []string{"xn--mnchen-3ya.de", "app.local"}) || got.Comment != "city" {
+		t.Errorf("ConvertIDNs() = %v %q", got.Hostnames, got.Comment)
+	}
+	if entry.Hostnames[0] != "münchen.de" {
+		t.Error("ConvertIDNs() must not modify the caller's hostnames")
+	}
+
+	hf.SetIDNMode(IDNAnnotate)
+	got, _ = hf.ConvertIDNs(entry)
+	if got.Comment != "city (münchen.de)" {
+		t.Errorf("Comment = %q, want the Unicode form appended", got.Comment)
+	}
+	// Converting again finds nothing new to annotate
+	if again, _ := hf.ConvertIDNs(got); again.Comment != got.Comment {
+		t.Errorf("Second conversion changed the comment to %q", again.Comment)
+	}
+
+	entry.Comment = ""
+	if got, _ = hf.ConvertIDNs(entry); got.Comment != "münchen.de" {
+		t.Errorf("Comment = %q, want the Unicode form", got.Comment)
+	}
+}
+
+func TestAddEntryIDN(t *testing.T) {
+	entry := Entry{IP: "127.0.0.1", Hostnames: []string{"münchen.de"}, Enabled: true}
+
+	hf := &HostsFile{}
+	if err := hf.AddEntry(entry); err == nil {
+		t.Error("International hostnames should be rejected by default")
+	}
+
+	hf.SetIDNMode(IDNPunycode)
+	if err := hf.AddEntry(entry); err != nil {
+		t.Fatalf("AddEntry() error = %v", err)
+	}
+	if found := hf.FindEntryByHostname("xn--mnchen-3ya.de"); len(found) != 1 {
+		t.Errorf("Expected the punycode hostname to be stored, got %+v", hf.Categories)
+	}
+
+	homograph := Entry{IP: "127.0.0.1", Hostnames: []string{"pаypal.com"}, Enabled: true} // Cyrillic 'а'
+	if err := hf.AddEntry(homograph); err == nil {
+		t.Error("Mixed-script hostnames should be rejected even when IDNs are converted")
+	}
+}
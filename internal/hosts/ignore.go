@@ -0,0 +1,125 @@
+package hosts
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// IgnoreFileName is the name of the file listing hostnames that hosts-manager
+// must treat as read-only
+const IgnoreFileName = ".hostsignore"
+
+// IgnoreList holds hostname patterns loaded from an ignore file. Entries with a
+// matching hostname are preserved verbatim and excluded from managed operations.
+type IgnoreList struct {
+	patterns []string
+}
+
+// NewIgnoreList creates an ignore list from the given hostname patterns
+func NewIgnoreList(patterns []string) (*IgnoreList, error) {
+	il := &IgnoreList{}
+	for _, pattern := range patterns {
+		if err := il.add(pattern); err != nil {
+			return nil, err
+		}
+	}
+	return il, nil
+}
+
+// LoadIgnoreFile reads an ignore file with one hostname or glob pattern per line.
+// Blank lines and lines starting with '#' are skipped. A missing file yields an
+// empty list.
+func LoadIgnoreFile(filePath string) (*IgnoreList, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &IgnoreList{}, nil
+		}
+		return nil, fmt.Errorf("failed to open ignore file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	il := &IgnoreList{}
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := il.add(line); err != nil {
+			return nil, fmt.Errorf("ignore file line %d: %w", lineNum, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading ignore file: %w", err)
+	}
+
+	return il, nil
+}
+
+func (il *IgnoreList) add(pattern string) error {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return fmt.Errorf("empty ignore pattern")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid ignore pattern %q: %w", pattern, err)
+	}
+	il.patterns = append(il.patterns, pattern)
+	return nil
+}
+
+// Len returns the number of patterns in the list
+func (il *IgnoreList) Len() int {
+	if il == nil {
+		return 0
+	}
+	return len(il.patterns)
+}
+
+// Matches reports whether hostname matches any pattern in the list
+func (il *IgnoreList) Matches(hostname string) bool {
+	if il == nil {
+		return false
+	}
+	hostname = strings.ToLower(hostname)
+	for _, pattern := range il.patterns {
+		if matched, _ := path.Match(pattern, hostname); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesEntry reports whether any of the entry's hostnames match the list
+func (il *IgnoreList) MatchesEntry(entry Entry) bool {
+	for _, hostname := range entry.Hostnames {
+		if il.Matches(hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReadOnly reports whether any entry containing hostname is read-only
+func (hf *HostsFile) IsReadOnly(hostname string) bool {
+	for _, category := range hf.Categories {
+		for _, entry := range category.Entries {
+			if !entry.ReadOnly {
+				continue
+			}
+			for _, h := range entry.Hostnames {
+				if h == hostname {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
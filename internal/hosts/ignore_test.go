@@ -0,0 +1,149 @@
+package hosts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadIgnoreFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		expectErr bool
+		matches   []string
+		noMatches []string
+	}{
+		{
+			name:      "exact hostnames and comments",
+			content:   "# managed by docker\nhost.docker.internal\n\ngateway.docker.internal\n",
+			matches:   []string{"host.docker.internal", "HOST.docker.internal", "gateway.docker.internal"},
+			noMatches: []string{"docker.internal", "api.dev"},
+		},
+		{
+			name:      "glob patterns",
+			content:   "*.vpn.corp\nkube-?.local\n",
+			matches:   []string{"db.vpn.corp", "kube-1.local"},
+			noMatches: []string{"vpn.corp", "kube-10.local"},
+		},
+		{
+			name:      "invalid pattern",
+			content:   "[bad\n",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), IgnoreFileName)
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			il, err := LoadIgnoreFile(path)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, h := range tt.matches {
+				if !il.Matches(h) {
+					t.Errorf("expected %q to match", h)
+				}
+			}
+			for _, h := range tt.noMatches {
+				if il.Matches(h) {
+					t.Errorf("expected %q not to match", h)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadIgnoreFileMissing(t *testing.T) {
+	il, err := LoadIgnoreFile(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatalf("unexpected error for missing file: %v", err)
+	}
+	if il.Len() != 0 {
+		t.Errorf("expected empty ignore list, got %d patterns", il.Len())
+	}
+}
+
+func TestIgnoredEntriesPreservedOnWrite(t *testing.T) {
+	content := `127.0.0.1 localhost
+172.17.0.1    host.docker.internal    # added by docker
+192.168.1.10	api.dev
+`
+	filePath := createTestHostsFile(t, content)
+	defer func() { _ = os.Remove(filePath) }()
+
+	il, err := NewIgnoreList([]string{"*.docker.internal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parser := NewParser(filePath)
+	parser.SetIgnoreList(il)
+	hf, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	if !hf.IsReadOnly("host.docker.internal") {
+		t.Error("expected host.docker.internal to be read-only")
+	}
+	if hf.IsReadOnly("api.dev") {
+		t.Error("expected api.dev not to be read-only")
+	}
+
+	// Modified managed entries are reformatted
+	hf.DisableEntry("api.dev")
+
+	if err := hf.Write(filePath); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := string(data)
+
+	if !strings.Contains(output, "172.17.0.1    host.docker.internal    # added by docker\n") {
+		t.Errorf("ignored entry was not preserved verbatim:\n%s", output)
+	}
+	if !strings.Contains(output, "# 192.168.1.10 api.dev\n") {
+		t.Errorf("modified managed entry should be reformatted:\n%s", output)
+	}
+}
+
+func TestModifiedReadOnlyEntryIsReformatted(t *testing.T) {
+	filePath := createTestHostsFile(t, "172.17.0.1    host.docker.internal\n")
+	defer func() { _ = os.Remove(filePath) }()
+
+	il, _ := NewIgnoreList([]string{"host.docker.internal"})
+	parser := NewParser(filePath)
+	parser.SetIgnoreList(il)
+	hf, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	// A forced change must not be lost by re-emitting the stale raw line
+	hf.DisableEntry("host.docker.internal")
+	if err := hf.Write(filePath); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	data, _ := os.ReadFile(filePath)
+	if !strings.Contains(string(data), "# 172.17.0.1 host.docker.internal\n") {
+		t.Errorf("expected forced change to be written, got:\n%s", string(data))
+	}
+}
@@ -0,0 +1,91 @@
+package hosts
+
+import (
+	"net"
+	"strings"
+)
+
+// entryIndex maps hostnames and IPs to the positions of the entries that
+// declare them, so lookups on large files do not walk every entry
+type entryIndex struct {
+	// byHostname is keyed by the hostname exactly as written
+	byHostname map[string][]entryPos
+	// byIP is keyed by the normalized address without its zone
+	byIP map[string][]entryPos
+	// search holds each entry's lowercased IP and hostnames in file order
+	// for substring queries
+	search []searchKey
+}
+
+type entryPos struct {
+	category, entry int
+}
+
+type searchKey struct {
+	pos       entryPos
+	ip        string
+	hostnames []string
+}
+
+// BuildIndex indexes the entries by hostname and IP. While the index is
+// present, FindEntryByHostname and GetEntriesByIP are map lookups instead of
+// scans, and FindEntries scans precomputed lowercase keys. Every mutation
+// method drops the index, falling back to the linear scans until BuildIndex
+// is called again; code that changes Categories directly must call
+// BuildIndex again afterwards.
+func (hf *HostsFile) BuildIndex() {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+
+	index := &entryIndex{
+		byHostname: make(map[string][]entryPos),
+		byIP:       make(map[string][]entryPos),
+	}
+	for i, category := range hf.Categories {
+		for j, entry := range category.Entries {
+			pos := entryPos{category: i, entry: j}
+			key := searchKey{pos: pos, ip: strings.ToLower(entry.IP)}
+			for _, hostname := range entry.Hostnames {
+				index.byHostname[hostname] = appendPos(index.byHostname[hostname], pos)
+				key.hostnames = append(key.hostnames, strings.ToLower(hostname))
+			}
+			if ip, ok := indexIP(entry.IP); ok {
+				index.byIP[ip] = append(index.byIP[ip], pos)
+			}
+			index.search = append(index.search, key)
+		}
+	}
+	hf.index = index
+}
+
+// HasIndex reports whether BuildIndex has been called since the last change
+func (hf *HostsFile) HasIndex() bool {
+	return hf.index != nil
+}
+
+// invalidateIndex drops the index after a change to the entries
+func (hf *HostsFile) invalidateIndex() {
+	hf.index = nil
+}
+
+// appendPos adds pos unless an entry lists the same hostname twice
+func appendPos(positions []entryPos, pos entryPos) []entryPos {
+	if n := len(positions); n > 0 && positions[n-1] == pos {
+		return positions
+	}
+	return append(positions, pos)
+}
+
+// indexIP returns the key byIP uses for ip, ignoring any zone
+func indexIP(ip string) (string, bool) {
+	addr, _ := SplitZone(strings.TrimSpace(ip))
+	parsed := net.ParseIP(NormalizeIP(addr))
+	if parsed == nil {
+		return "", false
+	}
+	return parsed.String(), true
+}
+
+func (hf *HostsFile) entryAt(pos entryPos) *Entry {
+	return &hf.Categories[pos.category].Entries[pos.entry]
+}
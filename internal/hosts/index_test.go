@@ -0,0 +1,130 @@
+package hosts
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildIndexMatchesLinearLookups(t *testing.T) {
+	content := `127.0.0.1 localhost
+::1 localhost ip6-localhost
+
+# @category development
+192.168.1.10 api.dev API.dev
+192.168.1.100 web.dev
+fe80::1%eth0 router.lan
+# 10.0.0.1 old.dev
+`
+	linear, err := NewParser("").ParseReader(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	indexed := linear.Clone()
+	indexed.BuildIndex()
+	if !indexed.HasIndex() || linear.HasIndex() {
+		t.Fatal("Expected only the indexed file to have an index")
+	}
+
+	for _, query := range []string{"api", "API.dev", "192.168.1.10", "localhost", "dev", "missing"} {
+		if got, want := indexed.FindEntries(query), linear.FindEntries(query); !reflect.DeepEqual(got, want) {
+			t.Errorf("FindEntries(%q) = %+v, want %+v", query, got, want)
+		}
+	}
+	for _, ip := range []string{"192.168.1.10", "0:0:0:0:0:0:0:1", "fe80::1", "fe80::1%eth0", "fe80::1%eth1", "10.0.0.1", "bogus"} {
+		if got, want := indexed.GetEntriesByIP(ip), linear.GetEntriesByIP(ip); !reflect.DeepEqual(got, want) {
+			t.Errorf("GetEntriesByIP(%q) = %+v, want %+v", ip, got, want)
+		}
+	}
+	for _, hostname := range []string{"api.dev", "API.dev", "localhost", "old.dev", "Web.dev"} {
+		got, want := indexed.FindEntryByHostname(hostname), linear.FindEntryByHostname(hostname)
+		if len(got) != len(want) {
+			t.Errorf("FindEntryByHostname(%q) returned %d entries, want %d", hostname, len(got), len(want))
+			continue
+		}
+		for i := range got {
+			if !reflect.DeepEqual(*got[i], *want[i]) {
+				t.Errorf("FindEntryByHostname(%q)[%d] = %+v, want %+v", hostname, i, *got[i], *want[i])
+			}
+		}
+	}
+}
+
+func TestIndexInvalidatedOnMutation(t *testing.T) {
+	hf, err := NewParser("").ParseReader(strings.NewReader("# @category development\n192.168.1.10 api.dev\n"))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	mutations := map[string]func(){
+		"AddEntry": func() {
+			_ = hf.AddEntry(Entry{IP: "192.168.1.11", Hostnames: []string{"web.dev"}, Category: "development", Enabled: true})
+		},
+		"DisableEntry": func() { hf.DisableEntry("web.dev") },
+		"SortEntries":  func() { _ = hf.SortEntries(SortByHostname, false) },
+		"RemoveEntry":  func() { hf.RemoveEntry("api.dev") },
+	}
+	for _, name := range []string{"AddEntry", "DisableEntry", "SortEntries", "RemoveEntry"} {
+		hf.BuildIndex()
+		mutations[name]()
+		if hf.HasIndex() {
+			t.Errorf("%s should drop the index", name)
+		}
+	}
+
+	hf.BuildIndex()
+	if entries := hf.FindEntryByHostname("web.dev"); len(entries) != 1 || entries[0].Enabled {
+		t.Errorf("FindEntryByHostname(web.dev) after rebuild = %+v", entries)
+	}
+	if entries := hf.FindEntryByHostname("api.dev"); len(entries) != 0 {
+		t.Errorf("Removed entry still found: %+v", entries)
+	}
+}
+
+func largeHostsFile(n int) *HostsFile {
+	hf := &HostsFile{Categories: []Category{{Name: CategoryDefault, Enabled: true}}}
+	for i := 0; i < n; i++ {
+		hf.Categories[0].Entries = append(hf.Categories[0].Entries, Entry{
+			IP:        fmt.Sprintf("10.%d.%d.%d", i>>16&255, i>>8&255, i&255),
+			Hostnames: []string{fmt.Sprintf("host%d.local", i)},
+			Category:  CategoryDefault,
+			Enabled:   true,
+		})
+	}
+	return hf
+}
+
+func BenchmarkLookup10k(b *testing.B) {
+	const n = 10000
+	for _, indexed := range []bool{false, true} {
+		hf := largeHostsFile(n)
+		name := "linear"
+		if indexed {
+			hf.BuildIndex()
+			name = "indexed"
+		}
+
+		b.Run("FindEntryByHostname/"+name, func(b *testing.B) {
+			i := 0
+			for b.Loop() {
+				hf.FindEntryByHostname(fmt.Sprintf("host%d.local", i%n))
+				i++
+			}
+		})
+		b.Run("GetEntriesByIP/"+name, func(b *testing.B) {
+			i := 0
+			for b.Loop() {
+				hf.GetEntriesByIP(fmt.Sprintf("10.%d.%d.%d", i%n>>16&255, i%n>>8&255, i%n&255))
+				i++
+			}
+		})
+		b.Run("FindEntries/"+name, func(b *testing.B) {
+			i := 0
+			for b.Loop() {
+				hf.FindEntries(fmt.Sprintf("host%d.local", i%n))
+				i++
+			}
+		})
+	}
+}
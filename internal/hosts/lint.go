@@ -0,0 +1,108 @@
+package hosts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Problem is a single validation failure found by Lint
+type Problem struct {
+	LineNum int
+	Field   string // ip, hostname, comment, category, or line
+	Value   string
+	Reason  string
+}
+
+// Lint validates every entry in the hosts file without modifying it and
+// returns the problems found, ordered by line number. Lines skipped during
+// parsing, duplicate hostnames, and
+// hostnames shadowed by an earlier entry with a different IP are reported too.
+func (hf *HostsFile) Lint() []Problem {
+	var problems []Problem
+
+	for _, issue := range hf.ParseIssues {
+		problem := Problem{LineNum: issue.LineNum, Field: "line", Value: strings.TrimSpace(issue.Line), Reason: issue.Reason}
+		if issue.InvalidIP {
+			problem.Field = "ip"
+			problem.Value = strings.Fields(issue.Line)[0]
+		}
+		problems = append(problems, problem)
+	}
+
+	for _, category := range hf.Categories {
+		categoryErr := ValidateCategoryName(category.Name)
+
+		for _, entry := range category.Entries {
+			if err := ValidateIP(entry.IP); err != nil {
+				problems = append(problems, Problem{LineNum: entry.LineNum, Field: "ip", Value: entry.IP, Reason: err.Error()})
+			}
+			for _, hostname := range entry.Hostnames {
+				if err := ValidateHostnameWithMode(hostname, hf.hostnameMode); err != nil {
+					problems = append(problems, Problem{LineNum: entry.LineNum, Field: "hostname", Value: hostname, Reason: err.Error()})
+				}
+			}
+			if err := ValidateComment(entry.Comment); err != nil {
+				problems = append(problems, Problem{LineNum: entry.LineNum, Field: "comment", Value: entry.Comment, Reason: err.Error()})
+			}
+			if categoryErr != nil {
+				problems = append(problems, Problem{LineNum: entry.LineNum, Field: "category", Value: category.Name, Reason: categoryErr.Error()})
+			}
+		}
+	}
+
+	// A shadow with the same IP is harmless and reported only as a duplicate
+	type lineHost struct {
+		line     int
+		hostname string
+	}
+	shadows := make(map[lineHost]ShadowedEntry)
+	for _, shadow := range hf.FindShadowedEntries() {
+		if CanonicalIP(shadow.Entry.IP) != CanonicalIP(shadow.ShadowedBy.IP) {
+			shadows[lineHost{shadow.Entry.LineNum, shadow.Hostname}] = shadow
+		}
+	}
+	shadowReason := func(shadow ShadowedEntry) string {
+		return fmt.Sprintf("shadowed by %s on line %d, so this entry never resolves", shadow.ShadowedBy.IP, shadow.ShadowedBy.LineNum)
+	}
+
+	for hostname, occurrences := range hf.FindDuplicateHostnames() {
+		lines := make([]string, len(occurrences))
+		for i, occurrence := range occurrences {
+			lines[i] = fmt.Sprintf("%d", occurrence.LineNum)
+		}
+		for _, occurrence := range occurrences[1:] {
+			reason := fmt.Sprintf("duplicate hostname (declared on lines %s)", strings.Join(lines, ", "))
+			key := lineHost{occurrence.LineNum, hostname}
+			if shadow, ok := shadows[key]; ok {
+				reason += "; " + shadowReason(shadow)
+				delete(shadows, key)
+			}
+			problems = append(problems, Problem{
+				LineNum: occurrence.LineNum,
+				Field:   "hostname",
+				Value:   hostname,
+				Reason:  reason,
+			})
+		}
+	}
+
+	// Shadows that differ only in case are not exact duplicates
+	for _, shadow := range shadows {
+		problems = append(problems, Problem{
+			LineNum: shadow.Entry.LineNum,
+			Field:   "hostname",
+			Value:   shadow.Hostname,
+			Reason:  shadowReason(shadow),
+		})
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].LineNum != problems[j].LineNum {
+			return problems[i].LineNum < problems[j].LineNum
+		}
+		return problems[i].Value < problems[j].Value
+	})
+
+	return problems
+}
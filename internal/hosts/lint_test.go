@@ -0,0 +1,63 @@
+package hosts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	content := `127.0.0.1 localhost
+999.1.1.1 badip.dev
+192.168.1.10 my_host.dev
+192.168.1.11 api.dev # <script>alert(1)</script>
+192.168.1.12 api.dev
+# 300.0.0.1 commented.dev
+`
+	hostsFile, err := NewParser("").ParseReader(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	expected := []struct {
+		line  int
+		field string
+		value string
+	}{
+		{2, "ip", "999.1.1.1"},
+		{3, "hostname", "my_host.dev"},
+		{4, "comment", "<script>alert(1)</script>"},
+		{5, "hostname", "api.dev"},
+	}
+
+	problems := hostsFile.Lint()
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %d: %+v", len(expected), len(problems), problems)
+	}
+	for i, want := range expected {
+		got := problems[i]
+		if got.LineNum != want.line || got.Field != want.field || got.Value != want.value {
+			t.Errorf("problem %d = {%d %s %q}, want {%d %s %q}", i, got.LineNum, got.Field, got.Value, want.line, want.field, want.value)
+		}
+		if got.Reason == "" {
+			t.Errorf("problem %d has no reason", i)
+		}
+	}
+
+	if !strings.Contains(problems[3].Reason, "lines 4, 5") {
+		t.Errorf("Expected duplicate reason to list both lines, got %q", problems[3].Reason)
+	}
+}
+
+func TestLintCleanFile(t *testing.T) {
+	hostsFile, err := NewParser("").ParseReader(strings.NewReader(sampleHostsContent))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	// localhost is declared for both 127.0.0.1 and ::1 in the sample
+	for _, problem := range hostsFile.Lint() {
+		if problem.Value != "localhost" {
+			t.Errorf("Unexpected problem in sample hosts file: %+v", problem)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package hosts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// localhostIPs are the addresses localhost is expected to resolve to
+var localhostIPs = []string{"127.0.0.1", "::1"}
+
+// isLocalhostIP reports whether ip is one of localhostIPs
+func isLocalhostIP(ip string) bool {
+	canonical := CanonicalIP(ip)
+	for _, expected := range localhostIPs {
+		if canonical == expected {
+			return true
+		}
+	}
+	return false
+}
+
+// localhostEntries returns the enabled entries that declare localhost
+func (hf *HostsFile) localhostEntries() []Entry {
+	var entries []Entry
+	for _, category := range hf.Categories {
+		for _, entry := range category.Entries {
+			if !entry.Enabled {
+				continue
+			}
+			for _, hostname := range entry.Hostnames {
+				if strings.EqualFold(hostname, "localhost") {
+					entries = append(entries, entry)
+					break
+				}
+			}
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return lineOrder(entries[i].LineNum) < lineOrder(entries[j].LineNum)
+	})
+	return entries
+}
+
+// HasLocalhostMapping reports whether an enabled entry maps localhost to
+// 127.0.0.1 or ::1
+func (hf *HostsFile) HasLocalhostMapping() bool {
+	for _, entry := range hf.localhostEntries() {
+		if isLocalhostIP(entry.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// LocalhostWarnings reports a missing localhost mapping, localhost mapped to
+// anything other than 127.0.0.1 or ::1, and the same mapping declared more
+// than once. Many tools break subtly without a correct localhost, but the
+// file is still usable, so these are warnings rather than Lint problems. A
+// missing mapping has no line number.
+func (hf *HostsFile) LocalhostWarnings() []Problem {
+	var warnings []Problem
+	if !hf.HasLocalhostMapping() {
+		warnings = append(warnings, Problem{
+			Field:  "hostname",
+			Value:  "localhost",
+			Reason: "localhost is not mapped to 127.0.0.1 or ::1",
+		})
+	}
+
+	firstLine := make(map[string]int)
+	for _, entry := range hf.localhostEntries() {
+		if !isLocalhostIP(entry.IP) {
+			warnings = append(warnings, Problem{
+				LineNum: entry.LineNum,
+				Field:   "ip",
+				Value:   entry.IP,
+				Reason:  "localhost should only resolve to 127.0.0.1 or ::1",
+			})
+			continue
+		}
+
+		ip := CanonicalIP(entry.IP)
+		if line, seen := firstLine[ip]; seen {
+			warnings = append(warnings, Problem{
+				LineNum: entry.LineNum,
+				Field:   "hostname",
+				Value:   "localhost",
+				Reason:  fmt.Sprintf("localhost is already mapped to %s on line %d", ip, line),
+			})
+			continue
+		}
+		firstLine[ip] = entry.LineNum
+	}
+
+	return warnings
+}
@@ -0,0 +1,72 @@
+package hosts
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLocalhostWarnings(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		mapped  bool
+		want    []string
+	}{
+		{
+			name:    "IPv4 and IPv6 mappings",
+			content: "127.0.0.1 localhost\n::1 localhost ip6-localhost\n",
+			mapped:  true,
+		},
+		{
+			name:    "missing",
+			content: "10.0.0.5 app.local\n# 127.0.0.1 localhost\n",
+			want:    []string{`hostname "localhost": localhost is not mapped to 127.0.0.1 or ::1`},
+		},
+		{
+			name:    "wrong IP",
+			content: "127.0.0.1 localhost\n10.0.0.5 LocalHost\n",
+			mapped:  true,
+			want:    []string{`2 ip "10.0.0.5": localhost should only resolve to 127.0.0.1 or ::1`},
+		},
+		{
+			name:    "duplicate",
+			content: "127.0.0.1 localhost\n::1 localhost\n127.0.0.1 localhost.localdomain localhost\n",
+			mapped:  true,
+			want:    []string{`3 hostname "localhost": localhost is already mapped to 127.0.0.1 on line 1`},
+		},
+		{
+			name:    "only wrong IP",
+			content: "192.168.1.10 localhost\n",
+			want: []string{
+				`hostname "localhost": localhost is not mapped to 127.0.0.1 or ::1`,
+				`1 ip "192.168.1.10": localhost should only resolve to 127.0.0.1 or ::1`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hf, err := NewParser("").ParseReader(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("ParseReader() error = %v", err)
+			}
+
+			if got := hf.HasLocalhostMapping(); got != tt.mapped {
+				t.Errorf("HasLocalhostMapping() = %v, want %v", got, tt.mapped)
+			}
+
+			var got []string
+			for _, w := range hf.LocalhostWarnings() {
+				warning := fmt.Sprintf("%s %q: %s", w.Field, w.Value, w.Reason)
+				if w.LineNum != 0 {
+					warning = fmt.Sprintf("%d %s", w.LineNum, warning)
+				}
+				got = append(got, warning)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("LocalhostWarnings() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package hosts
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Markers delimiting the region of the hosts file hosts-manager owns when
+// the parser is in managed block mode; see Parser.SetManagedBlockOnly
+const (
+	ManagedBlockBegin = "# BEGIN hosts-manager"
+	ManagedBlockEnd   = "# END hosts-manager"
+)
+
+// managedRegions is the content around the managed block, kept verbatim
+type managedRegions struct {
+	// before runs up to and including the BEGIN marker line
+	before string
+	// after runs from the END marker line to the end of the file
+	after string
+	// found reports whether the file had markers. Without them, before is
+	// the whole file and Write appends a new block after it.
+	found bool
+}
+
+// SetManagedBlockOnly limits parsing to the lines between ManagedBlockBegin
+// and ManagedBlockEnd. Everything outside the markers is kept byte for byte
+// and written back unchanged by Write, so other tools can edit the rest of
+// the file. A file without markers is kept whole, and the first Write
+// appends the managed block after it.
+func (p *Parser) SetManagedBlockOnly(enabled bool) {
+	p.managedBlock = enabled
+}
+
+// ManagedBlockOnly reports whether hf was parsed in managed block mode
+func (hf *HostsFile) ManagedBlockOnly() bool {
+	return hf.managed != nil
+}
+
+// splitManagedBlock splits data around the managed block markers and
+// returns the block and the number of lines before it. A BEGIN marker
+// without a matching END marker is an error rather than a guess, so a
+// damaged block never swallows the rest of the file.
+func splitManagedBlock(data []byte) (regions managedRegions, block []byte, lineOffset int, err error) {
+	beginAt, blockAt := -1, -1
+	line := 0
+	for offset := 0; offset < len(data); line++ {
+		end := bytes.IndexByte(data[offset:], '\n')
+		next := len(data)
+		if end >= 0 {
+			next = offset + end + 1
+		}
+		text := strings.TrimSpace(string(data[offset:next]))
+
+		switch {
+		case beginAt < 0 && text == ManagedBlockBegin:
+			beginAt, blockAt, lineOffset = offset, next, line+1
+		case beginAt >= 0 && text == ManagedBlockEnd:
+			return managedRegions{
+				before: string(data[:blockAt]),
+				after:  string(data[offset:]),
+				found:  true,
+			}, data[blockAt:offset], lineOffset, nil
+		case beginAt >= 0 && text == ManagedBlockBegin:
+			return managedRegions{}, nil, 0, fmt.Errorf("line %d: managed block begins again before %q", line+1, ManagedBlockEnd)
+		}
+		offset = next
+	}
+
+	if beginAt >= 0 {
+		return managedRegions{}, nil, 0, fmt.Errorf("line %d: %q has no matching %q", lineOffset, ManagedBlockBegin, ManagedBlockEnd)
+	}
+	return managedRegions{before: string(data)}, nil, 0, nil
+}
+
+// managedBefore returns what Write puts ahead of the generated content: the
+// preserved text through the BEGIN marker, adding the marker when the file
+// had none
+func (m *managedRegions) managedBefore() string {
+	if m.found {
+		return m.before
+	}
+	before := m.before
+	if before != "" && !strings.HasSuffix(before, "\n") {
+		before += "\n"
+	}
+	return before + ManagedBlockBegin + "\n"
+}
+
+// managedAfter returns what Write puts after the generated content
+func (m *managedRegions) managedAfter() string {
+	if m.found {
+		return m.after
+	}
+	return ManagedBlockEnd + "\n"
+}
@@ -0,0 +1,118 @@
+package hosts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func parseManaged(t *testing.T, path string) *HostsFile {
+	t.Helper()
+	parser := NewParser(path)
+	parser.SetManagedBlockOnly(true)
+	hf, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return hf
+}
+
+func TestManagedBlockRoundTrip(t *testing.T) {
+	before := "# Written by another tool\r\n127.0.0.1 localhost\r\n10.1.1.1   vpn.corp   # keep my spacing\n\n" + ManagedBlockBegin + "\n"
+	block := "# @category development\n192.168.1.10 api.dev\n"
+	after := ManagedBlockEnd + "\n# trailing notes\n10.2.2.2 other.corp"
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte(before+block+after), 0644); err != nil {
+		t.Fatalf("Failed to write hosts file: %v", err)
+	}
+
+	hf := parseManaged(t, path)
+	if !hf.ManagedBlockOnly() {
+		t.Fatal("Expected the file to be in managed block mode")
+	}
+	if len(hf.FindEntryByHostname("vpn.corp")) != 0 || len(hf.FindEntryByHostname("other.corp")) != 0 {
+		t.Error("Entries outside the managed block should not be parsed")
+	}
+	entries := hf.FindEntryByHostname("api.dev")
+	if len(entries) != 1 || entries[0].LineNum != 7 {
+		t.Fatalf("api.dev entries = %+v, want one on line 7", entries)
+	}
+
+	if err := hf.AddEntry(Entry{IP: "192.168.1.11", Hostnames: []string{"web.dev"}, Category: "development", Enabled: true}); err != nil {
+		t.Fatalf("AddEntry() error = %v", err)
+	}
+	if err := hf.Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read hosts file: %v", err)
+	}
+	written := string(data)
+	if !strings.HasPrefix(written, before) || !strings.HasSuffix(written, after) {
+		t.Fatalf("Content outside the managed block changed:\n%q", written)
+	}
+	if strings.Contains(written, "managed by hosts-manager") {
+		t.Error("The file-wide managed header should not be written in managed block mode")
+	}
+
+	reparsed := parseManaged(t, path)
+	if len(reparsed.FindEntryByHostname("web.dev")) != 1 || len(reparsed.FindEntryByHostname("api.dev")) != 1 {
+		t.Errorf("Managed block entries were not written back, got %+v", reparsed.Categories)
+	}
+}
+
+func TestManagedBlockWithoutMarkers(t *testing.T) {
+	original := "127.0.0.1 localhost\n::1 localhost"
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write hosts file: %v", err)
+	}
+
+	hf := parseManaged(t, path)
+	if err := hf.AddEntry(Entry{IP: "192.168.1.10", Hostnames: []string{"api.dev"}, Enabled: true}); err != nil {
+		t.Fatalf("AddEntry() error = %v", err)
+	}
+	if err := hf.Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	first, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read hosts file: %v", err)
+	}
+	if !strings.HasPrefix(string(first), original+"\n"+ManagedBlockBegin+"\n") || !strings.HasSuffix(string(first), ManagedBlockEnd+"\n") {
+		t.Fatalf("Expected the block appended after the original content, got:\n%s", first)
+	}
+
+	// Writing again, from the same file or a fresh parse, keeps one block
+	if err := hf.Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := parseManaged(t, path).Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	second, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read hosts file: %v", err)
+	}
+	if string(second) != string(first) {
+		t.Errorf("Rewriting changed the file:\n%s\nwant:\n%s", second, first)
+	}
+}
+
+func TestManagedBlockMalformed(t *testing.T) {
+	for name, content := range map[string]string{
+		"missing end":  "127.0.0.1 localhost\n" + ManagedBlockBegin + "\n192.168.1.10 api.dev\n",
+		"nested begin": ManagedBlockBegin + "\n" + ManagedBlockBegin + "\n" + ManagedBlockEnd + "\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			parser := NewParser("")
+			parser.SetManagedBlockOnly(true)
+			if _, err := parser.ParseReader(strings.NewReader(content)); err == nil {
+				t.Error("ParseReader() expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package hosts
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MergeStrategy decides what MergeFiles does when an incoming entry maps a
+// hostname to a different IP than the base file
+type MergeStrategy string
+
+const (
+	// MergeKeepBoth appends every incoming entry, leaving conflicting
+	// mappings side by side. This is how import --merge has always behaved.
+	MergeKeepBoth MergeStrategy = "keep-both"
+	// MergePreferBase drops conflicting hostnames from incoming entries
+	MergePreferBase MergeStrategy = "prefer-base"
+	// MergePreferIncoming removes conflicting hostnames from base entries
+	MergePreferIncoming MergeStrategy = "prefer-incoming"
+	// MergeFailOnConflict refuses to merge when there is any conflict
+	MergeFailOnConflict MergeStrategy = "fail-on-conflict"
+)
+
+// ErrMergeConflict is returned by MergeFiles under MergeFailOnConflict
+var ErrMergeConflict = errors.New("merge conflict")
+
+// ParseMergeStrategy converts a flag value to a MergeStrategy. An empty value
+// selects MergeKeepBoth.
+func ParseMergeStrategy(strategy string) (MergeStrategy, error) {
+	switch MergeStrategy(strategy) {
+	case "", MergeKeepBoth:
+		return MergeKeepBoth, nil
+	case MergePreferBase, MergePreferIncoming, MergeFailOnConflict:
+		return MergeStrategy(strategy), nil
+	default:
+		return MergeKeepBoth, fmt.Errorf("invalid merge strategy %q (must be keep-both, prefer-base, prefer-incoming, or fail-on-conflict)", strategy)
+	}
+}
+
+// Conflict is a hostname that an incoming entry maps to a different IP than
+// an entry of the base file
+type Conflict struct {
+	Hostname string
+	Base     Entry
+	Incoming Entry
+}
+
+// MergeFiles merges incoming into a copy of base under strategy, validating
+// incoming entries strictly. See MergeFilesWithMode.
+func MergeFiles(base, incoming *HostsFile, strategy MergeStrategy) (*HostsFile, []Conflict, error) {
+	return MergeFilesWithMode(base, incoming, strategy, HostnameStrict)
+}
+
+// MergeFilesWithMode merges the entries of incoming into a copy of base,
+// validating them under mode; base and incoming are not changed. A hostname
+// conflicts when base maps it, enabled or not, only to other IPs, as in
+// CompareForMerge. Every conflict is returned whatever the strategy, so
+// callers can report them.
+//
+// Except under MergeKeepBoth, hostnames already mapped to the incoming IP
+// are left out of the added entries, and entries left without hostnames are
+// skipped. MergePreferIncoming never changes
+// read-only base entries, and drops base entries left without hostnames.
+// Under MergeFailOnConflict any conflict returns a nil file and an error
+// wrapping ErrMergeConflict.
+func MergeFilesWithMode(base, incoming *HostsFile, strategy MergeStrategy, mode HostnameValidationMode) (*HostsFile, []Conflict, error) {
+	if base == nil || incoming == nil {
+		return nil, nil, fmt.Errorf("cannot merge a nil hosts file")
+	}
+	if _, err := ParseMergeStrategy(string(strategy)); err != nil {
+		return nil, nil, err
+	}
+
+	mapped := make(map[string][]Entry)
+	for _, category := range base.Categories {
+		for _, entry := range category.Entries {
+			for _, hostname := range entry.Hostnames {
+				hostname = strings.ToLower(hostname)
+				mapped[hostname] = append(mapped[hostname], entry)
+			}
+		}
+	}
+
+	var conflicts []Conflict
+	var additions []Entry
+	losing := make(map[string]string)
+	for _, category := range incoming.Categories {
+		for _, entry := range category.Entries {
+			if entry.Category == "" {
+				entry.Category = category.Name
+			}
+			ip := NormalizeIP(entry.IP)
+
+			var kept []string
+			for _, hostname := range entry.Hostnames {
+				existing := mapped[strings.ToLower(hostname)]
+				if slices.ContainsFunc(existing, func(e Entry) bool { return NormalizeIP(e.IP) == ip }) {
+					continue
+				}
+				if len(existing) == 0 {
+					kept = append(kept, hostname)
+					continue
+				}
+
+				for _, baseEntry := range existing {
+					conflicts = append(conflicts, Conflict{Hostname: hostname, Base: baseEntry, Incoming: entry})
+				}
+				if strategy != MergePreferBase {
+					kept = append(kept, hostname)
+				}
+				if strategy == MergePreferIncoming {
+					losing[strings.ToLower(hostname)] = ip
+				}
+			}
+
+			switch {
+			case strategy == MergeKeepBoth:
+				additions = append(additions, entry)
+			case len(kept) == 0:
+				continue
+			default:
+				entry.Hostnames = kept
+				additions = append(additions, entry)
+			}
+		}
+	}
+
+	if strategy == MergeFailOnConflict && len(conflicts) > 0 {
+		return nil, conflicts, fmt.Errorf("%w: %d hostname mappings differ from the current file", ErrMergeConflict, len(conflicts))
+	}
+
+	merged := base.Clone()
+	if len(losing) > 0 {
+		merged.dropConflictingHostnames(losing)
+	}
+	for _, entry := range additions {
+		if err := merged.AddEntryWithMode(entry, mode); err != nil {
+			return nil, conflicts, fmt.Errorf("failed to merge entry %s: %w", entry.IP, err)
+		}
+	}
+
+	return merged, conflicts, nil
+}
+
+// dropConflictingHostnames removes each hostname in winners from the entries
+// that map it to an IP other than the winning one. Read-only entries are
+// left alone and entries left without hostnames are removed.
+func (hf *HostsFile) dropConflictingHostnames(winners map[string]string) {
+	hf.invalidateIndex()
+	for i := range hf.Categories {
+		category := &hf.Categories[i]
+		entries := category.Entries[:0]
+		for _, entry := range category.Entries {
+			if !entry.ReadOnly {
+				ip := NormalizeIP(entry.IP)
+				var hostnames []string
+				for _, hostname := range entry.Hostnames {
+					if winner, ok := winners[strings.ToLower(hostname)]; !ok || winner == ip {
+						hostnames = append(hostnames, hostname)
+					}
+				}
+				if len(hostnames) == 0 {
+					continue
+				}
+				entry.Hostnames = hostnames
+			}
+			entries = append(entries, entry)
+		}
+		category.Entries = entries
+	}
+}
@@ -0,0 +1,144 @@
+package hosts
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMergeFiles(t *testing.T) {
+	parse := func(t *testing.T, content string) *HostsFile {
+		t.Helper()
+		hf, err := NewParser("").ParseReader(strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("ParseReader() error = %v", err)
+		}
+		return hf
+	}
+	base := `127.0.0.1 localhost
+
+# @category development
+192.168.1.10 api.dev web.dev
+192.168.1.11 db.dev
+`
+	incoming := `# @category development
+192.168.1.10 api.dev
+10.0.0.5 web.dev admin.dev
+10.0.0.6 db.dev
+`
+
+	tests := []struct {
+		strategy MergeStrategy
+		want     map[string][]string
+	}{
+		{
+			strategy: MergeKeepBoth,
+			want: map[string][]string{
+				"api.dev":   {"192.168.1.10", "192.168.1.10"},
+				"web.dev":   {"192.168.1.10", "10.0.0.5"},
+				"admin.dev": {"10.0.0.5"},
+				"db.dev":    {"192.168.1.11", "10.0.0.6"},
+			},
+		},
+		{
+			strategy: MergePreferBase,
+			want: map[string][]string{
+				"api.dev":   {"192.168.1.10"},
+				"web.dev":   {"192.168.1.10"},
+				"admin.dev": {"10.0.0.5"},
+				"db.dev":    {"192.168.1.11"},
+			},
+		},
+		{
+			strategy: MergePreferIncoming,
+			want: map[string][]string{
+				"api.dev":   {"192.168.1.10"},
+				"web.dev":   {"10.0.0.5"},
+				"admin.dev": {"10.0.0.5"},
+				"db.dev":    {"10.0.0.6"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			baseFile := parse(t, base)
+			merged, conflicts, err := MergeFiles(baseFile, parse(t, incoming), tt.strategy)
+			if err != nil {
+				t.Fatalf("MergeFiles() error = %v", err)
+			}
+
+			var hostnames []string
+			for _, conflict := range conflicts {
+				hostnames = append(hostnames, conflict.Hostname+"="+conflict.Base.IP)
+			}
+			if !slices.Equal(hostnames, []string{"web.dev=192.168.1.10", "db.dev=192.168.1.11"}) {
+				t.Errorf("MergeFiles() conflicts = %v", hostnames)
+			}
+
+			for hostname, wantIPs := range tt.want {
+				var ips []string
+				for _, entry := range merged.FindEntryByHostname(hostname) {
+					ips = append(ips, entry.IP)
+				}
+				if !slices.Equal(ips, wantIPs) {
+					t.Errorf("%s maps to %v, want %v", hostname, ips, wantIPs)
+				}
+			}
+
+			if len(baseFile.FindEntryByHostname("admin.dev")) != 0 || len(baseFile.FindEntryByHostname("web.dev")) != 1 {
+				t.Error("MergeFiles() should not change the base file")
+			}
+		})
+	}
+
+	t.Run(string(MergeFailOnConflict), func(t *testing.T) {
+		merged, conflicts, err := MergeFiles(parse(t, base), parse(t, incoming), MergeFailOnConflict)
+		if !errors.Is(err, ErrMergeConflict) {
+			t.Fatalf("MergeFiles() error = %v, want ErrMergeConflict", err)
+		}
+		if merged != nil || len(conflicts) != 2 {
+			t.Errorf("MergeFiles() = %v, %d conflicts, want nil and 2 conflicts", merged, len(conflicts))
+		}
+
+		merged, conflicts, err = MergeFiles(parse(t, base), parse(t, "10.0.0.7 new.dev\n"), MergeFailOnConflict)
+		if err != nil || len(conflicts) != 0 {
+			t.Fatalf("MergeFiles() without conflicts = %v, %v", conflicts, err)
+		}
+		if len(merged.FindEntryByHostname("new.dev")) != 1 {
+			t.Error("Expected new.dev to be merged")
+		}
+	})
+}
+
+func TestMergeFilesPreferIncomingKeepsReadOnly(t *testing.T) {
+	base, err := NewParser("").ParseReader(strings.NewReader("192.168.1.10 api.dev\n"))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	base.Categories[0].Entries[0].ReadOnly = true
+	incoming := &HostsFile{Categories: []Category{{Name: CategoryDefault, Entries: []Entry{
+		{IP: "10.0.0.5", Hostnames: []string{"api.dev"}, Enabled: true},
+	}}}}
+
+	merged, conflicts, err := MergeFiles(base, incoming, MergePreferIncoming)
+	if err != nil {
+		t.Fatalf("MergeFiles() error = %v", err)
+	}
+	if len(conflicts) != 1 || len(merged.FindEntryByHostname("api.dev")) != 2 {
+		t.Errorf("Read-only entry should be kept alongside the incoming one, got %+v", merged.Categories)
+	}
+}
+
+func TestParseMergeStrategy(t *testing.T) {
+	if strategy, err := ParseMergeStrategy(""); err != nil || strategy != MergeKeepBoth {
+		t.Errorf("ParseMergeStrategy(\"\") = %q, %v", strategy, err)
+	}
+	if strategy, err := ParseMergeStrategy("prefer-incoming"); err != nil || strategy != MergePreferIncoming {
+		t.Errorf("ParseMergeStrategy(prefer-incoming) = %q, %v", strategy, err)
+	}
+	if _, err := ParseMergeStrategy("newest"); err == nil {
+		t.Error("ParseMergeStrategy(newest) expected error")
+	}
+}
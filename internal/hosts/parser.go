@@ -2,29 +2,50 @@ package hosts
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 )
 
 var (
 	commentLineRegex = regexp.MustCompile(`^\s*#(.*)$`)
-	entryLineRegex   = regexp.MustCompile(`^\s*([0-9a-fA-F:.]+)\s+([^\s#]+(?:\s+[^\s#]+)*)\s*(?:#(.*))?$`)
+	entryLineRegex   = regexp.MustCompile(`^\s*([0-9a-fA-F:.]+(?:%[a-zA-Z0-9_.\-]+)?)\s+([^\s#]+(?:\s+[^\s#]+)*)\s*(?:#(.*))?$`)
 	categoryRegex    = regexp.MustCompile(`^\s*#\s*@category\s+(\w+)(?:\s+(.*))?$`)
-	sectionRegex     = regexp.MustCompile(`^\s*#\s*===+\s*(.*?)\s*===+\s*$`)
+	sectionRegex     = regexp.MustCompile(`^\s*#\s*(?:===+|---+)\s*(.*?)\s*(?:===+|---+)\s*$`)
 )
 
 type Parser struct {
 	filePath string
+	ignore   *IgnoreList
+	ipMode   IPMode
+	reversed ReversedLineMode
+	// managedBlock limits parsing to the managed block; see SetManagedBlockOnly
+	managedBlock bool
+	// bannerStyle is the banner template recognized under category markers
+	bannerStyle BannerStyle
 }
 
 func NewParser(filePath string) *Parser {
 	return &Parser{filePath: filePath}
 }
 
+// SetIgnoreList marks entries matching il as read-only during parsing
+func (p *Parser) SetIgnoreList(il *IgnoreList) {
+	p.ignore = il
+}
+
+// SetIPMode controls how IPv4 addresses with leading zeros (10.0.0.001) are
+// recorded; see IPMode
+func (p *Parser) SetIPMode(mode IPMode) {
+	p.ipMode = mode
+}
+
 func (p *Parser) Parse() (*HostsFile, error) {
 	file, err := os.Open(p.filePath)
 	if err != nil {
@@ -37,42 +58,170 @@ func (p *Parser) Parse() (*HostsFile, error) {
 		return nil, fmt.Errorf("failed to get file stats: %w", err)
 	}
 
+	hostsFile, err := p.ParseReader(file)
+	if err != nil {
+		return nil, err
+	}
+	hostsFile.Modified = stat.ModTime()
+	return hostsFile, nil
+}
+
+// ParseWithReport parses the file like Parse and also returns its
+// ParseIssues: every non-blank line that could not be read as an entry,
+// category marker, banner or comment. Such lines are skipped, or kept in the
+// header when they appear before the first entry, exactly as Parse does.
+func (p *Parser) ParseWithReport() (*HostsFile, []ParseIssue, error) {
+	hostsFile, err := p.Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+	return hostsFile, slices.Clone(hostsFile.ParseIssues), nil
+}
+
+// ParseReader parses hosts file content from r, such as a decompressed
+// backup. The result's FilePath is the parser's path and Modified is unset.
+func (p *Parser) ParseReader(r io.Reader) (*HostsFile, error) {
 	hostsFile := &HostsFile{
 		Categories: []Category{},
 		Header:     []string{},
 		Footer:     []string{},
-		Modified:   stat.ModTime(),
 		FilePath:   p.filePath,
 	}
 
-	scanner := bufio.NewScanner(file)
+	var categories = make(map[string]*Category)
+	var categoryOrder []string
+	addCategory := func(name, description string) {
+		if _, exists := categories[name]; !exists {
+			categoryOrder = append(categoryOrder, name)
+			categories[name] = &Category{
+				Name:        name,
+				Description: description,
+				Enabled:     true,
+				Entries:     []Entry{},
+			}
+		}
+	}
+
+	result, err := p.scan(r, addCategory, func(entry Entry) error {
+		addCategory(entry.Category, "")
+		categories[entry.Category].Entries = append(categories[entry.Category].Entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hostsFile.Header = append(hostsFile.Header, result.header...)
+	hostsFile.managed = result.managed
+	hostsFile.ParseIssues = result.issues
+	if len(result.footer) > 0 {
+		hostsFile.Footer = result.footer
+	}
+
+	for _, name := range categoryOrder {
+		hostsFile.Categories = append(hostsFile.Categories, *categories[name])
+	}
+
+	// Files written with BannerNone keep their categories in a sidecar
+	if !result.sawMarker && p.filePath != "" {
+		if err := hostsFile.applyCategorySidecar(CategorySidecarPath(p.filePath)); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(hostsFile.Categories) == 0 {
+		hostsFile.Categories = append(hostsFile.Categories, Category{
+			Name:    CategoryDefault,
+			Enabled: true,
+			Entries: []Entry{},
+		})
+	}
+
+	return hostsFile, nil
+}
+
+// ParseStream reads the parser's file line by line and calls fn with each
+// entry as it is read, without building the categories in memory, so large
+// blocklists can be counted, checked or searched cheaply. Entries are read
+// exactly as Parse reads them, except that category sidecars are not
+// applied, so entries of a file without category markers are all in the
+// default category. Parsing stops at the first error fn returns, and that
+// error is returned as is.
+func (p *Parser) ParseStream(fn func(Entry) error) error {
+	file, err := os.Open(p.filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open hosts file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	_, err = p.scan(file, nil, fn)
+	return err
+}
+
+// scanResult is what scan collects besides the entries
+type scanResult struct {
+	header []string
+	footer []string
+	issues []ParseIssue
+	// sawMarker reports whether the content had any category marker
+	sawMarker bool
+	// managed is the content around the managed block in managed block mode
+	managed *managedRegions
+}
+
+// scan reads hosts file content from r one line at a time. It calls
+// onCategory, when set, for every category marker and onEntry for every
+// entry in file order, and returns the header, footer and parse issues.
+// In managed block mode only the block is scanned, after reading the whole
+// content to keep the text around it.
+func (p *Parser) scan(r io.Reader, onCategory func(name, description string), onEntry func(Entry) error) (scanResult, error) {
+	var result scanResult
 	lineNum := 0
+	if p.managedBlock {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return result, fmt.Errorf("error reading file: %w", err)
+		}
+		regions, block, lineOffset, err := splitManagedBlock(data)
+		if err != nil {
+			return result, fmt.Errorf("invalid managed block: %w", err)
+		}
+		result.managed = &regions
+		r = bytes.NewReader(block)
+		lineNum = lineOffset
+	}
+
+	scanner := bufio.NewScanner(r)
 	currentCategory := CategoryDefault
-	var categories = make(map[string]*Category)
 	var headerDone bool
+	// markerCategory is set only on the line directly after a category marker,
+	// where that category's banner is written
+	var markerCategory string
+	// pending collects comment and blank lines after the header until they
+	// can be attached to the next entry, or become the footer at end of file
+	var pending []string
 
 	for scanner.Scan() {
 		lineNum++
 		line := scanner.Text()
 		originalLine := line
+		afterMarker := markerCategory
+		markerCategory = ""
 
 		if matches := categoryRegex.FindStringSubmatch(line); matches != nil {
 			currentCategory = matches[1]
-			if _, exists := categories[currentCategory]; !exists {
-				categories[currentCategory] = &Category{
-					Name:    currentCategory,
-					Enabled: true,
-					Entries: []Entry{},
-				}
-				if len(matches) > 2 && matches[2] != "" {
-					categories[currentCategory].Description = matches[2]
-				}
+			if onCategory != nil {
+				onCategory(currentCategory, matches[2])
 			}
 			headerDone = true
+			result.sawMarker = true
+			markerCategory = currentCategory
+			// Blank lines around a marker are the separator Write adds itself
+			pending = trimBlankLines(pending)
 			continue
 		}
 
-		if sectionRegex.MatchString(line) {
+		if sectionRegex.MatchString(line) || (afterMarker != "" && p.isCategoryBanner(line, afterMarker)) {
 			headerDone = true
 			continue
 		}
@@ -80,43 +229,69 @@ func (p *Parser) Parse() (*HostsFile, error) {
 		if entry, isEntry := p.parseEntry(line, lineNum); isEntry {
 			headerDone = true
 			entry.Category = currentCategory
+			entry.Raw = originalLine
+			entry.ReadOnly = p.ignore.MatchesEntry(entry)
+			entry.LeadingComments = pending
+			pending = nil
 
-			if _, exists := categories[currentCategory]; !exists {
-				categories[currentCategory] = &Category{
-					Name:    currentCategory,
-					Enabled: true,
-					Entries: []Entry{},
-				}
+			if err := onEntry(entry); err != nil {
+				return result, err
 			}
-			categories[currentCategory].Entries = append(categories[currentCategory].Entries, entry)
 		} else if commentLineRegex.MatchString(line) || strings.TrimSpace(line) == "" {
 			if !headerDone {
-				hostsFile.Header = append(hostsFile.Header, originalLine)
+				result.header = append(result.header, originalLine)
+			} else {
+				pending = append(pending, originalLine)
 			}
 		} else if strings.TrimSpace(line) != "" {
+			issue := ParseIssue{LineNum: lineNum, Line: originalLine, Reason: "not an entry, category marker or comment"}
+			if hostnames, ip, _, reversed := splitReversedLine(line); reversed {
+				issue.Reason = fmt.Sprintf("hostname written before IP address; did you mean %q?", ip+" "+strings.Join(hostnames, " "))
+			} else if matches := entryLineRegex.FindStringSubmatch(line); matches != nil {
+				if err := ValidateIP(matches[1]); err != nil {
+					issue.Reason = err.Error()
+					issue.InvalidIP = true
+				}
+			} else if fields := strings.Fields(line); len(fields) == 1 && net.ParseIP(NormalizeIP(fields[0])) != nil {
+				issue.Reason = "IP address without hostnames"
+			}
+			result.issues = append(result.issues, issue)
 			if !headerDone {
-				hostsFile.Header = append(hostsFile.Header, originalLine)
+				result.header = append(result.header, originalLine)
 			}
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file: %w", err)
+		return result, fmt.Errorf("error reading file: %w", err)
 	}
 
-	for _, category := range categories {
-		hostsFile.Categories = append(hostsFile.Categories, *category)
-	}
+	result.footer = trimBlankLines(pending)
+	return result, nil
+}
 
-	if len(hostsFile.Categories) == 0 {
-		hostsFile.Categories = append(hostsFile.Categories, Category{
-			Name:    CategoryDefault,
-			Enabled: true,
-			Entries: []Entry{},
-		})
+// trimBlankLines drops blank lines from both ends of lines
+func trimBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
 	}
+	return lines
+}
 
-	return hostsFile, nil
+// SetReversedLineMode controls whether lines written hostname first are
+// reported or read as entries; see ReversedLineMode
+func (p *Parser) SetReversedLineMode(mode ReversedLineMode) {
+	p.reversed = mode
+}
+
+// ParseLine parses one line the way Parse reads entry lines, so a
+// commented-out entry is returned disabled. It reports false for blank
+// lines, plain comments and lines that are not a valid entry.
+func (p *Parser) ParseLine(line string, lineNum int) (Entry, bool) {
+	return p.parseEntry(line, lineNum)
 }
 
 func (p *Parser) parseEntry(line string, lineNum int) (Entry, bool) {
@@ -134,12 +309,15 @@ func (p *Parser) parseEntry(line string, lineNum int) (Entry, bool) {
 				}
 
 				if p.isValidIP(ip) && len(hostnames) > 0 {
+					comment, tags := splitCommentTags(comment)
 					return Entry{
-						IP:        ip,
+						IP:        p.recordIP(ip, lineNum),
 						Hostnames: hostnames,
 						Comment:   comment,
 						Enabled:   false,
 						LineNum:   lineNum,
+						Tags:      tags.tags,
+						ExpiresAt: tags.expiresAt,
 					}, true
 				}
 			}
@@ -147,46 +325,108 @@ func (p *Parser) parseEntry(line string, lineNum int) (Entry, bool) {
 		return Entry{}, false
 	}
 
-	matches := entryLineRegex.FindStringSubmatch(line)
-	if matches == nil {
-		return Entry{}, false
-	}
+	var ip, comment string
+	var hostnames []string
+	if reversedHostnames, reversedIP, reversedComment, reversed := splitReversedLine(line); reversed {
+		if p.reversed != ReversedFix || !p.isValidIP(reversedIP) {
+			return Entry{}, false
+		}
+		ip, hostnames, comment = reversedIP, reversedHostnames, reversedComment
+		logValidationFailure(line, "reversed_entry_warning", fmt.Sprintf("line %d: hostname written before IP address, read as %s %s", lineNum, ip, strings.Join(hostnames, " ")))
+	} else {
+		matches := entryLineRegex.FindStringSubmatch(line)
+		if matches == nil {
+			return Entry{}, false
+		}
 
-	ip := matches[1]
-	hostnames := strings.Fields(matches[2])
-	comment := ""
-	if len(matches) > 3 {
-		comment = strings.TrimSpace(matches[3])
-	}
+		ip = matches[1]
+		hostnames = strings.Fields(matches[2])
+		if len(matches) > 3 {
+			comment = strings.TrimSpace(matches[3])
+		}
 
-	if !p.isValidIP(ip) || len(hostnames) == 0 {
-		return Entry{}, false
+		if !p.isValidIP(ip) || len(hostnames) == 0 {
+			return Entry{}, false
+		}
 	}
 
+	comment, tags := splitCommentTags(comment)
 	return Entry{
-		IP:        ip,
+		IP:        p.recordIP(ip, lineNum),
 		Hostnames: hostnames,
 		Comment:   comment,
 		Enabled:   true,
 		LineNum:   lineNum,
+		Tags:      tags.tags,
+		ExpiresAt: tags.expiresAt,
 	}, true
 }
 
+// splitReversedLine detects an entry written hostname first, such as
+// "localhost 127.0.0.1": the last field is an IP address and no other field
+// is. It returns the line's hostnames, IP and comment.
+func splitReversedLine(line string) (hostnames []string, ip, comment string, ok bool) {
+	body, comment, _ := strings.Cut(line, "#")
+	fields := strings.Fields(body)
+	if len(fields) < 2 {
+		return nil, "", "", false
+	}
+
+	isIP := func(field string) bool {
+		addr, _ := SplitZone(field)
+		return net.ParseIP(NormalizeIP(addr)) != nil
+	}
+	ip = fields[len(fields)-1]
+	if !isIP(ip) {
+		return nil, "", "", false
+	}
+	for _, field := range fields[:len(fields)-1] {
+		if isIP(field) {
+			return nil, "", "", false
+		}
+	}
+
+	return fields[:len(fields)-1], ip, strings.TrimSpace(comment), true
+}
+
 func (p *Parser) isValidIP(ip string) bool {
 	return ValidateIP(ip) == nil
 }
 
+// recordIP returns the IP text to store for a validated address, applying
+// the parser's IPMode to leading-zero IPv4 addresses
+func (p *Parser) recordIP(ip string, lineNum int) string {
+	normalized := NormalizeIP(ip)
+	if normalized == ip || p.ipMode == IPPreserve {
+		return ip
+	}
+
+	logValidationFailure(ip, "ip_normalized_warning", fmt.Sprintf("line %d: leading zeros stripped from IP address, written as %s", lineNum, normalized))
+	return normalized
+}
+
 func (hf *HostsFile) Write(filePath string) error {
-	return AtomicWrite(filePath, func(file io.Writer) error {
+	if hf.normalizeIPs {
+		hf.canonicalizeIPs()
+	}
+
+	err := AtomicWrite(filePath, func(file io.Writer) error {
 		writer := bufio.NewWriter(file)
 		defer func() { _ = writer.Flush() }()
 
-		// Write managed file header
+		// Write managed file header. In managed block mode only the block is
+		// ours, so the text around it replaces the header.
 		managedHeader := []string{
 			"# This file is currently managed by hosts-manager",
 			"# See https://github.com/brandonhon/hosts-manager for usage",
 			"",
 		}
+		if hf.managed != nil {
+			if _, err := writer.WriteString(hf.managed.managedBefore()); err != nil {
+				return fmt.Errorf("failed to write content before the managed block: %w", err)
+			}
+			managedHeader = nil
+		}
 
 		for _, line := range managedHeader {
 			if _, err := writer.WriteString(line + "\n"); err != nil {
@@ -242,28 +482,32 @@ func (hf *HostsFile) Write(filePath string) error {
 				continue
 			}
 
-			// Add separator between categories (but not before first)
-			if i > 0 {
+			// Add separator between categories (but not before first).
+			// Without headers a separator would be read back as a comment.
+			if i > 0 && hf.bannerStyle != BannerNone {
 				if _, err := writer.WriteString("\n"); err != nil {
 					return fmt.Errorf("failed to write category separator: %w", err)
 				}
 			}
 
-			categoryHeader := fmt.Sprintf("# @category %s", category.Name)
-			if category.Description != "" {
-				categoryHeader += " " + category.Description
-			}
-			if _, err := writer.WriteString(categoryHeader + "\n"); err != nil {
-				return fmt.Errorf("failed to write category header: %w", err)
-			}
-
-			sectionHeader := fmt.Sprintf("# =============== %s ===============", strings.ToUpper(category.Name))
-			if _, err := writer.WriteString(sectionHeader + "\n"); err != nil {
-				return fmt.Errorf("failed to write section header: %w", err)
+			for _, headerLine := range hf.CategoryHeader(category) {
+				if _, err := writer.WriteString(headerLine + "\n"); err != nil {
+					return fmt.Errorf("failed to write category header: %w", err)
+				}
 			}
 
+			// Unmodified entries keep their original spacing and alignment
 			for _, entry := range category.Entries {
-				line := formatEntry(entry)
+				for _, comment := range entry.LeadingComments {
+					if _, err := writer.WriteString(comment + "\n"); err != nil {
+						return fmt.Errorf("failed to write entry comment: %w", err)
+					}
+				}
+
+				line := FormatEntry(entry)
+				if rawMatchesEntry(entry) {
+					line = entry.Raw
+				}
 				if _, err := writer.WriteString(line + "\n"); err != nil {
 					return fmt.Errorf("failed to write entry: %w", err)
 				}
@@ -282,16 +526,34 @@ func (hf *HostsFile) Write(filePath string) error {
 			}
 		}
 
+		if hf.managed != nil {
+			if _, err := writer.WriteString(hf.managed.managedAfter()); err != nil {
+				return fmt.Errorf("failed to write content after the managed block: %w", err)
+			}
+		}
+
 		hf.Modified = time.Now()
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	if hf.managed != nil && !hf.managed.found {
+		// The file now has markers, so later writes keep the same layout
+		hf.managed = &managedRegions{before: hf.managed.managedBefore(), after: hf.managed.managedAfter(), found: true}
+	}
+
+	return hf.writeCategorySidecar(filePath)
 }
 
-func formatEntry(entry Entry) string {
+// FormatEntry returns entry as it is written to the hosts file, commented
+// out when disabled
+func FormatEntry(entry Entry) string {
 	line := fmt.Sprintf("%s %s", entry.IP, strings.Join(entry.Hostnames, " "))
 
-	if entry.Comment != "" {
-		line += " # " + entry.Comment
+	if comment := entry.TaggedComment(); comment != "" {
+		line += " # " + comment
 	}
 
 	if !entry.Enabled {
@@ -301,11 +563,61 @@ func formatEntry(entry Entry) string {
 	return line
 }
 
+// rawMatchesEntry reports whether entry.Raw still describes the entry, meaning
+// the entry has not been modified since it was parsed. A normalized IP counts
+// as a modification unless the entry is read-only, so normalization is written.
+func rawMatchesEntry(entry Entry) bool {
+	if entry.Raw == "" {
+		return false
+	}
+
+	parsed, ok := (&Parser{ipMode: IPPreserve}).parseEntry(entry.Raw, entry.LineNum)
+	if !ok {
+		return false
+	}
+
+	ipMatches := parsed.IP == entry.IP
+	if entry.ReadOnly {
+		ipMatches = NormalizeIP(parsed.IP) == NormalizeIP(entry.IP)
+	}
+
+	return ipMatches &&
+		parsed.Enabled == entry.Enabled &&
+		parsed.Comment == entry.Comment &&
+		parsed.ExpiresAt.Equal(entry.ExpiresAt) &&
+		slices.Equal(parsed.Tags, entry.Tags) &&
+		slices.Equal(parsed.Hostnames, entry.Hostnames)
+}
+
+// AddEntry adds entry after validating its hostnames under the file's
+// hostname mode; see SetHostnameMode
 func (hf *HostsFile) AddEntry(entry Entry) error {
+	return hf.AddEntryWithMode(entry, hf.hostnameMode)
+}
+
+// AddEntryWithMode adds entry after validating its hostnames under the given mode
+func (hf *HostsFile) AddEntryWithMode(entry Entry, mode HostnameValidationMode) error {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.invalidateIndex()
+
+	return hf.addEntry(entry, mode)
+}
+
+// addEntry implements AddEntryWithMode; the caller must hold hf.mu
+func (hf *HostsFile) addEntry(entry Entry, mode HostnameValidationMode) error {
+	entry, err := hf.ConvertIDNs(entry)
+	if err != nil {
+		return fmt.Errorf("entry validation failed: %w", err)
+	}
+
 	// Validate the entry before adding
-	if err := ValidateEntry(entry); err != nil {
+	if err := ValidateEntryWithMode(entry, mode); err != nil {
 		return fmt.Errorf("entry validation failed: %w", err)
 	}
+	if hf.normalizeIPs {
+		entry.IP = CanonicalIP(entry.IP)
+	}
 
 	categoryName := entry.Category
 	if categoryName == "" {
@@ -329,7 +641,95 @@ func (hf *HostsFile) AddEntry(entry Entry) error {
 	return nil
 }
 
+// AddOrMergeEntry adds entry, or, when an enabled entry with the same IP
+// already exists in the same category, appends the new hostnames to it
+// instead. The existing comment is kept unless entry has one. Read-only
+// entries are never merged into. It reports whether the entry was merged.
+func (hf *HostsFile) AddOrMergeEntry(entry Entry, mode HostnameValidationMode) (bool, error) {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.invalidateIndex()
+
+	entry, err := hf.ConvertIDNs(entry)
+	if err != nil {
+		return false, fmt.Errorf("entry validation failed: %w", err)
+	}
+	if err := ValidateEntryWithMode(entry, mode); err != nil {
+		return false, fmt.Errorf("entry validation failed: %w", err)
+	}
+
+	categoryName := entry.Category
+	if categoryName == "" {
+		categoryName = CategoryDefault
+	}
+
+	if category := hf.GetCategory(categoryName); category != nil {
+		for i := range category.Entries {
+			existing := &category.Entries[i]
+			if !existing.Enabled || existing.ReadOnly || NormalizeIP(existing.IP) != NormalizeIP(entry.IP) {
+				continue
+			}
+
+			for _, hostname := range entry.Hostnames {
+				if !slices.Contains(existing.Hostnames, hostname) {
+					existing.Hostnames = append(existing.Hostnames, hostname)
+				}
+			}
+			if entry.Comment != "" {
+				existing.Comment = entry.Comment
+			}
+			return true, nil
+		}
+	}
+
+	return false, hf.addEntry(entry, mode)
+}
+
+// InsertEntry adds entry to its category directly before the entry declaring
+// beforeHostname, keeping related entries together. If no entry in that
+// category declares beforeHostname, the entry is appended as AddEntry would.
+// It reports whether the anchor was found.
+func (hf *HostsFile) InsertEntry(entry Entry, beforeHostname string) (bool, error) {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.invalidateIndex()
+
+	entry, err := hf.ConvertIDNs(entry)
+	if err != nil {
+		return false, fmt.Errorf("entry validation failed: %w", err)
+	}
+	if err := ValidateEntryWithMode(entry, hf.hostnameMode); err != nil {
+		return false, fmt.Errorf("entry validation failed: %w", err)
+	}
+
+	categoryName := entry.Category
+	if categoryName == "" {
+		categoryName = CategoryDefault
+		entry.Category = categoryName
+	}
+
+	if category := hf.GetCategory(categoryName); category != nil {
+		for i, existing := range category.Entries {
+			if !slices.ContainsFunc(existing.Hostnames, func(h string) bool { return strings.EqualFold(h, beforeHostname) }) {
+				continue
+			}
+
+			if hf.normalizeIPs {
+				entry.IP = CanonicalIP(entry.IP)
+			}
+			category.Entries = slices.Insert(category.Entries, i, entry)
+			return true, nil
+		}
+	}
+
+	return false, hf.addEntry(entry, hf.hostnameMode)
+}
+
 func (hf *HostsFile) RemoveEntry(hostname string) bool {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.invalidateIndex()
+
 	for i := range hf.Categories {
 		for j := len(hf.Categories[i].Entries) - 1; j >= 0; j-- {
 			entry := &hf.Categories[i].Entries[j]
@@ -349,39 +749,141 @@ func (hf *HostsFile) RemoveEntry(hostname string) bool {
 }
 
 func (hf *HostsFile) EnableEntry(hostname string) bool {
+	return hf.setEntryEnabled(hostname, "", true)
+}
+
+func (hf *HostsFile) DisableEntry(hostname string) bool {
+	return hf.setEntryEnabled(hostname, "", false)
+}
+
+// EnableEntryInCategory enables the first entry in category that lists
+// hostname, leaving entries for it in other categories alone
+func (hf *HostsFile) EnableEntryInCategory(hostname, category string) bool {
+	return hf.setEntryEnabled(hostname, category, true)
+}
+
+// DisableEntryInCategory disables the first entry in category that lists
+// hostname, leaving entries for it in other categories alone
+func (hf *HostsFile) DisableEntryInCategory(hostname, category string) bool {
+	return hf.setEntryEnabled(hostname, category, false)
+}
+
+// setEntryEnabled sets the state of the first entry listing hostname,
+// looking only in category unless it is empty
+func (hf *HostsFile) setEntryEnabled(hostname, category string, enabled bool) bool {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.invalidateIndex()
+
 	for i := range hf.Categories {
+		if category != "" && hf.Categories[i].Name != category {
+			continue
+		}
 		for j := range hf.Categories[i].Entries {
 			entry := &hf.Categories[i].Entries[j]
-			for _, h := range entry.Hostnames {
-				if h == hostname {
-					entry.Enabled = true
-					return true
-				}
+			if slices.Contains(entry.Hostnames, hostname) {
+				entry.Enabled = enabled
+				return true
 			}
 		}
 	}
 	return false
 }
 
-func (hf *HostsFile) DisableEntry(hostname string) bool {
+// SplitHostname moves hostname out of a multi-hostname entry into its own
+// entry, inserted directly after the original with the same IP, category,
+// comment, and enabled state. It returns the entry now holding hostname,
+// which is the original entry if it had no other hostnames.
+func (hf *HostsFile) SplitHostname(hostname string) (*Entry, error) {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.invalidateIndex()
+
+	return hf.splitHostname(hostname, "")
+}
+
+// splitHostname is SplitHostname looking only in category unless it is
+// empty; the caller must hold hf.mu
+func (hf *HostsFile) splitHostname(hostname, category string) (*Entry, error) {
 	for i := range hf.Categories {
-		for j := range hf.Categories[i].Entries {
-			entry := &hf.Categories[i].Entries[j]
-			for _, h := range entry.Hostnames {
+		if category != "" && hf.Categories[i].Name != category {
+			continue
+		}
+		entries := hf.Categories[i].Entries
+		for j := range entries {
+			index := -1
+			for k, h := range entries[j].Hostnames {
 				if h == hostname {
-					entry.Enabled = false
-					return true
+					index = k
+					break
 				}
 			}
+			if index < 0 {
+				continue
+			}
+			if len(entries[j].Hostnames) == 1 {
+				return &entries[j], nil
+			}
+
+			split := entries[j]
+			split.Hostnames = []string{hostname}
+			split.LineNum = 0
+			split.Raw = ""
+			split.LeadingComments = nil
+
+			remaining := make([]string, 0, len(entries[j].Hostnames)-1)
+			remaining = append(remaining, entries[j].Hostnames[:index]...)
+			remaining = append(remaining, entries[j].Hostnames[index+1:]...)
+			entries[j].Hostnames = remaining
+
+			entries = append(entries[:j+1], append([]Entry{split}, entries[j+1:]...)...)
+			hf.Categories[i].Entries = entries
+			return &hf.Categories[i].Entries[j+1], nil
 		}
 	}
-	return false
+	return nil, fmt.Errorf("hostname not found: %s", hostname)
+}
+
+// DisableHostname disables only hostname, splitting it out of a shared entry
+// so the entry's other hostnames stay enabled
+func (hf *HostsFile) DisableHostname(hostname string) bool {
+	return hf.DisableHostnameInCategory(hostname, "")
+}
+
+// DisableHostnameInCategory is DisableHostname looking only in category
+// unless it is empty
+func (hf *HostsFile) DisableHostnameInCategory(hostname, category string) bool {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.invalidateIndex()
+
+	entry, err := hf.splitHostname(hostname, category)
+	if err != nil {
+		return false
+	}
+	entry.Enabled = false
+	return true
 }
 
 func (hf *HostsFile) FindEntries(query string) []Entry {
 	var results []Entry
 	query = strings.ToLower(query)
 
+	if hf.index != nil {
+		for _, key := range hf.index.search {
+			for _, hostname := range key.hostnames {
+				if strings.Contains(hostname, query) {
+					results = append(results, *hf.entryAt(key.pos))
+					break
+				}
+			}
+			if strings.Contains(key.ip, query) {
+				results = append(results, *hf.entryAt(key.pos))
+			}
+		}
+		return results
+	}
+
 	for _, category := range hf.Categories {
 		for _, entry := range category.Entries {
 			for _, hostname := range entry.Hostnames {
@@ -399,6 +901,115 @@ func (hf *HostsFile) FindEntries(query string) []Entry {
 	return results
 }
 
+// GetEntriesByIP returns every entry whose IP is exactly ip, in file order.
+// Addresses are compared after normalization, so 10.0.0.1 does not match
+// 10.0.0.10 and ::1 matches 0:0:0:0:0:0:0:1. A zone in ip must match too.
+func (hf *HostsFile) GetEntriesByIP(ip string) []Entry {
+	addr, zone := SplitZone(strings.TrimSpace(ip))
+	target := net.ParseIP(NormalizeIP(addr))
+	if target == nil {
+		return nil
+	}
+
+	var results []Entry
+	if hf.index != nil {
+		for _, pos := range hf.index.byIP[target.String()] {
+			entry := hf.entryAt(pos)
+			if _, entryZone := SplitZone(entry.IP); zone == "" || entryZone == zone {
+				results = append(results, *entry)
+			}
+		}
+		return results
+	}
+
+	for _, category := range hf.Categories {
+		for _, entry := range category.Entries {
+			entryAddr, entryZone := SplitZone(entry.IP)
+			if zone != "" && entryZone != zone {
+				continue
+			}
+			if entryIP := net.ParseIP(NormalizeIP(entryAddr)); entryIP != nil && entryIP.Equal(target) {
+				results = append(results, entry)
+			}
+		}
+	}
+	return results
+}
+
+// FindEntryByHostname returns pointers to every entry that lists hostname.
+// The pointers are only valid until the hosts file is next modified.
+func (hf *HostsFile) FindEntryByHostname(hostname string) []*Entry {
+	var matches []*Entry
+	if hf.index != nil {
+		for _, pos := range hf.index.byHostname[hostname] {
+			matches = append(matches, hf.entryAt(pos))
+		}
+		return matches
+	}
+
+	for i := range hf.Categories {
+		for j := range hf.Categories[i].Entries {
+			entry := &hf.Categories[i].Entries[j]
+			for _, h := range entry.Hostnames {
+				if h == hostname {
+					matches = append(matches, entry)
+					break
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// FindEntryByLine returns the entry parsed from line lineNum, or nil if that
+// line is not an entry. Entries added since parsing have no line number.
+func (hf *HostsFile) FindEntryByLine(lineNum int) *Entry {
+	if lineNum <= 0 {
+		return nil
+	}
+	for i := range hf.Categories {
+		for j := range hf.Categories[i].Entries {
+			if hf.Categories[i].Entries[j].LineNum == lineNum {
+				return &hf.Categories[i].Entries[j]
+			}
+		}
+	}
+	return nil
+}
+
+// MoveEntry moves the entry pointed to by entry (as returned by
+// FindEntryByHostname) to the end of targetCategory, which must already exist
+func (hf *HostsFile) MoveEntry(entry *Entry, targetCategory string) error {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.invalidateIndex()
+
+	target := hf.GetCategory(targetCategory)
+	if target == nil {
+		return fmt.Errorf("target category not found: %s", targetCategory)
+	}
+
+	for i := range hf.Categories {
+		source := &hf.Categories[i]
+		for j := range source.Entries {
+			if &source.Entries[j] != entry {
+				continue
+			}
+			if source.Name == targetCategory {
+				return nil
+			}
+
+			moved := source.Entries[j]
+			moved.Category = targetCategory
+			source.Entries = append(source.Entries[:j], source.Entries[j+1:]...)
+			target.Entries = append(target.Entries, moved)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("entry not found in hosts file")
+}
+
 func (hf *HostsFile) GetCategory(name string) *Category {
 	for i := range hf.Categories {
 		if hf.Categories[i].Name == name {
@@ -409,6 +1020,10 @@ func (hf *HostsFile) GetCategory(name string) *Category {
 }
 
 func (hf *HostsFile) EnableCategory(name string) {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.invalidateIndex()
+
 	if category := hf.GetCategory(name); category != nil {
 		category.Enabled = true
 		for i := range category.Entries {
@@ -418,6 +1033,10 @@ func (hf *HostsFile) EnableCategory(name string) {
 }
 
 func (hf *HostsFile) DisableCategory(name string) {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.invalidateIndex()
+
 	if category := hf.GetCategory(name); category != nil {
 		category.Enabled = false
 		for i := range category.Entries {
@@ -427,7 +1046,11 @@ func (hf *HostsFile) DisableCategory(name string) {
 }
 
 func (hf *HostsFile) AddCategory(name, description string) error {
-	if err := validateCategoryName(name); err != nil {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.invalidateIndex()
+
+	if err := ValidateCategoryName(name); err != nil {
 		return fmt.Errorf("category name validation failed: %w", err)
 	}
 
@@ -445,3 +1068,52 @@ func (hf *HostsFile) AddCategory(name, description string) error {
 	hf.Categories = append(hf.Categories, newCategory)
 	return nil
 }
+
+// RemoveCategory deletes the named category. A category that still has
+// entries is only removed if they are reassigned to moveTo or deleteEntries
+// is set. The default category cannot be removed. It returns the number of
+// entries moved and deleted.
+func (hf *HostsFile) RemoveCategory(name, moveTo string, deleteEntries bool) (moved, deleted int, err error) {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.invalidateIndex()
+
+	if name == CategoryDefault {
+		return 0, 0, fmt.Errorf("the %s category cannot be deleted", CategoryDefault)
+	}
+
+	index := -1
+	for i := range hf.Categories {
+		if hf.Categories[i].Name == name {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return 0, 0, fmt.Errorf("category not found: %s", name)
+	}
+
+	entries := hf.Categories[index].Entries
+	switch {
+	case moveTo != "":
+		if moveTo == name {
+			return 0, 0, fmt.Errorf("cannot move entries into the category being deleted")
+		}
+		target := hf.GetCategory(moveTo)
+		if target == nil {
+			return 0, 0, fmt.Errorf("target category not found: %s", moveTo)
+		}
+		for _, entry := range entries {
+			entry.Category = moveTo
+			target.Entries = append(target.Entries, entry)
+		}
+		moved = len(entries)
+	case deleteEntries:
+		deleted = len(entries)
+	case len(entries) > 0:
+		return 0, 0, fmt.Errorf("category %s still has %d entries", name, len(entries))
+	}
+
+	hf.Categories = append(hf.Categories[:index], hf.Categories[index+1:]...)
+	return moved, deleted, nil
+}
@@ -0,0 +1,72 @@
+package hosts
+
+import (
+	"net"
+	"strings"
+)
+
+// ShadowedEntry is a hostname declaration that never takes effect because an
+// earlier enabled entry already resolves the same hostname
+type ShadowedEntry struct {
+	Hostname   string
+	Entry      Entry
+	ShadowedBy Entry
+}
+
+// FindShadowedEntries returns, in file order, every hostname on an enabled
+// entry that an earlier enabled entry already declares. Resolvers use the
+// first matching line, so the later declaration is dead. Hostnames compare
+// case-insensitively, and only entries of the same address family shadow
+// each other, since IPv4 and IPv6 lookups are answered separately.
+func (hf *HostsFile) FindShadowedEntries() []ShadowedEntry {
+	type familyHost struct {
+		family   string
+		hostname string
+	}
+
+	var shadowed []ShadowedEntry
+	first := make(map[familyHost]Entry)
+	for _, category := range hf.Categories {
+		for _, entry := range category.Entries {
+			if !entry.Enabled {
+				continue
+			}
+
+			family := ipFamily(entry.IP)
+			seen := make(map[string]bool)
+			for _, hostname := range entry.Hostnames {
+				key := familyHost{family, strings.ToLower(hostname)}
+				// A hostname repeated on one line is a duplicate, not a shadow
+				if seen[key.hostname] {
+					continue
+				}
+				seen[key.hostname] = true
+
+				if earlier, ok := first[key]; ok {
+					shadowed = append(shadowed, ShadowedEntry{
+						Hostname:   hostname,
+						Entry:      entry,
+						ShadowedBy: earlier,
+					})
+					continue
+				}
+				first[key] = entry
+			}
+		}
+	}
+	return shadowed
+}
+
+// ipFamily returns "ipv4" or "ipv6", or "" for an unparseable address
+func ipFamily(ip string) string {
+	addr, _ := SplitZone(ip)
+	parsed := net.ParseIP(NormalizeIP(addr))
+	switch {
+	case parsed == nil:
+		return ""
+	case parsed.To4() != nil:
+		return "ipv4"
+	default:
+		return "ipv6"
+	}
+}
@@ -0,0 +1,71 @@
+package hosts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindShadowedEntries(t *testing.T) {
+	content := `127.0.0.1 localhost app.local
+::1 localhost
+
+# @category development
+10.0.0.5 App.local api.dev
+# 10.0.0.6 api.dev
+10.0.0.7 web.dev web.dev
+
+# @category staging
+10.0.0.8 api.dev
+fe80::1 app.local
+`
+	hf, err := NewParser("").ParseReader(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	shadowed := hf.FindShadowedEntries()
+	if len(shadowed) != 2 {
+		t.Fatalf("FindShadowedEntries() = %+v, want 2 shadows", shadowed)
+	}
+
+	// The development entry loses App.local to the default category's line
+	if shadowed[0].Hostname != "App.local" || shadowed[0].Entry.IP != "10.0.0.5" || shadowed[0].ShadowedBy.IP != "127.0.0.1" {
+		t.Errorf("shadowed[0] = %+v, want App.local on 10.0.0.5 shadowed by 127.0.0.1", shadowed[0])
+	}
+	// The disabled 10.0.0.6 line does not count; 10.0.0.5 wins for api.dev
+	if shadowed[1].Hostname != "api.dev" || shadowed[1].Entry.IP != "10.0.0.8" || shadowed[1].ShadowedBy.IP != "10.0.0.5" {
+		t.Errorf("shadowed[1] = %+v, want api.dev on 10.0.0.8 shadowed by 10.0.0.5", shadowed[1])
+	}
+}
+
+func TestLintReportsShadowedEntries(t *testing.T) {
+	content := "127.0.0.1 app.local\n10.0.0.5 app.local\n127.0.0.1 app.local\n10.0.0.9 APP.local\n"
+	hf, err := NewParser("").ParseReader(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	var shadowProblems []Problem
+	for _, problem := range hf.Lint() {
+		if strings.Contains(problem.Reason, "shadowed") {
+			shadowProblems = append(shadowProblems, problem)
+		}
+	}
+
+	// The same-IP repeat on line 3 is only a duplicate, and the case-only
+	// match on line 4 is not an exact duplicate but still shadowed
+	if len(shadowProblems) != 2 {
+		t.Fatalf("shadow problems = %+v, want lines 2 and 4", shadowProblems)
+	}
+	if shadowProblems[0].LineNum != 2 || !strings.HasPrefix(shadowProblems[0].Reason, "duplicate hostname") ||
+		!strings.Contains(shadowProblems[0].Reason, "127.0.0.1 on line 1") {
+		t.Errorf("shadowProblems[0] = %+v, want a duplicate on line 2 shadowed by line 1", shadowProblems[0])
+	}
+	if shadowProblems[1].LineNum != 4 || shadowProblems[1].Value != "APP.local" {
+		t.Errorf("shadowProblems[1] = %+v, want APP.local on line 4", shadowProblems[1])
+	}
+
+	if got := hf.Stats().ShadowedHostnames; got != 3 {
+		t.Errorf("Stats().ShadowedHostnames = %d, want 3", got)
+	}
+}
@@ -0,0 +1,140 @@
+package hosts
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// SortKey selects the field entries are ordered by
+type SortKey string
+
+const (
+	SortByHostname SortKey = "hostname"
+	SortByIP       SortKey = "ip"
+	SortByComment  SortKey = "comment"
+	// SortByStatus puts enabled entries before disabled ones
+	SortByStatus SortKey = "status"
+)
+
+// ParseSortKey validates a user-supplied sort key
+func ParseSortKey(key string) (SortKey, error) {
+	switch SortKey(key) {
+	case SortByHostname, SortByIP, SortByComment, SortByStatus:
+		return SortKey(key), nil
+	default:
+		return "", fmt.Errorf("invalid sort key %q (must be hostname, ip, comment, or status)", key)
+	}
+}
+
+// SortEntries stably reorders the entries inside each category by key.
+// When protectReadOnly is set, read-only entries keep their positions and
+// only the remaining entries are reordered around them.
+func (hf *HostsFile) SortEntries(key SortKey, protectReadOnly bool) error {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.invalidateIndex()
+
+	less, err := entryLess(key)
+	if err != nil {
+		return err
+	}
+
+	for i := range hf.Categories {
+		entries := hf.Categories[i].Entries
+
+		var slots []int
+		var movable []Entry
+		for j, entry := range entries {
+			if protectReadOnly && entry.ReadOnly {
+				continue
+			}
+			slots = append(slots, j)
+			movable = append(movable, entry)
+		}
+
+		sort.SliceStable(movable, func(a, b int) bool {
+			return less(movable[a], movable[b])
+		})
+
+		for k, slot := range slots {
+			entries[slot] = movable[k]
+		}
+	}
+
+	return nil
+}
+
+// SortCategories stably orders categories by name
+func (hf *HostsFile) SortCategories() {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+	hf.invalidateIndex()
+
+	sort.SliceStable(hf.Categories, func(i, j int) bool {
+		return strings.ToLower(hf.Categories[i].Name) < strings.ToLower(hf.Categories[j].Name)
+	})
+}
+
+func entryLess(key SortKey) (func(a, b Entry) bool, error) {
+	switch key {
+	case SortByHostname:
+		return func(a, b Entry) bool {
+			return strings.ToLower(firstHostname(a)) < strings.ToLower(firstHostname(b))
+		}, nil
+	case SortByIP:
+		return func(a, b Entry) bool {
+			return compareIPs(a.IP, b.IP) < 0
+		}, nil
+	case SortByComment:
+		return func(a, b Entry) bool {
+			return strings.ToLower(a.Comment) < strings.ToLower(b.Comment)
+		}, nil
+	case SortByStatus:
+		return func(a, b Entry) bool {
+			return a.Enabled && !b.Enabled
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid sort key %q (must be hostname, ip, comment, or status)", key)
+	}
+}
+
+func firstHostname(entry Entry) string {
+	if len(entry.Hostnames) == 0 {
+		return ""
+	}
+	return entry.Hostnames[0]
+}
+
+// compareIPs orders addresses numerically, with IPv4 before IPv6 and
+// unparseable addresses last (compared as strings). Addresses differing only
+// by zone are ordered by zone.
+func compareIPs(a, b string) int {
+	addrA, zoneA := SplitZone(a)
+	addrB, zoneB := SplitZone(b)
+	ipA, ipB := net.ParseIP(NormalizeIP(addrA)), net.ParseIP(NormalizeIP(addrB))
+
+	switch {
+	case ipA == nil && ipB == nil:
+		return strings.Compare(a, b)
+	case ipA == nil:
+		return 1
+	case ipB == nil:
+		return -1
+	}
+
+	v4A, v4B := ipA.To4() != nil, ipB.To4() != nil
+	if v4A != v4B {
+		if v4A {
+			return -1
+		}
+		return 1
+	}
+
+	if c := bytes.Compare(ipA.To16(), ipB.To16()); c != 0 {
+		return c
+	}
+	return strings.Compare(zoneA, zoneB)
+}
@@ -0,0 +1,146 @@
+package hosts
+
+import (
+	"slices"
+	"testing"
+)
+
+func sortFixture() *HostsFile {
+	return &HostsFile{
+		Categories: []Category{
+			{
+				Name:    "staging",
+				Enabled: true,
+				Entries: []Entry{
+					{IP: "10.0.0.10", Hostnames: []string{"web.stage"}, Comment: "b", Enabled: true},
+					{IP: "10.0.0.2", Hostnames: []string{"Api.stage"}, Comment: "c", Enabled: true},
+					{IP: "::1", Hostnames: []string{"db.stage"}, Comment: "a", Enabled: true},
+					{IP: "9.255.255.255", Hostnames: []string{"cache.stage"}, Enabled: true},
+				},
+			},
+			{
+				Name:    "development",
+				Enabled: true,
+				Entries: []Entry{
+					{IP: "192.168.1.2", Hostnames: []string{"b.dev"}, Enabled: true},
+					{IP: "192.168.1.1", Hostnames: []string{"a.dev"}, Enabled: true},
+				},
+			},
+		},
+	}
+}
+
+func entryIPs(category Category) []string {
+	var ips []string
+	for _, entry := range category.Entries {
+		ips = append(ips, entry.IP)
+	}
+	return ips
+}
+
+func TestSortEntries(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      SortKey
+		expected []string
+	}{
+		{
+			name:     "by ip is numeric",
+			key:      SortByIP,
+			expected: []string{"9.255.255.255", "10.0.0.2", "10.0.0.10", "::1"},
+		},
+		{
+			name:     "by hostname is case insensitive",
+			key:      SortByHostname,
+			expected: []string{"10.0.0.2", "9.255.255.255", "::1", "10.0.0.10"},
+		},
+		{
+			name:     "by comment",
+			key:      SortByComment,
+			expected: []string{"9.255.255.255", "::1", "10.0.0.10", "10.0.0.2"},
+		},
+		{
+			name:     "by status keeps order among equals",
+			key:      SortByStatus,
+			expected: []string{"10.0.0.10", "::1", "9.255.255.255", "10.0.0.2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hostsFile := sortFixture()
+			hostsFile.Categories[0].Entries[1].Enabled = false
+			if err := hostsFile.SortEntries(tt.key, false); err != nil {
+				t.Fatalf("SortEntries() error = %v", err)
+			}
+
+			if got := entryIPs(hostsFile.Categories[0]); !slices.Equal(got, tt.expected) {
+				t.Errorf("SortEntries(%s) = %v, want %v", tt.key, got, tt.expected)
+			}
+			if hostsFile.Categories[0].Name != "staging" {
+				t.Error("Expected category order to be unchanged")
+			}
+		})
+	}
+}
+
+func TestSortEntriesKeepsReadOnlyInPlace(t *testing.T) {
+	hostsFile := sortFixture()
+	hostsFile.Categories[0].Entries[1].ReadOnly = true
+
+	if err := hostsFile.SortEntries(SortByIP, true); err != nil {
+		t.Fatalf("SortEntries() error = %v", err)
+	}
+
+	expected := []string{"9.255.255.255", "10.0.0.2", "10.0.0.10", "::1"}
+	if got := entryIPs(hostsFile.Categories[0]); !slices.Equal(got, expected) {
+		t.Errorf("SortEntries() = %v, want %v", got, expected)
+	}
+
+	hostsFile = sortFixture()
+	hostsFile.Categories[0].Entries[0].ReadOnly = true
+	if err := hostsFile.SortEntries(SortByIP, true); err != nil {
+		t.Fatalf("SortEntries() error = %v", err)
+	}
+	if got := hostsFile.Categories[0].Entries[0].IP; got != "10.0.0.10" {
+		t.Errorf("Expected read-only entry to stay first, got %s", got)
+	}
+}
+
+func TestSortEntriesInvalidKey(t *testing.T) {
+	if err := sortFixture().SortEntries("line", false); err == nil {
+		t.Error("Expected error for invalid sort key")
+	}
+	if _, err := ParseSortKey("line"); err == nil {
+		t.Error("Expected ParseSortKey to reject invalid key")
+	}
+}
+
+func TestSortCategories(t *testing.T) {
+	hostsFile := sortFixture()
+	hostsFile.SortCategories()
+
+	if hostsFile.Categories[0].Name != "development" || hostsFile.Categories[1].Name != "staging" {
+		t.Errorf("Expected categories sorted by name, got %s, %s", hostsFile.Categories[0].Name, hostsFile.Categories[1].Name)
+	}
+}
+
+func TestCompareIPs(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"10.0.0.2", "10.0.0.10", -1},
+		{"10.0.0.10", "10.0.0.2", 1},
+		{"127.0.0.1", "127.0.0.1", 0},
+		{"255.255.255.255", "::1", -1},
+		{"fe80::1", "::1", 1},
+		{"invalid", "10.0.0.1", 1},
+	}
+
+	for _, tt := range tests {
+		if got := compareIPs(tt.a, tt.b); got != tt.expected {
+			t.Errorf("compareIPs(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
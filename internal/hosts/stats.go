@@ -0,0 +1,62 @@
+package hosts
+
+import "net"
+
+// Stats summarizes the entries in a hosts file
+type Stats struct {
+	Total              int             `json:"total" yaml:"total"`
+	Enabled            int             `json:"enabled" yaml:"enabled"`
+	Disabled           int             `json:"disabled" yaml:"disabled"`
+	IPv4               int             `json:"ipv4" yaml:"ipv4"`
+	IPv6               int             `json:"ipv6" yaml:"ipv6"`
+	DuplicateHostnames int             `json:"duplicate_hostnames" yaml:"duplicate_hostnames"`
+	ShadowedHostnames  int             `json:"shadowed_hostnames" yaml:"shadowed_hostnames"`
+	Categories         []CategoryStats `json:"categories" yaml:"categories"`
+}
+
+// CategoryStats counts the entries in one category
+type CategoryStats struct {
+	Name     string `json:"name" yaml:"name"`
+	Total    int    `json:"total" yaml:"total"`
+	Enabled  int    `json:"enabled" yaml:"enabled"`
+	Disabled int    `json:"disabled" yaml:"disabled"`
+}
+
+// Stats counts entries overall and per category, in file order. Entries
+// whose IP does not parse are counted in the totals but as neither IPv4 nor
+// IPv6. Shadowed hostnames are declarations that FindShadowedEntries
+// reports as never resolving. An empty file yields all zeros.
+func (hf *HostsFile) Stats() Stats {
+	stats := Stats{Categories: []CategoryStats{}}
+
+	for _, category := range hf.Categories {
+		categoryStats := CategoryStats{Name: category.Name}
+		for _, entry := range category.Entries {
+			categoryStats.Total++
+			if entry.Enabled {
+				categoryStats.Enabled++
+			} else {
+				categoryStats.Disabled++
+			}
+
+			addr, _ := SplitZone(entry.IP)
+			if ip := net.ParseIP(NormalizeIP(addr)); ip != nil {
+				if ip.To4() != nil {
+					stats.IPv4++
+				} else {
+					stats.IPv6++
+				}
+			}
+		}
+
+		stats.Total += categoryStats.Total
+		stats.Enabled += categoryStats.Enabled
+		stats.Disabled += categoryStats.Disabled
+		stats.Categories = append(stats.Categories, categoryStats)
+	}
+
+	stats.DuplicateHostnames = len(hf.FindDuplicateHostnames())
+	stats.ShadowedHostnames = len(hf.FindShadowedEntries())
+
+	return stats
+}
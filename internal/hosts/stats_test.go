@@ -0,0 +1,67 @@
+package hosts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	hostsFile, err := NewParser("").ParseReader(strings.NewReader(`127.0.0.1 localhost
+::1 localhost
+
+# @category development
+192.168.1.10 api.dev
+# 192.168.1.11 web.dev
+fe80::1%eth0 router.dev
+
+# @category staging
+10.0.0.5 api.dev
+`))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	stats := hostsFile.Stats()
+
+	if stats.Total != 6 || stats.Enabled != 5 || stats.Disabled != 1 {
+		t.Errorf("Expected 6 total, 5 enabled, 1 disabled, got %+v", stats)
+	}
+	if stats.IPv4 != 4 || stats.IPv6 != 2 {
+		t.Errorf("Expected 4 IPv4 and 2 IPv6 entries, got %d and %d", stats.IPv4, stats.IPv6)
+	}
+	// localhost and api.dev are each declared twice
+	if stats.DuplicateHostnames != 2 {
+		t.Errorf("Expected 2 duplicate hostnames, got %d", stats.DuplicateHostnames)
+	}
+
+	expected := []CategoryStats{
+		{Name: "default", Total: 2, Enabled: 2},
+		{Name: "development", Total: 3, Enabled: 2, Disabled: 1},
+		{Name: "staging", Total: 1, Enabled: 1},
+	}
+	if len(stats.Categories) != len(expected) {
+		t.Fatalf("Expected %d categories, got %+v", len(expected), stats.Categories)
+	}
+	for i, want := range expected {
+		if stats.Categories[i] != want {
+			t.Errorf("Category %d = %+v, want %+v", i, stats.Categories[i], want)
+		}
+	}
+}
+
+func TestStatsEmptyFile(t *testing.T) {
+	hostsFile, err := NewParser("").ParseReader(strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+
+	stats := hostsFile.Stats()
+	if stats.Total != 0 || stats.Enabled != 0 || stats.Disabled != 0 || stats.IPv4 != 0 || stats.IPv6 != 0 || stats.DuplicateHostnames != 0 {
+		t.Errorf("Expected all zeros for an empty file, got %+v", stats)
+	}
+	for _, category := range stats.Categories {
+		if category.Total != 0 {
+			t.Errorf("Expected empty category, got %+v", category)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package hosts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseStream(t *testing.T) {
+	content := `# Static table lookup for hostnames.
+127.0.0.1 localhost
+
+# @category development
+# =============== DEVELOPMENT ===============
+# API server
+192.168.1.10 api.dev # local api
+# 192.168.1.11 web.dev
+999.1.1.1 bad.dev
+`
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write hosts file: %v", err)
+	}
+
+	parsed, err := NewParser(path).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	var want []Entry
+	for _, category := range parsed.Categories {
+		want = append(want, category.Entries...)
+	}
+
+	var got []Entry
+	if err := NewParser(path).ParseStream(func(entry Entry) error {
+		got = append(got, entry)
+		return nil
+	}); err != nil {
+		t.Fatalf("ParseStream() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStream() entries = %+v, want %+v", got, want)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = NewParser(path).ParseStream(func(Entry) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("ParseStream() = %v after %d calls, want the callback error after 1", err, calls)
+	}
+
+	if err := NewParser(filepath.Join(t.TempDir(), "missing")).ParseStream(func(Entry) error { return nil }); err == nil {
+		t.Error("ParseStream() on a missing file expected error")
+	}
+}
+
+func writeLargeHostsFile(b *testing.B, lines int) string {
+	b.Helper()
+	var sb strings.Builder
+	sb.WriteString("# @category blocklist\n")
+	for i := 0; i < lines; i++ {
+		fmt.Fprintf(&sb, "127.0.0.1 ads%d.example.com\n", i)
+	}
+	path := filepath.Join(b.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		b.Fatalf("Failed to write hosts file: %v", err)
+	}
+	return path
+}
+
+func BenchmarkParse200k(b *testing.B) {
+	path := writeLargeHostsFile(b, 200000)
+
+	b.Run("Parse", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := NewParser(path).Parse(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("ParseStream", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			count := 0
+			if err := NewParser(path).ParseStream(func(Entry) error {
+				count++
+				return nil
+			}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package hosts
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Structured tags are stored at the end of an entry's trailing comment, after
+// any free text, as in
+//
+//	10.0.0.1 demo.local # temporary @tags demo,qa @expires 2024-06-01T12:00:00Z
+//
+// Category markers are whole comment lines, so they never collide with these.
+const (
+	tagsTag    = "@tags"
+	expiresTag = "@expires"
+)
+
+var tagRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.\-]{0,63}$`)
+
+// commentTags holds the structured tags found in an entry comment
+type commentTags struct {
+	tags      []string
+	expiresAt time.Time
+}
+
+// splitCommentTags separates structured tags from the free text of an entry
+// comment. Tags with values that do not parse are left in the text.
+func splitCommentTags(comment string) (string, commentTags) {
+	var parsed commentTags
+	if !strings.Contains(comment, tagsTag) && !strings.Contains(comment, expiresTag) {
+		return comment, parsed
+	}
+
+	fields := strings.Fields(comment)
+	kept := fields[:0]
+	for i := 0; i < len(fields); i++ {
+		if i+1 < len(fields) {
+			switch fields[i] {
+			case tagsTag:
+				if tags, ok := parseTagList(fields[i+1]); ok {
+					parsed.tags = tags
+					i++
+					continue
+				}
+			case expiresTag:
+				if t, ok := parseExpiry(fields[i+1]); ok {
+					parsed.expiresAt = t
+					i++
+					continue
+				}
+			}
+		}
+		kept = append(kept, fields[i])
+	}
+
+	return strings.Join(kept, " "), parsed
+}
+
+func parseTagList(value string) ([]string, bool) {
+	tags := strings.Split(value, ",")
+	for _, tag := range tags {
+		if !tagRegex.MatchString(tag) {
+			return nil, false
+		}
+	}
+	return tags, true
+}
+
+// TaggedComment returns the comment as written to the hosts file: the free
+// text followed by any structured tags
+func (e Entry) TaggedComment() string {
+	parts := []string{}
+	if e.Comment != "" {
+		parts = append(parts, e.Comment)
+	}
+	if len(e.Tags) > 0 {
+		parts = append(parts, tagsTag+" "+strings.Join(e.Tags, ","))
+	}
+	if !e.ExpiresAt.IsZero() {
+		parts = append(parts, expiresTag+" "+e.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	return strings.Join(parts, " ")
+}
+
+// HasTag reports whether the entry carries tag, ignoring case
+func (e Entry) HasTag(tag string) bool {
+	return slices.ContainsFunc(e.Tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
+
+// ValidateTag checks that tag can be stored in an @tags list
+func ValidateTag(tag string) error {
+	if !tagRegex.MatchString(tag) {
+		return fmt.Errorf("invalid tag %q: use up to 64 letters, digits, '.', '_' or '-', starting with a letter or digit", tag)
+	}
+	return nil
+}
+
+// NormalizeTags lowercases tags and drops duplicates, keeping their order
+func NormalizeTags(tags []string) []string {
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
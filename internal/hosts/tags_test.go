@@ -0,0 +1,102 @@
+package hosts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitCommentTags(t *testing.T) {
+	tests := []struct {
+		comment   string
+		text      string
+		tags      string
+		expiresAt time.Time
+	}{
+		{"", "", "", time.Time{}},
+		{"plain comment", "plain comment", "", time.Time{}},
+		{"demo @expires 2024-06-01T12:00:00Z", "demo", "", time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)},
+		{"@expires 2024-06-01", "", "", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"API @tags web,qa", "API", "web,qa", time.Time{}},
+		{"@tags web @expires 2024-06-01", "", "web", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		// Unparseable values stay part of the free text
+		{"@expires soon", "@expires soon", "", time.Time{}},
+		{"ends with @expires", "ends with @expires", "", time.Time{}},
+		{"@tags bad!,web", "@tags bad!,web", "", time.Time{}},
+		{"email me @tags", "email me @tags", "", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		text, parsed := splitCommentTags(tt.comment)
+		if text != tt.text || strings.Join(parsed.tags, ",") != tt.tags || !parsed.expiresAt.Equal(tt.expiresAt) {
+			t.Errorf("splitCommentTags(%q) = %q, %v, %v; want %q, %s, %v",
+				tt.comment, text, parsed.tags, parsed.expiresAt, tt.text, tt.tags, tt.expiresAt)
+		}
+	}
+}
+
+func TestTagsRoundTrip(t *testing.T) {
+	content := `# @category development
+192.168.1.10 api.dev # API server
+192.168.1.11 web.dev # frontend @tags web,QA
+# 192.168.1.12 old.dev # @tags legacy
+`
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	hostsFile, err := NewParser(path).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	entries := hostsFile.GetCategory("development").Entries
+	if len(entries[0].Tags) != 0 || entries[0].Comment != "API server" {
+		t.Errorf("Expected untagged entry unchanged, got %+v", entries[0])
+	}
+	if entries[1].Comment != "frontend" || !entries[1].HasTag("qa") || !entries[1].HasTag("web") {
+		t.Errorf("Expected tags parsed, got %+v", entries[1])
+	}
+	if entries[2].Enabled || !entries[2].HasTag("legacy") {
+		t.Errorf("Expected tags parsed on disabled entry, got %+v", entries[2])
+	}
+
+	entries[0].Tags = []string{"api"}
+	if err := hostsFile.Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	for _, line := range []string{
+		"192.168.1.10 api.dev # API server @tags api\n",
+		"192.168.1.11 web.dev # frontend @tags web,QA\n",
+		"# 192.168.1.12 old.dev # @tags legacy\n",
+	} {
+		if !strings.Contains(string(data), line) {
+			t.Errorf("Expected %q in written file, got:\n%s", line, data)
+		}
+	}
+}
+
+func TestValidateTags(t *testing.T) {
+	for _, tag := range []string{"web", "team-a", "v1.2", "qa_env"} {
+		if err := ValidateTag(tag); err != nil {
+			t.Errorf("ValidateTag(%q) error = %v", tag, err)
+		}
+	}
+	for _, tag := range []string{"", "-web", "a,b", "has space", "@tags"} {
+		if err := ValidateTag(tag); err == nil {
+			t.Errorf("ValidateTag(%q) expected an error", tag)
+		}
+	}
+
+	entry := Entry{IP: "192.168.1.10", Hostnames: []string{"api.dev"}, Tags: []string{"bad tag"}}
+	if err := ValidateEntry(entry); err == nil {
+		t.Error("Expected entry with an invalid tag to be rejected")
+	}
+
+	if got := NormalizeTags([]string{"Web", " qa ", "web", ""}); strings.Join(got, ",") != "web,qa" {
+		t.Errorf("NormalizeTags() = %v, want [web qa]", got)
+	}
+}
@@ -1,6 +1,7 @@
 package hosts
 
 import (
+	"sync"
 	"time"
 )
 
@@ -11,6 +12,19 @@ type Entry struct {
 	Category  string   `json:"category" yaml:"category"`
 	Enabled   bool     `json:"enabled" yaml:"enabled"`
 	LineNum   int      `json:"line_num,omitempty" yaml:"line_num,omitempty"`
+	// Tags are free-form labels, stored in the comment as an @tags list
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// ExpiresAt is when a temporary entry should be removed; zero for permanent
+	// entries. It is stored in the comment as an @expires tag.
+	ExpiresAt time.Time `json:"expires_at,omitzero" yaml:"expires_at,omitempty"`
+	// LeadingComments are the comment and blank lines directly above the
+	// entry, written back before it so annotations survive an edit
+	LeadingComments []string `json:"-" yaml:"-"`
+	// Raw is the original line as read from disk
+	Raw string `json:"-" yaml:"-"`
+	// ReadOnly marks entries matched by the ignore file; they are written back
+	// verbatim and skipped by managed operations unless forced
+	ReadOnly bool `json:"-" yaml:"-"`
 }
 
 type Category struct {
@@ -26,6 +40,40 @@ type HostsFile struct {
 	Footer     []string   `json:"footer,omitempty" yaml:"footer,omitempty"`
 	Modified   time.Time  `json:"modified" yaml:"modified"`
 	FilePath   string     `json:"file_path" yaml:"file_path"`
+	// ParseIssues holds the non-blank lines that were not read as an entry,
+	// category marker, banner or comment, in file order. Those before the
+	// first entry stay in the header; the rest are not written back.
+	ParseIssues []ParseIssue `json:"-" yaml:"-"`
+
+	bannerStyle  BannerStyle
+	normalizeIPs bool
+	idnMode      IDNMode
+	hostnameMode HostnameValidationMode
+
+	// index is built by BuildIndex and dropped by every mutation method
+	index *entryIndex
+	// managed holds the text around the managed block when the file was
+	// parsed in managed block mode
+	managed *managedRegions
+
+	// mu serializes the mutation methods: the AddEntry, AddEntries,
+	// AddOrMergeEntry, InsertEntry, RemoveEntry, Enable*, Disable*,
+	// SplitHostname, MoveEntry, AddCategory, RemoveCategory, RemoveExpired,
+	// RemoveDuplicateHostnames and Sort* families, and BuildIndex. Write,
+	// lookups and direct field access are not guarded and still need external
+	// coordination with concurrent writers.
+	mu sync.Mutex
+}
+
+// ParseIssue is a non-blank line that was not read as an entry, category
+// marker, banner or comment, and the reason it was skipped
+type ParseIssue struct {
+	LineNum int
+	Line    string
+	Reason  string
+	// InvalidIP is set for entry-like lines skipped because their IP
+	// address failed validation
+	InvalidIP bool
 }
 
 type Profile struct {
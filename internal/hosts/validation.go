@@ -14,6 +14,9 @@ var (
 	// RFC-compliant hostname validation
 	hostnameRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?)*$`)
 
+	// Same as hostnameRegex but also accepts underscores, as found in many real-world blocklists
+	lenientHostnameRegex = regexp.MustCompile(`^[a-zA-Z0-9_]([a-zA-Z0-9_\-]{0,61}[a-zA-Z0-9_])?(\.[a-zA-Z0-9_]([a-zA-Z0-9_\-]{0,61}[a-zA-Z0-9_])?)*$`)
+
 	// Dangerous patterns to reject
 	dangerousHostnamePatterns = []*regexp.Regexp{
 		regexp.MustCompile(`\.\./`),                // Path traversal
@@ -23,8 +26,137 @@ var (
 		regexp.MustCompile(`\.$`),                  // Trailing dot (may cause issues)
 		regexp.MustCompile(`\s`),                   // Whitespace
 	}
+
+	// IPv6 zone identifiers name an interface (eth0, en0) or its index (3)
+	zoneRegex = regexp.MustCompile(`^[a-zA-Z0-9_.\-]{1,64}$`)
+)
+
+// HostnameValidationMode controls how strictly hostnames are validated
+type HostnameValidationMode int
+
+const (
+	// HostnameStrict enforces RFC hostname rules and all security checks
+	HostnameStrict HostnameValidationMode = iota
+	// HostnameLenient accepts underscores and downgrades homograph checks to logged warnings
+	HostnameLenient
+	// HostnameUnderscores accepts underscores, as used by SRV-style and many
+	// internal names, and keeps every other check
+	HostnameUnderscores
+)
+
+// allowsUnderscores reports whether mode accepts underscores in labels
+func (mode HostnameValidationMode) allowsUnderscores() bool {
+	return mode == HostnameLenient || mode == HostnameUnderscores
+}
+
+// SetHostnameMode sets how hostnames are validated when entries are added
+// to or linted in hf. The zero value is HostnameStrict.
+func (hf *HostsFile) SetHostnameMode(mode HostnameValidationMode) {
+	hf.hostnameMode = mode
+}
+
+// HostnameMode returns the mode set with SetHostnameMode
+func (hf *HostsFile) HostnameMode() HostnameValidationMode {
+	return hf.hostnameMode
+}
+
+// IPMode controls how IPv4 addresses written with leading zeros (10.0.0.001)
+// are recorded when parsing. Such octets are always read as decimal, never
+// octal. Shorthand forms like 10.1 are rejected in either mode.
+type IPMode int
+
+const (
+	// IPNormalize strips leading zeros on parse and logs a warning
+	IPNormalize IPMode = iota
+	// IPPreserve keeps the original text, validating only its numeric value
+	IPPreserve
+)
+
+// ReversedLineMode controls how lines written hostname first
+// ("localhost 127.0.0.1") are handled when parsing
+type ReversedLineMode int
+
+const (
+	// ReversedReport skips reversed lines and reports them through
+	// HostsFile.ParseIssues
+	ReversedReport ReversedLineMode = iota
+	// ReversedFix reads reversed lines as entries and logs a warning
+	ReversedFix
 )
 
+// ParseReversedLineMode converts a configuration value ("report" or "fix") to
+// a ReversedLineMode. An empty value selects ReversedReport.
+func ParseReversedLineMode(mode string) (ReversedLineMode, error) {
+	switch mode {
+	case "", "report":
+		return ReversedReport, nil
+	case "fix":
+		return ReversedFix, nil
+	default:
+		return ReversedReport, fmt.Errorf("invalid reversed lines mode %q (must be report or fix)", mode)
+	}
+}
+
+// ParseIPMode converts a configuration value ("normalize" or "preserve") to an
+// IPMode. An empty value selects IPNormalize.
+func ParseIPMode(mode string) (IPMode, error) {
+	switch mode {
+	case "", "normalize":
+		return IPNormalize, nil
+	case "preserve":
+		return IPPreserve, nil
+	default:
+		return IPNormalize, fmt.Errorf("invalid ip format %q (must be normalize or preserve)", mode)
+	}
+}
+
+// SplitZone splits an optional IPv6 zone identifier ("fe80::1%eth0") from an
+// address. zone is empty when the address has none.
+func SplitZone(ip string) (addr, zone string) {
+	if i := strings.IndexByte(ip, '%'); i >= 0 {
+		return ip[:i], ip[i+1:]
+	}
+	return ip, ""
+}
+
+// NormalizeIP strips leading zeros from the octets of an IPv4 address.
+// Addresses net.ParseIP already accepts, and invalid input, are returned
+// unchanged. An IPv6 zone identifier is kept as it is.
+func NormalizeIP(ip string) string {
+	if addr, zone := SplitZone(ip); zone != "" {
+		return NormalizeIP(addr) + "%" + zone
+	}
+
+	if net.ParseIP(ip) != nil {
+		return ip
+	}
+
+	octets := strings.Split(ip, ".")
+	if len(octets) != 4 {
+		return ip
+	}
+
+	canonical := make([]string, len(octets))
+	for i, octet := range octets {
+		if octet == "" || len(octet) > 3 {
+			return ip
+		}
+		value := 0
+		for _, r := range octet {
+			if r < '0' || r > '9' {
+				return ip
+			}
+			value = value*10 + int(r-'0')
+		}
+		if value > 255 {
+			return ip
+		}
+		canonical[i] = fmt.Sprintf("%d", value)
+	}
+
+	return strings.Join(canonical, ".")
+}
+
 // ValidateIP performs comprehensive IP address validation
 func ValidateIP(ip string) error {
 	if ip == "" {
@@ -32,13 +164,26 @@ func ValidateIP(ip string) error {
 		return fmt.Errorf("IP address cannot be empty")
 	}
 
-	// Basic format validation
-	parsedIP := net.ParseIP(ip)
+	// Basic format validation; leading-zero IPv4 octets are validated by value
+	addr, zone := SplitZone(ip)
+	parsedIP := net.ParseIP(NormalizeIP(addr))
 	if parsedIP == nil {
 		logValidationFailure(ip, "ip_address", "invalid IP address format")
 		return fmt.Errorf("invalid IP address format: %s", ip)
 	}
 
+	// A zone only selects the interface for link-local scope and is meaningless elsewhere
+	if strings.Contains(ip, "%") {
+		if !zoneRegex.MatchString(zone) {
+			logValidationFailure(ip, "ip_address", "invalid IPv6 zone identifier")
+			return fmt.Errorf("invalid IPv6 zone identifier: %s", ip)
+		}
+		if parsedIP.To4() != nil || !parsedIP.IsLinkLocalUnicast() {
+			logValidationFailure(ip, "ip_address", "zone identifier on non-link-local address")
+			return fmt.Errorf("zone identifiers are only allowed on IPv6 link-local addresses: %s", ip)
+		}
+	}
+
 	// Convert to standard format for consistent checking
 	ipStr := parsedIP.String()
 
@@ -103,6 +248,14 @@ func validateIPSecurity(ip net.IP) error {
 	return nil
 }
 
+// IsPrivateIP reports whether ip, which may carry an IPv6 zone, is a
+// private, loopback, or link-local address
+func IsPrivateIP(ip string) bool {
+	addr, _ := SplitZone(ip)
+	parsed := net.ParseIP(addr)
+	return parsed != nil && isPrivateIP(parsed)
+}
+
 // isPrivateIP checks if an IP is in private ranges (more comprehensive than Go's IsPrivate)
 func isPrivateIP(ip net.IP) bool {
 	// Standard private ranges
@@ -134,6 +287,11 @@ func isPrivateIP(ip net.IP) bool {
 
 // ValidateHostname performs comprehensive hostname validation
 func ValidateHostname(hostname string) error {
+	return ValidateHostnameWithMode(hostname, HostnameStrict)
+}
+
+// ValidateHostnameWithMode validates hostname under the given validation mode
+func ValidateHostnameWithMode(hostname string, mode HostnameValidationMode) error {
 	if hostname == "" {
 		logValidationFailure(hostname, "hostname", "hostname cannot be empty")
 		return fmt.Errorf("hostname cannot be empty")
@@ -146,29 +304,37 @@ func ValidateHostname(hostname string) error {
 	}
 
 	// Basic format validation using RFC-compliant regex
-	if !hostnameRegex.MatchString(hostname) {
+	regex := hostnameRegex
+	if mode.allowsUnderscores() {
+		regex = lenientHostnameRegex
+	}
+	if !regex.MatchString(hostname) {
 		logValidationFailure(hostname, "hostname", "invalid hostname format")
 		return fmt.Errorf("invalid hostname format: %s", hostname)
 	}
 
 	// Security validation
-	if err := validateHostnameSecurity(hostname); err != nil {
+	if err := validateHostnameSecurity(hostname, mode); err != nil {
 		return fmt.Errorf("hostname security validation failed: %w", err)
 	}
 
 	// Validate each label (part between dots)
 	labels := strings.Split(hostname, ".")
 	for _, label := range labels {
-		if err := validateHostnameLabel(label); err != nil {
+		if err := validateHostnameLabel(label, mode); err != nil {
 			return fmt.Errorf("invalid hostname label '%s': %w", label, err)
 		}
 	}
 
+	if mode == HostnameLenient && strings.Contains(hostname, "_") {
+		logValidationFailure(hostname, "hostname_lenient_warning", "underscore accepted in lenient hostname mode")
+	}
+
 	return nil
 }
 
 // validateHostnameSecurity checks for security issues in hostnames
-func validateHostnameSecurity(hostname string) error {
+func validateHostnameSecurity(hostname string, mode HostnameValidationMode) error {
 	// Check against dangerous patterns
 	for _, pattern := range dangerousHostnamePatterns {
 		if pattern.MatchString(hostname) {
@@ -190,6 +356,10 @@ func validateHostnameSecurity(hostname string) error {
 
 	// Check for homograph attacks (similar-looking characters)
 	if containsHomographs(hostname) {
+		if mode == HostnameLenient {
+			logValidationFailure(hostname, "hostname_lenient_warning", "possible homograph characters accepted in lenient hostname mode")
+			return nil
+		}
 		return fmt.Errorf("hostname may contain homograph attack characters: %s", hostname)
 	}
 
@@ -204,7 +374,7 @@ func validateHostnameSecurity(hostname string) error {
 }
 
 // validateHostnameLabel validates individual hostname labels
-func validateHostnameLabel(label string) error {
+func validateHostnameLabel(label string, mode HostnameValidationMode) error {
 	if label == "" {
 		return fmt.Errorf("empty label")
 	}
@@ -220,6 +390,9 @@ func validateHostnameLabel(label string) error {
 
 	// Ensure all characters are valid
 	for _, r := range label {
+		if r == '_' && mode.allowsUnderscores() {
+			continue
+		}
 		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' {
 			return fmt.Errorf("label contains invalid character: %c", r)
 		}
@@ -228,24 +401,24 @@ func validateHostnameLabel(label string) error {
 	return nil
 }
 
-// containsHomographs checks for potential homograph attack characters
+// homographScripts are scripts with letters that look alike; a label using
+// more than one of them is likely imitating another name
+var homographScripts = []*unicode.RangeTable{unicode.Latin, unicode.Cyrillic, unicode.Greek}
+
+// containsHomographs reports whether any label of hostname mixes letters from
+// different homograph scripts, such as a Cyrillic 'о' in "gооgle". Labels
+// written entirely in one script, like "тест", are not flagged.
 func containsHomographs(hostname string) bool {
-	// Simple check for common homograph characters
-	// In a production system, this would be more comprehensive
-	suspiciousChars := []rune{
-		0x430,  // Cyrillic 'a'
-		0x043e, // Cyrillic 'o'
-		0x0440, // Cyrillic 'p'
-		0x0435, // Cyrillic 'e'
-		// Add more as needed
-	}
-
-	for _, char := range hostname {
-		for _, suspicious := range suspiciousChars {
-			if char == suspicious {
-				return true
+	for _, label := range strings.Split(hostname, ".") {
+		scripts := 0
+		for _, script := range homographScripts {
+			if strings.IndexFunc(label, func(r rune) bool { return unicode.Is(script, r) }) >= 0 {
+				scripts++
 			}
 		}
+		if scripts > 1 {
+			return true
+		}
 	}
 
 	return false
@@ -294,6 +467,11 @@ func ValidateComment(comment string) error {
 
 // ValidateEntry performs comprehensive validation of a hosts entry
 func ValidateEntry(entry Entry) error {
+	return ValidateEntryWithMode(entry, HostnameStrict)
+}
+
+// ValidateEntryWithMode validates entry, checking its hostnames under the given mode
+func ValidateEntryWithMode(entry Entry, mode HostnameValidationMode) error {
 	// Validate IP address
 	if err := ValidateIP(entry.IP); err != nil {
 		return fmt.Errorf("invalid IP address: %w", err)
@@ -305,7 +483,7 @@ func ValidateEntry(entry Entry) error {
 	}
 
 	for _, hostname := range entry.Hostnames {
-		if err := ValidateHostname(hostname); err != nil {
+		if err := ValidateHostnameWithMode(hostname, mode); err != nil {
 			return fmt.Errorf("invalid hostname: %w", err)
 		}
 	}
@@ -315,9 +493,15 @@ func ValidateEntry(entry Entry) error {
 		return fmt.Errorf("invalid comment: %w", err)
 	}
 
+	for _, tag := range entry.Tags {
+		if err := ValidateTag(tag); err != nil {
+			return err
+		}
+	}
+
 	// Validate category name
 	if entry.Category != "" {
-		if err := validateCategoryName(entry.Category); err != nil {
+		if err := ValidateCategoryName(entry.Category); err != nil {
 			return fmt.Errorf("invalid category: %w", err)
 		}
 	}
@@ -325,8 +509,8 @@ func ValidateEntry(entry Entry) error {
 	return nil
 }
 
-// validateCategoryName validates category names
-func validateCategoryName(category string) error {
+// ValidateCategoryName checks that category is a safe category name
+func ValidateCategoryName(category string) error {
 	if category == "" {
 		return fmt.Errorf("category name cannot be empty")
 	}
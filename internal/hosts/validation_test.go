@@ -27,6 +27,8 @@ func TestValidateIP(t *testing.T) {
 		{name: "compressed IPv6", ip: "2001:db8::1", expectErr: false},
 		{name: "link-local IPv6", ip: "fe80::1", expectErr: false}, // Should be allowed with warning
 		{name: "private IPv6", ip: "fc00::1", expectErr: false},
+		{name: "link-local IPv6 with zone", ip: "fe80::1%eth0", expectErr: false},
+		{name: "link-local IPv6 with numeric zone", ip: "fe80::1%3", expectErr: false},
 
 		// Invalid addresses
 		{name: "empty string", ip: "", expectErr: true},
@@ -40,6 +42,12 @@ func TestValidateIP(t *testing.T) {
 		// Multicast addresses (should be rejected)
 		{name: "IPv4 multicast", ip: "224.0.0.1", expectErr: true},
 		{name: "IPv6 multicast", ip: "ff02::1", expectErr: true},
+		// Zones are only meaningful on IPv6 link-local addresses
+		{name: "zone on global IPv6", ip: "2001:db8::1%eth0", expectErr: true},
+		{name: "zone on IPv4", ip: "192.168.1.1%eth0", expectErr: true},
+		{name: "zone on IPv4 link-local", ip: "169.254.1.1%eth0", expectErr: true},
+		{name: "empty zone", ip: "fe80::1%", expectErr: true},
+		{name: "zone with path", ip: "fe80::1%../eth0", expectErr: true},
 	}
 
 	for _, tt := range tests {
@@ -276,13 +284,13 @@ func TestValidateCategoryName(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateCategoryName(tt.category)
+			err := ValidateCategoryName(tt.category)
 
 			if tt.expectErr && err == nil {
-				t.Errorf("validateCategoryName(%q) expected error but got none", tt.category)
+				t.Errorf("ValidateCategoryName(%q) expected error but got none", tt.category)
 			}
 			if !tt.expectErr && err != nil {
-				t.Errorf("validateCategoryName(%q) unexpected error: %v", tt.category, err)
+				t.Errorf("ValidateCategoryName(%q) unexpected error: %v", tt.category, err)
 			}
 		})
 	}
@@ -364,8 +372,18 @@ func TestIsPrivateIP(t *testing.T) {
 			if result != tt.private {
 				t.Errorf("isPrivateIP(%q) = %v, want %v", tt.ip, result, tt.private)
 			}
+			if IsPrivateIP(tt.ip) != tt.private {
+				t.Errorf("IsPrivateIP(%q) = %v, want %v", tt.ip, !tt.private, tt.private)
+			}
 		})
 	}
+
+	if !IsPrivateIP("fe80::1%eth0") {
+		t.Error("Expected zoned link-local address to be private")
+	}
+	if IsPrivateIP("not-an-ip") {
+		t.Error("Expected invalid address not to be private")
+	}
 }
 
 // TestContainsHomographs tests homograph detection
@@ -380,6 +398,8 @@ func TestContainsHomographs(t *testing.T) {
 		{name: "cyrillic a", hostname: "еxample.com", expectHomographs: true},  // Cyrillic 'e'
 		{name: "cyrillic o", hostname: "examplе.com", expectHomographs: true},  // Cyrillic 'e'
 		{name: "mixed script", hostname: "gооgle.com", expectHomographs: true}, // Cyrillic 'o'
+		{name: "single script label", hostname: "тест.com", expectHomographs: false},
+		{name: "Greek and Latin", hostname: "αlpha.com", expectHomographs: true},
 	}
 
 	for _, tt := range tests {
@@ -418,7 +438,7 @@ func TestValidateHostnameLabel(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateHostnameLabel(tt.label)
+			err := validateHostnameLabel(tt.label, HostnameStrict)
 
 			if tt.expectErr && err == nil {
 				t.Errorf("validateHostnameLabel(%q) expected error but got none", tt.label)
@@ -457,7 +477,7 @@ func TestValidateHostnameSecurity(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateHostnameSecurity(tt.hostname)
+			err := validateHostnameSecurity(tt.hostname, HostnameStrict)
 
 			if tt.expectErr && err == nil {
 				t.Errorf("validateHostnameSecurity(%q) expected error but got none", tt.hostname)
@@ -582,3 +602,119 @@ func BenchmarkValidateEntry(b *testing.B) {
 		_ = ValidateEntry(testEntry)
 	}
 }
+
+// TestValidateHostnameWithMode tests strict, lenient and underscore hostname validation
+func TestValidateHostnameWithMode(t *testing.T) {
+	tests := []struct {
+		name          string
+		hostname      string
+		strictErr     bool
+		lenientErr    bool
+		underscoreErr bool
+	}{
+		{name: "plain hostname", hostname: "api.example.com", strictErr: false, lenientErr: false, underscoreErr: false},
+		{name: "underscore", hostname: "my_host.example.com", strictErr: true, lenientErr: false, underscoreErr: false},
+		{name: "leading underscore label", hostname: "_dmarc.example.com", strictErr: true, lenientErr: false, underscoreErr: false},
+		{name: "SRV-style name", hostname: "_sip._tcp.corp.internal", strictErr: true, lenientErr: false, underscoreErr: false},
+		{name: "label too long", hostname: strings.Repeat("a", 64) + ".com", strictErr: true, lenientErr: true, underscoreErr: true},
+		{name: "script injection", hostname: "<script>.com", strictErr: true, lenientErr: true, underscoreErr: true},
+		{name: "control character", hostname: "my_host\x00.com", strictErr: true, lenientErr: true, underscoreErr: true},
+		{name: "non-ASCII", hostname: "тест.com", strictErr: true, lenientErr: true, underscoreErr: true},
+		{name: "leading hyphen", hostname: "-my_host.com", strictErr: true, lenientErr: true, underscoreErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateHostnameWithMode(tt.hostname, HostnameStrict); (err != nil) != tt.strictErr {
+				t.Errorf("strict ValidateHostnameWithMode(%q) error = %v, wantErr %v", tt.hostname, err, tt.strictErr)
+			}
+			if err := ValidateHostnameWithMode(tt.hostname, HostnameLenient); (err != nil) != tt.lenientErr {
+				t.Errorf("lenient ValidateHostnameWithMode(%q) error = %v, wantErr %v", tt.hostname, err, tt.lenientErr)
+			}
+			if err := ValidateHostnameWithMode(tt.hostname, HostnameUnderscores); (err != nil) != tt.underscoreErr {
+				t.Errorf("underscore ValidateHostnameWithMode(%q) error = %v, wantErr %v", tt.hostname, err, tt.underscoreErr)
+			}
+		})
+	}
+}
+
+func TestHostsFileHostnameMode(t *testing.T) {
+	hf, err := NewParser("").ParseReader(strings.NewReader("10.0.0.1 build_01.internal\n"))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	entry := Entry{IP: "10.0.0.2", Hostnames: []string{"build_02.internal"}, Enabled: true}
+
+	if err := hf.AddEntry(entry); err == nil {
+		t.Error("AddEntry() should reject underscores by default")
+	}
+	if len(hf.Lint()) != 1 {
+		t.Errorf("Lint() = %+v, want the underscore hostname reported by default", hf.Lint())
+	}
+
+	hf.SetHostnameMode(HostnameUnderscores)
+	if err := hf.AddEntry(entry); err != nil {
+		t.Errorf("AddEntry() with HostnameUnderscores error = %v", err)
+	}
+	if problems := hf.Lint(); len(problems) != 0 {
+		t.Errorf("Lint() with HostnameUnderscores = %+v, want none", problems)
+	}
+	if hf.Clone().HostnameMode() != HostnameUnderscores {
+		t.Error("Clone() should keep the hostname mode")
+	}
+}
+
+// TestNormalizeIP tests leading-zero IPv4 handling
+func TestNormalizeIP(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"10.0.0.001", "10.0.0.1"},
+		{"010.000.000.010", "10.0.0.10"}, // decimal, not octal
+		{"192.168.1.1", "192.168.1.1"},
+		{"::0001", "::0001"}, // accepted by net.ParseIP, left as-is
+		{"10.0.0.256", "10.0.0.256"},
+		{"10.0.0.0001", "10.0.0.0001"},
+		{"10.1", "10.1"},
+		{"not-an-ip", "not-an-ip"},
+		{"fe80::1%eth0", "fe80::1%eth0"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeIP(tt.input); got != tt.expected {
+			t.Errorf("NormalizeIP(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+
+	if err := ValidateIP("10.0.0.001"); err != nil {
+		t.Errorf("ValidateIP should accept leading-zero IPv4, got: %v", err)
+	}
+	if err := ValidateIP("10.1"); err == nil {
+		t.Error("ValidateIP should reject shorthand IPv4")
+	}
+}
+
+// TestParseIPMode tests converting configuration values to IP modes
+func TestParseIPMode(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  IPMode
+		expectErr bool
+	}{
+		{"", IPNormalize, false},
+		{"normalize", IPNormalize, false},
+		{"preserve", IPPreserve, false},
+		{"octal", IPNormalize, true},
+	}
+
+	for _, tt := range tests {
+		mode, err := ParseIPMode(tt.input)
+		if (err != nil) != tt.expectErr {
+			t.Errorf("ParseIPMode(%q) error = %v, expectErr %v", tt.input, err, tt.expectErr)
+		}
+		if mode != tt.expected {
+			t.Errorf("ParseIPMode(%q) = %v, want %v", tt.input, mode, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// keyAction names a main view command. The names are the keys accepted in
+// the ui.key_bindings config section.
+type keyAction string
+
+const (
+	actionUp              keyAction = "up"
+	actionDown            keyAction = "down"
+	actionTop             keyAction = "top"
+	actionBottom          keyAction = "bottom"
+	actionNextPage        keyAction = "next_page"
+	actionPrevPage        keyAction = "prev_page"
+	actionToggle          keyAction = "toggle"
+	actionSelect          keyAction = "select"
+	actionCopy            keyAction = "copy"
+	actionEnableSelected  keyAction = "enable_selected"
+	actionDisableSelected keyAction = "disable_selected"
+	actionDeleteSelected  keyAction = "delete_selected"
+	actionAdd             keyAction = "add"
+	actionCreateCategory  keyAction = "create_category"
+	actionEdit            keyAction = "edit"
+	actionMove            keyAction = "move"
+	actionDelete          keyAction = "delete"
+	actionSave            keyAction = "save"
+	actionSaveFlush       keyAction = "save_flush"
+	actionRefresh         keyAction = "refresh"
+	actionFilter          keyAction = "filter"
+	actionSearch          keyAction = "search"
+	actionDetails         keyAction = "details"
+	actionHelp            keyAction = "help"
+	actionQuit            keyAction = "quit"
+)
+
+// keyBinding ties an action to the keys that trigger it. A configured key
+// replaces keys; fixed keys always stay bound so the arrows and ctrl+c keep
+// working whatever the config says.
+type keyBinding struct {
+	action keyAction
+	group  string
+	help   string
+	keys   []string
+	fixed  []string
+}
+
+// defaultKeyBindings lists every main view action in help order
+var defaultKeyBindings = []keyBinding{
+	{action: actionUp, group: "Navigation", help: "Move cursor up", keys: []string{"k"}, fixed: []string{"up"}},
+	{action: actionDown, group: "Navigation", help: "Move cursor down", keys: []string{"j"}, fixed: []string{"down"}},
+	{action: actionTop, group: "Navigation", help: "Go to top", keys: []string{"g"}},
+	{action: actionBottom, group: "Navigation", help: "Go to bottom", keys: []string{"G"}},
+	{action: actionNextPage, group: "Navigation", help: "Next page", keys: []string{"n"}, fixed: []string{"pgdown"}},
+	{action: actionPrevPage, group: "Navigation", help: "Previous page", keys: []string{"p"}, fixed: []string{"pgup"}},
+	{action: actionToggle, group: "Actions", help: "Toggle entry enabled/disabled", keys: []string{" "}},
+	{action: actionSelect, group: "Actions", help: "Select/deselect entry for bulk actions", keys: []string{"x"}},
+	{action: actionCopy, group: "Actions", help: "Copy entry line to the clipboard", keys: []string{"y"}},
+	{action: actionEnableSelected, group: "Actions", help: "Enable all selected entries", keys: []string{"E"}},
+	{action: actionDisableSelected, group: "Actions", help: "Disable all selected entries", keys: []string{"I"}},
+	{action: actionDeleteSelected, group: "Actions", help: "Delete all selected entries", keys: []string{"D"}},
+	{action: actionAdd, group: "Actions", help: "Add new entry", keys: []string{"a"}},
+	{action: actionCreateCategory, group: "Actions", help: "Create new category", keys: []string{"c"}},
+	{action: actionEdit, group: "Actions", help: "Edit selected entry", keys: []string{"e"}},
+	{action: actionMove, group: "Actions", help: "Move entry to different category", keys: []string{"m"}},
+	{action: actionDelete, group: "Actions", help: "Delete entry", keys: []string{"d"}},
+	{action: actionSave, group: "Actions", help: "Save changes to hosts file", keys: []string{"s"}},
+	{action: actionSaveFlush, group: "Actions", help: "Save changes and flush the DNS cache", keys: []string{"S"}},
+	{action: actionRefresh, group: "Actions", help: "Refresh entry list", keys: []string{"r"}},
+	{action: actionFilter, group: "Actions", help: "Cycle status filter (all/enabled/disabled)", keys: []string{"f"}},
+	{action: actionSearch, group: "Actions", help: "Search entries", keys: []string{"/"}},
+	{action: actionDetails, group: "Actions", help: "Show entry details", keys: []string{"enter"}},
+	{action: actionHelp, group: "Views", help: "Show/hide this help", keys: []string{"?", "h"}},
+	{action: actionQuit, group: "Views", help: "Quit application", keys: []string{"q"}, fixed: []string{"ctrl+c"}},
+}
+
+// keyMap resolves main view keys to actions
+type keyMap struct {
+	bindings []keyBinding
+	actions  map[string]keyAction
+}
+
+// newKeyMap builds the main view keymap from the ui.key_bindings config
+// section. Actions that are not configured keep their default keys, as do
+// actions configured with one of their default keys, except keys the config
+// gives to another action. Unknown actions and keys configured for more than
+// one action are returned as errors.
+func newKeyMap(configured map[string]string) (*keyMap, error) {
+	km := &keyMap{actions: make(map[string]keyAction)}
+	index := make(map[keyAction]int, len(defaultKeyBindings))
+	for i, binding := range defaultKeyBindings {
+		binding.keys = append([]string(nil), binding.keys...)
+		km.bindings = append(km.bindings, binding)
+		index[binding.action] = i
+	}
+
+	names := make([]string, 0, len(configured))
+	for name := range configured {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	configuredKeys := make(map[string]keyAction)
+	for _, name := range names {
+		i, ok := index[keyAction(name)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown action %q", name))
+			continue
+		}
+		key := normalizeKey(configured[name])
+		if key == "" {
+			errs = append(errs, fmt.Errorf("%s: empty key", name))
+			continue
+		}
+		if other, taken := configuredKeys[key]; taken {
+			errs = append(errs, fmt.Errorf("%s is bound to both %s and %s", displayKey(key), other, name))
+			continue
+		}
+		binding := &km.bindings[i]
+		if !slices.Contains(binding.keys, key) && !slices.Contains(binding.fixed, key) {
+			binding.keys = []string{key}
+		}
+		configuredKeys[key] = binding.action
+	}
+
+	// A configured key takes over from another action's default key
+	for i := range km.bindings {
+		binding := &km.bindings[i]
+		binding.keys = slices.DeleteFunc(binding.keys, func(key string) bool {
+			owner, ok := configuredKeys[key]
+			return ok && owner != binding.action
+		})
+	}
+
+	for _, binding := range km.bindings {
+		for _, key := range append(append([]string(nil), binding.fixed...), binding.keys...) {
+			if other, taken := km.actions[key]; taken && other != binding.action {
+				errs = append(errs, fmt.Errorf("%s is bound to both %s and %s", displayKey(key), other, binding.action))
+				continue
+			}
+			km.actions[key] = binding.action
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid key bindings: %w", errors.Join(errs...))
+	}
+	return km, nil
+}
+
+// defaultKeyMap returns the keymap used when nothing is configured
+func defaultKeyMap() *keyMap {
+	km, _ := newKeyMap(nil)
+	return km
+}
+
+// action returns the action bound to key, as reported by tea.KeyMsg.String,
+// or "" if the key is unbound
+func (km *keyMap) action(key string) keyAction {
+	return km.actions[key]
+}
+
+// label returns the keys bound to action for display, e.g. "?/h"
+func (km *keyMap) label(action keyAction) string {
+	for _, binding := range km.bindings {
+		if binding.action != action {
+			continue
+		}
+		var keys []string
+		for _, key := range append(append([]string(nil), binding.fixed...), binding.keys...) {
+			keys = append(keys, displayKey(key))
+		}
+		if len(keys) == 0 {
+			return "unbound"
+		}
+		return strings.Join(keys, "/")
+	}
+	return ""
+}
+
+// primary returns the first configurable key bound to action for display
+func (km *keyMap) primary(action keyAction) string {
+	for _, binding := range km.bindings {
+		if binding.action == action && len(binding.keys) > 0 {
+			return displayKey(binding.keys[0])
+		}
+	}
+	return "-"
+}
+
+// normalizeKey converts a configured key to the form bubbletea reports:
+// "space" becomes " ", "shift+a" becomes "A" and "F5" becomes "f5".
+// Single characters keep their case so g and G stay distinct.
+func normalizeKey(key string) string {
+	key = strings.TrimSpace(key)
+	if len([]rune(key)) == 1 {
+		return key
+	}
+
+	lower := strings.ToLower(key)
+	switch {
+	case lower == "space":
+		return " "
+	case strings.HasPrefix(lower, "shift+") && len(key) == len("shift+")+1:
+		return strings.ToUpper(key[len("shift+"):])
+	case strings.HasPrefix(lower, "alt+"):
+		return "alt+" + key[len("alt+"):]
+	}
+	return lower
+}
+
+// displayKey returns key as shown in the help and controls
+func displayKey(key string) string {
+	switch key {
+	case " ":
+		return "space"
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	}
+	return key
+}
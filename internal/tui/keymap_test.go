@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brandonhon/hosts-manager/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNewKeyMapDefaults(t *testing.T) {
+	km, err := newKeyMap(config.DefaultConfig().UI.KeyBindings)
+	if err != nil {
+		t.Fatalf("newKeyMap() with the default config error = %v", err)
+	}
+
+	tests := map[string]keyAction{
+		"q":      actionQuit,
+		"ctrl+c": actionQuit,
+		"?":      actionHelp,
+		"h":      actionHelp,
+		" ":      actionToggle,
+		"up":     actionUp,
+		"k":      actionUp,
+		"G":      actionBottom,
+		"g":      actionTop,
+		"enter":  actionDetails,
+		"z":      "",
+	}
+	for key, want := range tests {
+		if got := km.action(key); got != want {
+			t.Errorf("action(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestNewKeyMapRebinds(t *testing.T) {
+	km, err := newKeyMap(map[string]string{"quit": "x", "toggle": "shift+t", "help": "F1"})
+	if err != nil {
+		t.Fatalf("newKeyMap() error = %v", err)
+	}
+
+	tests := map[string]keyAction{
+		"x":      actionQuit,
+		"q":      "",
+		"ctrl+c": actionQuit,
+		"T":      actionToggle,
+		" ":      "",
+		"f1":     actionHelp,
+	}
+	for key, want := range tests {
+		if got := km.action(key); got != want {
+			t.Errorf("action(%q) = %q, want %q", key, got, want)
+		}
+	}
+	if got := km.label(actionQuit); got != "ctrl+c/x" {
+		t.Errorf("label(quit) = %q, want ctrl+c/x", got)
+	}
+
+	// x was select's default key; select is left unbound rather than refused
+	if got := km.label(actionSelect); got != "unbound" {
+		t.Errorf("label(select) = %q, want unbound", got)
+	}
+}
+
+func TestNewKeyMapErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		bindings map[string]string
+		want     []string
+	}{
+		{
+			name:     "conflict between configured keys",
+			bindings: map[string]string{"save": "w", "refresh": "w"},
+			want:     []string{"w is bound to both refresh and save"},
+		},
+		{
+			name:     "fixed key",
+			bindings: map[string]string{"save": "ctrl+c"},
+			want:     []string{"ctrl+c is bound to both save and quit"},
+		},
+		{
+			name:     "unknown action",
+			bindings: map[string]string{"launch": "l", "quit": ""},
+			want:     []string{`unknown action "launch"`, "quit: empty key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newKeyMap(tt.bindings)
+			if err == nil {
+				t.Fatal("newKeyMap() expected error, got nil")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("newKeyMap() error = %v, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeKey(t *testing.T) {
+	tests := map[string]string{
+		"q":         "q",
+		"G":         "G",
+		"space":     " ",
+		"Space":     " ",
+		"shift+a":   "A",
+		"shift+tab": "shift+tab",
+		"ctrl+X":    "ctrl+x",
+		"alt+X":     "alt+X",
+		"F5":        "f5",
+		"Enter":     "enter",
+	}
+	for key, want := range tests {
+		if got := normalizeKey(key); got != want {
+			t.Errorf("normalizeKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestConfiguredKeysDriveMainView(t *testing.T) {
+	m := createTestModel()
+	keys, err := newKeyMap(map[string]string{"quit": "x", "select": "v", "help": "F1"})
+	if err != nil {
+		t.Fatalf("newKeyMap() error = %v", err)
+	}
+	m.keys = keys
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}}); cmd != nil {
+		t.Error("q should no longer quit once quit is rebound")
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}}); cmd == nil {
+		t.Fatal("Expected x to quit")
+	} else if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("Expected x to quit")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}})
+	if len(m.selected) != 1 {
+		t.Errorf("Expected v to select the entry, got %d selected", len(m.selected))
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyF1})
+	if m.currentView != viewHelp {
+		t.Fatal("Expected F1 to open help")
+	}
+	help := m.View()
+	for _, want := range []string{"ctrl+c/x", "Quit application", "v ", "Select/deselect", "Press f1 to return"} {
+		if !strings.Contains(help, want) {
+			t.Errorf("Help should reflect the active keymap, missing %q:\n%s", want, help)
+		}
+	}
+	if !strings.Contains(m.controlsView(), "[x]") {
+		t.Error("Controls should show the configured quit key")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyF1})
+	if m.currentView != viewMain {
+		t.Error("Expected F1 to close help")
+	}
+}
@@ -0,0 +1,160 @@
+package tui
+
+import "github.com/charmbracelet/lipgloss"
+
+// palette holds the colors the TUI styles are built from
+type palette struct {
+	title      lipgloss.TerminalColor
+	muted      lipgloss.TerminalColor
+	selectedFg lipgloss.TerminalColor
+	selectedBg lipgloss.TerminalColor
+	enabled    lipgloss.TerminalColor
+	category   lipgloss.TerminalColor
+	error      lipgloss.TerminalColor
+	success    lipgloss.TerminalColor
+	marked     lipgloss.TerminalColor
+	moveFg     lipgloss.TerminalColor
+	moveBg     lipgloss.TerminalColor
+	key        lipgloss.TerminalColor
+}
+
+var (
+	darkPalette = palette{
+		title:      lipgloss.Color("205"),
+		muted:      lipgloss.Color("241"),
+		selectedFg: lipgloss.Color("229"),
+		selectedBg: lipgloss.Color("57"),
+		enabled:    lipgloss.Color("76"),
+		category:   lipgloss.Color("39"),
+		error:      lipgloss.Color("196"),
+		success:    lipgloss.Color("76"),
+		marked:     lipgloss.Color("214"),
+		moveFg:     lipgloss.Color("208"),
+		moveBg:     lipgloss.Color("53"),
+		key:        lipgloss.Color("76"),
+	}
+
+	// lightPalette uses darker shades that stay readable on a light background
+	lightPalette = palette{
+		title:      lipgloss.Color("125"),
+		muted:      lipgloss.Color("238"),
+		selectedFg: lipgloss.Color("231"),
+		selectedBg: lipgloss.Color("25"),
+		enabled:    lipgloss.Color("28"),
+		category:   lipgloss.Color("25"),
+		error:      lipgloss.Color("160"),
+		success:    lipgloss.Color("28"),
+		marked:     lipgloss.Color("130"),
+		moveFg:     lipgloss.Color("231"),
+		moveBg:     lipgloss.Color("130"),
+		key:        lipgloss.Color("28"),
+	}
+
+	// noColorPalette keeps the layout and emphasis but emits no colors
+	noColorPalette = palette{
+		title:      lipgloss.NoColor{},
+		muted:      lipgloss.NoColor{},
+		selectedFg: lipgloss.NoColor{},
+		selectedBg: lipgloss.NoColor{},
+		enabled:    lipgloss.NoColor{},
+		category:   lipgloss.NoColor{},
+		error:      lipgloss.NoColor{},
+		success:    lipgloss.NoColor{},
+		marked:     lipgloss.NoColor{},
+		moveFg:     lipgloss.NoColor{},
+		moveBg:     lipgloss.NoColor{},
+		key:        lipgloss.NoColor{},
+	}
+)
+
+// paletteFor returns the palette for a ui.color_scheme value. auto asks the
+// terminal for its background color, which lipgloss reports as dark when it
+// cannot tell.
+func paletteFor(scheme string) palette {
+	switch scheme {
+	case "light":
+		return lightPalette
+	case "none":
+		return noColorPalette
+	case "auto":
+		if !lipgloss.HasDarkBackground() {
+			return lightPalette
+		}
+	}
+	return darkPalette
+}
+
+// styles are the rendering styles the TUI views use
+type styles struct {
+	title    lipgloss.Style
+	header   lipgloss.Style
+	selected lipgloss.Style
+	enabled  lipgloss.Style
+	disabled lipgloss.Style
+	category lipgloss.Style
+	help     lipgloss.Style
+	error    lipgloss.Style
+	success  lipgloss.Style
+	marked   lipgloss.Style
+	move     lipgloss.Style
+	key      lipgloss.Style
+	action   lipgloss.Style
+}
+
+func newStyles(p palette) styles {
+	return styles{
+		title: lipgloss.NewStyle().
+			Foreground(p.title).
+			Bold(true).
+			Margin(1, 0, 0, 2),
+
+		header: lipgloss.NewStyle().
+			Foreground(p.muted).
+			Bold(true).
+			Margin(0, 0, 1, 2),
+
+		selected: lipgloss.NewStyle().
+			Foreground(p.selectedFg).
+			Background(p.selectedBg).
+			Bold(true),
+
+		enabled: lipgloss.NewStyle().
+			Foreground(p.enabled),
+
+		disabled: lipgloss.NewStyle().
+			Foreground(p.muted),
+
+		category: lipgloss.NewStyle().
+			Foreground(p.category).
+			Bold(true).
+			Margin(1, 0, 0, 0),
+
+		help: lipgloss.NewStyle().
+			Foreground(p.muted).
+			Margin(1, 0, 0, 2),
+
+		error: lipgloss.NewStyle().
+			Foreground(p.error).
+			Bold(true),
+
+		success: lipgloss.NewStyle().
+			Foreground(p.success).
+			Bold(true),
+
+		marked: lipgloss.NewStyle().
+			Foreground(p.marked).
+			Bold(true),
+
+		move: lipgloss.NewStyle().
+			Foreground(p.moveFg).
+			Background(p.moveBg).
+			Bold(true),
+
+		key: lipgloss.NewStyle().
+			Foreground(p.key).
+			Bold(true),
+
+		action: lipgloss.NewStyle().
+			Foreground(p.muted),
+	}
+}
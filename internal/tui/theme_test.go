@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/brandonhon/hosts-manager/internal/config"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestPaletteFor(t *testing.T) {
+	tests := []struct {
+		scheme   string
+		expected palette
+	}{
+		{"dark", darkPalette},
+		{"light", lightPalette},
+		{"none", noColorPalette},
+		{"", darkPalette},
+	}
+
+	for _, tt := range tests {
+		if got := paletteFor(tt.scheme); got != tt.expected {
+			t.Errorf("paletteFor(%q) = %+v, want %+v", tt.scheme, got, tt.expected)
+		}
+	}
+
+	// Grey 241 is unreadable on light terminals
+	if lightPalette.muted == lipgloss.Color("241") {
+		t.Error("Expected the light palette to use a darker muted color")
+	}
+}
+
+func TestColorSchemeNoColor(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.UI.ColorScheme = "dark"
+	if got := colorScheme(cfg, Options{}); got != "dark" {
+		t.Errorf("colorScheme() = %q, want the configured scheme", got)
+	}
+	if got := colorScheme(cfg, Options{NoColor: true}); got != "none" {
+		t.Errorf("colorScheme() with NoColor = %q, want none", got)
+	}
+}
+
+func TestNewStylesUsesPalette(t *testing.T) {
+	s := newStyles(lightPalette)
+	if s.title.GetForeground() != lightPalette.title {
+		t.Errorf("Expected title foreground %v, got %v", lightPalette.title, s.title.GetForeground())
+	}
+	if s.selected.GetBackground() != lightPalette.selectedBg {
+		t.Errorf("Expected selected background %v, got %v", lightPalette.selectedBg, s.selected.GetBackground())
+	}
+	if s.help.GetForeground() != lightPalette.muted {
+		t.Errorf("Expected help foreground %v, got %v", lightPalette.muted, s.help.GetForeground())
+	}
+}
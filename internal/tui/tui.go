@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/brandonhon/hosts-manager/internal/config"
@@ -11,14 +12,47 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// Options controls optional TUI behavior that is set from the command line
+type Options struct {
+	// Force bypasses the write safety check when saving
+	Force bool
+	// Watch polls the hosts file and prompts to reload when it changes externally
+	Watch bool
+	// AutoSave writes the hosts file after every change instead of waiting for s
+	AutoSave bool
+	// Backup is called once before the first write of a session. Skipped when nil.
+	Backup func() error
+	// Reload re-reads the hosts file when reloading after an external change.
+	// Defaults to a plain parse when nil.
+	Reload func(path string) (*hosts.HostsFile, error)
+	// AfterSave is called after each successful write, e.g. to flush the DNS
+	// cache. Its error does not fail the save. Skipped when nil.
+	AfterSave func() error
+	// NoColor draws the TUI without colors, as ui.color_scheme "none" does,
+	// whatever the configured scheme
+	NoColor bool
+	// Copy copies text to the system clipboard. When nil or failing, the copy
+	// action shows the text in the message line instead.
+	Copy func(text string) error
+	// FlushDNS flushes the resolver cache, elevating first if the platform
+	// requires it, for the save-and-flush action. The action only saves when
+	// nil.
+	FlushDNS func() error
+}
+
 type model struct {
 	hostsFile    *hosts.HostsFile
 	config       *config.Config
+	styles       styles
+	keys         *keyMap
+	options      Options
 	currentView  view
 	cursor       int
-	selected     map[int]bool
+	offset       int          // Index of the first entry shown in the main list
+	selected     map[int]bool // Entries marked for bulk operations, by entryWithIndex.index
 	searchQuery  string
 	searchActive bool
+	statusFilter statusFilter
 	message      string
 	entries      []entryWithIndex
 	categories   []string
@@ -45,10 +79,51 @@ type model struct {
 	editComment    string // Comment being edited
 	editCategory   string // Category being edited
 	editField      int    // 0=IP, 1=hostnames, 2=comment, 3=category
+	// File watching
+	diskStamp      fileStamp       // On-disk version the in-memory copy is based on
+	knownHostnames map[string]bool // Hostnames present when the file was last loaded
+	saving         bool            // Set while our own save is in flight
+	dirty          bool            // Set when the in-memory copy has unsaved changes
+	// Saving
+	pendingSave bool // Set by a change that auto-save should write
+	autoSaving  bool // Set while an auto-save is in flight
+	backedUp    bool // Set once this session's backup has been taken
 }
 
 type view int
 
+// statusFilter limits the main list to entries in one state
+type statusFilter int
+
+const (
+	statusAll statusFilter = iota
+	statusEnabled
+	statusDisabled
+)
+
+func (f statusFilter) String() string {
+	switch f {
+	case statusEnabled:
+		return "enabled only"
+	case statusDisabled:
+		return "disabled only"
+	default:
+		return "all"
+	}
+}
+
+// matches reports whether an entry passes the filter
+func (f statusFilter) matches(entry hosts.Entry) bool {
+	switch f {
+	case statusEnabled:
+		return entry.Enabled
+	case statusDisabled:
+		return !entry.Enabled
+	default:
+		return true
+	}
+}
+
 const (
 	viewMain view = iota
 	viewSearch
@@ -57,6 +132,9 @@ const (
 	viewMove
 	viewCreateCategory
 	viewEdit
+	viewReload
+	viewQuitConfirm
+	viewDetail
 )
 
 type entryWithIndex struct {
@@ -66,76 +144,24 @@ type entryWithIndex struct {
 	catIndex int
 }
 
-var (
-	titleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("205")).
-			Bold(true).
-			Margin(1, 0, 0, 2)
-
-	headerStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			Bold(true).
-			Margin(0, 0, 1, 2)
-
-	selectedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("229")).
-			Background(lipgloss.Color("57")).
-			Bold(true)
-
-	enabledStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("76"))
-
-	disabledStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241"))
-
-	categoryStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("39")).
-			Bold(true).
-			Margin(1, 0, 0, 0)
-
-	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			Margin(1, 0, 0, 2)
-
-	errorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")).
-			Bold(true)
-
-	successStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("76")).
-			Bold(true)
-
-	moveStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("208")).
-			Background(lipgloss.Color("53")).
-			Bold(true)
-
-	keyStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("76")).
-			Bold(true)
-
-	actionStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241"))
-)
-
 // controlsView returns a nicely formatted help section for key bindings
-func controlsView() string {
+func (m *model) controlsView() string {
 	// Define key-action pairs with fixed widths for alignment
 	controls := []struct {
-		key    string
+		key    keyAction
 		action string
 		width  int
 	}{
-		{"Space", "Toggle", 19},
-		{"a", "Add", 12},
-		{"e", "Edit", 13},
-		{"c", "Create Category", 0},
-		{"m", "Move", 19},
-		{"d", "Delete", 12},
-		{"s", "Save", 13},
-		{"/", "Search", 0},
-		{"?", "Help", 19},
-		{"q", "Quit", 0},
+		{actionToggle, "Toggle", 19},
+		{actionAdd, "Add", 12},
+		{actionEdit, "Edit", 13},
+		{actionCreateCategory, "Create Category", 0},
+		{actionMove, "Move", 19},
+		{actionDelete, "Delete", 12},
+		{actionSave, "Save", 13},
+		{actionSearch, "Search", 0},
+		{actionHelp, "Help", 19},
+		{actionQuit, "Quit", 0},
 	}
 
 	// Create formatted control strings with fixed widths for alignment
@@ -147,9 +173,9 @@ func controlsView() string {
 	for i, ctrl := range controls {
 		formatted := lipgloss.JoinHorizontal(
 			lipgloss.Left,
-			keyStyle.Render("["+ctrl.key+"]"),
+			m.styles.key.Render("["+m.keyMap().primary(ctrl.key)+"]"),
 			" ",
-			actionStyle.Render(ctrl.action),
+			m.styles.action.Render(ctrl.action),
 		)
 
 		// Add padding to align columns
@@ -185,10 +211,27 @@ func controlsView() string {
 	return lipgloss.JoinVertical(lipgloss.Left, header, row1, row2, row3)
 }
 
-func Run(hostsFile *hosts.HostsFile, cfg *config.Config) error {
+// colorScheme returns the configured ui.color_scheme, or "none" when
+// opts.NoColor is set
+func colorScheme(cfg *config.Config, opts Options) string {
+	if opts.NoColor {
+		return "none"
+	}
+	return cfg.UI.ColorScheme
+}
+
+func Run(hostsFile *hosts.HostsFile, cfg *config.Config, opts Options) error {
+	keys, err := newKeyMap(cfg.UI.KeyBindings)
+	if err != nil {
+		return err
+	}
+
 	m := model{
 		hostsFile:   hostsFile,
 		config:      cfg,
+		options:     opts,
+		styles:      newStyles(paletteFor(colorScheme(cfg, opts))),
+		keys:        keys,
 		currentView: viewMain,
 		selected:    make(map[int]bool),
 		entries:     buildEntryList(hostsFile),
@@ -198,9 +241,10 @@ func Run(hostsFile *hosts.HostsFile, cfg *config.Config) error {
 	for i, cat := range hostsFile.Categories {
 		m.categories[i] = cat.Name
 	}
+	m.markLoaded()
 
-	p := tea.NewProgram(&m, tea.WithAltScreen())
-	_, err := p.Run()
+	p := tea.NewProgram(&m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	_, err = p.Run()
 	return err
 }
 
@@ -223,17 +267,53 @@ func buildEntryList(hostsFile *hosts.HostsFile) []entryWithIndex {
 	return entries
 }
 
+// keyMap returns the main view keymap, falling back to the defaults
+func (m *model) keyMap() *keyMap {
+	if m.keys == nil {
+		m.keys = defaultKeyMap()
+	}
+	return m.keys
+}
+
 func (m *model) Init() tea.Cmd {
+	if m.options.Watch {
+		return watchTick()
+	}
 	return nil
 }
 
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	result, cmd := m.update(msg)
+	// Keep the main list's viewport on the cursor however it moved
+	m.scrollToCursor()
+
+	if m.pendingSave && !m.saving {
+		m.pendingSave = false
+		m.saving = true
+		m.autoSaving = true
+		cmd = tea.Batch(cmd, m.saveFile())
+	}
+	return result, cmd
+}
+
+// markDirty records an unsaved change and, with auto-save on, queues a save
+func (m *model) markDirty() {
+	m.dirty = true
+	if m.options.AutoSave {
+		m.pendingSave = true
+	}
+}
+
+func (m *model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		return m, nil
 
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
+
 	case tea.KeyMsg:
 		switch m.currentView {
 		case viewMain:
@@ -250,14 +330,43 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateCreateCategory(msg)
 		case viewEdit:
 			return m.updateEdit(msg)
+		case viewReload:
+			return m.updateReload(msg)
+		case viewQuitConfirm:
+			return m.updateQuitConfirm(msg)
+		case viewDetail:
+			return m.updateDetail(msg)
 		}
 
+	case watchTickMsg:
+		return m, m.handleWatchTick()
+
+	case externalChangeMsg:
+		m.saving = false
+		m.autoSaving = false
+		m.currentView = viewReload
+		return m, nil
+
 	case errorMsg:
+		m.saving = false
+		m.autoSaving = false
 		m.message = fmt.Sprintf("Error: %v", msg.err)
 		return m, nil
 
 	case successMsg:
-		m.message = "File saved successfully!"
+		m.saving = false
+		m.dirty = false
+		m.markLoaded()
+		if msg.flushErr != nil {
+			m.message = fmt.Sprintf("Failed to flush DNS cache: %v (file saved)", msg.flushErr)
+		} else if msg.flushed {
+			m.message = "File saved and DNS cache flushed!"
+		} else if m.autoSaving && m.message != "" {
+			m.message += " (saved)"
+		} else {
+			m.message = "File saved successfully!"
+		}
+		m.autoSaving = false
 		return m, nil
 	}
 
@@ -265,29 +374,43 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *model) updateMain(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "q", "ctrl+c":
+	switch m.keyMap().action(msg.String()) {
+	case actionQuit:
+		if m.dirty {
+			m.currentView = viewQuitConfirm
+			return m, nil
+		}
 		return m, tea.Quit
 
-	case "up", "k":
+	case actionUp:
 		if m.cursor > 0 {
 			m.cursor--
 		}
 
-	case "down", "j":
+	case actionDown:
 		if m.cursor < len(m.entries)-1 {
 			m.cursor++
 		}
 
-	case "g":
+	case actionTop:
 		m.cursor = 0
 
-	case "G":
+	case actionBottom:
 		m.cursor = len(m.entries) - 1
 
-	case " ":
+	case actionNextPage:
+		m.cursor = min(m.cursor+m.visibleRows(m.offset), len(m.entries)-1)
+		m.cursor = max(m.cursor, 0)
+
+	case actionPrevPage:
+		m.cursor = max(m.cursor-m.visibleRows(m.offset), 0)
+
+	case actionToggle:
 		if m.cursor < len(m.entries) {
 			entry := &m.entries[m.cursor]
+			if m.isProtected(*entry) {
+				return m, nil
+			}
 			entry.entry.Enabled = !entry.entry.Enabled
 
 			// Update the corresponding entry in the hosts file
@@ -309,38 +432,82 @@ func (m *model) updateMain(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if entry.entry.Enabled {
 				status = "enabled"
 			}
+			m.markDirty()
 			m.message = fmt.Sprintf("Entry %s", status)
 		}
 
-	case "d":
+	case actionDelete:
 		if m.cursor < len(m.entries) {
 			entry := m.entries[m.cursor]
+			if m.isProtected(entry) {
+				return m, nil
+			}
 			hostname := entry.entry.Hostnames[0]
 
 			if m.hostsFile.RemoveEntry(hostname) {
-				m.entries = buildEntryList(m.hostsFile)
+				m.rebuildEntries()
 				if m.cursor >= len(m.entries) && len(m.entries) > 0 {
 					m.cursor = len(m.entries) - 1
 				}
+				m.markDirty()
 				m.message = fmt.Sprintf("Deleted entry: %s", hostname)
 			} else {
 				m.message = fmt.Sprintf("Failed to delete entry: %s", hostname)
 			}
 		}
 
-	case "/":
+	case actionCopy:
+		if m.cursor < len(m.entries) {
+			m.copyEntry(m.entries[m.cursor].entry)
+		}
+
+	case actionSelect:
+		if m.cursor < len(m.entries) {
+			index := m.entries[m.cursor].index
+			if m.selected[index] {
+				delete(m.selected, index)
+			} else {
+				m.selected[index] = true
+			}
+			m.message = fmt.Sprintf("%d selected", len(m.selected))
+		}
+
+	case actionEnableSelected:
+		m.bulkSetEnabled(true)
+
+	case actionDisableSelected:
+		m.bulkSetEnabled(false)
+
+	case actionDeleteSelected:
+		m.bulkDelete()
+
+	case actionSearch:
 		m.currentView = viewSearch
 		m.searchActive = true
 		m.searchQuery = ""
 
-	case "r":
-		m.entries = buildEntryList(m.hostsFile)
+	case actionFilter:
+		m.statusFilter = (m.statusFilter + 1) % 3
+		if m.searchQuery != "" {
+			m.filterEntries()
+		} else {
+			m.entries = m.visibleEntries()
+		}
+		m.message = fmt.Sprintf("Showing %s (%d entries)", m.statusFilter, len(m.entries))
+
+	case actionRefresh:
+		m.entries = m.visibleEntries()
 		m.message = "Refreshed"
 
-	case "s":
+	case actionSave:
+		m.saving = true
 		return m, m.saveFile()
 
-	case "a":
+	case actionSaveFlush:
+		m.saving = true
+		return m, m.saveAndFlushFile()
+
+	case actionAdd:
 		m.currentView = viewAdd
 		m.addIP = ""
 		m.addHostnames = ""
@@ -348,14 +515,17 @@ func (m *model) updateMain(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.addCategory = m.config.General.DefaultCategory
 		m.addField = 0
 
-	case "c":
+	case actionCreateCategory:
 		m.currentView = viewCreateCategory
 		m.createCategoryName = ""
 		m.createCategoryDescription = ""
 		m.createCategoryField = 0
 
-	case "e":
+	case actionEdit:
 		if m.cursor < len(m.entries) {
+			if m.isProtected(m.entries[m.cursor]) {
+				return m, nil
+			}
 			m.currentView = viewEdit
 			m.editEntryIndex = m.cursor
 			entry := m.entries[m.cursor].entry
@@ -368,8 +538,11 @@ func (m *model) updateMain(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.message = "No entry selected to edit"
 		}
 
-	case "m":
+	case actionMove:
 		if m.cursor < len(m.entries) {
+			if m.isProtected(m.entries[m.cursor]) {
+				return m, nil
+			}
 			m.currentView = viewMove
 			m.moveEntryIndex = m.cursor
 			m.moveCategoryCursor = 0
@@ -386,26 +559,40 @@ func (m *model) updateMain(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.message = "No entry selected to move"
 		}
 
-	case "?", "h":
+	case actionHelp:
 		m.currentView = viewHelp
 
-	case "enter":
+	case actionDetails:
 		if m.cursor < len(m.entries) {
-			entry := m.entries[m.cursor]
-			m.message = fmt.Sprintf("Selected: %s -> %v", entry.entry.IP, entry.entry.Hostnames)
+			m.currentView = viewDetail
 		}
 	}
 
 	return m, nil
 }
 
+// copyEntry copies entry's hosts file line to the clipboard, showing the
+// line in the message when the clipboard cannot be used
+func (m *model) copyEntry(entry hosts.Entry) {
+	line := hosts.FormatEntry(entry)
+	err := fmt.Errorf("no clipboard configured")
+	if m.options.Copy != nil {
+		err = m.options.Copy(line)
+	}
+	if err != nil {
+		m.message = fmt.Sprintf("Clipboard unavailable (%v); copy manually: %s", err, line)
+		return
+	}
+	m.message = "Copied: " + line
+}
+
 func (m *model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
 		m.currentView = viewMain
 		m.searchActive = false
 		m.searchQuery = ""
-		m.entries = buildEntryList(m.hostsFile)
+		m.entries = m.visibleEntries()
 
 	case "enter":
 		m.currentView = viewMain
@@ -427,8 +614,12 @@ func (m *model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m *model) updateHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "q", "esc", "?", "h":
+	if msg.String() == "esc" {
+		m.currentView = viewMain
+		return m, nil
+	}
+	switch m.keyMap().action(msg.String()) {
+	case actionHelp, actionQuit:
 		m.currentView = viewMain
 	}
 
@@ -463,7 +654,8 @@ func (m *model) updateAdd(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.message = fmt.Sprintf("Error adding entry: %v", err)
 				return m, nil
 			}
-			m.entries = buildEntryList(m.hostsFile)
+			m.rebuildEntries()
+			m.markDirty()
 			m.message = fmt.Sprintf("Added entry: %s -> %v", entry.IP, entry.Hostnames)
 			m.currentView = viewMain
 		} else {
@@ -532,11 +724,12 @@ func (m *model) updateMove(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.message = fmt.Sprintf("Error moving entry: %v", err)
 			} else {
 				entryToMove := m.entries[m.moveEntryIndex]
+				m.markDirty()
 				m.message = fmt.Sprintf("Moved %s from %s to %s",
 					entryToMove.entry.Hostnames[0],
 					entryToMove.category,
 					m.moveTargetCategory)
-				m.entries = buildEntryList(m.hostsFile)
+				m.rebuildEntries()
 				// Try to keep cursor on the same entry after move
 				m.cursor = m.findEntryAfterMove(entryToMove, m.moveTargetCategory)
 			}
@@ -582,7 +775,8 @@ func (m *model) updateCreateCategory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 			// Update categories list and entries
 			m.categories = append(m.categories, m.createCategoryName)
-			m.entries = buildEntryList(m.hostsFile)
+			m.rebuildEntries()
+			m.markDirty()
 			m.message = fmt.Sprintf("Created category: %s", m.createCategoryName)
 			m.currentView = viewMain
 		} else {
@@ -681,7 +875,8 @@ func (m *model) updateEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 		// Refresh entries and go back to main view
-		m.entries = buildEntryList(m.hostsFile)
+		m.rebuildEntries()
+		m.markDirty()
 		m.message = "Entry updated successfully"
 		m.currentView = viewMain
 
@@ -723,6 +918,153 @@ func (m *model) updateEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// rebuildEntries reloads the entry list after the hosts file changed. Entry
+// indexes shift, so the selection is cleared.
+func (m *model) rebuildEntries() {
+	m.entries = m.visibleEntries()
+	clear(m.selected)
+}
+
+// visibleEntries returns the entries that pass the status filter
+func (m *model) visibleEntries() []entryWithIndex {
+	entries := buildEntryList(m.hostsFile)
+	if m.statusFilter == statusAll {
+		return entries
+	}
+
+	var filtered []entryWithIndex
+	for _, entry := range entries {
+		if m.statusFilter.matches(entry.entry) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// selectedEntries returns the selected entries, collected up front so bulk
+// operations are unaffected by index drift as they mutate the hosts file
+func (m *model) selectedEntries() []entryWithIndex {
+	var targets []entryWithIndex
+	for _, entry := range buildEntryList(m.hostsFile) {
+		if m.selected[entry.index] {
+			targets = append(targets, entry)
+		}
+	}
+	return targets
+}
+
+// matchesEntry reports whether a hosts file entry is the one target was built from
+func matchesEntry(category string, entry hosts.Entry, target entryWithIndex) bool {
+	return category == target.category &&
+		entry.IP == target.entry.IP &&
+		slices.Equal(entry.Hostnames, target.entry.Hostnames)
+}
+
+// bulkSetEnabled enables or disables every selected entry, skipping protected ones
+func (m *model) bulkSetEnabled(enabled bool) {
+	targets := m.selectedEntries()
+	if len(targets) == 0 {
+		m.message = "No entries selected"
+		return
+	}
+
+	changed, skipped := 0, 0
+	for _, target := range targets {
+		if target.entry.ReadOnly && !m.options.Force {
+			skipped++
+			continue
+		}
+		category := m.hostsFile.GetCategory(target.category)
+		if category == nil {
+			continue
+		}
+		for i := range category.Entries {
+			if matchesEntry(category.Name, category.Entries[i], target) {
+				category.Entries[i].Enabled = enabled
+				changed++
+				break
+			}
+		}
+	}
+
+	action := "Disabled"
+	if enabled {
+		action = "Enabled"
+	}
+	m.finishBulk(changed, skipped, action)
+}
+
+// bulkDelete removes every selected entry in a single pass over the hosts
+// file, skipping protected ones
+func (m *model) bulkDelete() {
+	targets := m.selectedEntries()
+	if len(targets) == 0 {
+		m.message = "No entries selected"
+		return
+	}
+
+	skipped := 0
+	var remove []entryWithIndex
+	for _, target := range targets {
+		if target.entry.ReadOnly && !m.options.Force {
+			skipped++
+			continue
+		}
+		remove = append(remove, target)
+	}
+
+	deleted := 0
+	for i := range m.hostsFile.Categories {
+		category := &m.hostsFile.Categories[i]
+		kept := category.Entries[:0]
+		for _, entry := range category.Entries {
+			removed := false
+			for j, target := range remove {
+				if matchesEntry(category.Name, entry, target) {
+					// Each target removes one entry, even if it has an identical twin
+					remove = slices.Delete(remove, j, j+1)
+					removed = true
+					break
+				}
+			}
+			if removed {
+				deleted++
+				continue
+			}
+			kept = append(kept, entry)
+		}
+		category.Entries = kept
+	}
+
+	m.finishBulk(deleted, skipped, "Deleted")
+}
+
+// finishBulk rebuilds the entry list after a bulk operation and reports the outcome
+func (m *model) finishBulk(count, skipped int, action string) {
+	m.rebuildEntries()
+	if m.cursor >= len(m.entries) {
+		m.cursor = max(len(m.entries)-1, 0)
+	}
+	if count > 0 {
+		m.markDirty()
+	}
+
+	m.message = fmt.Sprintf("%s %d entries", action, count)
+	if skipped > 0 {
+		m.message += fmt.Sprintf(" (%d protected by %s skipped)", skipped, hosts.IgnoreFileName)
+	}
+}
+
+// isProtected reports whether entry is read-only via the ignore file and sets an
+// error message if so. Protected entries can still be changed when forced.
+func (m *model) isProtected(entry entryWithIndex) bool {
+	if !entry.entry.ReadOnly || m.options.Force {
+		return false
+	}
+	m.message = fmt.Sprintf("Error: %s is protected by %s", strings.Join(entry.entry.Hostnames, " "), hosts.IgnoreFileName)
+	return true
+}
+
 // validateCategoryName validates a category name using the same rules as the config validator
 func (m *model) validateCategoryName(name string) error {
 	if name == "" {
@@ -810,55 +1152,31 @@ func (m *model) moveEntry(entryIndex int, targetCategory string) error {
 		return fmt.Errorf("source category not found: %s", sourceCategory)
 	}
 
-	// Find the target category in hostsFile
-	var targetCat *hosts.Category
-	for i := range m.hostsFile.Categories {
-		if m.hostsFile.Categories[i].Name == targetCategory {
-			targetCat = &m.hostsFile.Categories[i]
-			break
-		}
-	}
-	if targetCat == nil {
-		return fmt.Errorf("target category not found: %s", targetCategory)
-	}
-
-	// Find and remove the entry from source category
-	var entryToMoveData hosts.Entry
-	entryFound := false
-	for i, entry := range sourceCat.Entries {
+	// Find the entry in the source category and move it with the same
+	// semantics as the CLI move command
+	for i := range sourceCat.Entries {
+		entry := &sourceCat.Entries[i]
 		if entry.IP == entryToMove.entry.IP &&
 			len(entry.Hostnames) > 0 &&
 			len(entryToMove.entry.Hostnames) > 0 &&
 			entry.Hostnames[0] == entryToMove.entry.Hostnames[0] {
-			entryToMoveData = entry
-			entryToMoveData.Category = targetCategory
-			// Remove from source category
-			sourceCat.Entries = append(sourceCat.Entries[:i], sourceCat.Entries[i+1:]...)
-			entryFound = true
-			break
+			return m.hostsFile.MoveEntry(entry, targetCategory)
 		}
 	}
 
-	if !entryFound {
-		return fmt.Errorf("entry not found in source category")
-	}
-
-	// Add to target category
-	targetCat.Entries = append(targetCat.Entries, entryToMoveData)
-
-	return nil
+	return fmt.Errorf("entry not found in source category")
 }
 
 func (m *model) filterEntries() {
 	if m.searchQuery == "" {
-		m.entries = buildEntryList(m.hostsFile)
+		m.entries = m.visibleEntries()
 		return
 	}
 
 	var filtered []entryWithIndex
 	query := strings.ToLower(m.searchQuery)
 
-	for _, entry := range m.entries {
+	for _, entry := range m.visibleEntries() {
 		match := false
 
 		for _, hostname := range entry.entry.Hostnames {
@@ -892,15 +1210,66 @@ func (m *model) filterEntries() {
 
 func (m *model) saveFile() tea.Cmd {
 	return func() tea.Msg {
-		if err := m.hostsFile.Write(m.hostsFile.FilePath); err != nil {
-			return errorMsg{err}
+		return m.writeFile(true)
+	}
+}
+
+// saveAndFlushFile saves like saveFile and then flushes the DNS cache, so
+// the changes take effect immediately. AfterSave is skipped to avoid
+// flushing twice.
+func (m *model) saveAndFlushFile() tea.Cmd {
+	return func() tea.Msg {
+		msg := m.writeFile(m.options.FlushDNS == nil)
+		if _, ok := msg.(successMsg); !ok || m.options.FlushDNS == nil {
+			return msg
 		}
-		return successMsg{}
+		return successMsg{flushed: true, flushErr: m.options.FlushDNS()}
 	}
 }
 
+// writeFile writes the hosts file, running AfterSave when afterSave is set,
+// and returns the message reporting the result
+func (m *model) writeFile(afterSave bool) tea.Msg {
+	if m.externallyModified() {
+		return externalChangeMsg{}
+	}
+	if !m.options.Force {
+		if err := m.hostsFile.CheckWriteSafety(m.hostsFile.FilePath, m.config.General.WriteGuardThreshold); err != nil {
+			return errorMsg{fmt.Errorf("%w; restart with --force to save anyway", err)}
+		}
+	}
+	bannerStyle, err := hosts.ParseBannerStyle(m.config.General.BannerStyle)
+	if err != nil {
+		return errorMsg{err}
+	}
+	if m.options.Backup != nil && !m.backedUp {
+		if err := m.options.Backup(); err != nil {
+			return errorMsg{fmt.Errorf("failed to create backup: %w", err)}
+		}
+		m.backedUp = true
+	}
+	m.hostsFile.SetBannerStyle(bannerStyle)
+	m.hostsFile.SetNormalizeIPs(m.config.General.NormalizeIPs)
+	if err := m.hostsFile.Write(m.hostsFile.FilePath); err != nil {
+		return errorMsg{err}
+	}
+	if afterSave && m.options.AfterSave != nil {
+		_ = m.options.AfterSave()
+	}
+	return successMsg{}
+}
+
 type errorMsg struct{ err error }
-type successMsg struct{}
+
+// successMsg reports a completed save. flushed is set when the save also
+// flushed the DNS cache, with flushErr holding any flush failure.
+type successMsg struct {
+	flushed  bool
+	flushErr error
+}
+
+// externalChangeMsg reports that a save was withheld because the file changed on disk
+type externalChangeMsg struct{}
 
 func (m *model) View() string {
 	switch m.currentView {
@@ -918,6 +1287,12 @@ func (m *model) View() string {
 		return m.viewCreateCategory()
 	case viewEdit:
 		return m.viewEdit()
+	case viewReload:
+		return m.viewReload()
+	case viewQuitConfirm:
+		return m.viewQuitConfirm()
+	case viewDetail:
+		return m.viewDetail()
 	}
 
 	return ""
@@ -926,21 +1301,35 @@ func (m *model) View() string {
 func (m *model) viewMain() string {
 	var b strings.Builder
 
-	b.WriteString(titleStyle.Render("Hosts Manager"))
-	b.WriteString("\n")
+	b.WriteString(m.mainHeaderView())
+	list, _ := m.mainListView()
+	b.WriteString(list)
 
-	if m.searchQuery != "" {
-		b.WriteString(headerStyle.Render(fmt.Sprintf("Search: %s (%d results)", m.searchQuery, len(m.entries))))
-	} else {
-		b.WriteString(headerStyle.Render(fmt.Sprintf("Total entries: %d", len(m.entries))))
+	if len(m.entries) > 0 {
+		b.WriteString(m.styles.action.Render(fmt.Sprintf("\n  row %d of %d", m.cursor+1, len(m.entries))))
+		b.WriteString("\n")
 	}
 
+	b.WriteString(m.mainFooterView())
+
+	return b.String()
+}
+
+// mainListView renders the entries in the main list's viewport. It also
+// returns the line of each rendered entry, counted from the last line of the
+// header, so mouse clicks can be mapped back to entries.
+func (m *model) mainListView() (string, []int) {
+	var b strings.Builder
+	var lines []int
+
+	end := m.offset + m.visibleRows(m.offset)
 	currentCategory := ""
-	for i, entry := range m.entries {
+	for i := m.offset; i < end; i++ {
+		entry := m.entries[i]
+		// Repeat the category heading at the top of the window for context
 		if entry.category != currentCategory {
 			currentCategory = entry.category
-			b.WriteString(categoryStyle.Render(fmt.Sprintf("\n=== %s ===", strings.ToUpper(currentCategory))))
-			b.WriteString("\n")
+			b.WriteString(m.categoryHeaderView(currentCategory))
 		}
 
 		cursor := "  "
@@ -949,10 +1338,14 @@ func (m *model) viewMain() string {
 		}
 
 		status := "✗"
-		style := disabledStyle
+		style := m.styles.disabled
 		if entry.entry.Enabled {
 			status = "✓"
-			style = enabledStyle
+			style = m.styles.enabled
+		}
+		if m.selected[entry.index] {
+			cursor = strings.TrimSuffix(cursor, " ") + "*"
+			style = m.styles.marked
 		}
 
 		line := fmt.Sprintf("%s%s %s -> %s",
@@ -966,27 +1359,262 @@ func (m *model) viewMain() string {
 		}
 
 		if m.cursor == i {
-			line = selectedStyle.Render(line)
+			line = m.styles.selected.Render(line)
 		} else {
 			line = style.Render(line)
 		}
 
+		lines = append(lines, strings.Count(b.String(), "\n"))
 		b.WriteString(line)
 		b.WriteString("\n")
 	}
 
+	return b.String(), lines
+}
+
+// entryAtLine returns the index of the entry shown on screen line y of the
+// main view, or false if that line shows no entry
+func (m *model) entryAtLine(y int) (int, bool) {
+	_, lines := m.mainListView()
+	y -= strings.Count(m.mainHeaderView(), "\n")
+	for i, line := range lines {
+		if line == y {
+			return m.offset + i, true
+		}
+	}
+	return 0, false
+}
+
+// updateMouse moves the cursor to a clicked entry and scrolls the main list
+// with the wheel, keeping the cursor inside the viewport
+func (m *model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.currentView != viewMain || msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonLeft:
+		if index, ok := m.entryAtLine(msg.Y); ok {
+			m.cursor = index
+		}
+
+	case tea.MouseButtonWheelDown:
+		if m.offset+m.visibleRows(m.offset) < len(m.entries) {
+			m.offset++
+		}
+		m.cursor = max(m.cursor, m.offset)
+
+	case tea.MouseButtonWheelUp:
+		if m.offset > 0 {
+			m.offset--
+		}
+		if last := m.offset + m.visibleRows(m.offset) - 1; m.cursor > last {
+			m.cursor = max(last, 0)
+		}
+	}
+
+	return m, nil
+}
+
+// mainHeaderView renders the title and summary above the main list
+func (m *model) mainHeaderView() string {
+	var b strings.Builder
+
+	title := "Hosts Manager"
+	if m.dirty {
+		title += " *"
+	}
+	b.WriteString(m.styles.title.Render(title))
+	b.WriteString("\n")
+
+	header := fmt.Sprintf("Total entries: %d", len(m.entries))
+	if m.searchQuery != "" {
+		header = fmt.Sprintf("Search: %s (%d results)", m.searchQuery, len(m.entries))
+	}
+	if m.statusFilter != statusAll {
+		header += fmt.Sprintf(" [%s]", m.statusFilter)
+	}
+	b.WriteString(m.styles.header.Render(header))
+
+	return b.String()
+}
+
+// mainFooterView renders the status message and controls below the main list
+func (m *model) mainFooterView() string {
+	var b strings.Builder
+
 	if m.message != "" {
 		b.WriteString("\n")
 		if strings.HasPrefix(m.message, "Error") || strings.HasPrefix(m.message, "Failed") {
-			b.WriteString(errorStyle.Render(m.message))
+			b.WriteString(m.styles.error.Render(m.message))
 		} else {
-			b.WriteString(successStyle.Render(m.message))
+			b.WriteString(m.styles.success.Render(m.message))
 		}
 		b.WriteString("\n")
 	}
 
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render(controlsView()))
+	b.WriteString(m.styles.help.Render(m.controlsView()))
+
+	return b.String()
+}
+
+func (m *model) categoryHeaderView(name string) string {
+	return m.styles.category.Render(fmt.Sprintf("\n=== %s ===", strings.ToUpper(name))) + "\n"
+}
+
+// visibleRows returns how many entries starting at offset fit in the main
+// list: at most the configured page size, and, once the terminal size is
+// known, no more than fit between the header and footer. At least one entry
+// is shown whenever any remain.
+func (m *model) visibleRows(offset int) int {
+	return m.rowsWithin(offset, m.listBudget())
+}
+
+// listBudget returns the lines available to the main list once the terminal
+// size is known, or -1 if it is not
+func (m *model) listBudget() int {
+	if m.height <= 0 {
+		return -1
+	}
+	// Lines left after the header, footer, and row indicator
+	return m.height - lipgloss.Height(m.mainHeaderView()) - lipgloss.Height(m.mainFooterView()) - 2
+}
+
+// rowsWithin returns how many entries starting at offset fit in budget lines
+// (any number if budget is negative), capped by the configured page size
+func (m *model) rowsWithin(offset, budget int) int {
+	remaining := len(m.entries) - offset
+	if remaining <= 0 {
+		return 0
+	}
+
+	limit := remaining
+	if m.config != nil && m.config.UI.PageSize > 0 {
+		limit = min(limit, m.config.UI.PageSize)
+	}
+	if budget < 0 {
+		return limit
+	}
+
+	rows := 0
+	currentCategory := ""
+	for i := offset; i < offset+limit; i++ {
+		lines := 1
+		if m.entries[i].category != currentCategory {
+			currentCategory = m.entries[i].category
+			lines += lipgloss.Height(m.categoryHeaderView(currentCategory)) - 1
+		}
+		if lines > budget && rows > 0 {
+			break
+		}
+		budget -= lines
+		rows++
+	}
+	return rows
+}
+
+// scrollToCursor moves the main list's viewport the least distance needed to
+// show the cursor
+func (m *model) scrollToCursor() {
+	if m.cursor >= len(m.entries) {
+		m.cursor = max(len(m.entries)-1, 0)
+	}
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	budget := m.listBudget()
+	for m.offset < m.cursor && m.cursor >= m.offset+m.rowsWithin(m.offset, budget) {
+		m.offset++
+	}
+	if m.offset > len(m.entries) {
+		m.offset = 0
+	}
+}
+
+func (m *model) updateQuitConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "ctrl+c":
+		return m, tea.Quit
+
+	case "n", "N", "esc":
+		m.currentView = viewMain
+	}
+
+	return m, nil
+}
+
+func (m *model) viewQuitConfirm() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.title.Render("Unsaved Changes"))
+	b.WriteString("\n\n")
+	b.WriteString("  You have unsaved changes — quit anyway? (y/n)\n\n")
+	b.WriteString("  " + m.styles.key.Render("[y]") + " " + m.styles.action.Render("Quit and discard changes") + "\n")
+	b.WriteString("  " + m.styles.key.Render("[n]") + " " + m.styles.action.Render("Go back (press s to save)") + "\n")
+
+	return b.String()
+}
+
+func (m *model) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "enter", "q":
+		m.currentView = viewMain
+	}
+
+	return m, nil
+}
+
+// viewDetail shows every field of the cursor entry, including hostnames the
+// main list truncates
+func (m *model) viewDetail() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.title.Render("Entry Details"))
+	b.WriteString("\n\n")
+
+	if m.cursor >= len(m.entries) {
+		b.WriteString("  No entry selected\n")
+		return b.String()
+	}
+	entry := m.entries[m.cursor].entry
+
+	field := func(name, value string) {
+		b.WriteString(fmt.Sprintf("  %-11s %s\n", m.styles.key.Render(name+":"), value))
+	}
+
+	field("IP", entry.IP)
+	if hosts.IsPrivateIP(entry.IP) {
+		field("Scope", "private")
+	} else {
+		field("Scope", "public")
+	}
+	field("Hostnames", "")
+	for _, hostname := range entry.Hostnames {
+		b.WriteString("    " + hostname + "\n")
+	}
+	comment := entry.Comment
+	if comment == "" {
+		comment = m.styles.help.Render("(none)")
+	}
+	field("Comment", comment)
+	field("Category", m.styles.category.Render(m.entries[m.cursor].category))
+	if entry.Enabled {
+		field("Status", m.styles.enabled.Render("✓ enabled"))
+	} else {
+		field("Status", m.styles.disabled.Render("✗ disabled"))
+	}
+	if entry.LineNum > 0 {
+		field("Line", fmt.Sprintf("%d", entry.LineNum))
+	} else {
+		field("Line", m.styles.help.Render("(not yet saved)"))
+	}
+	if entry.ReadOnly {
+		field("Protected", "yes, by "+hosts.IgnoreFileName)
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.styles.help.Render("Press Esc to return"))
 
 	return b.String()
 }
@@ -994,7 +1622,7 @@ func (m *model) viewMain() string {
 func (m *model) viewSearch() string {
 	var b strings.Builder
 
-	b.WriteString(titleStyle.Render("Search Mode"))
+	b.WriteString(m.styles.title.Render("Search Mode"))
 	b.WriteString("\n")
 
 	b.WriteString("Enter search query: ")
@@ -1002,7 +1630,7 @@ func (m *model) viewSearch() string {
 	b.WriteString("_")
 	b.WriteString("\n\n")
 
-	b.WriteString(helpStyle.Render("Press Enter to search, Esc to cancel"))
+	b.WriteString(m.styles.help.Render("Press Enter to search, Esc to cancel"))
 
 	return b.String()
 }
@@ -1010,41 +1638,37 @@ func (m *model) viewSearch() string {
 func (m *model) viewHelp() string {
 	var b strings.Builder
 
-	b.WriteString(titleStyle.Render("Help"))
+	b.WriteString(m.styles.title.Render("Help"))
 	b.WriteString("\n")
 
-	helpText := `
-Navigation:
-  ↑/k       Move cursor up
-  ↓/j       Move cursor down
-  g         Go to top
-  G         Go to bottom
-
-Actions:
-  space     Toggle entry enabled/disabled
-  a         Add new entry
-  c         Create new category
-  e         Edit selected entry
-  m         Move entry to different category
-  d         Delete entry
-  s         Save changes to hosts file
-  r         Refresh entry list
-  /         Search entries
-  enter     Show entry details
-
-Views:
-  ?/h       Show/hide this help
-  q         Quit application
-  esc       Cancel current action
+	keys := m.keyMap()
+	width := 0
+	for _, binding := range keys.bindings {
+		width = max(width, len([]rune(keys.label(binding.action))))
+	}
+
+	group := ""
+	for _, binding := range keys.bindings {
+		if binding.group != group {
+			group = binding.group
+			fmt.Fprintf(&b, "\n%s:\n", group)
+		}
+		label := keys.label(binding.action)
+		fmt.Fprintf(&b, "  %s%s  %s\n", label, strings.Repeat(" ", width-len([]rune(label))), binding.help)
+	}
+	fmt.Fprintf(&b, "  %-*s  %s\n", width, "esc", "Cancel current action")
 
+	b.WriteString(`
 Search:
   Search works on hostnames, IPs, comments, and categories.
   Press Enter to apply search, Esc to cancel.
-`
 
-	b.WriteString(helpText)
+Watching (--watch):
+  External changes to the hosts file raise a prompt to reload,
+  merge, or keep the in-memory version before saving.
+`)
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("Press ? or h to return to main view"))
+	b.WriteString(m.styles.help.Render(fmt.Sprintf("Press %s to return to main view", keys.label(actionHelp))))
 
 	return b.String()
 }
@@ -1052,41 +1676,41 @@ Search:
 func (m *model) viewAdd() string {
 	var b strings.Builder
 
-	b.WriteString(titleStyle.Render("Add New Entry"))
+	b.WriteString(m.styles.title.Render("Add New Entry"))
 	b.WriteString("\n\n")
 
 	// IP field
 	ipLabel := "IP Address:"
 	if m.addField == 0 {
-		ipLabel = selectedStyle.Render("IP Address:")
+		ipLabel = m.styles.selected.Render("IP Address:")
 	}
 	b.WriteString(fmt.Sprintf("%s %s\n", ipLabel, m.addIP))
 
 	// Hostnames field
 	hostnamesLabel := "Hostnames:"
 	if m.addField == 1 {
-		hostnamesLabel = selectedStyle.Render("Hostnames:")
+		hostnamesLabel = m.styles.selected.Render("Hostnames:")
 	}
 	b.WriteString(fmt.Sprintf("%s %s\n", hostnamesLabel, m.addHostnames))
 
 	// Comment field
 	commentLabel := "Comment (optional):"
 	if m.addField == 2 {
-		commentLabel = selectedStyle.Render("Comment (optional):")
+		commentLabel = m.styles.selected.Render("Comment (optional):")
 	}
 	b.WriteString(fmt.Sprintf("%s %s\n", commentLabel, m.addComment))
 
 	// Category field
 	categoryLabel := "Category:"
 	if m.addField == 3 {
-		categoryLabel = selectedStyle.Render("Category:")
+		categoryLabel = m.styles.selected.Render("Category:")
 	}
 	b.WriteString(fmt.Sprintf("%s %s\n", categoryLabel, m.addCategory))
 
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("Use Tab/Shift+Tab to navigate fields"))
+	b.WriteString(m.styles.help.Render("Use Tab/Shift+Tab to navigate fields"))
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("Press Enter to add entry, Esc to cancel"))
+	b.WriteString(m.styles.help.Render("Press Enter to add entry, Esc to cancel"))
 
 	return b.String()
 }
@@ -1094,7 +1718,7 @@ func (m *model) viewAdd() string {
 func (m *model) viewMove() string {
 	var b strings.Builder
 
-	b.WriteString(titleStyle.Render("Move Entry to Category"))
+	b.WriteString(m.styles.title.Render("Move Entry to Category"))
 	b.WriteString("\n\n")
 
 	// Show the entry being moved
@@ -1106,7 +1730,7 @@ func (m *model) viewMove() string {
 		if entry.entry.Comment != "" {
 			entryStr += " # " + entry.entry.Comment
 		}
-		b.WriteString(moveStyle.Render(entryStr))
+		b.WriteString(m.styles.move.Render(entryStr))
 		b.WriteString("\n")
 		b.WriteString(fmt.Sprintf("From category: %s\n\n", entry.category))
 	}
@@ -1123,7 +1747,7 @@ func (m *model) viewMove() string {
 
 		line := cursor + category
 		if i == m.moveCategoryCursor {
-			line = selectedStyle.Render(line)
+			line = m.styles.selected.Render(line)
 		}
 
 		b.WriteString(line)
@@ -1131,7 +1755,7 @@ func (m *model) viewMove() string {
 	}
 
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("Use ↑/↓ to select category, Enter to move, Esc to cancel"))
+	b.WriteString(m.styles.help.Render("Use ↑/↓ to select category, Enter to move, Esc to cancel"))
 
 	return b.String()
 }
@@ -1139,29 +1763,29 @@ func (m *model) viewMove() string {
 func (m *model) viewCreateCategory() string {
 	var b strings.Builder
 
-	b.WriteString(titleStyle.Render("Create New Category"))
+	b.WriteString(m.styles.title.Render("Create New Category"))
 	b.WriteString("\n\n")
 
 	// Name field
 	nameLabel := "Category Name:"
 	if m.createCategoryField == 0 {
-		nameLabel = selectedStyle.Render("Category Name:")
+		nameLabel = m.styles.selected.Render("Category Name:")
 	}
 	b.WriteString(fmt.Sprintf("%s %s\n", nameLabel, m.createCategoryName))
 
 	// Description field
 	descLabel := "Description (optional):"
 	if m.createCategoryField == 1 {
-		descLabel = selectedStyle.Render("Description (optional):")
+		descLabel = m.styles.selected.Render("Description (optional):")
 	}
 	b.WriteString(fmt.Sprintf("%s %s\n", descLabel, m.createCategoryDescription))
 
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("Category names can contain: a-z, A-Z, 0-9, _, -"))
+	b.WriteString(m.styles.help.Render("Category names can contain: a-z, A-Z, 0-9, _, -"))
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("Use Tab/Shift+Tab to navigate fields"))
+	b.WriteString(m.styles.help.Render("Use Tab/Shift+Tab to navigate fields"))
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("Press Enter to create category, Esc to cancel"))
+	b.WriteString(m.styles.help.Render("Press Enter to create category, Esc to cancel"))
 
 	return b.String()
 }
@@ -1169,45 +1793,45 @@ func (m *model) viewCreateCategory() string {
 func (m *model) viewEdit() string {
 	var b strings.Builder
 
-	b.WriteString(titleStyle.Render("Edit Entry"))
+	b.WriteString(m.styles.title.Render("Edit Entry"))
 	b.WriteString("\n\n")
 
 	// IP field
 	ipLabel := "IP Address:"
 	if m.editField == 0 {
-		ipLabel = selectedStyle.Render("IP Address:")
+		ipLabel = m.styles.selected.Render("IP Address:")
 	}
 	b.WriteString(fmt.Sprintf("%s %s\n", ipLabel, m.editIP))
 
 	// Hostnames field
 	hostnamesLabel := "Hostnames (space separated):"
 	if m.editField == 1 {
-		hostnamesLabel = selectedStyle.Render("Hostnames (space separated):")
+		hostnamesLabel = m.styles.selected.Render("Hostnames (space separated):")
 	}
 	b.WriteString(fmt.Sprintf("%s %s\n", hostnamesLabel, m.editHostnames))
 
 	// Comment field
 	commentLabel := "Comment (optional):"
 	if m.editField == 2 {
-		commentLabel = selectedStyle.Render("Comment (optional):")
+		commentLabel = m.styles.selected.Render("Comment (optional):")
 	}
 	b.WriteString(fmt.Sprintf("%s %s\n", commentLabel, m.editComment))
 
 	// Category field
 	categoryLabel := "Category:"
 	if m.editField == 3 {
-		categoryLabel = selectedStyle.Render("Category:")
+		categoryLabel = m.styles.selected.Render("Category:")
 	}
 	b.WriteString(fmt.Sprintf("%s %s\n", categoryLabel, m.editCategory))
 
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("Use Tab/Shift+Tab to navigate fields"))
+	b.WriteString(m.styles.help.Render("Use Tab/Shift+Tab to navigate fields"))
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("Press Enter to save changes, Esc to cancel"))
+	b.WriteString(m.styles.help.Render("Press Enter to save changes, Esc to cancel"))
 
 	if m.message != "" {
 		b.WriteString("\n\n")
-		b.WriteString(errorStyle.Render(m.message))
+		b.WriteString(m.styles.error.Render(m.message))
 	}
 
 	return b.String()
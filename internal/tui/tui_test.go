@@ -1,6 +1,9 @@
 package tui
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -78,6 +81,7 @@ func createTestModel() *model {
 	m := &model{
 		hostsFile:   hostsFile,
 		config:      cfg,
+		styles:      newStyles(darkPalette),
 		currentView: viewMain,
 		selected:    make(map[int]bool),
 		entries:     buildEntryList(hostsFile),
@@ -1095,3 +1099,567 @@ func TestViewEditRendering(t *testing.T) {
 		t.Errorf("Expected output to contain save instructions")
 	}
 }
+
+func TestSaveFileRefusesEmptyWrite(t *testing.T) {
+	m := createTestModel()
+
+	hostsPath := filepath.Join(t.TempDir(), "hosts")
+	original := "127.0.0.1 dev.local\n192.168.1.100 api.dev\n"
+	if err := os.WriteFile(hostsPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write test hosts file: %v", err)
+	}
+	m.hostsFile.FilePath = hostsPath
+
+	for _, name := range m.categories {
+		m.hostsFile.DisableCategory(name)
+	}
+
+	msg := m.saveFile()()
+	if _, ok := msg.(errorMsg); !ok {
+		t.Fatalf("Expected errorMsg when saving zero enabled entries, got %T", msg)
+	}
+
+	data, _ := os.ReadFile(hostsPath)
+	if string(data) != original {
+		t.Error("Hosts file should be unchanged after a refused save")
+	}
+
+	// Forcing the save allows the write through
+	m.options.Force = true
+	msg = m.saveFile()()
+	if _, ok := msg.(successMsg); !ok {
+		t.Fatalf("Expected successMsg with force enabled, got %T", msg)
+	}
+}
+
+func TestProtectedEntryCannotBeModified(t *testing.T) {
+	m := createTestModel()
+	m.hostsFile.Categories[0].Entries[0].ReadOnly = true
+	m.entries = buildEntryList(m.hostsFile)
+	m.cursor = 0
+
+	m.updateMain(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{' '}})
+	if !m.hostsFile.Categories[0].Entries[0].Enabled {
+		t.Error("Protected entry should not be toggled")
+	}
+	if !strings.Contains(m.message, "protected") {
+		t.Errorf("Expected protected message, got '%s'", m.message)
+	}
+
+	m.updateMain(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if len(m.hostsFile.Categories[0].Entries) != 2 {
+		t.Error("Protected entry should not be deleted")
+	}
+
+	m.options.Force = true
+	m.updateMain(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{' '}})
+	if m.hostsFile.Categories[0].Entries[0].Enabled {
+		t.Error("Protected entry should be toggled when forced")
+	}
+}
+
+func TestMainListPagination(t *testing.T) {
+	entries := make([]hosts.Entry, 50)
+	for i := range entries {
+		entries[i] = hosts.Entry{
+			IP:        fmt.Sprintf("10.0.0.%d", i+1),
+			Hostnames: []string{fmt.Sprintf("host%02d.local", i)},
+			Category:  "development",
+			Enabled:   true,
+		}
+	}
+	hostsFile := &hosts.HostsFile{
+		Categories: []hosts.Category{{Name: "development", Enabled: true, Entries: entries}},
+	}
+	cfg := &config.Config{UI: config.UI{PageSize: 10}}
+
+	m := &model{
+		hostsFile:   hostsFile,
+		config:      cfg,
+		currentView: viewMain,
+		selected:    make(map[int]bool),
+		entries:     buildEntryList(hostsFile),
+	}
+
+	press := func(key rune) {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+	}
+
+	// The page size caps the window before the terminal size is known
+	view := m.viewMain()
+	if !strings.Contains(view, "host09.local") || strings.Contains(view, "host10.local") {
+		t.Errorf("Expected the first 10 entries to be shown")
+	}
+	if !strings.Contains(view, "row 1 of 50") {
+		t.Errorf("Expected row indicator, got:\n%s", view)
+	}
+
+	// Moving past the bottom edge scrolls by one
+	for i := 0; i < 10; i++ {
+		press('j')
+	}
+	if m.cursor != 10 || m.offset != 1 {
+		t.Errorf("Expected cursor 10 at offset 1, got cursor %d offset %d", m.cursor, m.offset)
+	}
+
+	press('G')
+	if m.cursor != 49 || m.offset != 40 {
+		t.Errorf("Expected G to show the last page, got cursor %d offset %d", m.cursor, m.offset)
+	}
+	view = m.viewMain()
+	if !strings.Contains(view, "host49.local") || strings.Contains(view, "host39.local") || !strings.Contains(view, "row 50 of 50") {
+		t.Errorf("Expected the last page to be rendered, got:\n%s", view)
+	}
+
+	// Moving up within the window does not scroll until the top edge
+	for i := 0; i < 9; i++ {
+		press('k')
+	}
+	if m.offset != 40 {
+		t.Errorf("Expected offset to stay at 40, got %d", m.offset)
+	}
+	press('k')
+	if m.cursor != 39 || m.offset != 39 {
+		t.Errorf("Expected cursor 39 at offset 39, got cursor %d offset %d", m.cursor, m.offset)
+	}
+
+	press('g')
+	if m.cursor != 0 || m.offset != 0 {
+		t.Errorf("Expected g to return to the top, got cursor %d offset %d", m.cursor, m.offset)
+	}
+
+	// A short terminal shows fewer rows than the page size
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	if rows := m.visibleRows(0); rows < 1 || rows >= 10 {
+		t.Errorf("Expected the terminal height to limit the window, got %d rows", rows)
+	}
+	if lines := strings.Count(m.viewMain(), "\n") + 1; lines > 20 {
+		t.Errorf("Expected view to fit in 20 lines, got %d", lines)
+	}
+}
+
+func TestUnsavedChangesWarnOnQuit(t *testing.T) {
+	m := createTestModel()
+
+	isQuit := func(cmd tea.Cmd) bool {
+		if cmd == nil {
+			return false
+		}
+		_, ok := cmd().(tea.QuitMsg)
+		return ok
+	}
+
+	// Nothing changed yet, so q quits immediately
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}}); !isQuit(cmd) {
+		t.Fatal("Expected q to quit without unsaved changes")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	if !m.dirty {
+		t.Fatal("Expected toggling an entry to mark the model dirty")
+	}
+	if !strings.Contains(m.viewMain(), "Hosts Manager *") {
+		t.Error("Expected a dirty marker in the title")
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if isQuit(cmd) || m.currentView != viewQuitConfirm {
+		t.Fatal("Expected q to ask for confirmation with unsaved changes")
+	}
+	if !strings.Contains(m.View(), "quit anyway?") {
+		t.Errorf("Expected confirmation prompt, got:\n%s", m.View())
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if m.currentView != viewMain || !m.dirty {
+		t.Error("Expected n to return to the main view with changes kept")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}}); !isQuit(cmd) {
+		t.Error("Expected y to quit and discard changes")
+	}
+
+	// A completed save clears the flag
+	m.currentView = viewMain
+	m.Update(successMsg{})
+	if m.dirty {
+		t.Error("Expected a successful save to clear the dirty flag")
+	}
+}
+
+func TestBulkSelectionOperations(t *testing.T) {
+	m := createTestModel()
+	key := func(r rune) {
+		m.updateMain(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+
+	key('E')
+	if m.message != "No entries selected" {
+		t.Errorf("Expected no-selection message, got '%s'", m.message)
+	}
+
+	// Select dev.local and staging.local, toggling api.dev on and off again
+	m.cursor = 0
+	key('x')
+	m.cursor = 1
+	key('x')
+	key('x')
+	m.cursor = 2
+	key('x')
+	if len(m.selected) != 2 {
+		t.Fatalf("Expected 2 selected entries, got %d", len(m.selected))
+	}
+	if !strings.Contains(m.viewMain(), "*") {
+		t.Error("Expected selected rows to be marked")
+	}
+
+	key('I')
+	if m.hostsFile.Categories[0].Entries[0].Enabled || m.hostsFile.Categories[1].Entries[0].Enabled {
+		t.Error("Expected selected entries to be disabled")
+	}
+	if !m.hostsFile.Categories[0].Entries[1].Enabled {
+		t.Error("Expected unselected entry to be left enabled")
+	}
+	if len(m.selected) != 0 || !m.dirty {
+		t.Error("Expected bulk operation to clear the selection and mark the model dirty")
+	}
+
+	// Deleting entries from several categories must not drift onto neighbours
+	m.hostsFile.Categories[0].Entries[1].ReadOnly = true
+	for i := range m.entries {
+		m.cursor = i
+		key('x')
+	}
+	m.cursor = 0
+	key('D')
+
+	var remaining []string
+	for _, entry := range buildEntryList(m.hostsFile) {
+		remaining = append(remaining, entry.entry.Hostnames[0])
+	}
+	if strings.Join(remaining, ",") != "api.dev" {
+		t.Errorf("Expected only the protected entry to remain, got %v", remaining)
+	}
+	if !strings.Contains(m.message, "Deleted 3 entries") || !strings.Contains(m.message, "1 protected") {
+		t.Errorf("Unexpected bulk delete message '%s'", m.message)
+	}
+	if len(m.entries) != 1 || len(m.selected) != 0 {
+		t.Errorf("Expected entry list rebuilt with selection cleared, got %d entries, %d selected", len(m.entries), len(m.selected))
+	}
+}
+
+func TestEntryDetailView(t *testing.T) {
+	m := createTestModel()
+	m.hostsFile.Categories[0].Entries[1].Hostnames = []string{"api.dev", "api-v2.dev", "graphql.dev"}
+	m.hostsFile.Categories[0].Entries[1].LineNum = 12
+	m.entries = buildEntryList(m.hostsFile)
+	m.cursor = 1
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.currentView != viewDetail {
+		t.Fatalf("Expected enter to open the detail view, got %v", m.currentView)
+	}
+
+	view := m.View()
+	for _, want := range []string{"192.168.1.100", "private", "api.dev", "api-v2.dev", "graphql.dev", "API server", "development", "enabled", "12"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected detail view to contain %q, got:\n%s", want, view)
+		}
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.currentView != viewMain {
+		t.Error("Expected esc to return to the main view")
+	}
+}
+
+func TestAutoSaveWritesAfterEachChange(t *testing.T) {
+	m := createTestModel()
+
+	hostsPath := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(hostsPath, []byte("127.0.0.1 dev.local\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test hosts file: %v", err)
+	}
+	m.hostsFile.FilePath = hostsPath
+	m.markLoaded()
+
+	backups := 0
+	m.options.AutoSave = true
+	m.options.Backup = func() error {
+		backups++
+		return nil
+	}
+	// A failing after-save hook must not fail the save
+	afterSaves := 0
+	m.options.AfterSave = func() error {
+		afterSaves++
+		return fmt.Errorf("flush tool missing")
+	}
+
+	toggle := func() {
+		t.Helper()
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+		if cmd == nil {
+			t.Fatal("Expected a change to trigger a save")
+		}
+		msg := cmd()
+		if _, ok := msg.(successMsg); !ok {
+			t.Fatalf("Expected successMsg, got %#v", msg)
+		}
+		m.Update(msg)
+	}
+
+	toggle()
+	if m.dirty {
+		t.Error("Expected auto-save to clear the dirty flag")
+	}
+	if m.message != "Entry disabled (saved)" {
+		t.Errorf("Expected save result in the message line, got '%s'", m.message)
+	}
+	data, _ := os.ReadFile(hostsPath)
+	if !strings.Contains(string(data), "# 127.0.0.1 dev.local") {
+		t.Errorf("Expected disabled entry written to disk, got:\n%s", data)
+	}
+
+	toggle()
+	if backups != 1 {
+		t.Errorf("Expected one backup per session, got %d", backups)
+	}
+	if afterSaves != 2 {
+		t.Errorf("Expected the after-save hook to run after each save, got %d", afterSaves)
+	}
+
+	// Moving the cursor is not a change
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyDown}); cmd != nil {
+		t.Error("Expected navigation not to trigger a save")
+	}
+}
+
+func TestSaveAndFlush(t *testing.T) {
+	m := createTestModel()
+
+	hostsPath := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(hostsPath, []byte("127.0.0.1 dev.local\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test hosts file: %v", err)
+	}
+	m.hostsFile.FilePath = hostsPath
+	m.markLoaded()
+
+	afterSaves, flushes := 0, 0
+	m.options.AfterSave = func() error {
+		afterSaves++
+		return nil
+	}
+	var flushErr error
+	m.options.FlushDNS = func() error {
+		flushes++
+		return flushErr
+	}
+
+	saveAndFlush := func() {
+		t.Helper()
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+		if cmd == nil {
+			t.Fatal("Expected S to save")
+		}
+		m.Update(cmd())
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	saveAndFlush()
+	if m.dirty || m.message != "File saved and DNS cache flushed!" {
+		t.Errorf("Expected both results in the message line, got '%s'", m.message)
+	}
+	if flushes != 1 || afterSaves != 0 {
+		t.Errorf("Expected one flush and no after-save hook, got %d flushes and %d after-saves", flushes, afterSaves)
+	}
+	if data, _ := os.ReadFile(hostsPath); !strings.Contains(string(data), "# 127.0.0.1 dev.local") {
+		t.Errorf("Expected the change written to disk, got:\n%s", data)
+	}
+
+	// A failed elevation or flush is reported without losing the save
+	flushErr = fmt.Errorf("elevation required")
+	m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	saveAndFlush()
+	if m.dirty {
+		t.Error("Expected the file to be saved even though the flush failed")
+	}
+	if !strings.HasPrefix(m.message, "Failed to flush DNS cache: elevation required") {
+		t.Errorf("Expected the flush error in the message line, got '%s'", m.message)
+	}
+
+	// A failed save does not flush
+	m.hostsFile.FilePath = filepath.Join(t.TempDir(), "missing", "hosts")
+	saveAndFlush()
+	if flushes != 2 || !strings.HasPrefix(m.message, "Error") {
+		t.Errorf("Expected a failed save to skip the flush, got %d flushes and '%s'", flushes, m.message)
+	}
+}
+
+func TestCopyEntry(t *testing.T) {
+	m := createTestModel()
+	m.entries[0].entry.Enabled = false
+
+	var copied string
+	m.options.Copy = func(text string) error {
+		copied = text
+		return nil
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if copied != "# 127.0.0.1 dev.local # Dev server" {
+		t.Errorf("Expected the entry's hosts file line to be copied, got %q", copied)
+	}
+	if m.message != "Copied: "+copied {
+		t.Errorf("Expected the copied line in the message, got '%s'", m.message)
+	}
+	if m.dirty {
+		t.Error("Copying should not modify the hosts file")
+	}
+
+	// Headless systems fall back to showing the line
+	m.options.Copy = func(string) error { return fmt.Errorf("no supported clipboard command found") }
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if !strings.Contains(m.message, "no supported clipboard command found") || !strings.HasSuffix(m.message, "127.0.0.1 dev.local # Dev server") {
+		t.Errorf("Expected the line in the message when copying fails, got '%s'", m.message)
+	}
+}
+
+func TestStatusFilter(t *testing.T) {
+	m := createTestModel()
+	press := func(r rune) {
+		m.updateMain(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	hostnames := func() string {
+		var names []string
+		for _, entry := range m.entries {
+			names = append(names, entry.entry.Hostnames[0])
+		}
+		return strings.Join(names, ",")
+	}
+
+	m.hostsFile.Categories[0].Entries[1].Enabled = false
+	m.hostsFile.Categories[2].Entries[0].Enabled = false
+	m.entries = buildEntryList(m.hostsFile)
+
+	press('f')
+	if got := hostnames(); got != "dev.local,staging.local" {
+		t.Errorf("Expected enabled entries only, got %s", got)
+	}
+	if !strings.Contains(m.mainHeaderView(), "[enabled only]") {
+		t.Errorf("Expected filter indicator in header, got:\n%s", m.mainHeaderView())
+	}
+
+	press('f')
+	if got := hostnames(); got != "api.dev,prod.example.com" {
+		t.Errorf("Expected disabled entries only, got %s", got)
+	}
+
+	// Refreshing keeps the filter
+	press('r')
+	if got := hostnames(); got != "api.dev,prod.example.com" {
+		t.Errorf("Expected refresh to preserve the filter, got %s", got)
+	}
+
+	// The filter composes with search
+	m.searchQuery = "dev"
+	m.filterEntries()
+	if got := hostnames(); got != "api.dev" {
+		t.Errorf("Expected search within disabled entries, got %s", got)
+	}
+
+	press('f')
+	if m.statusFilter != statusAll {
+		t.Fatalf("Expected filter to cycle back to all, got %v", m.statusFilter)
+	}
+	if got := hostnames(); got != "dev.local,api.dev" {
+		t.Errorf("Expected search across all entries, got %s", got)
+	}
+}
+
+func TestMouseSelectsAndScrolls(t *testing.T) {
+	m := createTestModel()
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+
+	lineOf := func(text string) int {
+		for i, line := range strings.Split(m.viewMain(), "\n") {
+			if strings.Contains(line, text) {
+				return i
+			}
+		}
+		t.Fatalf("%q is not rendered", text)
+		return -1
+	}
+	click := func(y int) {
+		m.Update(tea.MouseMsg{X: 5, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	}
+
+	click(lineOf("staging.local"))
+	if m.entries[m.cursor].entry.Hostnames[0] != "staging.local" {
+		t.Errorf("Clicking staging.local selected %v", m.entries[m.cursor].entry.Hostnames)
+	}
+	click(lineOf("prod.example.com"))
+	if m.entries[m.cursor].entry.Hostnames[0] != "prod.example.com" {
+		t.Errorf("Clicking prod.example.com selected %v", m.entries[m.cursor].entry.Hostnames)
+	}
+
+	// Category separators and the header are not entries
+	cursor := m.cursor
+	click(lineOf("=== STAGING ==="))
+	click(0)
+	if m.cursor != cursor {
+		t.Errorf("Clicking outside the entries moved the cursor to %d", m.cursor)
+	}
+
+	// Mouse events are ignored outside the main view
+	m.currentView = viewHelp
+	click(lineOf("dev.local"))
+	if m.cursor != cursor {
+		t.Error("Clicks should be ignored outside the main view")
+	}
+	m.currentView = viewMain
+
+	// The wheel scrolls the viewport and drags the cursor along at the edges
+	entries := make([]hosts.Entry, 30)
+	for i := range entries {
+		entries[i] = hosts.Entry{
+			IP:        fmt.Sprintf("10.0.0.%d", i+1),
+			Hostnames: []string{fmt.Sprintf("host%02d.local", i)},
+			Category:  "development",
+			Enabled:   true,
+		}
+	}
+	m.hostsFile = &hosts.HostsFile{Categories: []hosts.Category{{Name: "development", Enabled: true, Entries: entries}}}
+	m.config = &config.Config{UI: config.UI{PageSize: 10}}
+	m.entries = buildEntryList(m.hostsFile)
+	m.cursor, m.offset = 0, 0
+
+	wheel := func(button tea.MouseButton) {
+		m.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: button})
+	}
+	wheel(tea.MouseButtonWheelDown)
+	wheel(tea.MouseButtonWheelDown)
+	if m.offset != 2 || m.cursor != 2 {
+		t.Errorf("Expected wheel down to scroll to offset 2 with the cursor, got offset %d cursor %d", m.offset, m.cursor)
+	}
+	if strings.Contains(m.viewMain(), "host01.local") {
+		t.Error("Scrolled-off entries should not be rendered")
+	}
+
+	click(lineOf("host05.local"))
+	if m.cursor != 5 {
+		t.Errorf("Expected a click after scrolling to select host05, got %d", m.cursor)
+	}
+
+	for i := 0; i < 40; i++ {
+		wheel(tea.MouseButtonWheelDown)
+	}
+	if m.offset != 20 {
+		t.Errorf("Expected scrolling to stop at the last page, got offset %d", m.offset)
+	}
+	m.cursor = 29
+	wheel(tea.MouseButtonWheelUp)
+	if m.offset != 19 || m.cursor != 28 {
+		t.Errorf("Expected wheel up to keep the cursor on screen, got offset %d cursor %d", m.offset, m.cursor)
+	}
+}
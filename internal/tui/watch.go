@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/brandonhon/hosts-manager/internal/hosts"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// watchInterval is how often the hosts file is polled for external changes
+const watchInterval = time.Second
+
+type watchTickMsg struct{}
+
+// fileStamp identifies a version of the hosts file on disk
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+func watchTick() tea.Cmd {
+	return tea.Tick(watchInterval, func(time.Time) tea.Msg {
+		return watchTickMsg{}
+	})
+}
+
+// markLoaded records the on-disk version and hostnames the in-memory copy is based on
+func (m *model) markLoaded() {
+	m.diskStamp = statFile(m.hostsFile.FilePath)
+	m.knownHostnames = hostnameSet(m.hostsFile)
+}
+
+// externallyModified reports whether the hosts file changed on disk since it
+// was last loaded or saved by this TUI
+func (m *model) externallyModified() bool {
+	return m.options.Watch && statFile(m.hostsFile.FilePath) != m.diskStamp
+}
+
+// handleWatchTick prompts for a reload when the file changed externally. The
+// prompt is only raised from the main view so in-progress input is not lost,
+// and never while our own save is in flight.
+func (m *model) handleWatchTick() tea.Cmd {
+	if m.currentView == viewMain && !m.saving && m.externallyModified() {
+		m.currentView = viewReload
+	}
+	return watchTick()
+}
+
+func (m *model) updateReload(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "r":
+		if err := m.reloadFromDisk(false); err != nil {
+			m.message = fmt.Sprintf("Error: failed to reload: %v", err)
+		} else {
+			m.dirty = false
+			m.message = "Reloaded from disk; unsaved changes discarded"
+		}
+		m.currentView = viewMain
+
+	case "m":
+		if err := m.reloadFromDisk(true); err != nil {
+			m.message = fmt.Sprintf("Error: failed to merge: %v", err)
+		} else {
+			m.markDirty()
+			m.message = "Merged external changes; save to write them"
+		}
+		m.currentView = viewMain
+
+	case "k", "esc":
+		// Keep the in-memory copy; the next save overwrites the external change
+		m.diskStamp = statFile(m.hostsFile.FilePath)
+		m.message = "Kept in-memory version; saving will overwrite the external change"
+		m.currentView = viewMain
+	}
+
+	return m, nil
+}
+
+// reloadFromDisk re-reads the hosts file. With merge set, in-memory changes
+// are kept and entries added externally since the last load are appended;
+// otherwise the in-memory copy is replaced.
+func (m *model) reloadFromDisk(merge bool) error {
+	reload := m.options.Reload
+	if reload == nil {
+		reload = func(path string) (*hosts.HostsFile, error) {
+			return hosts.NewParser(path).Parse()
+		}
+	}
+
+	onDisk, err := reload(m.hostsFile.FilePath)
+	if err != nil {
+		return err
+	}
+
+	if merge {
+		for _, category := range onDisk.Categories {
+			for _, entry := range category.Entries {
+				if m.isExternalAddition(entry) {
+					m.appendEntry(category, entry)
+				}
+			}
+		}
+	} else {
+		m.hostsFile = onDisk
+	}
+
+	m.rebuildEntries()
+	m.categories = m.categories[:0]
+	for _, category := range m.hostsFile.Categories {
+		m.categories = append(m.categories, category.Name)
+	}
+	if m.cursor >= len(m.entries) {
+		m.cursor = max(len(m.entries)-1, 0)
+	}
+	m.markLoaded()
+	return nil
+}
+
+// isExternalAddition reports whether none of entry's hostnames were present
+// when the file was last loaded or are present in memory now
+func (m *model) isExternalAddition(entry hosts.Entry) bool {
+	current := hostnameSet(m.hostsFile)
+	for _, hostname := range entry.Hostnames {
+		if m.knownHostnames[hostname] || current[hostname] {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *model) appendEntry(category hosts.Category, entry hosts.Entry) {
+	if target := m.hostsFile.GetCategory(category.Name); target != nil {
+		target.Entries = append(target.Entries, entry)
+		return
+	}
+	category.Entries = []hosts.Entry{entry}
+	m.hostsFile.Categories = append(m.hostsFile.Categories, category)
+}
+
+func hostnameSet(hostsFile *hosts.HostsFile) map[string]bool {
+	set := make(map[string]bool)
+	for _, category := range hostsFile.Categories {
+		for _, entry := range category.Entries {
+			for _, hostname := range entry.Hostnames {
+				set[hostname] = true
+			}
+		}
+	}
+	return set
+}
+
+func (m *model) viewReload() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.title.Render("Hosts File Changed"))
+	b.WriteString("\n\n")
+	b.WriteString("  The hosts file was modified outside hosts-manager.\n\n")
+	b.WriteString("  " + m.styles.key.Render("[r]") + " " + m.styles.action.Render("Reload and discard unsaved changes") + "\n")
+	b.WriteString("  " + m.styles.key.Render("[m]") + " " + m.styles.action.Render("Merge: keep unsaved changes and add new external entries") + "\n")
+	b.WriteString("  " + m.styles.key.Render("[k]") + " " + m.styles.action.Render("Keep in-memory version (saving overwrites the external change)") + "\n")
+
+	return b.String()
+}
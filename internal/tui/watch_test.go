@@ -0,0 +1,152 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brandonhon/hosts-manager/internal/hosts"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func createWatchedModel(t *testing.T, content string) (*model, string) {
+	t.Helper()
+
+	hostsPath := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(hostsPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test hosts file: %v", err)
+	}
+	hostsFile, err := hosts.NewParser(hostsPath).Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse test hosts file: %v", err)
+	}
+
+	m := createTestModel()
+	m.hostsFile = hostsFile
+	m.entries = buildEntryList(hostsFile)
+	m.options.Watch = true
+	m.markLoaded()
+	return m, hostsPath
+}
+
+// writeExternally simulates another process editing the file, moving the
+// modification time forward so the change is visible on coarse filesystems
+func writeExternally(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to modify hosts file: %v", err)
+	}
+	future := time.Now().Add(2 * time.Second)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("Failed to set file time: %v", err)
+	}
+}
+
+func TestWatchPromptsOnExternalChange(t *testing.T) {
+	m, hostsPath := createWatchedModel(t, "127.0.0.1 dev.local\n")
+
+	m.Update(watchTickMsg{})
+	if m.currentView != viewMain {
+		t.Fatal("Unchanged file should not raise a reload prompt")
+	}
+
+	writeExternally(t, hostsPath, "127.0.0.1 dev.local\n192.168.1.50 external.dev\n")
+
+	m.Update(watchTickMsg{})
+	if m.currentView != viewReload {
+		t.Fatalf("Expected reload prompt after external change, got view %d", m.currentView)
+	}
+	if m.View() == "" {
+		t.Error("Reload prompt should render")
+	}
+}
+
+func TestWatchIgnoresOwnSave(t *testing.T) {
+	m, _ := createWatchedModel(t, "127.0.0.1 dev.local\n")
+
+	m.updateMain(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if !m.saving {
+		t.Error("Expected saving flag while save is in flight")
+	}
+
+	// A tick arriving mid-save must not prompt
+	m.Update(watchTickMsg{})
+	if m.currentView != viewMain {
+		t.Fatal("Tick during own save should not raise a reload prompt")
+	}
+
+	msg := m.saveFile()()
+	if _, ok := msg.(successMsg); !ok {
+		t.Fatalf("Expected successMsg, got %T", msg)
+	}
+	m.Update(msg)
+
+	m.Update(watchTickMsg{})
+	if m.currentView != viewMain {
+		t.Error("Own save should not raise a reload prompt")
+	}
+}
+
+func TestWatchSaveWithheldAfterExternalChange(t *testing.T) {
+	m, hostsPath := createWatchedModel(t, "127.0.0.1 dev.local\n")
+	external := "127.0.0.1 dev.local\n192.168.1.50 external.dev\n"
+	writeExternally(t, hostsPath, external)
+
+	msg := m.saveFile()()
+	if _, ok := msg.(externalChangeMsg); !ok {
+		t.Fatalf("Expected externalChangeMsg, got %T", msg)
+	}
+	m.Update(msg)
+	if m.currentView != viewReload {
+		t.Error("Expected reload prompt when save is withheld")
+	}
+
+	data, _ := os.ReadFile(hostsPath)
+	if string(data) != external {
+		t.Error("External change should not be overwritten")
+	}
+}
+
+func TestWatchReloadOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		key          rune
+		expectLocal  bool
+		expectRemote bool
+	}{
+		{name: "reload discards", key: 'r', expectLocal: false, expectRemote: true},
+		{name: "merge keeps both", key: 'm', expectLocal: true, expectRemote: true},
+		{name: "keep ignores external", key: 'k', expectLocal: true, expectRemote: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, hostsPath := createWatchedModel(t, "127.0.0.1 dev.local\n")
+
+			if err := m.hostsFile.AddEntry(hosts.Entry{IP: "192.168.1.60", Hostnames: []string{"local.dev"}, Enabled: true}); err != nil {
+				t.Fatalf("Failed to add entry: %v", err)
+			}
+			writeExternally(t, hostsPath, "127.0.0.1 dev.local\n192.168.1.50 external.dev\n")
+
+			m.Update(watchTickMsg{})
+			m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{tt.key}})
+
+			if m.currentView != viewMain {
+				t.Errorf("Expected to return to main view, got %d", m.currentView)
+			}
+			if got := len(m.hostsFile.FindEntryByHostname("local.dev")) == 1; got != tt.expectLocal {
+				t.Errorf("local.dev present = %v, want %v", got, tt.expectLocal)
+			}
+			if got := len(m.hostsFile.FindEntryByHostname("external.dev")) == 1; got != tt.expectRemote {
+				t.Errorf("external.dev present = %v, want %v", got, tt.expectRemote)
+			}
+
+			m.Update(watchTickMsg{})
+			if m.currentView != viewMain {
+				t.Error("Prompt should not repeat after it was answered")
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Package watch keeps managed categories in the hosts file when another tool
+// rewrites it. The file is polled rather than watched with OS notifications,
+// matching how the TUI detects external changes.
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/brandonhon/hosts-manager/internal/hosts"
+)
+
+// Options configures a Watcher
+type Options struct {
+	// Interval is how often the hosts file is polled. Defaults to one second.
+	Interval time.Duration
+	// Debounce is how long the file must stay unchanged before a change is
+	// handled, so a burst of writes is reconciled once. Zero handles changes
+	// on the next poll.
+	Debounce time.Duration
+	// DryRun reports missing categories without restoring them
+	DryRun bool
+	// Parse reads the hosts file. Defaults to a plain parse when nil.
+	Parse func(path string) (*hosts.HostsFile, error)
+	// Write saves the reconciled hosts file. Defaults to HostsFile.Write when nil.
+	Write func(hostsFile *hosts.HostsFile, path string) error
+	// OnChange is called after each external change is handled. Skipped when nil.
+	OnChange func(Change)
+}
+
+// Change describes one external modification and how it was handled
+type Change struct {
+	// Missing lists the managed categories the change removed
+	Missing []string
+	// Restored reports whether the missing categories were written back
+	Restored bool
+	Err      error
+}
+
+// Watcher restores managed categories removed from the hosts file
+type Watcher struct {
+	path     string
+	snapshot *hosts.HostsFile
+	opts     Options
+
+	lastSeen  fileStamp
+	handled   fileStamp
+	changedAt time.Time
+}
+
+// fileStamp identifies a version of the hosts file on disk
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+// New returns a Watcher for the hosts file at path. Every non-empty category
+// in snapshot other than the default one is managed and restored, entries
+// included, if an external change removes it.
+func New(path string, snapshot *hosts.HostsFile, opts Options) *Watcher {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.Debounce < 0 {
+		opts.Debounce = 0
+	}
+	if opts.Parse == nil {
+		opts.Parse = func(path string) (*hosts.HostsFile, error) {
+			return hosts.NewParser(path).Parse()
+		}
+	}
+	if opts.Write == nil {
+		opts.Write = func(hostsFile *hosts.HostsFile, path string) error {
+			return hostsFile.Write(path)
+		}
+	}
+
+	stamp := statFile(path)
+	return &Watcher{
+		path:     path,
+		snapshot: snapshot.Clone(),
+		opts:     opts,
+		lastSeen: stamp,
+		handled:  stamp,
+	}
+}
+
+// Managed returns the names of the categories the watcher restores
+func (w *Watcher) Managed() []string {
+	var names []string
+	for _, category := range w.snapshot.Categories {
+		if isManaged(category) {
+			names = append(names, category.Name)
+		}
+	}
+	return names
+}
+
+// isManaged reports whether category is restored when removed. Empty
+// categories are never written, so they cannot be removed externally.
+func isManaged(category hosts.Category) bool {
+	return category.Name != hosts.CategoryDefault && len(category.Entries) > 0
+}
+
+// Run polls the hosts file until ctx is cancelled
+func (w *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			if change, ok := w.poll(now); ok && w.opts.OnChange != nil {
+				w.opts.OnChange(change)
+			}
+		}
+	}
+}
+
+// poll handles the file once it has stayed unchanged for the debounce
+// period. It reports whether an external change was handled.
+func (w *Watcher) poll(now time.Time) (Change, bool) {
+	stamp := statFile(w.path)
+	if stamp != w.lastSeen {
+		w.lastSeen = stamp
+		w.changedAt = now
+		return Change{}, false
+	}
+	if stamp == w.handled || now.Sub(w.changedAt) < w.opts.Debounce {
+		return Change{}, false
+	}
+
+	w.handled = stamp
+	change := w.reconcile()
+	// Our own write is not an external change
+	if change.Restored {
+		w.lastSeen = statFile(w.path)
+		w.handled = w.lastSeen
+	}
+	return change, true
+}
+
+// reconcile appends every managed category missing from the hosts file
+func (w *Watcher) reconcile() Change {
+	hostsFile, err := w.opts.Parse(w.path)
+	if err != nil {
+		return Change{Err: fmt.Errorf("failed to parse hosts file: %w", err)}
+	}
+
+	// Clone so restored categories never share entries with the snapshot
+	snapshot := w.snapshot.Clone()
+	var change Change
+	var missing []hosts.Category
+	for _, category := range snapshot.Categories {
+		if !isManaged(category) {
+			continue
+		}
+		if existing := hostsFile.GetCategory(category.Name); existing != nil && len(existing.Entries) > 0 {
+			continue
+		}
+		missing = append(missing, category)
+		change.Missing = append(change.Missing, category.Name)
+	}
+
+	if len(missing) == 0 || w.opts.DryRun {
+		return change
+	}
+
+	for _, category := range missing {
+		// A header left behind without entries is refilled in place
+		if existing := hostsFile.GetCategory(category.Name); existing != nil {
+			existing.Entries = category.Entries
+			continue
+		}
+		hostsFile.Categories = append(hostsFile.Categories, category)
+	}
+	if err := w.opts.Write(hostsFile, w.path); err != nil {
+		change.Err = fmt.Errorf("failed to restore categories: %w", err)
+		return change
+	}
+	change.Restored = true
+	return change
+}
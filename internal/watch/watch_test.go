@@ -0,0 +1,213 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandonhon/hosts-manager/internal/hosts"
+)
+
+const managedHosts = `127.0.0.1 localhost
+
+# @category development
+127.0.0.1 app.local api.local
+
+# @category blocked
+127.0.0.2 ads.example.com
+`
+
+func createWatcher(t *testing.T, opts Options) (*Watcher, string) {
+	t.Helper()
+
+	hostsPath := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(hostsPath, []byte(managedHosts), 0644); err != nil {
+		t.Fatalf("Failed to write test hosts file: %v", err)
+	}
+	snapshot, err := hosts.NewParser(hostsPath).Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse test hosts file: %v", err)
+	}
+	return New(hostsPath, snapshot, opts), hostsPath
+}
+
+// writeExternally simulates another process editing the file, moving the
+// modification time forward so the change is visible on coarse filesystems
+func writeExternally(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to modify hosts file: %v", err)
+	}
+	future := time.Now().Add(2 * time.Second)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("Failed to set file time: %v", err)
+	}
+}
+
+func TestManaged(t *testing.T) {
+	w, _ := createWatcher(t, Options{})
+
+	if got, want := w.Managed(), []string{"development", "blocked"}; !slices.Equal(got, want) {
+		t.Errorf("Managed() = %v, want %v", got, want)
+	}
+}
+
+func TestPollRestoresMissingCategories(t *testing.T) {
+	w, hostsPath := createWatcher(t, Options{Debounce: time.Second})
+	start := time.Now()
+
+	if _, ok := w.poll(start); ok {
+		t.Fatal("Unchanged file should not be handled")
+	}
+
+	writeExternally(t, hostsPath, "127.0.0.1 localhost\n\n# @category blocked\n127.0.0.2 ads.example.com\n")
+
+	if _, ok := w.poll(start.Add(time.Second)); ok {
+		t.Fatal("Change should wait for the debounce period")
+	}
+	if _, ok := w.poll(start.Add(1500 * time.Millisecond)); ok {
+		t.Fatal("Change should wait for the debounce period")
+	}
+
+	change, ok := w.poll(start.Add(2 * time.Second))
+	if !ok {
+		t.Fatal("Expected the change to be handled once the file settled")
+	}
+	if change.Err != nil || !change.Restored || !slices.Equal(change.Missing, []string{"development"}) {
+		t.Fatalf("poll() = %+v, want development restored", change)
+	}
+
+	restored, err := hosts.NewParser(hostsPath).Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse restored file: %v", err)
+	}
+	category := restored.GetCategory("development")
+	if category == nil || len(category.Entries) != 1 || !slices.Equal(category.Entries[0].Hostnames, []string{"app.local", "api.local"}) {
+		t.Errorf("development category not restored: %+v", category)
+	}
+
+	// The watcher's own write must not be handled as another external change
+	if _, ok := w.poll(start.Add(10 * time.Second)); ok {
+		t.Error("Restoring categories should not trigger another reconciliation")
+	}
+}
+
+func TestPollRefillsEmptiedCategory(t *testing.T) {
+	w, hostsPath := createWatcher(t, Options{Debounce: 0})
+
+	writeExternally(t, hostsPath, strings.Replace(managedHosts, "127.0.0.2 ads.example.com\n", "", 1))
+	now := time.Now()
+	w.poll(now)
+	change, ok := w.poll(now)
+	if !ok || !change.Restored || !slices.Equal(change.Missing, []string{"blocked"}) {
+		t.Fatalf("poll() = %+v, %v, want blocked restored", change, ok)
+	}
+
+	restored, err := hosts.NewParser(hostsPath).Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse restored file: %v", err)
+	}
+	count := 0
+	for _, category := range restored.Categories {
+		if category.Name == "blocked" {
+			count++
+		}
+	}
+	if count != 1 || len(restored.GetCategory("blocked").Entries) != 1 {
+		t.Errorf("blocked should be refilled in place, got %d categories", count)
+	}
+}
+
+func TestPollIgnoresUnrelatedChanges(t *testing.T) {
+	w, hostsPath := createWatcher(t, Options{Debounce: 0})
+
+	writeExternally(t, hostsPath, managedHosts+"\n10.0.0.5 printer.lan\n")
+	now := time.Now()
+	w.poll(now)
+	change, ok := w.poll(now)
+	if !ok {
+		t.Fatal("Expected the external change to be handled")
+	}
+	if len(change.Missing) != 0 || change.Restored {
+		t.Errorf("poll() = %+v, want nothing missing", change)
+	}
+}
+
+func TestPollDryRun(t *testing.T) {
+	w, hostsPath := createWatcher(t, Options{Debounce: 0, DryRun: true})
+
+	content := "127.0.0.1 localhost\n"
+	writeExternally(t, hostsPath, content)
+	now := time.Now()
+	w.poll(now)
+	change, ok := w.poll(now)
+	if !ok || change.Restored || !slices.Equal(change.Missing, []string{"development", "blocked"}) {
+		t.Fatalf("poll() = %+v, %v, want both categories reported", change, ok)
+	}
+
+	data, err := os.ReadFile(hostsPath)
+	if err != nil {
+		t.Fatalf("Failed to read hosts file: %v", err)
+	}
+	if string(data) != content {
+		t.Error("Dry run should not modify the hosts file")
+	}
+
+	// The same change is only reported once
+	if _, ok := w.poll(now.Add(time.Second)); ok {
+		t.Error("An already reported change should not be handled again")
+	}
+}
+
+func TestPollWriteError(t *testing.T) {
+	w, hostsPath := createWatcher(t, Options{
+		Debounce: 0,
+		Write: func(*hosts.HostsFile, string) error {
+			return errors.New("permission denied")
+		},
+	})
+
+	writeExternally(t, hostsPath, "127.0.0.1 localhost\n")
+	now := time.Now()
+	w.poll(now)
+	change, _ := w.poll(now)
+	if change.Err == nil || change.Restored {
+		t.Errorf("poll() = %+v, want a write error", change)
+	}
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	w, hostsPath := createWatcher(t, Options{Interval: 10 * time.Millisecond, Debounce: 20 * time.Millisecond})
+
+	changes := make(chan Change, 1)
+	w.opts.OnChange = func(change Change) { changes <- change }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+
+	writeExternally(t, hostsPath, "127.0.0.1 localhost\n")
+	select {
+	case change := <-changes:
+		if !change.Restored {
+			t.Errorf("Run() reported %+v, want categories restored", change)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not handle the external change")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not stop after cancellation")
+	}
+}
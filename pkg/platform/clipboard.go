@@ -0,0 +1,83 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ErrClipboardUnavailable is returned by CopyToClipboard when no known
+// clipboard command is available, e.g. on a headless server
+var ErrClipboardUnavailable = errors.New("no supported clipboard command found")
+
+// runWithInput runs a command with input on stdin; a variable so tests can
+// avoid touching the system clipboard
+var runWithInput = func(input, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(input)
+	return cmd.CombinedOutput()
+}
+
+// ClipboardCommand is a command that copies its standard input to the
+// system clipboard
+type ClipboardCommand struct {
+	Name string
+	Args []string
+}
+
+func (c ClipboardCommand) String() string {
+	return strings.TrimSpace(c.Name + " " + strings.Join(c.Args, " "))
+}
+
+// ClipboardCommands returns the installed clipboard commands for this
+// platform in order of preference. On Linux and other Unix systems wl-copy
+// is only used under Wayland and xclip or xsel only under X11, so a headless
+// system has none.
+func (p *Platform) ClipboardCommands() []ClipboardCommand {
+	var candidates []ClipboardCommand
+	switch p.OS {
+	case "darwin":
+		candidates = []ClipboardCommand{{Name: "pbcopy"}}
+	case "windows":
+		candidates = []ClipboardCommand{{Name: "clip"}}
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, ClipboardCommand{Name: "wl-copy"})
+		}
+		if os.Getenv("DISPLAY") != "" {
+			candidates = append(candidates,
+				ClipboardCommand{Name: "xclip", Args: []string{"-selection", "clipboard"}},
+				ClipboardCommand{Name: "xsel", Args: []string{"--clipboard", "--input"}},
+			)
+		}
+	}
+
+	var commands []ClipboardCommand
+	for _, command := range candidates {
+		if _, err := lookPath(command.Name); err == nil {
+			commands = append(commands, command)
+		}
+	}
+	return commands
+}
+
+// CopyToClipboard copies text to the system clipboard with the first
+// available clipboard command. It returns ErrClipboardUnavailable when there
+// is none.
+func (p *Platform) CopyToClipboard(text string) error {
+	commands := p.ClipboardCommands()
+	if len(commands) == 0 {
+		return ErrClipboardUnavailable
+	}
+
+	command := commands[0]
+	if output, err := runWithInput(text, command.Name, command.Args...); err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%s failed: %w: %s", command, err, msg)
+		}
+		return fmt.Errorf("%s failed: %w", command, err)
+	}
+	return nil
+}
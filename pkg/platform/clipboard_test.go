@@ -0,0 +1,74 @@
+package platform
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestClipboardCommands(t *testing.T) {
+	tests := []struct {
+		name      string
+		os        string
+		wayland   string
+		display   string
+		installed []string
+		expected  []string
+	}{
+		{"macOS", "darwin", "", "", []string{"pbcopy"}, []string{"pbcopy"}},
+		{"windows", "windows", "", "", []string{"clip"}, []string{"clip"}},
+		{"wayland", "linux", "wayland-0", ":0", []string{"wl-copy", "xclip"}, []string{"wl-copy", "xclip -selection clipboard"}},
+		{"x11", "linux", "", ":0", []string{"wl-copy", "xsel"}, []string{"xsel --clipboard --input"}},
+		{"headless", "linux", "", "", []string{"wl-copy", "xclip", "xsel"}, nil},
+		{"nothing installed", "linux", "", ":0", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeCommands(t, tt.installed, "")
+			t.Setenv("WAYLAND_DISPLAY", tt.wayland)
+			t.Setenv("DISPLAY", tt.display)
+
+			var got []string
+			for _, command := range (&Platform{OS: tt.os}).ClipboardCommands() {
+				got = append(got, command.String())
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("ClipboardCommands() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCopyToClipboard(t *testing.T) {
+	fakeCommands(t, []string{"pbcopy"}, "")
+	origRun := runWithInput
+	t.Cleanup(func() { runWithInput = origRun })
+
+	var copied, ran string
+	runWithInput = func(input, name string, args ...string) ([]byte, error) {
+		copied, ran = input, name
+		return nil, nil
+	}
+
+	p := &Platform{OS: "darwin"}
+	if err := p.CopyToClipboard("127.0.0.1 localhost"); err != nil {
+		t.Fatalf("CopyToClipboard() error = %v", err)
+	}
+	if copied != "127.0.0.1 localhost" || ran != "pbcopy" {
+		t.Errorf("CopyToClipboard() ran %q with %q", ran, copied)
+	}
+
+	runWithInput = func(input, name string, args ...string) ([]byte, error) {
+		return []byte("no display"), errors.New("exit status 1")
+	}
+	if err := p.CopyToClipboard("x"); err == nil || err.Error() != "pbcopy failed: exit status 1: no display" {
+		t.Errorf("CopyToClipboard() error = %v, want the command output", err)
+	}
+
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("DISPLAY", "")
+	if err := (&Platform{OS: "linux"}).CopyToClipboard("x"); !errors.Is(err, ErrClipboardUnavailable) {
+		t.Errorf("CopyToClipboard() on a headless system error = %v, want ErrClipboardUnavailable", err)
+	}
+}
@@ -0,0 +1,94 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrDNSFlushUnsupported is returned by FlushDNS when no known cache flush
+// command is available on this system
+var ErrDNSFlushUnsupported = errors.New("no supported DNS cache flush command found")
+
+// lookPath and runCommand are variables so tests can avoid touching the system
+var (
+	lookPath   = exec.LookPath
+	runCommand = func(name string, args ...string) ([]byte, error) {
+		return exec.Command(name, args...).CombinedOutput()
+	}
+)
+
+// DNSFlushCommand is one command run to flush the OS resolver cache
+type DNSFlushCommand struct {
+	Name string
+	Args []string
+}
+
+func (c DNSFlushCommand) String() string {
+	return strings.TrimSpace(c.Name + " " + strings.Join(c.Args, " "))
+}
+
+// DNSFlushCommands returns the commands that flush the resolver cache on this
+// platform, or nil when none are available. On Linux this is resolvectl,
+// falling back to the older systemd-resolve; other resolvers are not
+// supported.
+func (p *Platform) DNSFlushCommands() []DNSFlushCommand {
+	var candidates [][]DNSFlushCommand
+	switch p.OS {
+	case "darwin":
+		candidates = [][]DNSFlushCommand{{
+			{Name: "dscacheutil", Args: []string{"-flushcache"}},
+			{Name: "killall", Args: []string{"-HUP", "mDNSResponder"}},
+		}}
+	case "windows":
+		candidates = [][]DNSFlushCommand{{
+			{Name: "ipconfig", Args: []string{"/flushdns"}},
+		}}
+	case "linux":
+		candidates = [][]DNSFlushCommand{
+			{{Name: "resolvectl", Args: []string{"flush-caches"}}},
+			{{Name: "systemd-resolve", Args: []string{"--flush-caches"}}},
+		}
+	}
+
+	// Use the first set whose commands are all installed
+	for _, commands := range candidates {
+		available := true
+		for _, command := range commands {
+			if _, err := lookPath(command.Name); err != nil {
+				available = false
+				break
+			}
+		}
+		if available {
+			return commands
+		}
+	}
+	return nil
+}
+
+// DNSFlushNeedsElevation reports whether flushing the cache requires root or
+// administrator privileges
+func (p *Platform) DNSFlushNeedsElevation() bool {
+	return p.OS == "darwin"
+}
+
+// FlushDNS runs the platform's cache flush commands in order and returns the
+// commands it ran. It returns ErrDNSFlushUnsupported when there are none.
+func (p *Platform) FlushDNS() ([]DNSFlushCommand, error) {
+	commands := p.DNSFlushCommands()
+	if len(commands) == 0 {
+		return nil, ErrDNSFlushUnsupported
+	}
+
+	for i, command := range commands {
+		if output, err := runCommand(command.Name, command.Args...); err != nil {
+			if msg := strings.TrimSpace(string(output)); msg != "" {
+				return commands[:i], fmt.Errorf("%s failed: %w: %s", command, err, msg)
+			}
+			return commands[:i], fmt.Errorf("%s failed: %w", command, err)
+		}
+	}
+	return commands, nil
+}
@@ -0,0 +1,94 @@
+package platform
+
+import (
+	"errors"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeCommands makes only the named commands look installed and records the
+// commands run
+func fakeCommands(t *testing.T, installed []string, fail string) *[]string {
+	t.Helper()
+	origLookPath, origRun := lookPath, runCommand
+	t.Cleanup(func() { lookPath, runCommand = origLookPath, origRun })
+
+	var ran []string
+	lookPath = func(name string) (string, error) {
+		if slices.Contains(installed, name) {
+			return "/usr/bin/" + name, nil
+		}
+		return "", exec.ErrNotFound
+	}
+	runCommand = func(name string, args ...string) ([]byte, error) {
+		ran = append(ran, strings.TrimSpace(name+" "+strings.Join(args, " ")))
+		if name == fail {
+			return []byte("permission denied"), errors.New("exit status 1")
+		}
+		return nil, nil
+	}
+	return &ran
+}
+
+func TestDNSFlushCommands(t *testing.T) {
+	tests := []struct {
+		name      string
+		os        string
+		installed []string
+		expected  []string
+	}{
+		{"macOS", "darwin", []string{"dscacheutil", "killall"}, []string{"dscacheutil -flushcache", "killall -HUP mDNSResponder"}},
+		{"macOS missing killall", "darwin", []string{"dscacheutil"}, nil},
+		{"windows", "windows", []string{"ipconfig"}, []string{"ipconfig /flushdns"}},
+		{"linux resolvectl", "linux", []string{"resolvectl", "systemd-resolve"}, []string{"resolvectl flush-caches"}},
+		{"linux systemd-resolve", "linux", []string{"systemd-resolve"}, []string{"systemd-resolve --flush-caches"}},
+		{"linux without resolved", "linux", nil, nil},
+		{"unsupported OS", "freebsd", []string{"resolvectl"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeCommands(t, tt.installed, "")
+
+			var got []string
+			for _, command := range (&Platform{OS: tt.os}).DNSFlushCommands() {
+				got = append(got, command.String())
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("DNSFlushCommands() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFlushDNS(t *testing.T) {
+	ran := fakeCommands(t, []string{"dscacheutil", "killall"}, "")
+	p := &Platform{OS: "darwin"}
+
+	commands, err := p.FlushDNS()
+	if err != nil {
+		t.Fatalf("FlushDNS() error = %v", err)
+	}
+	if len(commands) != 2 || !slices.Equal(*ran, []string{"dscacheutil -flushcache", "killall -HUP mDNSResponder"}) {
+		t.Errorf("FlushDNS() ran %v, returned %v", *ran, commands)
+	}
+	if !p.DNSFlushNeedsElevation() {
+		t.Error("Expected macOS cache flushing to need elevation")
+	}
+
+	ran = fakeCommands(t, []string{"dscacheutil", "killall"}, "dscacheutil")
+	commands, err = p.FlushDNS()
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("FlushDNS() error = %v, want the command output", err)
+	}
+	if len(commands) != 0 || len(*ran) != 1 {
+		t.Errorf("FlushDNS() should stop at the failing command, ran %v", *ran)
+	}
+
+	fakeCommands(t, nil, "")
+	if _, err := (&Platform{OS: "linux"}).FlushDNS(); !errors.Is(err, ErrDNSFlushUnsupported) {
+		t.Errorf("FlushDNS() error = %v, want ErrDNSFlushUnsupported", err)
+	}
+}
@@ -10,6 +10,19 @@ import (
 	"time"
 )
 
+// HostsFileEnv names the environment variable that overrides the hosts file path
+const HostsFileEnv = "HOSTS_MANAGER_HOSTS_FILE"
+
+// wslWindowsHostsPath is where WSL mounts the Windows hosts file by default
+const wslWindowsHostsPath = "/mnt/c/Windows/System32/drivers/etc/hosts"
+
+// procVersionPath is read to detect WSL; a variable so tests can replace it
+var procVersionPath = "/proc/version"
+
+// hostsFileOverride replaces the OS hosts file path for every Platform
+// created by New once set
+var hostsFileOverride string
+
 type Platform struct {
 	OS       string
 	HostsDir string
@@ -22,7 +35,49 @@ func New() *Platform {
 	}
 }
 
+// SetHostsFileOverride makes New use path as the hosts file instead of the
+// OS default. The path must be absolute and either an existing regular file
+// or creatable in an existing directory. An empty path clears the override.
+func SetHostsFileOverride(path string) error {
+	if path == "" {
+		hostsFileOverride = ""
+		return nil
+	}
+	if err := validateHostsFileOverride(path); err != nil {
+		return err
+	}
+	hostsFileOverride = filepath.Clean(path)
+	return nil
+}
+
+func validateHostsFileOverride(path string) error {
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("hosts file override %q must be an absolute path", path)
+	}
+
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("hosts file override %q is not a regular file", path)
+		}
+		return nil
+	case !os.IsNotExist(err):
+		return fmt.Errorf("cannot access hosts file override %q: %w", path, err)
+	}
+
+	dir, err := os.Stat(filepath.Dir(path))
+	if err != nil || !dir.IsDir() {
+		return fmt.Errorf("hosts file override %q cannot be created: directory %s does not exist", path, filepath.Dir(path))
+	}
+	return nil
+}
+
 func getHostsPath() string {
+	if hostsFileOverride != "" {
+		return hostsFileOverride
+	}
+
 	switch runtime.GOOS {
 	case "windows":
 		return `C:\Windows\System32\drivers\etc\hosts`
@@ -55,6 +110,11 @@ func (p *Platform) ElevateIfNeeded() error {
 		return nil
 	}
 
+	// Linux privileges do not apply to files on the Windows side
+	if p.HostsDir == wslWindowsHostsPath && p.IsWSL() {
+		return fmt.Errorf("cannot write the Windows hosts file at %s from WSL - sudo does not help here; start your WSL terminal from an elevated (Run as administrator) Windows session", p.HostsDir)
+	}
+
 	// Check if already elevated but still no write permission (other issue)
 	if p.IsElevated() {
 		return fmt.Errorf("elevated privileges detected but still cannot write to hosts file at %s - check file permissions or disk space", p.HostsDir)
@@ -92,6 +152,24 @@ func (p *Platform) ElevateIfNeededStrict() error {
 	return nil
 }
 
+// IsWSL reports whether we are running under the Windows Subsystem for Linux
+func (p *Platform) IsWSL() bool {
+	if p.OS != "linux" {
+		return false
+	}
+	data, err := os.ReadFile(procVersionPath)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(data)), "microsoft")
+}
+
+// GetWindowsHostsPathFromWSL returns the Windows hosts file as seen from WSL.
+// Edits to /etc/hosts inside WSL do not affect Windows name resolution.
+func (p *Platform) GetWindowsHostsPathFromWSL() string {
+	return wslWindowsHostsPath
+}
+
 func (p *Platform) CreateBackupPath(timestamp string) string {
 	switch runtime.GOOS {
 	case "windows":
@@ -474,3 +474,92 @@ func TestSanitizePathEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestSetHostsFileOverride(t *testing.T) {
+	t.Cleanup(func() { _ = SetHostsFileOverride("") })
+	defaultPath := New().GetHostsFilePath()
+
+	tempDir := t.TempDir()
+	existing := filepath.Join(tempDir, "hosts")
+	if err := os.WriteFile(existing, []byte("127.0.0.1 localhost\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		expectError bool
+	}{
+		{"existing file", existing, false},
+		{"creatable file", filepath.Join(tempDir, "new-hosts"), false},
+		{"relative path", "hosts", true},
+		{"directory", tempDir, true},
+		{"missing directory", filepath.Join(tempDir, "missing", "hosts"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() { _ = SetHostsFileOverride("") })
+
+			err := SetHostsFileOverride(tt.path)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("SetHostsFileOverride(%q) error = %v, expectError %v", tt.path, err, tt.expectError)
+			}
+
+			want := defaultPath
+			if !tt.expectError {
+				want = tt.path
+			}
+			if got := New().GetHostsFilePath(); got != want {
+				t.Errorf("GetHostsFilePath() = %q, want %q", got, want)
+			}
+		})
+	}
+
+	if err := SetHostsFileOverride(""); err != nil {
+		t.Fatalf("Clearing the override failed: %v", err)
+	}
+	if got := New().GetHostsFilePath(); got != defaultPath {
+		t.Errorf("Expected default path after clearing the override, got %q", got)
+	}
+}
+
+func TestIsWSL(t *testing.T) {
+	original := procVersionPath
+	t.Cleanup(func() { procVersionPath = original })
+
+	tests := []struct {
+		name     string
+		os       string
+		version  string
+		expected bool
+	}{
+		{"WSL2", "linux", "Linux version 5.15.90.1-microsoft-standard-WSL2 (gcc)", true},
+		{"WSL1", "linux", "Linux version 4.4.0-19041-Microsoft (Microsoft@Microsoft.com)", true},
+		{"native linux", "linux", "Linux version 6.8.0-45-generic (buildd@lcy02-amd64)", false},
+		{"not linux", "darwin", "Linux version 5.15.90.1-microsoft-standard-WSL2", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			procVersionPath = filepath.Join(t.TempDir(), "version")
+			if err := os.WriteFile(procVersionPath, []byte(tt.version), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			p := &Platform{OS: tt.os}
+			if got := p.IsWSL(); got != tt.expected {
+				t.Errorf("IsWSL() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	procVersionPath = filepath.Join(t.TempDir(), "missing")
+	if (&Platform{OS: "linux"}).IsWSL() {
+		t.Error("Expected IsWSL() to be false when /proc/version is unreadable")
+	}
+
+	if got := New().GetWindowsHostsPathFromWSL(); got != "/mnt/c/Windows/System32/drivers/etc/hosts" {
+		t.Errorf("GetWindowsHostsPathFromWSL() = %q", got)
+	}
+}
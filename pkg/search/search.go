@@ -1,6 +1,8 @@
 package search
 
 import (
+	"fmt"
+	"net"
 	"sort"
 	"strings"
 
@@ -11,11 +13,33 @@ type Result struct {
 	Entry hosts.Entry `json:"entry"`
 	Score float64     `json:"score"`
 	Match string      `json:"match"`
+	// MatchStart and MatchEnd are the byte range of Match covering the matched
+	// characters, and MatchPositions the byte offset of each one. Fuzzy
+	// matches may leave gaps between positions.
+	MatchStart     int   `json:"match_start"`
+	MatchEnd       int   `json:"match_end"`
+	MatchPositions []int `json:"match_positions,omitempty"`
+}
+
+func newResult(entry hosts.Entry, score float64, match string, positions []int) Result {
+	result := Result{
+		Entry:          entry,
+		Score:          score,
+		Match:          match,
+		MatchPositions: positions,
+	}
+	if len(positions) > 0 {
+		result.MatchStart = positions[0]
+		result.MatchEnd = positions[len(positions)-1] + 1
+	}
+	return result
 }
 
 type Searcher struct {
 	caseSensitive bool
 	fuzzy         bool
+	wholeWord     bool
+	minScore      float64
 }
 
 func NewSearcher(caseSensitive, fuzzy bool) *Searcher {
@@ -25,6 +49,22 @@ func NewSearcher(caseSensitive, fuzzy bool) *Searcher {
 	}
 }
 
+// SetWholeWord restricts matches to complete hostname labels (or IP octets and
+// comment words), so "api" matches api.dev but not rapidapi.dev. Whole-word
+// matching takes precedence over fuzzy matching.
+func (s *Searcher) SetWholeWord(wholeWord bool) {
+	s.wholeWord = wholeWord
+}
+
+// SetMinScore drops search results scoring below minScore. Scores range
+// from 0 to 1; the default of 0 keeps every match.
+func (s *Searcher) SetMinScore(minScore float64) {
+	s.minScore = minScore
+}
+
+// Search scores every entry against query and returns the matches, best
+// first. Entries with equal scores keep their file order, so results are
+// deterministic. See Score for how entries are scored.
 func (s *Searcher) Search(hostsFile *hosts.HostsFile, query string) []Result {
 	if query == "" {
 		return []Result{}
@@ -34,30 +74,41 @@ func (s *Searcher) Search(hostsFile *hosts.HostsFile, query string) []Result {
 
 	for _, category := range hostsFile.Categories {
 		for _, entry := range category.Entries {
-			if score, match := s.scoreEntry(entry, query); score > 0 {
-				results = append(results, Result{
-					Entry: entry,
-					Score: score,
-					Match: match,
-				})
+			if score, match, positions := s.scoreEntry(entry, query); score > 0 && score >= s.minScore {
+				results = append(results, newResult(entry, score, match, positions))
 			}
 		}
 	}
 
-	sort.Slice(results, func(i, j int) bool {
+	sort.SliceStable(results, func(i, j int) bool {
 		return results[i].Score > results[j].Score
 	})
 
 	return results
 }
 
-func (s *Searcher) scoreEntry(entry hosts.Entry, query string) (float64, string) {
+// Score returns how well entry matches query, from 0 (no match) to 1. Each
+// hostname and the IP are scored in full, tags at 0.8 of their score and the
+// comment at 0.5, and the best of these is the entry's score.
+//
+// Exact mode scores 1 for equality, 0.9 for a prefix, 0.8 for an equal word,
+// 0.7 for a substring, and 0.6 for a word prefix. Fuzzy mode scores
+// 1 - levenshtein/longer length, averaged with 0.8 when the query is a
+// substring and then with 0.9 when it is a prefix. Whole-word mode scores 1
+// when every label matches, 0.9 for leading labels and 0.8 for inner ones.
+func (s *Searcher) Score(entry hosts.Entry, query string) float64 {
+	score, _, _ := s.scoreEntry(entry, query)
+	return score
+}
+
+func (s *Searcher) scoreEntry(entry hosts.Entry, query string) (float64, string, []int) {
 	if !s.caseSensitive {
 		query = strings.ToLower(query)
 	}
 
 	maxScore := 0.0
 	bestMatch := ""
+	var bestPositions []int
 
 	for _, hostname := range entry.Hostnames {
 		searchText := hostname
@@ -65,16 +116,12 @@ func (s *Searcher) scoreEntry(entry hosts.Entry, query string) (float64, string)
 			searchText = strings.ToLower(hostname)
 		}
 
-		var score float64
-		if s.fuzzy {
-			score = s.fuzzyMatch(searchText, query)
-		} else {
-			score = s.exactMatch(searchText, query)
-		}
+		score := s.match(searchText, query)
 
 		if score > maxScore {
 			maxScore = score
 			bestMatch = hostname
+			bestPositions = s.locateMatch(searchText, query, isLabelBoundary)
 		}
 	}
 
@@ -83,16 +130,27 @@ func (s *Searcher) scoreEntry(entry hosts.Entry, query string) (float64, string)
 		ipSearchText = strings.ToLower(entry.IP)
 	}
 
-	var ipScore float64
-	if s.fuzzy {
-		ipScore = s.fuzzyMatch(ipSearchText, query)
-	} else {
-		ipScore = s.exactMatch(ipSearchText, query)
-	}
+	ipScore := s.match(ipSearchText, query)
 
 	if ipScore > maxScore {
 		maxScore = ipScore
 		bestMatch = entry.IP
+		bestPositions = s.locateMatch(ipSearchText, query, isLabelBoundary)
+	}
+
+	for _, tag := range entry.Tags {
+		tagSearchText := tag
+		if !s.caseSensitive {
+			tagSearchText = strings.ToLower(tag)
+		}
+
+		tagScore := s.match(tagSearchText, query) * 0.8
+
+		if tagScore > maxScore {
+			maxScore = tagScore
+			bestMatch = tag
+			bestPositions = s.locateMatch(tagSearchText, query, isLabelBoundary)
+		}
 	}
 
 	if entry.Comment != "" {
@@ -102,7 +160,9 @@ func (s *Searcher) scoreEntry(entry hosts.Entry, query string) (float64, string)
 		}
 
 		var commentScore float64
-		if s.fuzzy {
+		if s.wholeWord {
+			commentScore = s.wholeWordMatch(strings.Fields(commentSearchText), []string{query}) * 0.5
+		} else if s.fuzzy {
 			commentScore = s.fuzzyMatch(commentSearchText, query) * 0.5
 		} else {
 			commentScore = s.exactMatch(commentSearchText, query) * 0.5
@@ -111,10 +171,150 @@ func (s *Searcher) scoreEntry(entry hosts.Entry, query string) (float64, string)
 		if commentScore > maxScore {
 			maxScore = commentScore
 			bestMatch = entry.Comment
+			bestPositions = s.locateMatch(commentSearchText, query, isWordBoundary)
 		}
 	}
 
-	return maxScore, bestMatch
+	return maxScore, bestMatch, bestPositions
+}
+
+// locateMatch returns the byte offsets in text of the characters that matched
+// query: a whole-word or substring occurrence when there is one, otherwise
+// (in fuzzy mode) the characters of the best alignment
+func (s *Searcher) locateMatch(text, query string, boundary func(byte) bool) []int {
+	var start int
+	if s.wholeWord {
+		start = indexWholeWord(text, query, boundary)
+	} else {
+		start = strings.Index(text, query)
+	}
+	if start >= 0 {
+		return spanPositions(start, start+len(query))
+	}
+
+	if s.fuzzy && !s.wholeWord {
+		return alignedPositions(text, query)
+	}
+	return nil
+}
+
+func spanPositions(start, end int) []int {
+	positions := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		positions = append(positions, i)
+	}
+	return positions
+}
+
+// indexWholeWord finds the first occurrence of query in text bounded on both
+// sides by the string edges or a boundary character
+func indexWholeWord(text, query string, boundary func(byte) bool) int {
+	if query == "" {
+		return -1
+	}
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], query)
+		if i < 0 {
+			return -1
+		}
+		start, end := offset+i, offset+i+len(query)
+		if (start == 0 || boundary(text[start-1])) && (end == len(text) || boundary(text[end])) {
+			return start
+		}
+		offset = start + 1
+	}
+	return -1
+}
+
+func isLabelBoundary(c byte) bool {
+	return c == '.'
+}
+
+func isWordBoundary(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// alignedPositions returns the offsets in text of a longest common
+// subsequence with query, preferring the earliest alignment
+func alignedPositions(text, query string) []int {
+	n, m := len(text), len(query)
+	if n == 0 || m == 0 {
+		return nil
+	}
+
+	// lcs[i][j] is the LCS length of text[i:] and query[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if text[i] == query[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var positions []int
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case text[i] == query[j]:
+			positions = append(positions, i)
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return positions
+}
+
+// match scores a hostname or IP against query using the configured mode
+func (s *Searcher) match(text, query string) float64 {
+	switch {
+	case s.wholeWord:
+		return s.wholeWordMatch(strings.Split(text, "."), strings.Split(query, "."))
+	case s.fuzzy:
+		return s.fuzzyMatch(text, query)
+	default:
+		return s.exactMatch(text, query)
+	}
+}
+
+// wholeWordMatch scores queryTokens against a run of consecutive tokens, so a
+// query like "api.dev" must line up with whole labels; partial labels never match
+func (s *Searcher) wholeWordMatch(tokens, queryTokens []string) float64 {
+	if len(queryTokens) > len(tokens) {
+		return 0.0
+	}
+
+	for start := 0; start+len(queryTokens) <= len(tokens); start++ {
+		matched := true
+		for i, queryToken := range queryTokens {
+			if tokens[start+i] != queryToken {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		switch {
+		case len(queryTokens) == len(tokens):
+			return 1.0
+		case start == 0:
+			return 0.9
+		default:
+			return 0.8
+		}
+	}
+
+	return 0.0
 }
 
 func (s *Searcher) exactMatch(text, query string) float64 {
@@ -230,28 +430,80 @@ func (s *Searcher) SearchByCategory(hostsFile *hosts.HostsFile, query, category
 	return filtered
 }
 
-func (s *Searcher) SearchByIP(hostsFile *hosts.HostsFile, ip string) []Result {
-	var results []Result
+// FilterByTag keeps the results whose entry carries tag, compared
+// case-insensitively.
+func FilterByTag(results []Result, tag string) []Result {
+	var filtered []Result
+	for _, result := range results {
+		if result.Entry.HasTag(tag) {
+			filtered = append(filtered, result)
+		}
+	}
+
+	return filtered
+}
+
+// FilterByEnabled keeps the results whose entry is enabled, or disabled when
+// enabled is false.
+func FilterByEnabled(results []Result, enabled bool) []Result {
+	var filtered []Result
+	for _, result := range results {
+		if result.Entry.Enabled == enabled {
+			filtered = append(filtered, result)
+		}
+	}
+
+	return filtered
+}
 
+// SearchByTag returns every entry carrying tag in file order.
+func (s *Searcher) SearchByTag(hostsFile *hosts.HostsFile, tag string) []Result {
+	var results []Result
 	for _, category := range hostsFile.Categories {
 		for _, entry := range category.Entries {
-			entryIP := entry.IP
-			if !s.caseSensitive {
-				entryIP = strings.ToLower(entry.IP)
-				ip = strings.ToLower(ip)
+			for _, t := range entry.Tags {
+				if strings.EqualFold(t, tag) {
+					results = append(results, newResult(entry, 1.0, t, spanPositions(0, len(t))))
+					break
+				}
 			}
+		}
+	}
+
+	return results
+}
 
-			if entryIP == ip {
-				results = append(results, Result{
-					Entry: entry,
-					Score: 1.0,
-					Match: entry.IP,
-				})
+// SearchByIP returns every entry mapped to exactly ip, in file order.
+// Addresses are compared after normalization, so 10.0.0.1 does not match
+// 10.0.0.10 and letter case never matters.
+func (s *Searcher) SearchByIP(hostsFile *hosts.HostsFile, ip string) []Result {
+	var results []Result
+	for _, entry := range hostsFile.GetEntriesByIP(ip) {
+		results = append(results, newResult(entry, 1.0, entry.IP, spanPositions(0, len(entry.IP))))
+	}
+	return results
+}
+
+// SearchByCIDR returns every entry whose IP falls inside cidr, which may be an
+// IPv4 or IPv6 range. Entries are returned in file order.
+func (s *Searcher) SearchByCIDR(hostsFile *hosts.HostsFile, cidr string) ([]Result, error) {
+	_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+	}
+
+	var results []Result
+	for _, category := range hostsFile.Categories {
+		for _, entry := range category.Entries {
+			addr, _ := hosts.SplitZone(entry.IP)
+			ip := net.ParseIP(hosts.NormalizeIP(addr))
+			if ip != nil && network.Contains(ip) {
+				results = append(results, newResult(entry, 1.0, entry.IP, spanPositions(0, len(entry.IP))))
 			}
 		}
 	}
 
-	return results
+	return results, nil
 }
 
 func (s *Searcher) SearchByHostname(hostsFile *hosts.HostsFile, hostname string) []Result {
@@ -267,19 +519,10 @@ func (s *Searcher) SearchByHostname(hostsFile *hosts.HostsFile, hostname string)
 					queryText = strings.ToLower(hostname)
 				}
 
-				var score float64
-				if s.fuzzy {
-					score = s.fuzzyMatch(searchText, queryText)
-				} else {
-					score = s.exactMatch(searchText, queryText)
-				}
+				score := s.match(searchText, queryText)
 
 				if score > 0 {
-					results = append(results, Result{
-						Entry: entry,
-						Score: score,
-						Match: h,
-					})
+					results = append(results, newResult(entry, score, h, s.locateMatch(searchText, queryText, isLabelBoundary)))
 				}
 			}
 		}
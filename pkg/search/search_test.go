@@ -810,3 +810,267 @@ func TestSearchEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchWholeWord(t *testing.T) {
+	hostsFile := &hosts.HostsFile{
+		Categories: []hosts.Category{
+			{
+				Name:    "development",
+				Enabled: true,
+				Entries: []hosts.Entry{
+					{IP: "192.168.1.10", Hostnames: []string{"api.dev"}, Category: "development", Enabled: true},
+					{IP: "192.168.1.11", Hostnames: []string{"rapidapi.dev"}, Category: "development", Enabled: true},
+					{IP: "192.168.1.12", Hostnames: []string{"v2.api.dev"}, Category: "development", Enabled: true},
+					{IP: "192.168.1.13", Hostnames: []string{"apis.dev"}, Comment: "api gateway", Category: "development", Enabled: true},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{"single label", "api", []string{"api.dev", "v2.api.dev", "apis.dev"}},
+		{"multiple labels", "api.dev", []string{"api.dev", "v2.api.dev"}},
+		{"partial label", "pi.de", nil},
+		{"ip octets", "192.168.1.11", []string{"rapidapi.dev"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Fuzzy is enabled to confirm whole-word takes precedence
+			searcher := NewSearcher(false, true)
+			searcher.SetWholeWord(true)
+			results := searcher.Search(hostsFile, tt.query)
+
+			var got []string
+			for _, result := range results {
+				got = append(got, result.Entry.Hostnames[0])
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Search(%q) = %v, want %v", tt.query, got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Search(%q)[%d] = %s, want %s", tt.query, i, got[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSearchByCIDR(t *testing.T) {
+	hostsFile := createTestHostsFile()
+	hostsFile.Categories = append(hostsFile.Categories, hosts.Category{
+		Name:    "ipv6",
+		Enabled: true,
+		Entries: []hosts.Entry{
+			{IP: "fd00::10", Hostnames: []string{"ula.local"}, Category: "ipv6", Enabled: true},
+			{IP: "2001:db8::1", Hostnames: []string{"doc.example"}, Category: "ipv6", Enabled: true},
+		},
+	})
+	searcher := NewSearcher(false, false)
+
+	tests := []struct {
+		cidr     string
+		expected []string
+	}{
+		{"192.168.1.0/24", []string{"192.168.1.100"}},
+		{"127.0.0.0/8", []string{"127.0.0.1"}},
+		{"0.0.0.0/0", []string{"127.0.0.1", "192.168.1.100", "203.0.113.1", "198.51.100.50"}},
+		{"fd00::/8", []string{"fd00::10"}},
+		{"2001:db8::/32", []string{"2001:db8::1"}},
+		{"10.0.0.0/8", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cidr, func(t *testing.T) {
+			results, err := searcher.SearchByCIDR(hostsFile, tt.cidr)
+			if err != nil {
+				t.Fatalf("SearchByCIDR() error = %v", err)
+			}
+
+			var got []string
+			for _, result := range results {
+				got = append(got, result.Entry.IP)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("SearchByCIDR(%s) = %v, want %v", tt.cidr, got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("SearchByCIDR(%s)[%d] = %s, want %s", tt.cidr, i, got[i], tt.expected[i])
+				}
+			}
+		})
+	}
+
+	for _, invalid := range []string{"192.168.1.0", "192.168.1.0/33", "not-a-cidr"} {
+		if _, err := searcher.SearchByCIDR(hostsFile, invalid); err == nil {
+			t.Errorf("SearchByCIDR(%q) expected error, got nil", invalid)
+		}
+	}
+}
+
+func TestSearchByIPIsExact(t *testing.T) {
+	hostsFile := createTestHostsFile()
+	hostsFile.Categories = append(hostsFile.Categories, hosts.Category{
+		Name:    "lan",
+		Enabled: true,
+		Entries: []hosts.Entry{
+			{IP: "10.0.0.1", Hostnames: []string{"gateway.lan"}, Category: "lan", Enabled: true},
+			{IP: "10.0.0.10", Hostnames: []string{"nas.lan"}, Category: "lan", Enabled: true},
+		},
+	})
+	searcher := NewSearcher(false, true)
+
+	results := searcher.SearchByIP(hostsFile, "10.0.0.1")
+	if len(results) != 1 || results[0].Entry.Hostnames[0] != "gateway.lan" {
+		t.Fatalf("SearchByIP(10.0.0.1) = %+v, want only gateway.lan", results)
+	}
+	if results[0].Score != 1.0 || results[0].Match != "10.0.0.1" {
+		t.Errorf("SearchByIP() result = %+v, want an exact match on the IP", results[0])
+	}
+
+	if results := searcher.SearchByIP(hostsFile, "10.0.0"); len(results) != 0 {
+		t.Errorf("SearchByIP(10.0.0) = %+v, want no results", results)
+	}
+}
+
+func TestSearchTags(t *testing.T) {
+	hostsFile := createTestHostsFile()
+	hostsFile.Categories[0].Entries[0].Tags = []string{"frontend", "team-web"}
+	hostsFile.Categories[1].Entries[0].Tags = []string{"Frontend"}
+	searcher := NewSearcher(false, false)
+
+	results := searcher.Search(hostsFile, "team-web")
+	if len(results) != 1 || results[0].Entry.IP != "127.0.0.1" || results[0].Match != "team-web" {
+		t.Fatalf("Search(team-web) = %+v, want the tagged 127.0.0.1 entry", results)
+	}
+
+	byTag := searcher.SearchByTag(hostsFile, "FRONTEND")
+	if len(byTag) != 2 || byTag[0].Entry.IP != "127.0.0.1" || byTag[1].Entry.IP != "203.0.113.1" {
+		t.Fatalf("SearchByTag(FRONTEND) = %+v, want both frontend entries in file order", byTag)
+	}
+
+	filtered := FilterByTag(searcher.Search(hostsFile, "api"), "frontend")
+	if len(filtered) != 1 || filtered[0].Entry.IP != "203.0.113.1" {
+		t.Fatalf("FilterByTag(api, frontend) = %+v, want only 203.0.113.1", filtered)
+	}
+
+	if got := searcher.SearchByTag(hostsFile, "missing"); len(got) != 0 {
+		t.Errorf("SearchByTag(missing) = %+v, want no results", got)
+	}
+}
+
+func TestFilterByEnabled(t *testing.T) {
+	hostsFile := createTestHostsFile()
+	searcher := NewSearcher(false, false)
+	results := searcher.Search(hostsFile, "example.com")
+	if len(results) != 2 {
+		t.Fatalf("Search(example.com) = %d results, want 2", len(results))
+	}
+
+	enabled := FilterByEnabled(results, true)
+	if len(enabled) != 1 || enabled[0].Entry.IP != "203.0.113.1" {
+		t.Errorf("FilterByEnabled(true) = %+v, want only 203.0.113.1", enabled)
+	}
+
+	disabled := FilterByEnabled(results, false)
+	if len(disabled) != 1 || disabled[0].Entry.IP != "198.51.100.50" {
+		t.Errorf("FilterByEnabled(false) = %+v, want only 198.51.100.50", disabled)
+	}
+}
+
+func TestSearchMinScore(t *testing.T) {
+	hostsFile := createTestHostsFile()
+	searcher := NewSearcher(false, true)
+	entry := hostsFile.Categories[0].Entries[1]
+
+	if score := searcher.Score(entry, "backnd.local"); score < 0.3 {
+		t.Errorf("Score(backnd.local) = %.2f, want a near-exact typo at or above 0.3", score)
+	}
+	if score := searcher.Score(entry, "zqxwvy"); score >= 0.3 {
+		t.Errorf("Score(zqxwvy) = %.2f, want an unrelated term below 0.3", score)
+	}
+
+	if results := searcher.Search(hostsFile, "zqxwvy"); len(results) == 0 {
+		t.Fatal("Search(zqxwvy) without a threshold should return weak fuzzy matches")
+	}
+
+	searcher.SetMinScore(0.3)
+	if results := searcher.Search(hostsFile, "zqxwvy"); len(results) != 0 {
+		t.Errorf("Search(zqxwvy) with min score 0.3 = %+v, want no results", results)
+	}
+	results := searcher.Search(hostsFile, "backnd.local")
+	if len(results) == 0 || results[0].Entry.IP != "192.168.1.100" {
+		t.Errorf("Search(backnd.local) with min score 0.3 = %+v, want 192.168.1.100 first", results)
+	}
+	for _, result := range results {
+		if result.Score < 0.3 {
+			t.Errorf("Result %s scored %.2f, below the threshold", result.Entry.IP, result.Score)
+		}
+	}
+}
+
+func TestSearchDeterministicOrder(t *testing.T) {
+	hostsFile := createTestHostsFile()
+	searcher := NewSearcher(false, false)
+
+	// Both api entries score 0.9 as a prefix match, so file order decides
+	for i := 0; i < 20; i++ {
+		results := searcher.Search(hostsFile, "api")
+		if len(results) != 2 || results[0].Entry.IP != "192.168.1.100" || results[1].Entry.IP != "203.0.113.1" {
+			t.Fatalf("Search(api) = %+v, want equal scores in file order", results)
+		}
+	}
+}
+
+func TestSearchMatchPositions(t *testing.T) {
+	hostsFile := &hosts.HostsFile{
+		Categories: []hosts.Category{
+			{
+				Name: "development",
+				Entries: []hosts.Entry{
+					{IP: "192.168.1.10", Hostnames: []string{"MyApi.dev"}, Category: "development", Enabled: true},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		searcher  *Searcher
+		query     string
+		positions []int
+	}{
+		{"substring", NewSearcher(false, false), "api", []int{2, 3, 4}},
+		{"ip substring", NewSearcher(false, false), "168.1", []int{4, 5, 6, 7, 8}},
+		{"fuzzy alignment", NewSearcher(false, true), "mydev", []int{0, 1, 6, 7, 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := tt.searcher.Search(hostsFile, tt.query)
+			if len(results) != 1 {
+				t.Fatalf("Search(%q) returned %d results", tt.query, len(results))
+			}
+
+			result := results[0]
+			if len(result.MatchPositions) != len(tt.positions) {
+				t.Fatalf("MatchPositions = %v, want %v", result.MatchPositions, tt.positions)
+			}
+			for i := range tt.positions {
+				if result.MatchPositions[i] != tt.positions[i] {
+					t.Errorf("MatchPositions = %v, want %v", result.MatchPositions, tt.positions)
+					break
+				}
+			}
+			if result.MatchStart != tt.positions[0] || result.MatchEnd != tt.positions[len(tt.positions)-1]+1 {
+				t.Errorf("MatchStart/End = %d/%d, want %d/%d", result.MatchStart, result.MatchEnd, tt.positions[0], tt.positions[len(tt.positions)-1]+1)
+			}
+		})
+	}
+}